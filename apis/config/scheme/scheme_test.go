@@ -98,6 +98,7 @@ profiles:
 							Args: &config.CoschedulingArgs{
 								PermitWaitingTimeSeconds: 60,
 								PodGroupRejectPercentage: 10,
+								GangOrderingPolicy:       ptr.To(config.GangOrderingTimestamp),
 							},
 						},
 						{
@@ -378,10 +379,14 @@ profiles:
 - pluginConfig:
   - args:
       apiVersion: kubescheduler.config.k8s.io/v1
+      enableGangPreemption: false
+      enableGangReservation: false
+      gangAgingSeconds: 0
       kind: CoschedulingArgs
       permitWaitingTimeSeconds: 10
       podGroupBackoffSeconds: 0
       podGroupRejectPercentage: 0
+      simulatePodGroupPlacement: false
     name: Coscheduling
   - args:
       apiVersion: kubescheduler.config.k8s.io/v1
@@ -39,8 +39,76 @@ type CoschedulingArgs struct {
 	// Default: 10 (10%). Set to 0 to always reject on any failure.
 	// Set to 100 to never reject (disable PostFilter group rejection).
 	PodGroupRejectPercentage int32
+	// SimulatePodGroupPlacement enables PreFilter to simulate packing all pending members of a
+	// PodGroup onto the cluster, honoring their requests, node selectors, node affinity and taints,
+	// instead of only comparing the PodGroup's minResources with the free resources of the cluster.
+	SimulatePodGroupPlacement bool
+	// EnableGangPreemption enables PostFilter to preempt lower-priority pods on behalf of the whole
+	// PodGroup, so that the remaining pods needed to reach minMember fit at once. Nothing is preempted
+	// if they cannot all be placed.
+	EnableGangPreemption bool
+	// EnableGangReservation makes the scheduler set capacity aside for the remaining members of a
+	// PodGroup while some of its members wait in Permit, hiding it from the pods outside the PodGroup
+	// in Filter. The reservation is released once the PodGroup reaches its quorum or is rejected, or
+	// after its schedule timeout.
+	EnableGangReservation bool
+	// GangOrderingPolicy orders the pods of the same priority in the scheduling queue before the
+	// creation or last failure timestamps of their PodGroups do.
+	GangOrderingPolicy *GangOrderingPolicy
+	// GangAgingSeconds, if positive, raises a PodGroup one level for every GangAgingSeconds it waits
+	// since its creation. PodGroups of higher levels go first, whatever the GangOrderingPolicy and
+	// their last failures.
+	GangAgingSeconds int64
 }
 
+// GangOrderingPolicy is a "string" type.
+type GangOrderingPolicy string
+
+const (
+	// GangOrderingTimestamp orders PodGroups by their creation or last failure timestamps only.
+	GangOrderingTimestamp GangOrderingPolicy = "Timestamp"
+	// GangOrderingDominantResourceFairness orders first the PodGroups of the namespaces with the lowest
+	// dominant share, of their ElasticQuota min if any, or else of the cluster.
+	GangOrderingDominantResourceFairness GangOrderingPolicy = "DominantResourceFairness"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CapacitySchedulingArgs defines the parameters for CapacityScheduling plugin.
+type CapacitySchedulingArgs struct {
+	metav1.TypeMeta
+
+	// AccountingPolicy tells which pods count towards the usage of their ElasticQuota. It should match
+	// the accounting policy of the ElasticQuota controller, so that both agree on the usage.
+	AccountingPolicy *ElasticQuotaAccountingPolicy
+
+	// BorrowingPolicy tells how the quotas borrow the min other quotas leave unused.
+	BorrowingPolicy *ElasticQuotaBorrowingPolicy
+}
+
+// ElasticQuotaAccountingPolicy is a "string" type.
+type ElasticQuotaAccountingPolicy string
+
+const (
+	// ElasticQuotaAccountingBoundNonTerminal counts the pods bound to a node which have not terminated,
+	// including the ones still pulling images or creating containers.
+	ElasticQuotaAccountingBoundNonTerminal ElasticQuotaAccountingPolicy = "BoundNonTerminal"
+	// ElasticQuotaAccountingRunning counts the pods in the Running phase only.
+	ElasticQuotaAccountingRunning ElasticQuotaAccountingPolicy = "Running"
+)
+
+// ElasticQuotaBorrowingPolicy is a "string" type.
+type ElasticQuotaBorrowingPolicy string
+
+const (
+	// ElasticQuotaBorrowingFirstCome lets any quota borrow up to its max, as long as the usage of all the
+	// quotas stays within their total min.
+	ElasticQuotaBorrowingFirstCome ElasticQuotaBorrowingPolicy = "FirstCome"
+	// ElasticQuotaBorrowingFairShare splits the min left unused between the quotas borrowing it in
+	// proportion to their weight, by their dominant share of it.
+	ElasticQuotaBorrowingFairShare ElasticQuotaBorrowingPolicy = "FairShare"
+)
+
 // ModeType is a "string" type.
 type ModeType string
 
@@ -199,6 +267,23 @@ const (
 	CacheResyncScopeOnlyResources CacheResyncScope = "OnlyResources"
 )
 
+// CacheNRTValidationMode is a "string" type
+type CacheNRTValidationMode string
+
+const (
+	CacheNRTValidationNone       CacheNRTValidationMode = "None"
+	CacheNRTValidationWarn       CacheNRTValidationMode = "Warn"
+	CacheNRTValidationQuarantine CacheNRTValidationMode = "Quarantine"
+)
+
+// CacheGangAccountingMode is a "string" type
+type CacheGangAccountingMode string
+
+const (
+	CacheGangAccountingPessimistic CacheGangAccountingMode = "Pessimistic"
+	CacheGangAccountingPerNUMACell CacheGangAccountingMode = "PerNUMACell"
+)
+
 // NodeResourceTopologyCache define configuration details for the NodeResourceTopology cache.
 type NodeResourceTopologyCache struct {
 	// ForeignPodsDetect sets how foreign pods should be handled.
@@ -226,6 +311,26 @@ type NodeResourceTopologyCache struct {
 	// "All" to make the code react to node config changes avoiding reboots.
 	// Use "OnlyResources" to restore the previous behavior.
 	ResyncScope *CacheResyncScope
+	// NRTValidation controls how the NodeResourceTopology data is cross-validated against the Node allocatable
+	// when it enters the cache. The data is inconsistent if a NUMA zone reports negative availability,
+	// if the sum of the NUMA zones allocatable exceeds the Node allocatable, or if a resource reported
+	// in the NUMA zones is missing from the Node allocatable.
+	// "Warn" emits a Warning Event on the Node but keeps using the data; "Quarantine" also excludes
+	// the node from NUMA-aware scheduling until consistent data is received.
+	// Has no effect if caching is disabled (CacheResyncPeriod is zero) or if DiscardReservedNodes
+	// is enabled. If unspecified, default is "None".
+	NRTValidation *CacheNRTValidationMode
+	// GangAccounting controls how the resources reserved for pods belonging to the same PodGroup
+	// (identified by the coscheduling pod-group label) are accounted when checking a sibling pod.
+	// "Pessimistic" deducts the resources of every reserved pod from all the NUMA zones.
+	// "PerNUMACell" still deducts pessimistically the resources of pods outside the PodGroup,
+	// but on nodes admitting pods on a single NUMA zone at pod scope deducts each reserved sibling
+	// from exactly one zone: the first one, by NUMA ID, whose reported availability, minus the siblings
+	// accounted before, can fit it. So the siblings of a gang waiting at Permit make each other fail
+	// less often. Without such evidence, or if no zone fits, siblings are deducted from all the NUMA zones too.
+	// Has no effect if caching is disabled (CacheResyncPeriod is zero) or if DiscardReservedNodes
+	// is enabled. If unspecified, default is "Pessimistic".
+	GangAccounting *CacheGangAccountingMode
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -27,9 +27,17 @@ import (
 )
 
 var (
-	defaultPermitWaitingTimeSeconds int64 = 60
-	defaultPodGroupBackoffSeconds   int64 = 0
-	defaultPodGroupRejectPercentage int32 = 10
+	defaultPermitWaitingTimeSeconds  int64 = 60
+	defaultPodGroupBackoffSeconds    int64 = 0
+	defaultPodGroupRejectPercentage  int32 = 10
+	defaultSimulatePodGroupPlacement       = false
+	defaultEnableGangPreemption            = false
+	defaultEnableGangReservation           = false
+	defaultGangOrderingPolicy              = GangOrderingTimestamp
+	defaultGangAgingSeconds          int64 = 0
+
+	defaultElasticQuotaAccountingPolicy = ElasticQuotaAccountingBoundNonTerminal
+	defaultElasticQuotaBorrowingPolicy  = ElasticQuotaBorrowingFirstCome
 
 	defaultNodeResourcesAllocatableMode = Least
 
@@ -116,6 +124,31 @@ func SetDefaults_CoschedulingArgs(obj *CoschedulingArgs) {
 	if obj.PodGroupRejectPercentage == nil {
 		obj.PodGroupRejectPercentage = &defaultPodGroupRejectPercentage
 	}
+	if obj.SimulatePodGroupPlacement == nil {
+		obj.SimulatePodGroupPlacement = &defaultSimulatePodGroupPlacement
+	}
+	if obj.EnableGangPreemption == nil {
+		obj.EnableGangPreemption = &defaultEnableGangPreemption
+	}
+	if obj.EnableGangReservation == nil {
+		obj.EnableGangReservation = &defaultEnableGangReservation
+	}
+	if obj.GangOrderingPolicy == nil {
+		obj.GangOrderingPolicy = &defaultGangOrderingPolicy
+	}
+	if obj.GangAgingSeconds == nil {
+		obj.GangAgingSeconds = &defaultGangAgingSeconds
+	}
+}
+
+// SetDefaults_CapacitySchedulingArgs sets the default parameters for CapacityScheduling plugin.
+func SetDefaults_CapacitySchedulingArgs(obj *CapacitySchedulingArgs) {
+	if obj.AccountingPolicy == nil {
+		obj.AccountingPolicy = &defaultElasticQuotaAccountingPolicy
+	}
+	if obj.BorrowingPolicy == nil {
+		obj.BorrowingPolicy = &defaultElasticQuotaBorrowingPolicy
+	}
 }
 
 // SetDefaults_NodeResourcesAllocatableArgs sets the defaults parameters for NodeResourceAllocatable.
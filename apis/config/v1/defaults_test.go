@@ -30,6 +30,7 @@ import (
 )
 
 func TestSchedulingDefaults(t *testing.T) {
+	drf := GangOrderingDominantResourceFairness
 	tests := []struct {
 		name   string
 		config runtime.Object
@@ -39,22 +40,45 @@ func TestSchedulingDefaults(t *testing.T) {
 			name:   "empty config CoschedulingArgs",
 			config: &CoschedulingArgs{},
 			expect: &CoschedulingArgs{
-				PermitWaitingTimeSeconds: pointer.Int64Ptr(60),
-				PodGroupBackoffSeconds:   pointer.Int64Ptr(0),
-				PodGroupRejectPercentage: pointer.Int32Ptr(10),
+				PermitWaitingTimeSeconds:  pointer.Int64Ptr(60),
+				PodGroupBackoffSeconds:    pointer.Int64Ptr(0),
+				PodGroupRejectPercentage:  pointer.Int32Ptr(10),
+				SimulatePodGroupPlacement: pointer.Bool(false),
+				EnableGangPreemption:      pointer.Bool(false),
+				EnableGangReservation:     pointer.Bool(false),
+				GangOrderingPolicy:        &defaultGangOrderingPolicy,
+				GangAgingSeconds:          pointer.Int64Ptr(0),
 			},
 		},
 		{
 			name: "set non default CoschedulingArgs",
 			config: &CoschedulingArgs{
-				PermitWaitingTimeSeconds: pointer.Int64Ptr(60),
-				PodGroupBackoffSeconds:   pointer.Int64Ptr(20),
-				PodGroupRejectPercentage: pointer.Int32Ptr(50),
+				PermitWaitingTimeSeconds:  pointer.Int64Ptr(60),
+				PodGroupBackoffSeconds:    pointer.Int64Ptr(20),
+				PodGroupRejectPercentage:  pointer.Int32Ptr(50),
+				SimulatePodGroupPlacement: pointer.Bool(true),
+				EnableGangPreemption:      pointer.Bool(true),
+				EnableGangReservation:     pointer.Bool(true),
+				GangOrderingPolicy:        &drf,
+				GangAgingSeconds:          pointer.Int64Ptr(300),
 			},
 			expect: &CoschedulingArgs{
-				PermitWaitingTimeSeconds: pointer.Int64Ptr(60),
-				PodGroupBackoffSeconds:   pointer.Int64Ptr(20),
-				PodGroupRejectPercentage: pointer.Int32Ptr(50),
+				PermitWaitingTimeSeconds:  pointer.Int64Ptr(60),
+				PodGroupBackoffSeconds:    pointer.Int64Ptr(20),
+				PodGroupRejectPercentage:  pointer.Int32Ptr(50),
+				SimulatePodGroupPlacement: pointer.Bool(true),
+				EnableGangPreemption:      pointer.Bool(true),
+				EnableGangReservation:     pointer.Bool(true),
+				GangOrderingPolicy:        &drf,
+				GangAgingSeconds:          pointer.Int64Ptr(300),
+			},
+		},
+		{
+			name:   "empty config CapacitySchedulingArgs",
+			config: &CapacitySchedulingArgs{},
+			expect: &CapacitySchedulingArgs{
+				AccountingPolicy: &defaultElasticQuotaAccountingPolicy,
+				BorrowingPolicy:  &defaultElasticQuotaBorrowingPolicy,
 			},
 		},
 		{
@@ -40,6 +40,16 @@ func init() {
 // RegisterConversions adds conversion functions to the given scheme.
 // Public to allow building arbitrary schemes.
 func RegisterConversions(s *runtime.Scheme) error {
+	if err := s.AddGeneratedConversionFunc((*CapacitySchedulingArgs)(nil), (*config.CapacitySchedulingArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1_CapacitySchedulingArgs_To_config_CapacitySchedulingArgs(a.(*CapacitySchedulingArgs), b.(*config.CapacitySchedulingArgs), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*config.CapacitySchedulingArgs)(nil), (*CapacitySchedulingArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_config_CapacitySchedulingArgs_To_v1_CapacitySchedulingArgs(a.(*config.CapacitySchedulingArgs), b.(*CapacitySchedulingArgs), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*CoschedulingArgs)(nil), (*config.CoschedulingArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1_CoschedulingArgs_To_config_CoschedulingArgs(a.(*CoschedulingArgs), b.(*config.CoschedulingArgs), scope)
 	}); err != nil {
@@ -203,6 +213,28 @@ func RegisterConversions(s *runtime.Scheme) error {
 	return nil
 }
 
+func autoConvert_v1_CapacitySchedulingArgs_To_config_CapacitySchedulingArgs(in *CapacitySchedulingArgs, out *config.CapacitySchedulingArgs, s conversion.Scope) error {
+	out.AccountingPolicy = (*config.ElasticQuotaAccountingPolicy)(unsafe.Pointer(in.AccountingPolicy))
+	out.BorrowingPolicy = (*config.ElasticQuotaBorrowingPolicy)(unsafe.Pointer(in.BorrowingPolicy))
+	return nil
+}
+
+// Convert_v1_CapacitySchedulingArgs_To_config_CapacitySchedulingArgs is an autogenerated conversion function.
+func Convert_v1_CapacitySchedulingArgs_To_config_CapacitySchedulingArgs(in *CapacitySchedulingArgs, out *config.CapacitySchedulingArgs, s conversion.Scope) error {
+	return autoConvert_v1_CapacitySchedulingArgs_To_config_CapacitySchedulingArgs(in, out, s)
+}
+
+func autoConvert_config_CapacitySchedulingArgs_To_v1_CapacitySchedulingArgs(in *config.CapacitySchedulingArgs, out *CapacitySchedulingArgs, s conversion.Scope) error {
+	out.AccountingPolicy = (*ElasticQuotaAccountingPolicy)(unsafe.Pointer(in.AccountingPolicy))
+	out.BorrowingPolicy = (*ElasticQuotaBorrowingPolicy)(unsafe.Pointer(in.BorrowingPolicy))
+	return nil
+}
+
+// Convert_config_CapacitySchedulingArgs_To_v1_CapacitySchedulingArgs is an autogenerated conversion function.
+func Convert_config_CapacitySchedulingArgs_To_v1_CapacitySchedulingArgs(in *config.CapacitySchedulingArgs, out *CapacitySchedulingArgs, s conversion.Scope) error {
+	return autoConvert_config_CapacitySchedulingArgs_To_v1_CapacitySchedulingArgs(in, out, s)
+}
+
 func autoConvert_v1_CoschedulingArgs_To_config_CoschedulingArgs(in *CoschedulingArgs, out *config.CoschedulingArgs, s conversion.Scope) error {
 	if err := metav1.Convert_Pointer_int64_To_int64(&in.PermitWaitingTimeSeconds, &out.PermitWaitingTimeSeconds, s); err != nil {
 		return err
@@ -213,6 +245,19 @@ func autoConvert_v1_CoschedulingArgs_To_config_CoschedulingArgs(in *Coscheduling
 	if err := metav1.Convert_Pointer_int32_To_int32(&in.PodGroupRejectPercentage, &out.PodGroupRejectPercentage, s); err != nil {
 		return err
 	}
+	if err := metav1.Convert_Pointer_bool_To_bool(&in.SimulatePodGroupPlacement, &out.SimulatePodGroupPlacement, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_Pointer_bool_To_bool(&in.EnableGangPreemption, &out.EnableGangPreemption, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_Pointer_bool_To_bool(&in.EnableGangReservation, &out.EnableGangReservation, s); err != nil {
+		return err
+	}
+	out.GangOrderingPolicy = (*config.GangOrderingPolicy)(unsafe.Pointer(in.GangOrderingPolicy))
+	if err := metav1.Convert_Pointer_int64_To_int64(&in.GangAgingSeconds, &out.GangAgingSeconds, s); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -231,6 +276,19 @@ func autoConvert_config_CoschedulingArgs_To_v1_CoschedulingArgs(in *config.Cosch
 	if err := metav1.Convert_int32_To_Pointer_int32(&in.PodGroupRejectPercentage, &out.PodGroupRejectPercentage, s); err != nil {
 		return err
 	}
+	if err := metav1.Convert_bool_To_Pointer_bool(&in.SimulatePodGroupPlacement, &out.SimulatePodGroupPlacement, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_bool_To_Pointer_bool(&in.EnableGangPreemption, &out.EnableGangPreemption, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_bool_To_Pointer_bool(&in.EnableGangReservation, &out.EnableGangReservation, s); err != nil {
+		return err
+	}
+	out.GangOrderingPolicy = (*GangOrderingPolicy)(unsafe.Pointer(in.GangOrderingPolicy))
+	if err := metav1.Convert_int64_To_Pointer_int64(&in.GangAgingSeconds, &out.GangAgingSeconds, s); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -382,6 +440,8 @@ func autoConvert_v1_NodeResourceTopologyCache_To_config_NodeResourceTopologyCach
 	out.ResyncMethod = (*config.CacheResyncMethod)(unsafe.Pointer(in.ResyncMethod))
 	out.InformerMode = (*config.CacheInformerMode)(unsafe.Pointer(in.InformerMode))
 	out.ResyncScope = (*config.CacheResyncScope)(unsafe.Pointer(in.ResyncScope))
+	out.NRTValidation = (*config.CacheNRTValidationMode)(unsafe.Pointer(in.NRTValidation))
+	out.GangAccounting = (*config.CacheGangAccountingMode)(unsafe.Pointer(in.GangAccounting))
 	return nil
 }
 
@@ -395,6 +455,8 @@ func autoConvert_config_NodeResourceTopologyCache_To_v1_NodeResourceTopologyCach
 	out.ResyncMethod = (*CacheResyncMethod)(unsafe.Pointer(in.ResyncMethod))
 	out.InformerMode = (*CacheInformerMode)(unsafe.Pointer(in.InformerMode))
 	out.ResyncScope = (*CacheResyncScope)(unsafe.Pointer(in.ResyncScope))
+	out.NRTValidation = (*CacheNRTValidationMode)(unsafe.Pointer(in.NRTValidation))
+	out.GangAccounting = (*CacheGangAccountingMode)(unsafe.Pointer(in.GangAccounting))
 	return nil
 }
 
@@ -27,6 +27,41 @@ import (
 	configv1 "k8s.io/kube-scheduler/config/v1"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CapacitySchedulingArgs) DeepCopyInto(out *CapacitySchedulingArgs) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	if in.AccountingPolicy != nil {
+		in, out := &in.AccountingPolicy, &out.AccountingPolicy
+		*out = new(ElasticQuotaAccountingPolicy)
+		**out = **in
+	}
+	if in.BorrowingPolicy != nil {
+		in, out := &in.BorrowingPolicy, &out.BorrowingPolicy
+		*out = new(ElasticQuotaBorrowingPolicy)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CapacitySchedulingArgs.
+func (in *CapacitySchedulingArgs) DeepCopy() *CapacitySchedulingArgs {
+	if in == nil {
+		return nil
+	}
+	out := new(CapacitySchedulingArgs)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CapacitySchedulingArgs) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CoschedulingArgs) DeepCopyInto(out *CoschedulingArgs) {
 	*out = *in
@@ -46,6 +81,31 @@ func (in *CoschedulingArgs) DeepCopyInto(out *CoschedulingArgs) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.SimulatePodGroupPlacement != nil {
+		in, out := &in.SimulatePodGroupPlacement, &out.SimulatePodGroupPlacement
+		*out = new(bool)
+		**out = **in
+	}
+	if in.EnableGangPreemption != nil {
+		in, out := &in.EnableGangPreemption, &out.EnableGangPreemption
+		*out = new(bool)
+		**out = **in
+	}
+	if in.EnableGangReservation != nil {
+		in, out := &in.EnableGangReservation, &out.EnableGangReservation
+		*out = new(bool)
+		**out = **in
+	}
+	if in.GangOrderingPolicy != nil {
+		in, out := &in.GangOrderingPolicy, &out.GangOrderingPolicy
+		*out = new(GangOrderingPolicy)
+		**out = **in
+	}
+	if in.GangAgingSeconds != nil {
+		in, out := &in.GangAgingSeconds, &out.GangAgingSeconds
+		*out = new(int64)
+		**out = **in
+	}
 	return
 }
 
@@ -235,6 +295,16 @@ func (in *NodeResourceTopologyCache) DeepCopyInto(out *NodeResourceTopologyCache
 		*out = new(CacheResyncScope)
 		**out = **in
 	}
+	if in.NRTValidation != nil {
+		in, out := &in.NRTValidation, &out.NRTValidation
+		*out = new(CacheNRTValidationMode)
+		**out = **in
+	}
+	if in.GangAccounting != nil {
+		in, out := &in.GangAccounting, &out.GangAccounting
+		*out = new(CacheGangAccountingMode)
+		**out = **in
+	}
 	return
 }
 
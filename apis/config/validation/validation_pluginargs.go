@@ -29,6 +29,14 @@ import (
 var (
 	supportNodeResourcesMode sets.Set[string]
 	validScoringStrategy     sets.Set[string]
+	validNRTValidationModes  sets.Set[string]
+	validGangAccountingModes sets.Set[string]
+
+	// ValidElasticQuotaAccountingPolicies are the accounting policies of ElasticQuotas.
+	ValidElasticQuotaAccountingPolicies = sets.New(
+		string(config.ElasticQuotaAccountingBoundNonTerminal),
+		string(config.ElasticQuotaAccountingRunning),
+	)
 )
 
 func init() {
@@ -43,6 +51,17 @@ func init() {
 		string(config.LeastAllocated),
 		string(config.LeastNUMANodes),
 	)
+
+	validNRTValidationModes = sets.New[string](
+		string(config.CacheNRTValidationNone),
+		string(config.CacheNRTValidationWarn),
+		string(config.CacheNRTValidationQuarantine),
+	)
+
+	validGangAccountingModes = sets.New[string](
+		string(config.CacheGangAccountingPessimistic),
+		string(config.CacheGangAccountingPerNUMACell),
+	)
 }
 
 func ValidateNodeResourceTopologyMatchArgs(path *field.Path, args *config.NodeResourceTopologyMatchArgs) error {
@@ -51,10 +70,34 @@ func ValidateNodeResourceTopologyMatchArgs(path *field.Path, args *config.NodeRe
 	if err := validateScoringStrategyType(args.ScoringStrategy.Type, scoringStrategyTypePath); err != nil {
 		allErrs = append(allErrs, err)
 	}
+	if args.Cache != nil && args.Cache.NRTValidation != nil {
+		if err := validateNRTValidationMode(*args.Cache.NRTValidation, path.Child("cache", "nrtValidation")); err != nil {
+			allErrs = append(allErrs, err)
+		}
+	}
+	if args.Cache != nil && args.Cache.GangAccounting != nil {
+		if err := validateGangAccountingMode(*args.Cache.GangAccounting, path.Child("cache", "gangAccounting")); err != nil {
+			allErrs = append(allErrs, err)
+		}
+	}
 
 	return allErrs.ToAggregate()
 }
 
+func validateNRTValidationMode(mode config.CacheNRTValidationMode, path *field.Path) *field.Error {
+	if !validNRTValidationModes.Has(string(mode)) {
+		return field.NotSupported(path, mode, sets.List(validNRTValidationModes))
+	}
+	return nil
+}
+
+func validateGangAccountingMode(mode config.CacheGangAccountingMode, path *field.Path) *field.Error {
+	if !validGangAccountingModes.Has(string(mode)) {
+		return field.NotSupported(path, mode, sets.List(validGangAccountingModes))
+	}
+	return nil
+}
+
 func validateScoringStrategyType(scoringStrategy config.ScoringStrategyType, path *field.Path) *field.Error {
 	if !validScoringStrategy.Has(string(scoringStrategy)) {
 		return field.Invalid(path, scoringStrategy, "invalid ScoringStrategyType")
@@ -94,6 +137,23 @@ func ValidateNodeResourcesAllocatableArgs(args *config.NodeResourcesAllocatableA
 	return allErrs.ToAggregate()
 }
 
+// ValidateCapacitySchedulingArgs validates the accounting and borrowing policies of the CapacityScheduling plugin.
+func ValidateCapacitySchedulingArgs(args *config.CapacitySchedulingArgs, path *field.Path) error {
+	var allErrs field.ErrorList
+	if policy := args.AccountingPolicy; policy != nil && !ValidElasticQuotaAccountingPolicies.Has(string(*policy)) {
+		allErrs = append(allErrs, field.NotSupported(path.Child("accountingPolicy"), *policy, sets.List(ValidElasticQuotaAccountingPolicies)))
+	}
+	if policy := args.BorrowingPolicy; policy != nil && *policy != config.ElasticQuotaBorrowingFirstCome &&
+		*policy != config.ElasticQuotaBorrowingFairShare {
+		allErrs = append(allErrs, field.NotSupported(path.Child("borrowingPolicy"), *policy,
+			[]string{string(config.ElasticQuotaBorrowingFirstCome), string(config.ElasticQuotaBorrowingFairShare)}))
+	}
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return allErrs.ToAggregate()
+}
+
 func ValidateCoschedulingArgs(args *config.CoschedulingArgs, _ *field.Path) error {
 	var allErrs field.ErrorList
 	if args.PermitWaitingTimeSeconds < 0 {
@@ -108,6 +168,15 @@ func ValidateCoschedulingArgs(args *config.CoschedulingArgs, _ *field.Path) erro
 		allErrs = append(allErrs, field.Invalid(field.NewPath("podGroupRejectPercentage"),
 			args.PodGroupRejectPercentage, "must be between 0 and 100"))
 	}
+	if policy := args.GangOrderingPolicy; policy != nil && *policy != config.GangOrderingTimestamp &&
+		*policy != config.GangOrderingDominantResourceFairness {
+		allErrs = append(allErrs, field.NotSupported(field.NewPath("gangOrderingPolicy"), *policy,
+			[]string{string(config.GangOrderingTimestamp), string(config.GangOrderingDominantResourceFairness)}))
+	}
+	if args.GangAgingSeconds < 0 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("gangAgingSeconds"),
+			args.GangAgingSeconds, "must be greater than or equal to 0"))
+	}
 	if len(allErrs) == 0 {
 		return nil
 	}
@@ -24,6 +24,7 @@ import (
 	gocmp "github.com/google/go-cmp/cmp"
 
 	schedconfig "k8s.io/kubernetes/pkg/scheduler/apis/config"
+	"k8s.io/utils/ptr"
 
 	"sigs.k8s.io/scheduler-plugins/apis/config"
 )
@@ -51,6 +52,52 @@ func TestValidateNodeResourceTopologyMatchArgs(t *testing.T) {
 			},
 			expectedErr: fmt.Errorf("scoringStrategy.type: Invalid value:"),
 		},
+		{
+			description: "correct config, NRT validation mode",
+			args: &config.NodeResourceTopologyMatchArgs{
+				ScoringStrategy: config.ScoringStrategy{
+					Type: config.MostAllocated,
+				},
+				Cache: &config.NodeResourceTopologyCache{
+					NRTValidation: ptr.To(config.CacheNRTValidationQuarantine),
+				},
+			},
+		},
+		{
+			description: "incorrect config, unknown NRT validation mode",
+			args: &config.NodeResourceTopologyMatchArgs{
+				ScoringStrategy: config.ScoringStrategy{
+					Type: config.MostAllocated,
+				},
+				Cache: &config.NodeResourceTopologyCache{
+					NRTValidation: ptr.To(config.CacheNRTValidationMode("Quarantined")),
+				},
+			},
+			expectedErr: fmt.Errorf("cache.nrtValidation: Unsupported value:"),
+		},
+		{
+			description: "correct config, gang accounting mode",
+			args: &config.NodeResourceTopologyMatchArgs{
+				ScoringStrategy: config.ScoringStrategy{
+					Type: config.MostAllocated,
+				},
+				Cache: &config.NodeResourceTopologyCache{
+					GangAccounting: ptr.To(config.CacheGangAccountingPerNUMACell),
+				},
+			},
+		},
+		{
+			description: "incorrect config, unknown gang accounting mode",
+			args: &config.NodeResourceTopologyMatchArgs{
+				ScoringStrategy: config.ScoringStrategy{
+					Type: config.MostAllocated,
+				},
+				Cache: &config.NodeResourceTopologyCache{
+					GangAccounting: ptr.To(config.CacheGangAccountingMode("PerNUMAZone")),
+				},
+			},
+			expectedErr: fmt.Errorf("cache.gangAccounting: Unsupported value:"),
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -141,6 +188,25 @@ func TestValidateCoschedulingArgs(t *testing.T) {
 			},
 			expectedErr: fmt.Errorf("podGroupRejectPercentage: Invalid value: %v: must be between 0 and 100", 150),
 		},
+		{
+			description: "invalid GangOrderingPolicy",
+			args: &config.CoschedulingArgs{
+				PermitWaitingTimeSeconds: 30,
+				PodGroupRejectPercentage: 10,
+				GangOrderingPolicy:       ptr.To(config.GangOrderingPolicy("Random")),
+			},
+			expectedErr: fmt.Errorf(`gangOrderingPolicy: Unsupported value: "Random": supported values: "Timestamp", "DominantResourceFairness"`),
+		},
+		{
+			description: "invalid GangAgingSeconds (negative value)",
+			args: &config.CoschedulingArgs{
+				PermitWaitingTimeSeconds: 30,
+				PodGroupRejectPercentage: 10,
+				GangOrderingPolicy:       ptr.To(config.GangOrderingDominantResourceFairness),
+				GangAgingSeconds:         -60,
+			},
+			expectedErr: fmt.Errorf("gangAgingSeconds: Invalid value: %v: must be greater than or equal to 0", -60),
+		},
 		{
 			description: "both PermitWaitingTimeSeconds and PodGroupBackoffSeconds are negative",
 			args: &config.CoschedulingArgs{
@@ -174,6 +240,53 @@ func TestValidateCoschedulingArgs(t *testing.T) {
 	}
 }
 
+func TestValidateCapacitySchedulingArgs(t *testing.T) {
+	testCases := []struct {
+		description string
+		args        *config.CapacitySchedulingArgs
+		expectedErr error
+	}{
+		{
+			description: "correct config",
+			args: &config.CapacitySchedulingArgs{
+				AccountingPolicy: ptr.To(config.ElasticQuotaAccountingRunning),
+				BorrowingPolicy:  ptr.To(config.ElasticQuotaBorrowingFairShare),
+			},
+		},
+		{
+			description: "invalid AccountingPolicy",
+			args: &config.CapacitySchedulingArgs{
+				AccountingPolicy: ptr.To(config.ElasticQuotaAccountingPolicy("Scheduled")),
+			},
+			expectedErr: fmt.Errorf(`accountingPolicy: Unsupported value: "Scheduled": supported values: "BoundNonTerminal", "Running"`),
+		},
+		{
+			description: "invalid BorrowingPolicy",
+			args: &config.CapacitySchedulingArgs{
+				BorrowingPolicy: ptr.To(config.ElasticQuotaBorrowingPolicy("Weighted")),
+			},
+			expectedErr: fmt.Errorf(`borrowingPolicy: Unsupported value: "Weighted": supported values: "FirstCome", "FairShare"`),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			err := ValidateCapacitySchedulingArgs(testCase.args, nil)
+			if testCase.expectedErr != nil {
+				if err == nil {
+					t.Fatalf("expected err to equal %v not nil", testCase.expectedErr)
+				}
+				if diff := gocmp.Diff(err.Error(), testCase.expectedErr.Error()); diff != "" {
+					t.Fatalf("expected err to contain %s in error message: %s", testCase.expectedErr.Error(), err.Error())
+				}
+			}
+			if testCase.expectedErr == nil && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
 func TestValidateNodeResourcesAllocatableArgs(t *testing.T) {
 	testCases := []struct {
 		args        *config.NodeResourcesAllocatableArgs
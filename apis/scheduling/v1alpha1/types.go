@@ -30,6 +30,8 @@ import (
 // +kubebuilder:subresource:status
 // +kubebuilder:metadata:annotations="api-approved.kubernetes.io=https://github.com/kubernetes-sigs/scheduler-plugins/pull/52"
 // +kubebuilder:printcolumn:name="Used",JSONPath=".status.used",type=string,description="Used is the current observed total usage of the resource in the namespace."
+// +kubebuilder:printcolumn:name="Borrowed",JSONPath=".status.borrowed",type=string,description="Borrowed is the usage above Min, which may be reclaimed by other quotas."
+// +kubebuilder:printcolumn:name="Lent Out",JSONPath=".status.lentOut",type=string,description="LentOut is the part of Min left unused and currently borrowed by other quotas."
 // +kubebuilder:printcolumn:name="Max",JSONPath=".spec.max",type=string,description="Max is the set of desired max limits for each named resource."
 // +kubebuilder:printcolumn:name="Age",JSONPath=".metadata.creationTimestamp",type=date,description="Age is the time ElasticQuota was created."
 type ElasticQuota struct {
@@ -58,6 +60,68 @@ type ElasticQuotaSpec struct {
 	// successfully scheduled pods.
 	// +optional
 	Max v1.ResourceList `json:"max,omitempty" protobuf:"bytes,2,rep,name=max, casttype=ResourceList,castkey=ResourceName"`
+
+	// Parent is the ElasticQuota this quota belongs to in a tree of quotas, e.g. the quota of a department
+	// for the quota of one of its teams. The usage of a quota counts against its parent as well, and the
+	// quota borrows first from the unused min of its siblings before borrowing from the rest of the tree.
+	// +optional
+	Parent *ElasticQuotaReference `json:"parent,omitempty" protobuf:"bytes,3,opt,name=parent"`
+
+	// Selector selects, by their labels, the pods of the namespace this quota applies to. A namespace may have
+	// several ElasticQuotas with selectors or priority classes not overlapping, e.g. one for batch and one for
+	// serving pods, and at most one without either, which applies to the pods no other quota selects.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty" protobuf:"bytes,4,opt,name=selector"`
+
+	// PriorityClassNames selects, by their priority class, the pods of the namespace this quota applies to.
+	// When set along with Selector, the pods have to match both.
+	// +optional
+	PriorityClassNames []string `json:"priorityClassNames,omitempty" protobuf:"bytes,5,rep,name=priorityClassNames"`
+
+	// Weight is the share of the min left unused by the quotas this quota may borrow, relative to the other
+	// quotas borrowing it, when the CapacityScheduling plugin shares it fairly. In a tree of quotas, the
+	// weight of the root applies to the whole tree. Defaults to 1.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Weight *int32 `json:"weight,omitempty" protobuf:"varint,6,opt,name=weight"`
+
+	// Pools scope guarantees and limits of the quota to pools of nodes, e.g. the nodes of a GPU model or of a
+	// zone, on top of Min and Max, which apply to the whole cluster. The quotas naming the same pool share
+	// its min the way they share the min of the cluster: the usage of all of them on the nodes of the pool may
+	// not exceed the sum of their min of the pool, for the resources their min names.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Pools []ElasticQuotaPool `json:"pools,omitempty" protobuf:"bytes,7,rep,name=pools"`
+}
+
+// ElasticQuotaPool defines the Min and Max of a quota on the nodes of a pool.
+type ElasticQuotaPool struct {
+	// Name identifies the pool among the quotas. The quotas naming the same pool should select the same nodes.
+	Name string `json:"name" protobuf:"bytes,1,opt,name=name"`
+
+	// NodeSelector selects, by their labels, the nodes of the pool. An empty selector selects all the nodes.
+	// +optional
+	NodeSelector *metav1.LabelSelector `json:"nodeSelector,omitempty" protobuf:"bytes,2,opt,name=nodeSelector"`
+
+	// Min is the set of guaranteed limits on the nodes of the pool, for each named resource.
+	// +optional
+	Min v1.ResourceList `json:"min,omitempty" protobuf:"bytes,3,rep,name=min,casttype=ResourceList,castkey=ResourceName"`
+
+	// Max is the set of max limits on the nodes of the pool, for each named resource. The resources not
+	// named are not limited in the pool.
+	// +optional
+	Max v1.ResourceList `json:"max,omitempty" protobuf:"bytes,4,rep,name=max,casttype=ResourceList,castkey=ResourceName"`
+}
+
+// ElasticQuotaReference refers to an ElasticQuota.
+type ElasticQuotaReference struct {
+	// Namespace is the namespace of the ElasticQuota.
+	Namespace string `json:"namespace" protobuf:"bytes,1,opt,name=namespace"`
+
+	// Name is the name of the ElasticQuota.
+	// +optional
+	Name string `json:"name,omitempty" protobuf:"bytes,2,opt,name=name"`
 }
 
 // ElasticQuotaStatus defines the observed use.
@@ -65,6 +129,23 @@ type ElasticQuotaStatus struct {
 	// Used is the current observed total usage of the resource in the namespace.
 	// +optional
 	Used v1.ResourceList `json:"used,omitempty" protobuf:"bytes,1,rep,name=used,casttype=ResourceList,castkey=ResourceName"`
+
+	// Borrowed is the part of the usage above Min, for each resource used over it. Pods using it run on the
+	// unused min of other quotas, and may be preempted when those quotas reclaim it. For a quota with
+	// children, it includes the usage of its descendants.
+	// +optional
+	Borrowed v1.ResourceList `json:"borrowed,omitempty" protobuf:"bytes,2,rep,name=borrowed,casttype=ResourceList,castkey=ResourceName"`
+
+	// LentOut is the part of Min left unused that quotas over their min currently borrow. It is split
+	// between the siblings lending to each other in proportion to their unused min.
+	// +optional
+	LentOut v1.ResourceList `json:"lentOut,omitempty" protobuf:"bytes,3,rep,name=lentOut,casttype=ResourceList,castkey=ResourceName"`
+
+	// PodsAtRisk are the names of the pods of the quota running beyond its min, which may be preempted to
+	// reclaim the resources they use. The pods are ranked the way preemption reprieves them, by priority
+	// then by start time, and the ones still fitting within Min are guaranteed. At most 100 pods are listed.
+	// +optional
+	PodsAtRisk []string `json:"podsAtRisk,omitempty" protobuf:"bytes,4,rep,name=podsAtRisk"`
 }
 
 // +kubebuilder:object:root=true
@@ -110,6 +191,58 @@ const (
 
 	// PodGroupLabel is the default label of coscheduling
 	PodGroupLabel = scheduling.GroupName + "/pod-group"
+
+	// PodGroupNamespaceLabel is the label of a pod naming the namespace of its pod group,
+	// when the pod group lives in another namespace than the pod.
+	PodGroupNamespaceLabel = scheduling.GroupName + "/pod-group-namespace"
+
+	// AllowedPodGroupNamespacesAnnotation is the annotation of a namespace listing, comma-separated,
+	// the other namespaces whose pod groups the pods of the namespace may belong to.
+	AllowedPodGroupNamespacesAnnotation = scheduling.GroupName + "/allowed-pod-group-namespaces"
+
+	// MinAvailableAnnotation is the annotation of a workload, e.g. a Job, asking the controller to
+	// create its pod group. Its value, if not empty, is the minMember of the pod group; otherwise the
+	// minMember is derived from the parallelism or the replicas of the workload, and it is bounded by them.
+	// The pods of the workload are labeled with the PodGroupLabel by the webhook of the controller, unless
+	// the pod templates carry it themselves.
+	MinAvailableAnnotation = scheduling.GroupName + "/min-available"
+)
+
+// These are the condition types of a pod group.
+const (
+	// PodGroupScheduled means at least `spec.minMember` pods of the pod group have been scheduled.
+	PodGroupScheduled = "Scheduled"
+
+	// PodGroupQuorumReached means the pod group has at least `spec.minMember` pods, satisfying all its roles.
+	PodGroupQuorumReached = "QuorumReached"
+
+	// PodGroupBackoff means the scheduler rejected the pod group in its last scheduling attempt;
+	// its message and last transition time tell why and when.
+	PodGroupBackoff = "Backoff"
+)
+
+// These are the reasons of the conditions of a pod group.
+const (
+	// PodGroupReasonScheduled is the reason of the Scheduled condition once the quorum is scheduled,
+	// and of the Backoff condition cleared since.
+	PodGroupReasonScheduled = "Scheduled"
+
+	// PodGroupReasonNotScheduled is the reason of the Scheduled condition while the quorum is not scheduled.
+	PodGroupReasonNotScheduled = "NotScheduled"
+
+	// PodGroupReasonQuorumReached is the reason of the QuorumReached condition when it is true.
+	PodGroupReasonQuorumReached = "QuorumReached"
+
+	// PodGroupReasonNotEnoughPods is the reason of the QuorumReached condition when it is false.
+	PodGroupReasonNotEnoughPods = "NotEnoughPods"
+
+	// PodGroupReasonRejected is the reason of the Backoff condition when the pod group was rejected
+	// because one of its pods could not be placed.
+	PodGroupReasonRejected = "Rejected"
+
+	// PodGroupReasonUnreserved is the reason of the Backoff condition when the pod group was rejected
+	// because one of its pods timed out in Permit or failed to be bound.
+	PodGroupReasonUnreserved = "Unreserved"
 )
 
 // PodGroup is a collection of Pod; used for batch workload.
@@ -150,6 +283,19 @@ type PodGroupSpec struct {
 	// +kubebuilder:validation:Minimum=1
 	MinMember int32 `json:"minMember,omitempty"`
 
+	// MaxMember defines the maximal number of members/tasks of the pod group running at the same time;
+	// once MinMember is reached, more members/tasks are admitted opportunistically up to MaxMember.
+	// If unset, there is no upper bound. It must not be less than MinMember.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaxMember *int32 `json:"maxMember,omitempty"`
+
+	// ScaleUpWindowSeconds bounds how long after MinMember members/tasks of the pod group were scheduled
+	// more members/tasks, up to MaxMember, are still admitted. If unset, they are admitted at any time.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	ScaleUpWindowSeconds *int32 `json:"scaleUpWindowSeconds,omitempty"`
+
 	// MinResources defines the minimal resource of members/tasks to run the pod group;
 	// if there's not enough resources to start all tasks, the scheduler
 	// will not start any.
@@ -157,6 +303,59 @@ type PodGroupSpec struct {
 
 	// ScheduleTimeoutSeconds defines the maximal time of members/tasks to wait before run the pod group;
 	ScheduleTimeoutSeconds *int32 `json:"scheduleTimeoutSeconds,omitempty"`
+
+	// Roles defines named subsets of the members/tasks of the pod group, each with its own minimal number
+	// of members. The pod group can run only if the quorum of every role is met, in addition to MinMember.
+	// A member can belong to more than one role, but roles are expected to select disjoint sets of members.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Roles []PodGroupRole `json:"roles,omitempty"`
+
+	// TopologyConstraint constrains all the members/tasks of the pod group within one topology domain,
+	// e.g. a rack or a zone.
+	// +optional
+	TopologyConstraint *PodGroupTopologyConstraint `json:"topologyConstraint,omitempty"`
+}
+
+// PodGroupRole is a named subset of the members/tasks of a pod group.
+type PodGroupRole struct {
+	// Name of the role; unique within the pod group.
+	Name string `json:"name"`
+
+	// MinMember defines the minimal number of members/tasks of this role to run the pod group.
+	// +kubebuilder:validation:Minimum=0
+	MinMember int32 `json:"minMember"`
+
+	// Selector selects the members/tasks of this role among the pods of the pod group.
+	// An empty selector selects all the pods of the pod group.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+}
+
+// TopologyConstraintMode defines how strictly the members of a pod group are kept within one topology domain.
+type TopologyConstraintMode string
+
+const (
+	// TopologyConstraintRequired means the members of the pod group are only placed on the nodes of the chosen domain.
+	TopologyConstraintRequired TopologyConstraintMode = "Required"
+
+	// TopologyConstraintPreferred means the members of the pod group are placed on the nodes of the chosen domain
+	// if possible, but can be placed elsewhere otherwise.
+	TopologyConstraintPreferred TopologyConstraintMode = "Preferred"
+)
+
+// PodGroupTopologyConstraint defines the topology domain the members of a pod group are placed within.
+type PodGroupTopologyConstraint struct {
+	// TopologyKey is the key of the node label identifying the topology domain. Nodes with the same value
+	// for this label are in the same domain.
+	TopologyKey string `json:"topologyKey"`
+
+	// Mode is either Required or Preferred; defaults to Required.
+	// +kubebuilder:validation:Enum=Required;Preferred
+	// +kubebuilder:default=Required
+	// +optional
+	Mode TopologyConstraintMode `json:"mode,omitempty"`
 }
 
 // PodGroupStatus represents the current state of a pod group.
@@ -182,6 +381,25 @@ type PodGroupStatus struct {
 
 	// ScheduleStartTime of the group
 	ScheduleStartTime metav1.Time `json:"scheduleStartTime,omitempty"`
+
+	// The number of members/tasks the pod group asks for: its pods which are not terminated,
+	// up to MaxMember. Only reported if MaxMember is set.
+	// +optional
+	Desired int32 `json:"desired,omitempty"`
+
+	// The number of members/tasks of the pod group admitted by the scheduler: its pods bound to
+	// a node which are not terminated. Only reported if MaxMember is set.
+	// +optional
+	Admitted int32 `json:"admitted,omitempty"`
+
+	// Conditions represent the latest observations of the pod group, e.g. why the scheduler
+	// rejected it.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	// +patchStrategy=merge
+	// +patchMergeKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
 }
 
 // +kubebuilder:object:root=true
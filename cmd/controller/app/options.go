@@ -18,15 +18,25 @@ package app
 
 import (
 	"github.com/spf13/pflag"
+
+	pluginconfig "sigs.k8s.io/scheduler-plugins/apis/config"
 )
 
 type ServerRunOptions struct {
-	MetricsAddr          string
-	ProbeAddr            string
-	ApiServerQPS         int
-	ApiServerBurst       int
-	Workers              int
-	EnableLeaderElection bool
+	MetricsAddr           string
+	ProbeAddr             string
+	ApiServerQPS          int
+	ApiServerBurst        int
+	Workers               int
+	EnableLeaderElection  bool
+	PodGroupWorkloadKinds []string
+	// ElasticQuotaAccountingPolicy tells which pods count towards the usage of their ElasticQuota.
+	ElasticQuotaAccountingPolicy string
+	// EnableWebhooks serves the validating and defaulting webhooks of ElasticQuotas and PodGroups, and the
+	// one labeling the pods of the PodGroupWorkloadKinds.
+	EnableWebhooks bool
+	WebhookPort    int
+	WebhookCertDir string
 }
 
 func NewServerRunOptions() *ServerRunOptions {
@@ -42,4 +52,9 @@ func (s *ServerRunOptions) addAllFlags() {
 	pflag.IntVar(&s.ApiServerBurst, "burst", 10, "burst of query apiserver.")
 	pflag.IntVar(&s.Workers, "workers", 1, "workers of scheduler-plugin-controllers.")
 	pflag.BoolVar(&s.EnableLeaderElection, "enableLeaderElection", s.EnableLeaderElection, "If EnableLeaderElection for controller.")
+	pflag.StringSliceVar(&s.PodGroupWorkloadKinds, "podGroupWorkloadKinds", nil, "Kinds of the workloads, among Job, JobSet and StatefulSet, whose PodGroups are created from the min-available annotation.")
+	pflag.StringVar(&s.ElasticQuotaAccountingPolicy, "elasticQuotaAccountingPolicy", string(pluginconfig.ElasticQuotaAccountingBoundNonTerminal), "Pods counting towards the usage of their ElasticQuota, either BoundNonTerminal or Running. It should match the accountingPolicy of the CapacityScheduling plugin.")
+	pflag.BoolVar(&s.EnableWebhooks, "enableWebhooks", s.EnableWebhooks, "If serve the validating and defaulting webhooks of ElasticQuotas and PodGroups, and the one labeling the pods of the podGroupWorkloadKinds.")
+	pflag.IntVar(&s.WebhookPort, "webhookPort", 9443, "Port the webhook server listens on.")
+	pflag.StringVar(&s.WebhookCertDir, "webhookCertDir", "", "Directory of the tls.crt and tls.key of the webhook server. Defaults to <temp-dir>/k8s-webhook-server/serving-certs.")
 }
@@ -17,6 +17,8 @@ limitations under the License.
 package app
 
 import (
+	"fmt"
+
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
@@ -24,7 +26,10 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
+	pluginconfig "sigs.k8s.io/scheduler-plugins/apis/config"
+	"sigs.k8s.io/scheduler-plugins/apis/config/validation"
 	schedulingv1a1 "sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
 	"sigs.k8s.io/scheduler-plugins/pkg/controllers"
 )
@@ -41,13 +46,17 @@ func init() {
 }
 
 func Run(s *ServerRunOptions) error {
+	if !validation.ValidElasticQuotaAccountingPolicies.Has(s.ElasticQuotaAccountingPolicy) {
+		return fmt.Errorf("unsupported ElasticQuota accounting policy %q", s.ElasticQuotaAccountingPolicy)
+	}
+
 	config := ctrl.GetConfigOrDie()
 	config.QPS = float32(s.ApiServerQPS)
 	config.Burst = s.ApiServerBurst
 
 	// Controller Runtime Controllers
 	ctrl.SetLogger(klogr.New())
-	mgr, err := ctrl.NewManager(config, ctrl.Options{
+	options := ctrl.Options{
 		Scheme: scheme,
 		Metrics: metricsserver.Options{
 			BindAddress: s.MetricsAddr,
@@ -56,7 +65,14 @@ func Run(s *ServerRunOptions) error {
 		LeaderElection:          s.EnableLeaderElection,
 		LeaderElectionID:        "sched-plugins-controllers",
 		LeaderElectionNamespace: "kube-system",
-	})
+	}
+	if s.EnableWebhooks {
+		options.WebhookServer = webhook.NewServer(webhook.Options{
+			Port:    s.WebhookPort,
+			CertDir: s.WebhookCertDir,
+		})
+	}
+	mgr, err := ctrl.NewManager(config, options)
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		return err
@@ -72,14 +88,50 @@ func Run(s *ServerRunOptions) error {
 	}
 
 	if err = (&controllers.ElasticQuotaReconciler{
-		Client:  mgr.GetClient(),
-		Scheme:  mgr.GetScheme(),
-		Workers: s.Workers,
+		Client:           mgr.GetClient(),
+		Scheme:           mgr.GetScheme(),
+		Workers:          s.Workers,
+		AccountingPolicy: pluginconfig.ElasticQuotaAccountingPolicy(s.ElasticQuotaAccountingPolicy),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ElasticQuota")
 		return err
 	}
 
+	for _, kind := range s.PodGroupWorkloadKinds {
+		if err = (&controllers.WorkloadPodGroupReconciler{
+			Client:      mgr.GetClient(),
+			Scheme:      mgr.GetScheme(),
+			Workers:     s.Workers,
+			Kind:        kind,
+			PodsLabeled: s.EnableWebhooks,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "PodGroup", "workload", kind)
+			return err
+		}
+	}
+
+	if s.EnableWebhooks {
+		if err = (&controllers.ElasticQuotaWebhook{
+			Client: mgr.GetClient(),
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "ElasticQuota")
+			return err
+		}
+		if err = (&controllers.PodGroupWebhook{}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "PodGroup")
+			return err
+		}
+		if len(s.PodGroupWorkloadKinds) != 0 {
+			if err = (&controllers.WorkloadPodWebhook{
+				Client: mgr.GetClient(),
+				Kinds:  s.PodGroupWorkloadKinds,
+			}).SetupWithManager(mgr); err != nil {
+				setupLog.Error(err, "unable to create webhook", "webhook", "Pod")
+				return err
+			}
+		}
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		return err
@@ -0,0 +1,120 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"errors"
+
+	"github.com/go-logr/logr"
+	"github.com/spf13/cobra"
+
+	"k8s.io/client-go/tools/clientcmd"
+
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/capacity"
+)
+
+type capacityReportOptions struct {
+	kubeconfig string
+	fromFiles  []string
+	shapes     []string
+	shapeFiles []string
+	output     string
+}
+
+func newCapacityReportCommand(logh logr.Logger) *cobra.Command {
+	opts := capacityReportOptions{}
+	cmd := &cobra.Command{
+		Use:   "capacity-report",
+		Short: "Report how many more pods of the given shapes fit, NUMA-aligned, on each node",
+		Long: `Report how many more pods of the given shapes fit, NUMA-aligned, on each node and in the whole cluster,
+using the same fit semantics of the NodeResourceTopologyMatch plugin. The NodeResourceTopology objects, the Nodes
+and the Pods are either listed from the cluster or read from YAML dumps.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCapacityReport(cmd, logh, opts)
+		},
+	}
+
+	// the scheduler command installs its own help and usage functions, which describe the scheduler flags
+	defaults := &cobra.Command{}
+	cmd.SetHelpFunc(defaults.HelpFunc())
+	cmd.SetUsageFunc(defaults.UsageFunc())
+
+	flags := cmd.Flags()
+	flags.StringVar(&opts.kubeconfig, "kubeconfig", "", "Path to the kubeconfig file used to list the objects from the cluster. Ignored if --from-file is given.")
+	flags.StringSliceVar(&opts.fromFiles, "from-file", nil, "YAML dumps to read the NodeResourceTopology, Node and Pod objects from, instead of listing them from the cluster. Can be repeated.")
+	flags.StringArrayVar(&opts.shapes, "shape", nil, "Pod shape as comma-separated resource=quantity pairs, e.g. cpu=4,memory=8Gi. Describes a Guaranteed pod with a single container. Can be repeated.")
+	flags.StringSliceVar(&opts.shapeFiles, "shape-file", nil, "YAML files with the Pods to use as shapes. Can be repeated.")
+	flags.StringVarP(&opts.output, "output", "o", capacity.OutputText, "Output format: text or json.")
+	return cmd
+}
+
+func runCapacityReport(cmd *cobra.Command, logh logr.Logger, opts capacityReportOptions) error {
+	var shapes []capacity.Shape
+	for _, value := range opts.shapes {
+		shape, err := capacity.ParseShape(value)
+		if err != nil {
+			return err
+		}
+		shapes = append(shapes, shape)
+	}
+	if len(opts.shapeFiles) > 0 {
+		shapeSnap, err := capacity.LoadFromFiles(opts.shapeFiles...)
+		if err != nil {
+			return err
+		}
+		shapes = append(shapes, capacity.ShapesFromPods(shapeSnap.Pods)...)
+	}
+	if len(shapes) == 0 {
+		return errors.New("at least one shape is required, use --shape or --shape-file")
+	}
+
+	var (
+		snap capacity.Snapshot
+		err  error
+	)
+	if len(opts.fromFiles) > 0 {
+		snap, err = capacity.LoadFromFiles(opts.fromFiles...)
+	} else {
+		snap, err = loadCapacitySnapshotFromCluster(cmd, opts.kubeconfig)
+	}
+	if err != nil {
+		return err
+	}
+
+	report, err := capacity.Compute(logh, snap, shapes)
+	if err != nil {
+		return err
+	}
+	return capacity.Write(cmd.OutOrStdout(), report, opts.output)
+}
+
+func loadCapacitySnapshotFromCluster(cmd *cobra.Command, kubeconfig string) (capacity.Snapshot, error) {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = kubeconfig
+	cfg, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, &clientcmd.ConfigOverrides{}).ClientConfig()
+	if err != nil {
+		return capacity.Snapshot{}, err
+	}
+	cli, err := ctrlclient.New(cfg, ctrlclient.Options{Scheme: capacity.Scheme})
+	if err != nil {
+		return capacity.Snapshot{}, err
+	}
+	return capacity.LoadFromCluster(cmd.Context(), cli)
+}
@@ -60,6 +60,7 @@ func main() {
 		app.WithPlugin(noderesourcetopology.Name, noderesourcetopology.New),
 		app.WithPlugin(knidebug.Name, knidebug.New),
 	)
+	command.AddCommand(newCapacityReportCommand(logh))
 
 	// TODO: once we switch everything over to Cobra commands, we can go back to calling
 	// utilflag.InitFlags() (by removing its pflag.Parse() call). For now, we have to set the
@@ -0,0 +1,147 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/clientcmd"
+
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/scheduler-plugins/apis/config"
+	"sigs.k8s.io/scheduler-plugins/pkg/capacityscheduling"
+)
+
+type capacitySchedulingDryRunOptions struct {
+	kubeconfig       string
+	fromFiles        []string
+	pod              string
+	podFile          string
+	accountingPolicy string
+	borrowingPolicy  string
+	output           string
+}
+
+func newCapacitySchedulingDryRunCommand() *cobra.Command {
+	opts := capacitySchedulingDryRunOptions{}
+	cmd := &cobra.Command{
+		Use:   "capacityscheduling-dry-run",
+		Short: "Simulate the admission of a pod by the CapacityScheduling plugin, and the preemption it would trigger",
+		Long: `Simulate the admission of a pod by the CapacityScheduling plugin: run its PreFilter, then on each node its
+Filter along with NodeResourcesFit, and the selection of the victims of preemption where the pod does not fit.
+Print the verdict, the arithmetic of the ElasticQuota of the pod and of its ancestors, and the candidate victims.
+The ElasticQuotas, Nodes, Pods and PodDisruptionBudgets are either listed from the cluster or read from YAML dumps.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCapacitySchedulingDryRun(cmd, opts)
+		},
+	}
+
+	// the scheduler command installs its own help and usage functions, which describe the scheduler flags
+	defaults := &cobra.Command{}
+	cmd.SetHelpFunc(defaults.HelpFunc())
+	cmd.SetUsageFunc(defaults.UsageFunc())
+
+	flags := cmd.Flags()
+	flags.StringVar(&opts.kubeconfig, "kubeconfig", "", "Path to the kubeconfig file used to list the objects from the cluster. Ignored if --from-file is given.")
+	flags.StringSliceVar(&opts.fromFiles, "from-file", nil, "YAML dumps to read the ElasticQuota, Node, Pod and PodDisruptionBudget objects from, instead of listing them from the cluster. Can be repeated.")
+	flags.StringVar(&opts.pod, "pod", "", "Namespace and name of the pod to simulate, e.g. ns1/pod1, among the listed or read pods.")
+	flags.StringVar(&opts.podFile, "pod-file", "", "YAML file with the pod to simulate, instead of --pod.")
+	flags.StringVar(&opts.accountingPolicy, "accounting-policy", string(config.ElasticQuotaAccountingBoundNonTerminal), "Pods counting towards the usage of their ElasticQuota, either BoundNonTerminal or Running, as in the accountingPolicy of the plugin.")
+	flags.StringVar(&opts.borrowingPolicy, "borrowing-policy", string(config.ElasticQuotaBorrowingFirstCome), "How ElasticQuotas borrow the min the others leave unused, either FirstCome or FairShare, as in the borrowingPolicy of the plugin.")
+	flags.StringVarP(&opts.output, "output", "o", capacityscheduling.OutputText, "Output format: text or json.")
+	return cmd
+}
+
+func runCapacitySchedulingDryRun(cmd *cobra.Command, opts capacitySchedulingDryRunOptions) error {
+	var (
+		snap capacityscheduling.DryRunSnapshot
+		err  error
+	)
+	if len(opts.fromFiles) > 0 {
+		snap, err = capacityscheduling.LoadDryRunSnapshotFromFiles(opts.fromFiles...)
+	} else {
+		snap, err = loadDryRunSnapshotFromCluster(cmd, opts.kubeconfig)
+	}
+	if err != nil {
+		return err
+	}
+
+	pod, err := dryRunPod(snap, opts)
+	if err != nil {
+		return err
+	}
+
+	accountingPolicy := config.ElasticQuotaAccountingPolicy(opts.accountingPolicy)
+	borrowingPolicy := config.ElasticQuotaBorrowingPolicy(opts.borrowingPolicy)
+	args := &config.CapacitySchedulingArgs{
+		AccountingPolicy: &accountingPolicy,
+		BorrowingPolicy:  &borrowingPolicy,
+	}
+	report, err := capacityscheduling.DryRun(cmd.Context(), snap, pod, args)
+	if err != nil {
+		return err
+	}
+	return capacityscheduling.WriteDryRunReport(cmd.OutOrStdout(), report, opts.output)
+}
+
+// dryRunPod returns the pod to simulate, either read from --pod-file or found by --pod in the snapshot.
+func dryRunPod(snap capacityscheduling.DryRunSnapshot, opts capacitySchedulingDryRunOptions) (*v1.Pod, error) {
+	if opts.podFile != "" {
+		podSnap, err := capacityscheduling.LoadDryRunSnapshotFromFiles(opts.podFile)
+		if err != nil {
+			return nil, err
+		}
+		if len(podSnap.Pods) != 1 {
+			return nil, fmt.Errorf("expected one pod in %q, found %d", opts.podFile, len(podSnap.Pods))
+		}
+		return &podSnap.Pods[0], nil
+	}
+	if opts.pod == "" {
+		return nil, errors.New("the pod to simulate is required, use --pod or --pod-file")
+	}
+	namespace, name, found := strings.Cut(opts.pod, "/")
+	if !found {
+		return nil, fmt.Errorf("expected --pod as namespace/name, got %q", opts.pod)
+	}
+	for i := range snap.Pods {
+		if snap.Pods[i].Namespace == namespace && snap.Pods[i].Name == name {
+			return &snap.Pods[i], nil
+		}
+	}
+	return nil, fmt.Errorf("pod %q not found", opts.pod)
+}
+
+func loadDryRunSnapshotFromCluster(cmd *cobra.Command, kubeconfig string) (capacityscheduling.DryRunSnapshot, error) {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = kubeconfig
+	cfg, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, &clientcmd.ConfigOverrides{}).ClientConfig()
+	if err != nil {
+		return capacityscheduling.DryRunSnapshot{}, err
+	}
+	cli, err := ctrlclient.New(cfg, ctrlclient.Options{Scheme: capacityscheduling.DryRunScheme()})
+	if err != nil {
+		return capacityscheduling.DryRunSnapshot{}, err
+	}
+	return capacityscheduling.LoadDryRunSnapshotFromCluster(cmd.Context(), cli)
+}
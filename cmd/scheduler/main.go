@@ -65,6 +65,7 @@ func main() {
 		app.WithPlugin(podstate.Name, podstate.New),
 		app.WithPlugin(qos.Name, qos.New),
 	)
+	command.AddCommand(newCapacitySchedulingDryRunCommand())
 
 	code := cli.Run(command)
 	os.Exit(code)
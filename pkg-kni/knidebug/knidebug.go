@@ -33,6 +33,7 @@ import (
 	"k8s.io/kubernetes/pkg/scheduler/framework"
 
 	knifeatures "sigs.k8s.io/scheduler-plugins/pkg-kni/features"
+	"sigs.k8s.io/scheduler-plugins/pkg/util"
 )
 
 type KNIDebug struct{}
@@ -125,51 +126,12 @@ func (hc humanCPU) String() string {
 }
 
 ///////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
-// logic taken from fit.go, changing the return value to use a plain *framework.Resource
-// https://github.com/kubernetes/kubernetes/blob/v1.24.0/pkg/scheduler/framework/plugins/noderesources/fit.go#L133-L175
-
-// computePodResourceRequest returns a framework.Resource that covers the largest
-// width in each resource dimension. Because init-containers run sequentially, we collect
-// the max in each dimension iteratively. In contrast, we sum the resource vectors for
-// regular containers since they run simultaneously.
-//
-// # The resources defined for Overhead should be added to the calculated Resource request sum
-//
-// Example:
-//
-// Pod:
-//
-//	InitContainers
-//	  IC1:
-//	    CPU: 2
-//	    Memory: 1G
-//	  IC2:
-//	    CPU: 2
-//	    Memory: 3G
-//	Containers
-//	  C1:
-//	    CPU: 2
-//	    Memory: 1G
-//	  C2:
-//	    CPU: 1
-//	    Memory: 1G
-//
-// Result: CPU: 3, Memory: 3G
-func computePodResourceRequest(pod *corev1.Pod) *framework.Resource {
-	result := &framework.Resource{}
-	for _, container := range pod.Spec.Containers {
-		result.Add(container.Resources.Requests)
-	}
-
-	// take max_resource(sum_pod, any_init_container)
-	for _, container := range pod.Spec.InitContainers {
-		result.SetMaxResource(container.Resources.Requests)
-	}
+// the pod requests are the ones shared with the other plugins, see util.PodRequests
 
-	if pod.Spec.Overhead != nil {
-		result.Add(pod.Spec.Overhead)
-	}
-	return result
+// computePodResourceRequest returns the requests of the pod, as computed by the other plugins,
+// as a plain *framework.Resource.
+func computePodResourceRequest(pod *corev1.Pod) *framework.Resource {
+	return framework.NewResource(util.PodRequests(pod))
 }
 
 // see again fit.go for the skeleton code. Here we intentionally only log
@@ -19,15 +19,20 @@ package capacityscheduling
 import (
 	"context"
 	"fmt"
+	"maps"
+	"slices"
 	"sort"
 	"sync"
 
 	v1 "k8s.io/api/core/v1"
 	policy "k8s.io/api/policy/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	quota "k8s.io/apiserver/pkg/quota/v1"
 	"k8s.io/client-go/informers"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	corelisters "k8s.io/client-go/listers/core/v1"
@@ -43,6 +48,8 @@ import (
 	schedutil "k8s.io/kubernetes/pkg/scheduler/util"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"sigs.k8s.io/scheduler-plugins/apis/config"
+	"sigs.k8s.io/scheduler-plugins/apis/config/validation"
 	"sigs.k8s.io/scheduler-plugins/apis/scheduling"
 	"sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
 	"sigs.k8s.io/scheduler-plugins/pkg/util"
@@ -61,9 +68,57 @@ type CapacityScheduling struct {
 	logger            klog.Logger
 	fh                fwk.Handle
 	podLister         corelisters.PodLister
+	nodeLister        corelisters.NodeLister
 	pdbLister         policylisters.PodDisruptionBudgetLister
 	client            client.Client
 	elasticQuotaInfos ElasticQuotaInfos
+	// selectors index the quotas of elasticQuotaInfos with a selector. It is replaced, never changed, as
+	// the snapshots share it.
+	selectors elasticQuotaSelectors
+	// accountingPolicy tells which pods count towards the usage of their quota.
+	accountingPolicy config.ElasticQuotaAccountingPolicy
+	// borrowingPolicy tells how the quotas borrow the min the others leave unused.
+	borrowingPolicy config.ElasticQuotaBorrowingPolicy
+	// pendingPods are the pods not bound yet, by key, when the min left unused is shared fairly between
+	// the quotas with pods waiting.
+	pendingPods map[string]*v1.Pod
+	// totals are the usage of all the quotas and the min of the roots of their trees, or nil until the next
+	// snapshot computes them again.
+	totals *elasticQuotaTotals
+	// pools are the pools of nodes the quotas name, with the usage of the pods bound or reserved to their
+	// nodes, or nil if no quota names a pool. pooledPods are the pools each of these pods counts in, by the
+	// key of the pod, and podsWaitingForNodes the pods bound to nodes not known yet, by node.
+	pools               elasticQuotaPools
+	pooledPods          map[string]pooledPod
+	podsWaitingForNodes map[string][]*v1.Pod
+	// snapshot is the copy of elasticQuotaInfos the scheduling cycles share and never change. Only the
+	// quotas of changedElasticQuotas are copied again, in place, when the next cycle takes it: the cycles
+	// run one at a time, and a state only holds the map during its cycle. snapshotPools is the copy of
+	// pools, copied again once they changed.
+	snapshot             ElasticQuotaInfos
+	changedElasticQuotas sets.Set[string]
+	snapshotPools        elasticQuotaPools
+	poolsChanged         bool
+	// nominatedPods are the pods waiting for preemption, with the node they are nominated to, by the key
+	// of the quota they count against and the key of the pod, so that PreFilter only asks the nominator
+	// about the nodes of the pods it adds up. nominatedElasticQuotas are the keys of their quotas, by the
+	// key of the pod. An entry may outlive the nomination, which only costs a lookup.
+	nominatedPods          map[string]map[string]nomination
+	nominatedElasticQuotas map[string]string
+}
+
+// pooledPod is a pod counting against the quota of key in the pools of the node it is bound or reserved to,
+// with the request it counts.
+type pooledPod struct {
+	key     string
+	pools   []string
+	request v1.ResourceList
+}
+
+// nomination is a pod nominated to a node.
+type nomination struct {
+	pod      *v1.Pod
+	nodeName string
 }
 
 // PreFilterState computed at PreFilter and used at PostFilter or Reserve.
@@ -79,6 +134,10 @@ type PreFilterState struct {
 	// 1. the pods subject to the same quota(namespace) and is more important than the preemptor.
 	// 2. the pods subject to the different quota(namespace) and the usage of quota(namespace) does not exceed min.
 	nominatedPodsReqWithPodReq framework.Resource
+
+	// waitingElasticQuotas are the keys of the roots of the other quota trees with pods waiting to be
+	// scheduled, when the min left unused is shared fairly, or else nil.
+	waitingElasticQuotas sets.Set[string]
 }
 
 // Clone the preFilter state.
@@ -86,19 +145,146 @@ func (s *PreFilterState) Clone() fwk.StateData {
 	return s
 }
 
-// ElasticQuotaSnapshotState stores the snapshot of elasticQuotas.
+// reclaimable returns whether the pod may take back the capacity the quota of <victimKey> borrows, either
+// from the branch of the quota trees of <preemptorKey>, or to rebalance toward fair shares.
+func (s *PreFilterState) reclaimable(elasticQuotaInfos ElasticQuotaInfos, preemptorKey, victimKey string) bool {
+	podRequest := &s.nominatedPodsReqInEQWithPodReq
+	return elasticQuotaInfos.reclaimable(preemptorKey, victimKey, podRequest) ||
+		s.waitingElasticQuotas != nil && elasticQuotaInfos.rebalanceable(preemptorKey, victimKey, podRequest, s.waitingElasticQuotas)
+}
+
+// ElasticQuotaSnapshotState stores the snapshot of elasticQuotas. The infos are copied on write: they may be
+// shared with the snapshot of the plugin and with other cycle states, and are only copied once the state
+// changes them, e.g. while preemption removes the victims.
 type ElasticQuotaSnapshotState struct {
 	elasticQuotaInfos ElasticQuotaInfos
+	// selectors index the quotas of elasticQuotaInfos with a selector, shared with the plugin.
+	selectors elasticQuotaSelectors
+	// sharedMap tells whether elasticQuotaInfos itself is shared with the plugin, and must be copied before
+	// any info is replaced.
+	sharedMap bool
+	// owned are the keys of the infos the state copied and may change in place, or nil if it owns all of them.
+	owned sets.Set[string]
+	// totals are the usage of all the quotas and the min of the roots of their trees, or nil until computed
+	// from the infos.
+	totals *elasticQuotaTotals
+	// pools are the pools of nodes the quotas name, with their usage, or nil if none does. sharedPools tells
+	// whether they are shared, and must be copied before they change.
+	pools       elasticQuotaPools
+	sharedPools bool
 }
 
-// Clone the ElasticQuotaSnapshot state.
+// Clone the ElasticQuotaSnapshot state. Only the infos the state owns are copied, the others stay shared.
 func (s *ElasticQuotaSnapshotState) Clone() fwk.StateData {
-	return &ElasticQuotaSnapshotState{
-		elasticQuotaInfos: s.elasticQuotaInfos.clone(),
+	clone := &ElasticQuotaSnapshotState{
+		elasticQuotaInfos: s.elasticQuotaInfos,
+		selectors:         s.selectors,
+		sharedMap:         s.sharedMap,
+		owned:             sets.New[string](),
+		pools:             s.pools,
+		sharedPools:       s.sharedPools,
+	}
+	if s.totals != nil {
+		totals := s.totals.clone()
+		clone.totals = &totals
+	}
+	if !s.sharedPools {
+		clone.pools = s.pools.clone()
+	}
+	if s.sharedMap {
+		return clone
+	}
+	clone.elasticQuotaInfos = make(ElasticQuotaInfos, len(s.elasticQuotaInfos))
+	for key, info := range s.elasticQuotaInfos {
+		if s.owns(key) {
+			info = info.clone()
+			clone.owned.Insert(key)
+		}
+		clone.elasticQuotaInfos[key] = info
+	}
+	return clone
+}
+
+func (s *ElasticQuotaSnapshotState) owns(key string) bool {
+	return s.owned == nil || s.owned.Has(key)
+}
+
+// forPod returns the key and the info of the quota the pod counts against.
+func (s *ElasticQuotaSnapshotState) forPod(pod *v1.Pod) (string, *ElasticQuotaInfo) {
+	return s.selectors.forPod(s.elasticQuotaInfos, pod)
+}
+
+// forPodToUpdate returns the key and the info of the quota the pod counts against, like forPod, the info
+// and the ones of its ancestors being copied first if they are shared so that the state may change them.
+func (s *ElasticQuotaSnapshotState) forPodToUpdate(pod *v1.Pod) (string, *ElasticQuotaInfo) {
+	key, info := s.forPod(pod)
+	if info == nil {
+		return key, info
+	}
+	for _, k := range s.elasticQuotaInfos.pathKeys(key) {
+		if s.owns(k) {
+			continue
+		}
+		if s.sharedMap {
+			s.elasticQuotaInfos = maps.Clone(s.elasticQuotaInfos)
+			s.sharedMap = false
+		}
+		s.elasticQuotaInfos[k] = s.elasticQuotaInfos[k].clone()
+		s.owned.Insert(k)
+	}
+	return key, s.elasticQuotaInfos[key]
+}
+
+// elasticQuotaTotals returns the totals, computed from the infos first if the state was not given them.
+func (s *ElasticQuotaSnapshotState) elasticQuotaTotals() *elasticQuotaTotals {
+	if s.totals == nil {
+		totals := newElasticQuotaTotals(s.elasticQuotaInfos)
+		s.totals = &totals
+	}
+	return s.totals
+}
+
+// poolsToUpdate returns the pools, copied first if they are shared so that the state may change them.
+func (s *ElasticQuotaSnapshotState) poolsToUpdate() elasticQuotaPools {
+	if s.sharedPools {
+		s.pools = s.pools.clone()
+		s.sharedPools = false
+	}
+	return s.pools
+}
+
+// addPod adds the pod on the given node to the quota of the given key, to the totals and to the pools of the
+// node, if known.
+func (s *ElasticQuotaSnapshotState) addPod(key string, pod *v1.Pod, node *v1.Node) error {
+	added, err := s.elasticQuotaInfos.addPod(key, pod)
+	if added {
+		if s.totals != nil {
+			s.totals.addPod(pod, 1)
+		}
+		if node != nil && len(s.pools) > 0 {
+			s.poolsToUpdate().addPod(key, pod, node.Labels)
+		}
+	}
+	return err
+}
+
+// deletePod deletes the pod on the given node from the quota of the given key, from the totals and from the
+// pools of the node, if known.
+func (s *ElasticQuotaSnapshotState) deletePod(key string, pod *v1.Pod, node *v1.Node) error {
+	deleted, err := s.elasticQuotaInfos.deletePod(key, pod)
+	if deleted {
+		if s.totals != nil {
+			s.totals.addPod(pod, -1)
+		}
+		if node != nil && len(s.pools) > 0 {
+			s.poolsToUpdate().removePod(key, pod, node.Labels)
+		}
 	}
+	return err
 }
 
 var _ fwk.PreFilterPlugin = &CapacityScheduling{}
+var _ fwk.FilterPlugin = &CapacityScheduling{}
 var _ fwk.PostFilterPlugin = &CapacityScheduling{}
 var _ fwk.ReservePlugin = &CapacityScheduling{}
 var _ fwk.EnqueueExtensions = &CapacityScheduling{}
@@ -121,12 +307,29 @@ func (c *CapacityScheduling) Name() string {
 // New initializes a new plugin and returns it.
 func New(ctx context.Context, obj runtime.Object, handle fwk.Handle) (fwk.Plugin, error) {
 	lh := klog.FromContext(ctx).WithValues("plugin", Name)
+	args, ok := obj.(*config.CapacitySchedulingArgs)
+	if !ok {
+		return nil, fmt.Errorf("want args to be of type CapacitySchedulingArgs, got %T", obj)
+	}
+	if err := validation.ValidateCapacitySchedulingArgs(args, nil); err != nil {
+		return nil, err
+	}
+
 	c := &CapacityScheduling{
 		logger:            lh,
 		fh:                handle,
 		elasticQuotaInfos: NewElasticQuotaInfos(),
 		podLister:         handle.SharedInformerFactory().Core().V1().Pods().Lister(),
+		nodeLister:        handle.SharedInformerFactory().Core().V1().Nodes().Lister(),
 		pdbLister:         getPDBLister(handle.SharedInformerFactory()),
+		accountingPolicy:  config.ElasticQuotaAccountingBoundNonTerminal,
+		borrowingPolicy:   config.ElasticQuotaBorrowingFirstCome,
+	}
+	if args.AccountingPolicy != nil {
+		c.accountingPolicy = *args.AccountingPolicy
+	}
+	if args.BorrowingPolicy != nil {
+		c.borrowingPolicy = *args.BorrowingPolicy
 	}
 	logger := klog.FromContext(ctx)
 
@@ -187,6 +390,36 @@ func New(ctx context.Context, obj runtime.Object, handle fwk.Handle) (fwk.Plugin
 			},
 		},
 	)
+	// The nodes joining or leaving the pools of the quotas change their usage.
+	handle.SharedInformerFactory().Core().V1().Nodes().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    c.addNode,
+		UpdateFunc: c.updateNode,
+	})
+	if c.borrowingPolicy == config.ElasticQuotaBorrowingFairShare {
+		c.pendingPods = make(map[string]*v1.Pod)
+	}
+	podInformer.AddEventHandler(
+		cache.FilteringResourceEventHandler{
+			FilterFunc: func(obj interface{}) bool {
+				switch t := obj.(type) {
+				case *v1.Pod:
+					return pendingPod(t)
+				case cache.DeletedFinalStateUnknown:
+					if pod, ok := t.Obj.(*v1.Pod); ok {
+						return pendingPod(pod)
+					}
+					return false
+				default:
+					return false
+				}
+			},
+			Handler: cache.ResourceEventHandlerFuncs{
+				AddFunc:    c.addPendingPod,
+				UpdateFunc: func(_, newObj interface{}) { c.addPendingPod(newObj) },
+				DeleteFunc: c.deletePendingPod,
+			},
+		},
+	)
 	logger.Info("CapacityScheduling start")
 	return c, nil
 }
@@ -196,25 +429,33 @@ func (c *CapacityScheduling) EventsToRegister(_ context.Context) ([]fwk.ClusterE
 	// https://github.com/kubernetes/kubernetes/pull/101394
 	// Please follow: eventhandlers.go#L403-L410
 	eqGVK := fmt.Sprintf("elasticquotas.v1alpha1.%v", scheduling.GroupName)
-	return []fwk.ClusterEventWithHint{
+	events := []fwk.ClusterEventWithHint{
 		{Event: fwk.ClusterEvent{Resource: fwk.Pod, ActionType: fwk.Delete}},
 		{Event: fwk.ClusterEvent{Resource: fwk.EventResource(eqGVK), ActionType: fwk.All}},
-	}, nil
+		// Nodes may join the pools of the quotas.
+		{Event: fwk.ClusterEvent{Resource: fwk.Node, ActionType: fwk.Add | fwk.UpdateNodeLabel}},
+	}
+	if c.borrowingPolicy == config.ElasticQuotaBorrowingFairShare {
+		// The fair share of a quota grows once the pods of the other quotas waiting are scheduled.
+		events = append(events, fwk.ClusterEventWithHint{Event: fwk.ClusterEvent{Resource: fwk.Pod, ActionType: fwk.Add}})
+	}
+	return events, nil
 }
 
 // PreFilter performs the following validations.
-// 1. Check if the (pod.request + eq.allocated) is less than eq.max.
+// 1. Check if the (pod.request + eq.allocated) is less than eq.max, and the max of the ancestors of eq.
 // 2. Check if the sum(eq's usage) > sum(eq's min).
+// 3. With the FairShare borrowing policy, check if the tree of eq borrows no more than its fair share
+// while other quotas have pods waiting.
+// Only the pods nominated to a node and adding up, i.e. of eq or of quotas within their min, are summed.
 func (c *CapacityScheduling) PreFilter(ctx context.Context, state fwk.CycleState, pod *v1.Pod, nodes []fwk.NodeInfo) (*fwk.PreFilterResult, *fwk.Status) {
-	// TODO improve the efficiency of taking snapshot
-	// e.g. use a two-pointer data structure to only copy the updated EQs when necessary.
 	snapshotElasticQuota := c.snapshotElasticQuota()
 	podReq := computePodResourceRequest(pod)
 
 	state.Write(ElasticQuotaSnapshotKey, snapshotElasticQuota)
 
 	elasticQuotaInfos := snapshotElasticQuota.elasticQuotaInfos
-	eq := snapshotElasticQuota.elasticQuotaInfos[pod.Namespace]
+	eqKey, eq := snapshotElasticQuota.forPod(pod)
 	if eq == nil {
 		preFilterState := &PreFilterState{
 			podReq: *podReq,
@@ -232,29 +473,27 @@ func (c *CapacityScheduling) PreFilter(ctx context.Context, state fwk.CycleState
 	// 2. the pods subject to the different quota(namespace) and the usage of quota(namespace) does not exceed min.
 	nominatedPodsReqWithPodReq := &framework.Resource{}
 
-	nodeList, err := c.fh.SnapshotSharedLister().NodeInfos().List()
-	if err != nil {
-		return nil, fwk.NewStatus(fwk.Error, fmt.Sprintf("Error getting the nodelist: %v", err))
-	}
-
-	for _, node := range nodeList {
-		nominatedPods := c.fh.NominatedPodsForNode(node.Node().Name)
+	nodeLister := c.fh.SnapshotSharedLister().NodeInfos()
+	for _, nodeName := range c.nominatedNodeNames(snapshotElasticQuota, eqKey) {
+		if nodeInfo, err := nodeLister.Get(nodeName); err != nil || nodeInfo == nil {
+			continue
+		}
+		nominatedPods := c.fh.NominatedPodsForNode(nodeName)
 		for _, p := range nominatedPods {
 			if p.GetPod().UID == pod.UID {
 				continue
 			}
-			ns := p.GetPod().Namespace
-			info := c.elasticQuotaInfos[ns]
+			key, info := snapshotElasticQuota.forPod(p.GetPod())
 			if info != nil {
 				pResourceRequest := util.ResourceList(computePodResourceRequest(p.GetPod()))
 				// If they are subject to the same quota(namespace) and p is more important than pod,
 				// p will be added to the nominatedResource and totalNominatedResource.
 				// If they aren't subject to the same quota(namespace) and the usage of quota(p's namespace) does not exceed min,
 				// p will be added to the totalNominatedResource.
-				if ns == pod.Namespace && corev1helpers.PodPriority(p.GetPod()) >= corev1helpers.PodPriority(pod) {
+				if key == eqKey && corev1helpers.PodPriority(p.GetPod()) >= corev1helpers.PodPriority(pod) {
 					nominatedPodsReqInEQWithPodReq.Add(pResourceRequest)
 					nominatedPodsReqWithPodReq.Add(pResourceRequest)
-				} else if ns != pod.Namespace && !info.usedOverMin() {
+				} else if key != eqKey && !info.usedOverMin() {
 					nominatedPodsReqWithPodReq.Add(pResourceRequest)
 				}
 			}
@@ -268,19 +507,80 @@ func (c *CapacityScheduling) PreFilter(ctx context.Context, state fwk.CycleState
 		nominatedPodsReqInEQWithPodReq: *nominatedPodsReqInEQWithPodReq,
 		nominatedPodsReqWithPodReq:     *nominatedPodsReqWithPodReq,
 	}
+	if c.borrowingPolicy == config.ElasticQuotaBorrowingFairShare {
+		preFilterState.waitingElasticQuotas = c.waitingElasticQuotas(snapshotElasticQuota, pod)
+	}
 	state.Write(preFilterStateKey, preFilterState)
 
-	if eq.usedOverMaxWith(nominatedPodsReqInEQWithPodReq) {
-		return nil, fwk.NewStatus(fwk.Unschedulable, fmt.Sprintf("Pod %v/%v is rejected in PreFilter because ElasticQuota %v is more than Max", pod.Namespace, pod.Name, eq.Namespace))
+	if elasticQuotaInfos.usedOverMaxWith(eqKey, nominatedPodsReqInEQWithPodReq) {
+		return nil, fwk.NewStatus(fwk.Unschedulable, fmt.Sprintf("Pod %v/%v is rejected in PreFilter because ElasticQuota %v is more than Max", pod.Namespace, pod.Name, eqKey))
 	}
 
-	if elasticQuotaInfos.aggregatedUsedOverMinWith(*nominatedPodsReqWithPodReq) {
+	if snapshotElasticQuota.elasticQuotaTotals().usedOverMinWith(nominatedPodsReqWithPodReq) {
 		return nil, fwk.NewStatus(fwk.Unschedulable, fmt.Sprintf("Pod %v/%v is rejected in PreFilter because total ElasticQuota used is more than min", pod.Namespace, pod.Name))
 	}
 
+	if c.borrowingPolicy == config.ElasticQuotaBorrowingFairShare &&
+		elasticQuotaInfos.overFairShareWith(eqKey, nominatedPodsReqInEQWithPodReq, preFilterState.waitingElasticQuotas) {
+		return nil, fwk.NewStatus(fwk.Unschedulable, fmt.Sprintf("Pod %v/%v is rejected in PreFilter because ElasticQuota %v would borrow more than its fair share", pod.Namespace, pod.Name, eqKey))
+	}
+
 	return nil, fwk.NewStatus(fwk.Success, "")
 }
 
+// waitingElasticQuotas returns the keys of the roots of the quota trees with pods waiting to be scheduled,
+// other than the tree of the given pod.
+func (c *CapacityScheduling) waitingElasticQuotas(s *ElasticQuotaSnapshotState, pod *v1.Pod) sets.Set[string] {
+	c.RLock()
+	defer c.RUnlock()
+
+	podKey, _ := s.forPod(pod)
+	own := s.elasticQuotaInfos.rootKey(podKey)
+	waiting := sets.New[string]()
+	for _, p := range c.pendingPods {
+		key, info := s.forPod(p)
+		if info == nil {
+			continue
+		}
+		if root := s.elasticQuotaInfos.rootKey(key); root != own {
+			waiting.Insert(root)
+		}
+	}
+	return waiting
+}
+
+// Filter checks the pools of nodes the ElasticQuota of the pod names which the node belongs to:
+// 1. Check if the (pod.request + eq.allocated in the pool) is less than the max of eq in the pool.
+// 2. Check if the sum(usage of the quotas in the pool) <= sum(min of the quotas in the pool).
+func (c *CapacityScheduling) Filter(ctx context.Context, cycleState fwk.CycleState, pod *v1.Pod, nodeInfo fwk.NodeInfo) *fwk.Status {
+	elasticQuotaSnapshotState, err := getElasticQuotaSnapshotState(cycleState)
+	if err != nil {
+		return fwk.AsStatus(err)
+	}
+	pools := elasticQuotaSnapshotState.pools
+	if pools == nil || nodeInfo.Node() == nil {
+		return nil
+	}
+	eqKey, eq := elasticQuotaSnapshotState.forPod(pod)
+	if eq == nil {
+		return nil
+	}
+	preFilterState, err := getPreFilterState(cycleState)
+	if err != nil {
+		return fwk.AsStatus(err)
+	}
+
+	podReq := util.ResourceList(&preFilterState.podReq)
+	nodeLabels := nodeInfo.Node().Labels
+	if pool := pools.usedOverMaxWith(eqKey, podReq, nodeLabels); pool != "" {
+		return fwk.NewStatus(fwk.Unschedulable, fmt.Sprintf("ElasticQuota %v is more than Max of pool %v", eqKey, pool))
+	}
+	if pool := pools.aggregatedUsedOverMinWith(podReq, nodeLabels); pool != "" {
+		return fwk.NewStatus(fwk.Unschedulable, fmt.Sprintf("total ElasticQuota used of pool %v is more than min", pool))
+	}
+	return nil
+}
+
 // PreFilterExtensions returns prefilter extensions, pod add and remove.
 func (c *CapacityScheduling) PreFilterExtensions() fwk.PreFilterExtensions {
 	return c
@@ -296,9 +596,9 @@ func (c *CapacityScheduling) AddPod(ctx context.Context, cycleState fwk.CycleSta
 		return fwk.NewStatus(fwk.Error, err.Error())
 	}
 
-	elasticQuotaInfo := elasticQuotaSnapshotState.elasticQuotaInfos[podToAdd.GetPod().Namespace]
+	key, elasticQuotaInfo := elasticQuotaSnapshotState.forPodToUpdate(podToAdd.GetPod())
 	if elasticQuotaInfo != nil {
-		err := elasticQuotaInfo.addPodIfNotPresent(podToAdd.GetPod())
+		err := elasticQuotaSnapshotState.addPod(key, podToAdd.GetPod(), nodeInfo.Node())
 		if err != nil {
 			logger.Error(err, "Failed to add Pod to its associated elasticQuota", "pod", klog.KObj(podToAdd.GetPod()))
 		}
@@ -317,9 +617,9 @@ func (c *CapacityScheduling) RemovePod(ctx context.Context, cycleState fwk.Cycle
 		return fwk.NewStatus(fwk.Error, err.Error())
 	}
 
-	elasticQuotaInfo := elasticQuotaSnapshotState.elasticQuotaInfos[podToRemove.GetPod().Namespace]
+	key, elasticQuotaInfo := elasticQuotaSnapshotState.forPodToUpdate(podToRemove.GetPod())
 	if elasticQuotaInfo != nil {
-		err = elasticQuotaInfo.deletePodIfPresent(podToRemove.GetPod())
+		err = elasticQuotaSnapshotState.deletePod(key, podToRemove.GetPod(), nodeInfo.Node())
 		if err != nil {
 			logger.Error(err, "Failed to delete Pod from its associated elasticQuota", "pod", klog.KObj(podToRemove.GetPod()))
 		}
@@ -344,7 +644,14 @@ func (c *CapacityScheduling) PostFilter(ctx context.Context, state fwk.CycleStat
 		false, // enableAsyncPreemption
 	)
 
-	return pe.Preempt(ctx, state, pod, m)
+	result, status := pe.Preempt(ctx, state, pod, m)
+	if result != nil && result.NominatingInfo != nil && result.NominatedNodeName != "" {
+		// The status of the pod only tells the node it is nominated to once updated.
+		c.Lock()
+		c.nominatePod(pod, result.NominatedNodeName)
+		c.Unlock()
+	}
+	return result, status
 }
 
 func (c *CapacityScheduling) Reserve(ctx context.Context, state fwk.CycleState, pod *v1.Pod, nodeName string) *fwk.Status {
@@ -352,9 +659,9 @@ func (c *CapacityScheduling) Reserve(ctx context.Context, state fwk.CycleState,
 	defer c.Unlock()
 	logger := klog.FromContext(klog.NewContext(ctx, c.logger)).WithValues("ExtensionPoint", "Reserve")
 
-	elasticQuotaInfo := c.elasticQuotaInfos[pod.Namespace]
+	key, elasticQuotaInfo := c.forPod(pod)
 	if elasticQuotaInfo != nil {
-		err := elasticQuotaInfo.addPodIfNotPresent(pod)
+		err := c.addPodToElasticQuota(key, pod, nodeName)
 		if err != nil {
 			logger.Error(err, "Failed to add Pod to its associated elasticQuota", "pod", klog.KObj(pod))
 			return fwk.NewStatus(fwk.Error, err.Error())
@@ -369,9 +676,9 @@ func (c *CapacityScheduling) Unreserve(ctx context.Context, state fwk.CycleState
 
 	logger := klog.FromContext(ctx)
 
-	elasticQuotaInfo := c.elasticQuotaInfos[pod.Namespace]
+	key, elasticQuotaInfo := c.forPod(pod)
 	if elasticQuotaInfo != nil {
-		err := elasticQuotaInfo.deletePodIfPresent(pod)
+		err := c.deletePodFromElasticQuota(key, pod)
 		if err != nil {
 			logger.Error(err, "Failed to delete Pod from its associated elasticQuota", "pod", klog.KObj(pod))
 		}
@@ -441,27 +748,29 @@ func (p *preemptor) PodEligibleToPreemptOthers(ctx context.Context, pod *v1.Pod,
 		}
 
 		podPriority := corev1helpers.PodPriority(pod)
-		preemptorEQInfo, preemptorWithEQ := elasticQuotaSnapshotState.elasticQuotaInfos[pod.Namespace]
-		if preemptorWithEQ {
-			moreThanMinWithPreemptor := preemptorEQInfo.usedOverMinWith(&preFilterState.nominatedPodsReqInEQWithPodReq)
+		podReq := util.ResourceList(&preFilterState.podReq)
+		elasticQuotaInfos := elasticQuotaSnapshotState.elasticQuotaInfos
+		preemptorKey, preemptorEQInfo := elasticQuotaSnapshotState.forPod(pod)
+		if preemptorEQInfo != nil {
 			for _, p := range nodeInfo.GetPods() {
 				// Checking terminating pods
 				if p.GetPod().DeletionTimestamp != nil {
-					eqInfo, withEQ := elasticQuotaSnapshotState.elasticQuotaInfos[p.GetPod().Namespace]
-					if !withEQ {
+					key, eqInfo := elasticQuotaSnapshotState.forPod(p.GetPod())
+					if eqInfo == nil {
 						continue
 					}
-					if p.GetPod().Namespace == pod.Namespace && corev1helpers.PodPriority(p.GetPod()) < podPriority {
+					if key == preemptorKey && corev1helpers.PodPriority(p.GetPod()) < podPriority {
 						// There is a terminating pod on the nominated node.
 						// If the terminating pod is in the same namespace with preemptor
 						// and it is less important than preemptor,
 						// return false to avoid preempting more pods.
 						return false, "not eligible due to a terminating pod on the nominated node."
-					} else if p.GetPod().Namespace != pod.Namespace && !moreThanMinWithPreemptor && eqInfo.usedOverMin() {
+					} else if key != preemptorKey && (preFilterState.reclaimable(elasticQuotaInfos, preemptorKey, key) ||
+						elasticQuotaSnapshotState.pools.reclaimable(preemptorKey, key, podReq, nodeInfo.Node().Labels)) {
 						// There is a terminating pod on the nominated node.
 						// The terminating pod isn't in the same namespace with preemptor.
-						// If moreThanMinWithPreemptor is false, it indicates that preemptor can preempt the pods in other EQs whose used is over min.
-						// And if the used of terminating pod's quota is over min, so the room released by terminating pod on the nominated node can be used by the preemptor.
+						// If the preemptor can reclaim the capacity borrowed by the terminating pod's quota, e.g. its quota is over min,
+						// or over its min of a pool of the node, while the preemptor's one is not, the room released by terminating pod on the nominated node can be used by the preemptor.
 						// return false to avoid preempting more pods.
 						return false, "not eligible due to a terminating pod on the nominated node."
 					}
@@ -469,8 +778,7 @@ func (p *preemptor) PodEligibleToPreemptOthers(ctx context.Context, pod *v1.Pod,
 			}
 		} else {
 			for _, p := range nodeInfo.GetPods() {
-				_, withEQ := elasticQuotaSnapshotState.elasticQuotaInfos[p.GetPod().Namespace]
-				if withEQ {
+				if _, eqInfo := elasticQuotaSnapshotState.forPod(p.GetPod()); eqInfo != nil {
 					continue
 				}
 				if p.GetPod().DeletionTimestamp != nil && corev1helpers.PodPriority(p.GetPod()) < podPriority {
@@ -529,9 +837,11 @@ func (p *preemptor) SelectVictimsOnNode(
 		return nil
 	}
 
-	elasticQuotaInfos := elasticQuotaSnapshotState.elasticQuotaInfos
+	// The infos are read from the state each time: removing and adding pods copies them, along with
+	// their map if the state shares it.
 	podPriority := corev1helpers.PodPriority(pod)
-	preemptorElasticQuotaInfo, preemptorWithElasticQuota := elasticQuotaInfos[pod.Namespace]
+	preemptorKey, preemptorElasticQuotaInfo := elasticQuotaSnapshotState.forPod(pod)
+	preemptorWithElasticQuota := preemptorElasticQuotaInfo != nil
 
 	// sort the pods in node by the priority class
 	sort.Slice(nodeInfo.GetPods(), func(i, j int) bool {
@@ -544,43 +854,49 @@ func (p *preemptor) SelectVictimsOnNode(
 		nominatedPodsReqWithPodReq = preFilterState.nominatedPodsReqWithPodReq
 		moreThanMinWithPreemptor := preemptorElasticQuotaInfo.usedOverMinWith(&nominatedPodsReqInEQWithPodReq)
 		for _, p := range nodeInfo.GetPods() {
-			eqInfo, withEQ := elasticQuotaInfos[p.GetPod().Namespace]
-			if !withEQ {
+			key, eqInfo := elasticQuotaSnapshotState.forPod(p.GetPod())
+			if eqInfo == nil {
 				continue
 			}
 
-			if moreThanMinWithPreemptor {
+			if key == preemptorKey {
 				// If Preemptor.Request + Quota.Used > Quota.Min:
 				// It means that its guaranteed isn't borrowed by other
 				// quotas. So that we will select the pods which subject to the
 				// same quota(namespace) with the lower priority than the
 				// preemptor's priority as potential victims in a node.
-				if p.GetPod().Namespace == pod.Namespace && corev1helpers.PodPriority(p.GetPod()) < podPriority {
+				if moreThanMinWithPreemptor && corev1helpers.PodPriority(p.GetPod()) < podPriority {
 					potentialVictims = append(potentialVictims, p)
 					if err := removePod(p); err != nil {
 						return nil, 0, fwk.AsStatus(err)
 					}
 				}
-
-			} else {
+			} else if preFilterState.reclaimable(elasticQuotaSnapshotState.elasticQuotaInfos, preemptorKey, key) ||
+				elasticQuotaSnapshotState.pools.reclaimable(preemptorKey, key, util.ResourceList(&podReq), nodeInfo.Node().Labels) {
 				// If Preemptor.Request + Quota.allocated <= Quota.min: It
 				// means that its min(guaranteed) resource is used or
 				// `borrowed` by other Quota. Potential victims in a node
 				// will be chosen from Quotas that allocates more resources
 				// than its min, i.e., borrowing resources from other
-				// Quotas.
-				if p.GetPod().Namespace != pod.Namespace && eqInfo.usedOverMin() {
-					potentialVictims = append(potentialVictims, p)
-					if err := removePod(p); err != nil {
-						return nil, 0, fwk.AsStatus(err)
-					}
+				// Quotas. In a tree of quotas, this applies below the
+				// closest common ancestor of both quotas, so that a quota
+				// over its min may still reclaim what other departments
+				// borrowed from its own, and capacity lent to siblings is
+				// reclaimed before capacity lent further away. With the
+				// FairShare borrowing policy, the pods of the quota trees
+				// borrowing more than their fair share may be preempted as
+				// well by a tree staying within its own, and so may the pods
+				// of the quotas over their min of a pool of nodes the node
+				// belongs to by a quota within its own.
+				potentialVictims = append(potentialVictims, p)
+				if err := removePod(p); err != nil {
+					return nil, 0, fwk.AsStatus(err)
 				}
 			}
 		}
 	} else {
 		for _, p := range nodeInfo.GetPods() {
-			_, withEQ := elasticQuotaInfos[p.GetPod().Namespace]
-			if withEQ {
+			if _, eqInfo := elasticQuotaSnapshotState.forPod(p.GetPod()); eqInfo != nil {
 				continue
 			}
 			if corev1helpers.PodPriority(p.GetPod()) < podPriority {
@@ -612,8 +928,9 @@ func (p *preemptor) SelectVictimsOnNode(
 	// after removing all the lower priority pods,
 	// we are almost done and this node is not suitable for preemption.
 	if preemptorWithElasticQuota {
-		if preemptorElasticQuotaInfo.usedOverMaxWith(&podReq) ||
-			elasticQuotaInfos.aggregatedUsedOverMinWith(podReq) {
+		if elasticQuotaSnapshotState.elasticQuotaInfos.usedOverMaxWith(preemptorKey, &podReq) ||
+			elasticQuotaSnapshotState.elasticQuotaTotals().usedOverMinWith(&podReq) ||
+			elasticQuotaSnapshotState.elasticQuotaInfos.overFairShareWith(preemptorKey, &podReq, preFilterState.waitingElasticQuotas) {
 			return nil, 0, fwk.NewStatus(fwk.Unschedulable, "global quota max exceeded")
 		}
 	}
@@ -643,7 +960,7 @@ func (p *preemptor) SelectVictimsOnNode(
 			logger.V(5).Info("Found a potential preemption victim on node", "pod", klog.KObj(pi.GetPod()), "node", klog.KObj(nodeInfo.Node()))
 		}
 
-		if preemptorWithElasticQuota && (preemptorElasticQuotaInfo.usedOverMaxWith(&nominatedPodsReqInEQWithPodReq) || elasticQuotaInfos.aggregatedUsedOverMinWith(nominatedPodsReqWithPodReq)) {
+		if preemptorWithElasticQuota && (elasticQuotaSnapshotState.elasticQuotaInfos.usedOverMaxWith(preemptorKey, &nominatedPodsReqInEQWithPodReq) || elasticQuotaSnapshotState.elasticQuotaTotals().usedOverMinWith(&nominatedPodsReqWithPodReq)) {
 			if err := removePod(pi); err != nil {
 				return false, err
 			}
@@ -678,39 +995,130 @@ func (p *preemptor) SelectVictimsOnNode(
 
 func (c *CapacityScheduling) addElasticQuota(obj interface{}) {
 	eq := obj.(*v1alpha1.ElasticQuota)
-	oldElasticQuotaInfo := c.elasticQuotaInfos[eq.Namespace]
+	key := elasticQuotaKey(eq)
+	oldElasticQuotaInfo := c.elasticQuotaInfos[key]
 	if oldElasticQuotaInfo != nil {
 		return
 	}
 
-	elasticQuotaInfo := newElasticQuotaInfo(eq.Namespace, eq.Spec.Min, eq.Spec.Max, nil)
+	elasticQuotaInfo := newElasticQuotaInfoFor(eq)
 
 	c.Lock()
 	defer c.Unlock()
-	c.elasticQuotaInfos[eq.Namespace] = elasticQuotaInfo
+	shared := c.hasElasticQuotas(eq.Namespace)
+	c.elasticQuotaInfos[key] = elasticQuotaInfo
+	c.indexElasticQuotas()
+	defer c.elasticQuotaTreesChanged()
+	if shared {
+		// Pods of the namespace may now count against the new quota.
+		c.recountElasticQuotas(eq.Namespace)
+	}
 }
 
 func (c *CapacityScheduling) updateElasticQuota(oldObj, newObj interface{}) {
 	oldEQ := oldObj.(*v1alpha1.ElasticQuota)
 	newEQ := newObj.(*v1alpha1.ElasticQuota)
-	newEQInfo := newElasticQuotaInfo(newEQ.Namespace, newEQ.Spec.Min, newEQ.Spec.Max, nil)
+	if apiequality.Semantic.DeepEqual(oldEQ.Spec, newEQ.Spec) {
+		// Only the status changed, e.g. the usage the controller reports, which the plugin keeps itself.
+		return
+	}
+	newEQInfo := newElasticQuotaInfoFor(newEQ)
 
 	c.Lock()
 	defer c.Unlock()
 
-	oldEQInfo := c.elasticQuotaInfos[oldEQ.Namespace]
+	oldKey, newKey := elasticQuotaKey(oldEQ), elasticQuotaKey(newEQ)
+	if oldKey != newKey || !sameElasticQuotaSelector(oldEQ, newEQ) {
+		// The quota selects other pods.
+		c.elasticQuotasChanged(oldKey)
+		defer c.elasticQuotaTreesChanged()
+		delete(c.elasticQuotaInfos, oldKey)
+		c.elasticQuotaInfos[newKey] = newEQInfo
+		c.indexElasticQuotas()
+		c.recountElasticQuotas(newEQ.Namespace)
+		return
+	}
+	oldEQInfo := c.elasticQuotaInfos[oldKey]
 	if oldEQInfo != nil {
 		newEQInfo.pods = oldEQInfo.pods
 		newEQInfo.Used = oldEQInfo.Used
+		newEQInfo.descendantsUsed = oldEQInfo.descendantsUsed
+	}
+	c.elasticQuotaInfos[newKey] = newEQInfo
+	c.indexElasticQuotas()
+	if oldEQInfo == nil || oldEQInfo.Parent != newEQInfo.Parent || !apiequality.Semantic.DeepEqual(oldEQ.Spec.Pools, newEQ.Spec.Pools) {
+		// The trees of quotas or the pools changed.
+		c.elasticQuotaTreesChanged()
+		return
+	}
+	// Only the min, the max or the weight of the quota changed: its usage, and the one of the trees and the
+	// pools, stay the same.
+	c.elasticQuotaPathChanged(newKey)
+	if !apiequality.Semantic.DeepEqual(oldEQ.Spec.Min, newEQ.Spec.Min) {
+		c.totals = nil
 	}
-	c.elasticQuotaInfos[newEQ.Namespace] = newEQInfo
 }
 
 func (c *CapacityScheduling) deleteElasticQuota(obj interface{}) {
 	elasticQuota := obj.(*v1alpha1.ElasticQuota)
 	c.Lock()
 	defer c.Unlock()
-	delete(c.elasticQuotaInfos, elasticQuota.Namespace)
+	key := elasticQuotaKey(elasticQuota)
+	delete(c.elasticQuotaInfos, key)
+	c.indexElasticQuotas()
+	c.elasticQuotasChanged(key)
+	defer c.elasticQuotaTreesChanged()
+	if c.hasElasticQuotas(elasticQuota.Namespace) {
+		// The pods of the quota may now count against another quota of the namespace.
+		c.recountElasticQuotas(elasticQuota.Namespace)
+	}
+}
+
+// hasElasticQuotas returns whether the namespace has any quota.
+func (c *CapacityScheduling) hasElasticQuotas(namespace string) bool {
+	return c.elasticQuotaInfos[namespace] != nil || len(c.selectors[namespace]) > 0
+}
+
+// forPod returns the key and the info of the quota the pod counts against. It must be called with the lock
+// held.
+func (c *CapacityScheduling) forPod(pod *v1.Pod) (string, *ElasticQuotaInfo) {
+	return c.selectors.forPod(c.elasticQuotaInfos, pod)
+}
+
+// indexElasticQuotas indexes again the quotas with a selector once quotas were added or removed. It must
+// be called with the lock held.
+func (c *CapacityScheduling) indexElasticQuotas() {
+	c.selectors = newElasticQuotaSelectors(c.elasticQuotaInfos)
+}
+
+// recountElasticQuotas computes again the usage of the quotas of the namespace from its pods, after pods
+// may have moved between them. Pods assumed but not counted by the accounting policy yet are counted later.
+func (c *CapacityScheduling) recountElasticQuotas(namespace string) {
+	if c.podLister == nil {
+		return
+	}
+	pods, err := c.podLister.Pods(namespace).List(labels.Everything())
+	if err != nil {
+		c.logger.Error(err, "Failed to list pods", "namespace", namespace)
+		return
+	}
+	for _, key := range append([]string{namespace}, c.selectors[namespace]...) {
+		if info := c.elasticQuotaInfos[key]; info != nil {
+			c.elasticQuotasChanged(key)
+			info.pods = sets.New[string]()
+			info.Used = framework.NewResource(nil)
+		}
+	}
+	for _, pod := range pods {
+		if !util.PodCountsTowardsElasticQuota(pod, c.accountingPolicy) {
+			continue
+		}
+		if _, info := c.forPod(pod); info != nil {
+			if err := info.addPodIfNotPresent(pod); err != nil {
+				c.logger.Error(err, "Failed to add Pod to its associated elasticQuota", "pod", klog.KObj(pod))
+			}
+		}
+	}
 }
 
 func (c *CapacityScheduling) addPod(obj interface{}) {
@@ -718,11 +1126,14 @@ func (c *CapacityScheduling) addPod(obj interface{}) {
 	logger := klog.FromContext(ctx)
 
 	pod := obj.(*v1.Pod)
+	if !util.PodCountsTowardsElasticQuota(pod, c.accountingPolicy) {
+		return
+	}
 
 	c.Lock()
 	defer c.Unlock()
 
-	elasticQuotaInfo := c.elasticQuotaInfos[pod.Namespace]
+	key, elasticQuotaInfo := c.forPod(pod)
 	// If elasticQuotaInfo is nil, try to list ElasticQuotas through elasticQuotaLister
 	if elasticQuotaInfo == nil {
 		var eqList v1alpha1.ElasticQuotaList
@@ -737,15 +1148,19 @@ func (c *CapacityScheduling) addPod(obj interface{}) {
 			return
 		}
 
-		if len(eqs) > 0 {
-			// only one elasticquota is supported in each namespace
-			eq := eqs[0]
-			elasticQuotaInfo = newElasticQuotaInfo(eq.Namespace, eq.Spec.Min, eq.Spec.Max, nil)
-			c.elasticQuotaInfos[eq.Namespace] = elasticQuotaInfo
+		for i := range eqs {
+			if key := elasticQuotaKey(&eqs[i]); c.elasticQuotaInfos[key] == nil {
+				c.elasticQuotaInfos[key] = newElasticQuotaInfoFor(&eqs[i])
+			}
+		}
+		c.indexElasticQuotas()
+		c.elasticQuotaTreesChanged()
+		if key, elasticQuotaInfo = c.forPod(pod); elasticQuotaInfo == nil {
+			return
 		}
 	}
 
-	err := elasticQuotaInfo.addPodIfNotPresent(pod)
+	err := c.addPodToElasticQuota(key, pod, pod.Spec.NodeName)
 	if err != nil {
 		logger.Error(err, "Failed to add Pod to its associated elasticQuota", "pod", klog.KObj(pod))
 	}
@@ -765,16 +1180,75 @@ func (c *CapacityScheduling) updatePod(oldObj, newObj interface{}) {
 		c.Lock()
 		defer c.Unlock()
 
-		elasticQuotaInfo := c.elasticQuotaInfos[newPod.Namespace]
+		key, elasticQuotaInfo := c.forPod(newPod)
 		if elasticQuotaInfo != nil {
-			err := elasticQuotaInfo.deletePodIfPresent(newPod)
+			err := c.deletePodFromElasticQuota(key, newPod)
 			if err != nil {
 				logger.Error(err, "Failed to delete Pod from its associated elasticQuota", "pod", klog.KObj(newPod))
 			}
 		}
+		return
+	}
+
+	// The pod may only count once running, e.g. with the Running accounting policy. Pods reserved by
+	// the scheduler already count.
+	if !util.PodCountsTowardsElasticQuota(oldPod, c.accountingPolicy) && util.PodCountsTowardsElasticQuota(newPod, c.accountingPolicy) {
+		c.addPod(newPod)
+		return
+	}
+
+	// The requests of the pod change while it is resized in place.
+	if apiequality.Semantic.DeepEqual(computePodResourceRequest(oldPod), computePodResourceRequest(newPod)) {
+		return
+	}
+	c.Lock()
+	defer c.Unlock()
+
+	key, elasticQuotaInfo := c.forPod(newPod)
+	if elasticQuotaInfo != nil {
+		if err := c.updatePodInElasticQuota(key, oldPod, newPod); err != nil {
+			logger.Error(err, "Failed to update Pod in its associated elasticQuota", "pod", klog.KObj(newPod))
+		}
 	}
 }
 
+func (c *CapacityScheduling) addPendingPod(obj interface{}) {
+	pod := obj.(*v1.Pod)
+	key, err := framework.GetPodKey(pod)
+	if err != nil {
+		c.logger.Error(err, "Failed to get the key of a pending Pod", "pod", klog.KObj(pod))
+		return
+	}
+	c.Lock()
+	defer c.Unlock()
+	if c.pendingPods != nil {
+		c.pendingPods[key] = pod
+	}
+	// A nomination cleared from the status is kept, the status may lag the nominations of the scheduler.
+	if nodeName := pod.Status.NominatedNodeName; nodeName != "" {
+		c.nominatePod(pod, nodeName)
+	}
+}
+
+func (c *CapacityScheduling) deletePendingPod(obj interface{}) {
+	var pod *v1.Pod
+	switch t := obj.(type) {
+	case *v1.Pod:
+		pod = t
+	case cache.DeletedFinalStateUnknown:
+		pod = t.Obj.(*v1.Pod)
+	}
+	key, err := framework.GetPodKey(pod)
+	if err != nil {
+		c.logger.Error(err, "Failed to get the key of a pending Pod", "pod", klog.KObj(pod))
+		return
+	}
+	c.Lock()
+	defer c.Unlock()
+	delete(c.pendingPods, key)
+	c.forgetNomination(key)
+}
+
 func (c *CapacityScheduling) deletePod(obj interface{}) {
 	logger := klog.FromContext(context.TODO())
 
@@ -782,23 +1256,351 @@ func (c *CapacityScheduling) deletePod(obj interface{}) {
 	c.Lock()
 	defer c.Unlock()
 
-	elasticQuotaInfo := c.elasticQuotaInfos[pod.Namespace]
+	key, elasticQuotaInfo := c.forPod(pod)
 	if elasticQuotaInfo != nil {
-		err := elasticQuotaInfo.deletePodIfPresent(pod)
+		err := c.deletePodFromElasticQuota(key, pod)
 		if err != nil {
 			logger.Error(err, "Failed to delete Pod from its associated elasticQuota", "pod", klog.KObj(pod))
 		}
 	}
 }
 
-// getElasticQuotasSnapshot will return the snapshot of elasticQuotas.
+// addPodToElasticQuota adds the pod, bound or reserved to the given node, to the quota of the given key, to
+// the totals and to the pools of the node. It must be called with the lock held.
+func (c *CapacityScheduling) addPodToElasticQuota(key string, pod *v1.Pod, nodeName string) error {
+	added, err := c.elasticQuotaInfos.addPod(key, pod)
+	if err != nil {
+		return err
+	}
+	if added {
+		c.elasticQuotaPathChanged(key)
+		if c.totals != nil {
+			c.totals.addPod(pod, 1)
+		}
+	}
+	// A pod reserved before the pools were counted again only counts in them once bound.
+	c.addPodToPools(key, pod, nodeName)
+	return nil
+}
+
+// deletePodFromElasticQuota deletes the pod from the quota of the given key, from the totals and from the
+// pools it counts in. It must be called with the lock held.
+func (c *CapacityScheduling) deletePodFromElasticQuota(key string, pod *v1.Pod) error {
+	c.removePodFromPools(pod)
+	deleted, err := c.elasticQuotaInfos.deletePod(key, pod)
+	if deleted {
+		c.elasticQuotaPathChanged(key)
+		if c.totals != nil {
+			c.totals.addPod(pod, -1)
+		}
+	}
+	return err
+}
+
+// updatePodInElasticQuota replaces the requests of oldPod by the ones of newPod in the quota of the given
+// key, in the totals and in the pools the pod counts in. It must be called with the lock held.
+func (c *CapacityScheduling) updatePodInElasticQuota(key string, oldPod, newPod *v1.Pod) error {
+	updated, err := c.elasticQuotaInfos.updatePod(key, oldPod, newPod)
+	if updated {
+		c.elasticQuotaPathChanged(key)
+		if c.totals != nil {
+			c.totals.updatePod(oldPod, newPod)
+		}
+		c.updatePodInPools(newPod)
+	}
+	return err
+}
+
+// addPodToPools counts the pod, bound or reserved to the given node, against the quota of the given key in
+// the pools of the node, unless it counts already. The pods of the nodes not known yet wait for them. It
+// must be called with the lock held.
+func (c *CapacityScheduling) addPodToPools(key string, pod *v1.Pod, nodeName string) {
+	if len(c.pools) == 0 || nodeName == "" || pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+		return
+	}
+	podKey, err := framework.GetPodKey(pod)
+	if err != nil {
+		return
+	}
+	if _, ok := c.pooledPods[podKey]; ok {
+		return
+	}
+	nodeLabels, ok := c.nodeLabels(nodeName)
+	if !ok {
+		if c.podsWaitingForNodes == nil {
+			c.podsWaitingForNodes = make(map[string][]*v1.Pod)
+		}
+		c.podsWaitingForNodes[nodeName] = append(c.podsWaitingForNodes[nodeName], pod)
+		return
+	}
+	pooled := pooledPod{key: key, pools: c.pools.matching(nodeLabels), request: util.PodRequests(pod)}
+	if c.pooledPods == nil {
+		c.pooledPods = make(map[string]pooledPod)
+	}
+	c.pooledPods[podKey] = pooled
+	if len(pooled.pools) > 0 {
+		c.pools.update(pooled.pools, key, pooled.request, quota.Add)
+		c.poolsChanged = true
+	}
+}
+
+// removePodFromPools no longer counts the pod in the pools it counts in. It must be called with the lock held.
+func (c *CapacityScheduling) removePodFromPools(pod *v1.Pod) {
+	podKey, err := framework.GetPodKey(pod)
+	if err != nil {
+		return
+	}
+	pooled, ok := c.pooledPods[podKey]
+	if !ok {
+		return
+	}
+	delete(c.pooledPods, podKey)
+	if len(pooled.pools) > 0 {
+		c.pools.update(pooled.pools, pooled.key, pooled.request, quota.Subtract)
+		c.poolsChanged = true
+	}
+}
+
+// updatePodInPools replaces the requests the pod counts in its pools by its current ones, e.g. once it was
+// resized in place. It must be called with the lock held.
+func (c *CapacityScheduling) updatePodInPools(pod *v1.Pod) {
+	podKey, err := framework.GetPodKey(pod)
+	if err != nil {
+		return
+	}
+	pooled, ok := c.pooledPods[podKey]
+	if !ok || len(pooled.pools) == 0 {
+		return
+	}
+	request := util.PodRequests(pod)
+	c.pools.update(pooled.pools, pooled.key, pooled.request, quota.Subtract)
+	c.pools.update(pooled.pools, pooled.key, request, quota.Add)
+	pooled.request = request
+	c.pooledPods[podKey] = pooled
+	c.poolsChanged = true
+}
+
+// nodeLabels returns the labels of the node of the given name, and whether the node is known.
+func (c *CapacityScheduling) nodeLabels(nodeName string) (map[string]string, bool) {
+	if c.nodeLister == nil {
+		return nil, true
+	}
+	node, err := c.nodeLister.Get(nodeName)
+	if err != nil {
+		return nil, false
+	}
+	return node.Labels, true
+}
+
+// recountElasticQuotaPools computes again the pools the quotas name and their usage from the pods counting
+// against the quotas, once the quotas or the labels of the nodes changed. It must be called with the lock
+// held.
+func (c *CapacityScheduling) recountElasticQuotaPools() {
+	c.pools = newElasticQuotaPools(c.elasticQuotaInfos)
+	c.pooledPods, c.podsWaitingForNodes = nil, nil
+	c.poolsChanged = true
+	if len(c.pools) == 0 || c.podLister == nil {
+		return
+	}
+	pods, err := c.podLister.List(labels.Everything())
+	if err != nil {
+		c.logger.Error(err, "Failed to list pods")
+		return
+	}
+	for _, pod := range pods {
+		if key, info := c.forPod(pod); info != nil && info.hasPod(pod) {
+			c.addPodToPools(key, pod, pod.Spec.NodeName)
+		}
+	}
+}
+
+// addNode counts the pods waiting for the node in the pools of the node.
+func (c *CapacityScheduling) addNode(obj interface{}) {
+	node, ok := obj.(*v1.Node)
+	if !ok {
+		return
+	}
+	c.Lock()
+	defer c.Unlock()
+	pods := c.podsWaitingForNodes[node.Name]
+	delete(c.podsWaitingForNodes, node.Name)
+	for _, pod := range pods {
+		if key, info := c.forPod(pod); info != nil && info.hasPod(pod) {
+			c.addPodToPools(key, pod, node.Name)
+		}
+	}
+}
+
+// updateNode counts the pods in the pools again once the labels of a node changed, as it may have joined
+// or left pools.
+func (c *CapacityScheduling) updateNode(oldObj, newObj interface{}) {
+	oldNode, ok := oldObj.(*v1.Node)
+	if !ok {
+		return
+	}
+	newNode, ok := newObj.(*v1.Node)
+	if !ok || labels.Equals(oldNode.Labels, newNode.Labels) {
+		return
+	}
+	c.Lock()
+	defer c.Unlock()
+	if len(c.pools) > 0 {
+		c.recountElasticQuotaPools()
+	}
+}
+
+// newElasticQuotaInfoFor returns the info of the given ElasticQuota, without usage.
+func newElasticQuotaInfoFor(eq *v1alpha1.ElasticQuota) *ElasticQuotaInfo {
+	info := newElasticQuotaInfo(eq.Namespace, eq.Spec.Min, eq.Spec.Max, nil)
+	if util.ElasticQuotaHasSelector(eq) {
+		info.selector = eq
+	}
+	if eq.Spec.Weight != nil {
+		info.Weight = int64(*eq.Spec.Weight)
+	}
+	info.pools = eq.Spec.Pools
+	if parent := eq.Spec.Parent; parent != nil {
+		info.Parent = parent.Namespace
+		if parent.Name != "" {
+			info.Parent += "/" + parent.Name
+		}
+	}
+	return info
+}
+
+// sameElasticQuotaSelector returns whether both ElasticQuotas select the same pods.
+func sameElasticQuotaSelector(a, b *v1alpha1.ElasticQuota) bool {
+	return apiequality.Semantic.DeepEqual(a.Spec.Selector, b.Spec.Selector) &&
+		slices.Equal(a.Spec.PriorityClassNames, b.Spec.PriorityClassNames)
+}
+
+// snapshotElasticQuota returns the snapshot of elasticQuotas, shared with the other cycles. Only the quotas
+// changed since the previous snapshot are copied again, the infos the previous cycles hold stay as they were.
 func (c *CapacityScheduling) snapshotElasticQuota() *ElasticQuotaSnapshotState {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.snapshot == nil {
+		c.snapshot = c.elasticQuotaInfos.clone()
+	} else {
+		for key := range c.changedElasticQuotas {
+			if info := c.elasticQuotaInfos[key]; info != nil {
+				c.snapshot[key] = info.clone()
+			} else {
+				delete(c.snapshot, key)
+			}
+		}
+	}
+	c.changedElasticQuotas = nil
+	if c.totals == nil {
+		totals := newElasticQuotaTotals(c.elasticQuotaInfos)
+		c.totals = &totals
+	}
+	if c.poolsChanged {
+		c.snapshotPools = c.pools.clone()
+		c.poolsChanged = false
+	}
+	totals := c.totals.clone()
+	return &ElasticQuotaSnapshotState{
+		elasticQuotaInfos: c.snapshot,
+		selectors:         c.selectors,
+		sharedMap:         true,
+		owned:             sets.New[string](),
+		totals:            &totals,
+		pools:             c.snapshotPools,
+		sharedPools:       true,
+	}
+}
+
+// elasticQuotaPathChanged records the quota of the given key and its ancestors as changed, once the usage
+// of the quota changed. It must be called with the lock held.
+func (c *CapacityScheduling) elasticQuotaPathChanged(key string) {
+	c.elasticQuotasChanged(c.elasticQuotaInfos.pathKeys(key)...)
+}
+
+// elasticQuotaTreesChanged computes again the usage of the descendants of every quota, the pools and
+// their usage once the trees of quotas or their usage changed at once, and records them all as changed.
+// The totals are computed again by the next snapshot. It must be called with the lock held.
+func (c *CapacityScheduling) elasticQuotaTreesChanged() {
+	c.elasticQuotaInfos.computeDescendantsUsed()
+	c.elasticQuotasChanged(slices.Collect(maps.Keys(c.elasticQuotaInfos))...)
+	c.totals = nil
+	c.recountElasticQuotaPools()
+	c.indexNominations()
+}
+
+// elasticQuotasChanged records the quotas of the given keys as changed, to be copied again by the next
+// snapshot. It must be called with the lock held.
+func (c *CapacityScheduling) elasticQuotasChanged(keys ...string) {
+	if c.changedElasticQuotas == nil {
+		c.changedElasticQuotas = sets.New[string]()
+	}
+	c.changedElasticQuotas.Insert(keys...)
+}
+
+// nominatedNodeNames returns the nodes the pods PreFilter adds up may be nominated to: the pods of the quota
+// of the given key, and the ones of the other quotas within their min in the snapshot.
+func (c *CapacityScheduling) nominatedNodeNames(s *ElasticQuotaSnapshotState, eqKey string) []string {
 	c.RLock()
 	defer c.RUnlock()
 
-	elasticQuotaInfosDeepCopy := c.elasticQuotaInfos.clone()
-	return &ElasticQuotaSnapshotState{
-		elasticQuotaInfos: elasticQuotaInfosDeepCopy,
+	nodeNames := sets.New[string]()
+	for key, pods := range c.nominatedPods {
+		if key != eqKey {
+			if info := s.elasticQuotaInfos[key]; info == nil || info.usedOverMin() {
+				continue
+			}
+		}
+		for _, n := range pods {
+			nodeNames.Insert(n.nodeName)
+		}
+	}
+	return nodeNames.UnsortedList()
+}
+
+// nominatePod records the node the pod is nominated to, by the key of its quota. It must be called with the
+// lock held.
+func (c *CapacityScheduling) nominatePod(pod *v1.Pod, nodeName string) {
+	podKey, err := framework.GetPodKey(pod)
+	if err != nil {
+		return
+	}
+	c.forgetNomination(podKey)
+	key, _ := c.forPod(pod)
+	if c.nominatedPods == nil {
+		c.nominatedPods = make(map[string]map[string]nomination)
+		c.nominatedElasticQuotas = make(map[string]string)
+	}
+	if c.nominatedPods[key] == nil {
+		c.nominatedPods[key] = make(map[string]nomination)
+	}
+	c.nominatedPods[key][podKey] = nomination{pod: pod, nodeName: nodeName}
+	c.nominatedElasticQuotas[podKey] = key
+}
+
+// forgetNomination no longer records the node the pod of the given key is nominated to. It must be called
+// with the lock held.
+func (c *CapacityScheduling) forgetNomination(podKey string) {
+	key, ok := c.nominatedElasticQuotas[podKey]
+	if !ok {
+		return
+	}
+	delete(c.nominatedElasticQuotas, podKey)
+	delete(c.nominatedPods[key], podKey)
+	if len(c.nominatedPods[key]) == 0 {
+		delete(c.nominatedPods, key)
+	}
+}
+
+// indexNominations records the nominated pods again by the keys of their quotas, once the quotas changed.
+// It must be called with the lock held.
+func (c *CapacityScheduling) indexNominations() {
+	nominatedPods := c.nominatedPods
+	c.nominatedPods, c.nominatedElasticQuotas = nil, nil
+	for _, pods := range nominatedPods {
+		for _, n := range pods {
+			c.nominatePod(n.pod, n.nodeName)
+		}
 	}
 }
 
@@ -837,10 +1639,13 @@ func getPDBLister(informerFactory informers.SharedInformerFactory) policylisters
 // computePodResourceRequest returns a framework.Resource that covers the largest
 // width in each resource dimension. Because init-containers run sequentially, we collect
 // the max in each dimension iteratively. In contrast, we sum the resource vectors for
-// regular containers since they run simultaneously.
+// regular containers since they run simultaneously. Restartable init containers (sidecars) keep
+// running along with the regular containers, so they are summed with them, and with the init
+// containers started after them. It is the same calculation as the ElasticQuota controller's.
 //
-// If Pod Overhead is specified and the feature gate is set, the resources defined for Overhead
-// are added to the calculated Resource request sum
+// If Pod Overhead is specified, the resources defined for Overhead are added to the calculated
+// Resource request sum. While the pod is resized in place, the largest of its desired, allocated
+// and actuated requests count.
 //
 // Example:
 //
@@ -863,22 +1668,7 @@ func getPDBLister(informerFactory informers.SharedInformerFactory) policylisters
 //
 // Result: CPU: 3, Memory: 3G
 func computePodResourceRequest(pod *v1.Pod) *framework.Resource {
-	result := &framework.Resource{}
-	for _, container := range pod.Spec.Containers {
-		result.Add(container.Resources.Requests)
-	}
-
-	// take max_resource(sum_pod, any_init_container)
-	for _, container := range pod.Spec.InitContainers {
-		result.SetMaxResource(container.Resources.Requests)
-	}
-
-	// If Overhead is being utilized, add to the total requests for the pod
-	if pod.Spec.Overhead != nil {
-		result.Add(pod.Spec.Overhead)
-	}
-
-	return result
+	return framework.NewResource(util.PodRequests(pod))
 }
 
 // filterPodsWithPDBViolation groups the given "pods" into two groups of "violatingPods"
@@ -937,3 +1727,9 @@ func filterPodsWithPDBViolation(podInfos []fwk.PodInfo, pdbs []*policy.PodDisrup
 func assignedPod(pod *v1.Pod) bool {
 	return len(pod.Spec.NodeName) != 0
 }
+
+// pendingPod selects pods waiting to be scheduled.
+func pendingPod(pod *v1.Pod) bool {
+	return !assignedPod(pod) && pod.DeletionTimestamp == nil && len(pod.Spec.SchedulingGates) == 0 &&
+		pod.Status.Phase != v1.PodSucceeded && pod.Status.Phase != v1.PodFailed
+}
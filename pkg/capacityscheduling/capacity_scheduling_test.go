@@ -31,7 +31,10 @@ import (
 	apiruntime "k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/informers"
 	clientsetfake "k8s.io/client-go/kubernetes/fake"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/events"
+	"k8s.io/klog/v2"
 	extenderv1 "k8s.io/kube-scheduler/extender/v1"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
 	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/defaultbinder"
@@ -45,8 +48,10 @@ import (
 	st "k8s.io/kubernetes/pkg/scheduler/testing"
 	tf "k8s.io/kubernetes/pkg/scheduler/testing/framework"
 	imageutils "k8s.io/kubernetes/test/utils/image"
+	"k8s.io/utils/ptr"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/scheduler-plugins/apis/config"
 	"sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
 	testutil "sigs.k8s.io/scheduler-plugins/test/util"
 )
@@ -64,11 +69,36 @@ func TestPreFilter(t *testing.T) {
 		memReq       int64
 	}
 
+	fairShareQuotas := func() map[string]*ElasticQuotaInfo {
+		return map[string]*ElasticQuotaInfo{
+			"ns1": {
+				Namespace: "ns1",
+				Min:       &framework.Resource{Memory: 1000},
+				Max:       &framework.Resource{Memory: 5000},
+				Used:      &framework.Resource{Memory: 1000},
+			},
+			"ns2": {
+				Namespace: "ns2",
+				Min:       &framework.Resource{Memory: 1000},
+				Max:       &framework.Resource{Memory: 5000},
+				Used:      &framework.Resource{Memory: 1000},
+			},
+			"ns3": {
+				Namespace: "ns3",
+				Min:       &framework.Resource{Memory: 2000},
+				Max:       &framework.Resource{Memory: 5000},
+				Used:      &framework.Resource{},
+			},
+		}
+	}
+
 	tests := []struct {
-		name          string
-		podInfos      []podInfo
-		elasticQuotas map[string]*ElasticQuotaInfo
-		expected      []fwk.Code
+		name            string
+		podInfos        []podInfo
+		elasticQuotas   map[string]*ElasticQuotaInfo
+		borrowingPolicy config.ElasticQuotaBorrowingPolicy
+		pendingPods     []podInfo
+		expected        []fwk.Code
 	}{
 		{
 			name: "pod subjects to ElasticQuota",
@@ -140,6 +170,49 @@ func TestPreFilter(t *testing.T) {
 				fwk.Success,
 			},
 		},
+		{
+			name: "borrowing over the fair share while another quota waits",
+			podInfos: []podInfo{
+				{podName: "ns1-p1", podNamespace: "ns1", memReq: 600},
+				{podName: "ns1-p2", podNamespace: "ns1", memReq: 1200},
+			},
+			elasticQuotas:   fairShareQuotas(),
+			borrowingPolicy: config.ElasticQuotaBorrowingFairShare,
+			pendingPods: []podInfo{
+				{podName: "ns2-p1", podNamespace: "ns2", memReq: 500},
+			},
+			expected: []fwk.Code{
+				fwk.Success,
+				fwk.Unschedulable,
+			},
+		},
+		{
+			name: "borrowing over the fair share while no other quota waits",
+			podInfos: []podInfo{
+				{podName: "ns1-p1", podNamespace: "ns1", memReq: 1200},
+			},
+			elasticQuotas:   fairShareQuotas(),
+			borrowingPolicy: config.ElasticQuotaBorrowingFairShare,
+			pendingPods: []podInfo{
+				{podName: "ns1-p2", podNamespace: "ns1", memReq: 500},
+			},
+			expected: []fwk.Code{
+				fwk.Success,
+			},
+		},
+		{
+			name: "borrowing first come",
+			podInfos: []podInfo{
+				{podName: "ns1-p1", podNamespace: "ns1", memReq: 1200},
+			},
+			elasticQuotas: fairShareQuotas(),
+			pendingPods: []podInfo{
+				{podName: "ns2-p1", podNamespace: "ns2", memReq: 500},
+			},
+			expected: []fwk.Code{
+				fwk.Success,
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -166,6 +239,12 @@ func TestPreFilter(t *testing.T) {
 			cs := &CapacityScheduling{
 				elasticQuotaInfos: tt.elasticQuotas,
 				fh:                fwk,
+				borrowingPolicy:   tt.borrowingPolicy,
+				pendingPods:       map[string]*v1.Pod{},
+			}
+			for _, podInfo := range tt.pendingPods {
+				pod := makePod(podInfo.podName, podInfo.podNamespace, podInfo.memReq, 0, 0, 0, podInfo.podName, "")
+				cs.pendingPods[podInfo.podNamespace+"/"+podInfo.podName] = pod
 			}
 
 			pods := make([]*v1.Pod, 0)
@@ -184,6 +263,173 @@ func TestPreFilter(t *testing.T) {
 	}
 }
 
+func TestFilter(t *testing.T) {
+	gpus := func(n int64) v1.ResourceList {
+		return v1.ResourceList{ResourceGPU: *resource.NewQuantity(n, resource.DecimalSI)}
+	}
+	poolA := func(min, max v1.ResourceList) v1alpha1.ElasticQuotaPool {
+		return v1alpha1.ElasticQuotaPool{
+			Name:         "a",
+			NodeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"gpu": "a"}},
+			Min:          min,
+			Max:          max,
+		}
+	}
+	eq1 := makeEQ("ns1", "eq1", nil, nil)
+	eq1.Spec.Pools = []v1alpha1.ElasticQuotaPool{poolA(gpus(2), gpus(3))}
+	eq2 := makeEQ("ns2", "eq2", nil, nil)
+	eq2.Spec.Pools = []v1alpha1.ElasticQuotaPool{poolA(gpus(2), nil)}
+
+	nodes := []*v1.Node{
+		st.MakeNode().Name("node-a").Label("gpu", "a").Obj(),
+		st.MakeNode().Name("node-b").Label("gpu", "b").Obj(),
+	}
+	pods := []*v1.Pod{
+		makePod("ns1-p1", "ns1", 0, 0, 2, midPriority, "ns1-p1", "node-a"),
+		makePod("ns2-p1", "ns2", 0, 0, 1, midPriority, "ns2-p1", "node-a"),
+		makePod("ns2-p2", "ns2", 0, 0, 4, midPriority, "ns2-p2", "node-b"),
+		makePodWithStatus(makePod("ns2-p3", "ns2", 0, 0, 4, midPriority, "ns2-p3", "node-a"), v1.PodSucceeded),
+	}
+
+	tests := []struct {
+		name     string
+		pod      *v1.Pod
+		expected map[string]fwk.Code
+	}{
+		{
+			name:     "within the max and the aggregated min of the pool",
+			pod:      makePod("p", "ns1", 0, 0, 1, midPriority, "p", ""),
+			expected: map[string]fwk.Code{"node-a": fwk.Success, "node-b": fwk.Success},
+		},
+		{
+			name:     "over the max of the pool",
+			pod:      makePod("p", "ns1", 0, 0, 2, midPriority, "p", ""),
+			expected: map[string]fwk.Code{"node-a": fwk.Unschedulable, "node-b": fwk.Success},
+		},
+		{
+			name:     "over the aggregated min of the pool",
+			pod:      makePod("p", "ns2", 0, 0, 2, midPriority, "p", ""),
+			expected: map[string]fwk.Code{"node-a": fwk.Unschedulable, "node-b": fwk.Success},
+		},
+		{
+			name:     "requesting resources the pool does not limit",
+			pod:      makePod("p", "ns2", 100, 100, 0, midPriority, "p", ""),
+			expected: map[string]fwk.Code{"node-a": fwk.Success, "node-b": fwk.Success},
+		},
+		{
+			name:     "without elasticQuotaInfo",
+			pod:      makePod("p", "ns3", 0, 0, 8, midPriority, "p", ""),
+			expected: map[string]fwk.Code{"node-a": fwk.Success, "node-b": fwk.Success},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cs := &CapacityScheduling{
+				elasticQuotaInfos: ElasticQuotaInfos{
+					"ns1": newElasticQuotaInfoFor(eq1),
+					"ns2": newElasticQuotaInfoFor(eq2),
+				},
+				nodeLister: newNodeLister(t, nodes),
+			}
+			cs.indexElasticQuotas()
+			cs.elasticQuotaTreesChanged()
+			for _, pod := range pods {
+				cs.addPod(pod)
+			}
+			nodeInfos, err := testutil.NewFakeSharedLister(pods, nodes).NodeInfos().List()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			state := framework.NewCycleState()
+			state.Write(ElasticQuotaSnapshotKey, cs.snapshotElasticQuota())
+			state.Write(preFilterStateKey, &PreFilterState{podReq: *computePodResourceRequest(tt.pod)})
+
+			for _, nodeInfo := range nodeInfos {
+				got := cs.Filter(context.TODO(), state, tt.pod, nodeInfo)
+				if got.Code() != tt.expected[nodeInfo.Node().Name] {
+					t.Errorf("expected %v on %v, got %v", tt.expected[nodeInfo.Node().Name], nodeInfo.Node().Name, got)
+				}
+			}
+		})
+	}
+}
+
+func TestElasticQuotaPoolsUsage(t *testing.T) {
+	eq1 := makeEQ("ns1", "eq1", nil, nil)
+	eq1.Spec.Pools = []v1alpha1.ElasticQuotaPool{{
+		Name:         "a",
+		NodeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"gpu": "a"}},
+		Min:          v1.ResourceList{ResourceGPU: *resource.NewQuantity(2, resource.DecimalSI)},
+	}}
+	nodeA := st.MakeNode().Name("node-a").Label("gpu", "a").Obj()
+	bound := makePod("ns1-p1", "ns1", 0, 0, 1, midPriority, "ns1-p1", "node-a")
+	reserved := makePod("ns1-p2", "ns1", 0, 0, 1, midPriority, "ns1-p2", "")
+
+	nodeIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	podIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	if err := podIndexer.Add(bound); err != nil {
+		t.Fatal(err)
+	}
+	cs := &CapacityScheduling{
+		elasticQuotaInfos: ElasticQuotaInfos{"ns1": newElasticQuotaInfoFor(eq1)},
+		nodeLister:        corelisters.NewNodeLister(nodeIndexer),
+		podLister:         corelisters.NewPodLister(podIndexer),
+	}
+	cs.indexElasticQuotas()
+	cs.elasticQuotaTreesChanged()
+	usedGPUs := func() int64 {
+		used := cs.snapshotElasticQuota().pools["a"].used["ns1"][ResourceGPU]
+		return used.Value()
+	}
+
+	// The pod bound to a node not known yet counts once the node is.
+	cs.addPod(bound)
+	if got := usedGPUs(); got != 0 {
+		t.Errorf("expected the pod of an unknown node not to count, got %v", got)
+	}
+	if err := nodeIndexer.Add(nodeA); err != nil {
+		t.Fatal(err)
+	}
+	cs.addNode(nodeA)
+	if got := usedGPUs(); got != 1 {
+		t.Errorf("expected the pod to count once its node is known, got %v", got)
+	}
+
+	if got := cs.Reserve(context.TODO(), framework.NewCycleState(), reserved, "node-a"); !got.IsSuccess() {
+		t.Fatalf("expected success, got %v", got.Message())
+	}
+	if got := usedGPUs(); got != 2 {
+		t.Errorf("expected the reserved pod to count, got %v", got)
+	}
+	cs.Unreserve(context.TODO(), framework.NewCycleState(), reserved, "node-a")
+	if got := usedGPUs(); got != 1 {
+		t.Errorf("expected the unreserved pod no longer to count, got %v", got)
+	}
+
+	// The node leaves the pool.
+	relabeled := st.MakeNode().Name("node-a").Label("gpu", "b").Obj()
+	if err := nodeIndexer.Update(relabeled); err != nil {
+		t.Fatal(err)
+	}
+	cs.updateNode(nodeA, relabeled)
+	if got := usedGPUs(); got != 0 {
+		t.Errorf("expected the pods of a node leaving the pool no longer to count, got %v", got)
+	}
+}
+
+// newNodeLister returns a lister of the given nodes.
+func newNodeLister(t *testing.T, nodes []*v1.Node) corelisters.NodeLister {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, node := range nodes {
+		if err := indexer.Add(node); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return corelisters.NewNodeLister(indexer)
+}
+
 func TestPostFilter(t *testing.T) {
 	res := map[v1.ResourceName]string{v1.ResourceMemory: "150"}
 	tests := []struct {
@@ -1370,6 +1616,7 @@ func TestUpdatePod(t *testing.T) {
 	tests := []struct {
 		name         string
 		ns           []string
+		policy       config.ElasticQuotaAccountingPolicy
 		elasticQuota *v1alpha1.ElasticQuota
 		updatePods   [][2]*v1.Pod
 		expected     map[string]*ElasticQuotaInfo
@@ -1384,6 +1631,36 @@ func TestUpdatePod(t *testing.T) {
 				},
 			},
 			ns: []string{"ns1"},
+			expected: map[string]*ElasticQuotaInfo{
+				"ns1": {
+					Namespace: "ns1",
+					// A terminated pod never runs again, so it does not count once it is reported running.
+					pods: sets.Set[string]{},
+					Max: &framework.Resource{
+						MilliCPU: 100,
+						Memory:   1000,
+					},
+					Min: &framework.Resource{
+						MilliCPU: 10,
+						Memory:   100,
+					},
+					Used: &framework.Resource{
+						MilliCPU: 0,
+						Memory:   0,
+					},
+				},
+			},
+		},
+		{
+			name:         "Update Pod With Pod Status PodPending and PodRunning",
+			elasticQuota: makeEQ("ns1", "t1-eq1", makeResourceList(100, 1000), makeResourceList(10, 100)),
+			updatePods: [][2]*v1.Pod{
+				{
+					makePodWithStatus(makePod("t1-p1", "ns1", 100, 30, 0, midPriority, "t1-p1", "node-a"), v1.PodPending),
+					makePodWithStatus(makePod("t1-p1", "ns1", 100, 30, 0, highPriority, "t1-p1", "node-a"), v1.PodRunning),
+				},
+			},
+			ns: []string{"ns1"},
 			expected: map[string]*ElasticQuotaInfo{
 				"ns1": {
 					Namespace: "ns1",
@@ -1406,6 +1683,75 @@ func TestUpdatePod(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:         "Update Pod With Pod Status PodPending and PodRunning under the Running accounting policy",
+			policy:       config.ElasticQuotaAccountingRunning,
+			elasticQuota: makeEQ("ns1", "t1-eq1", makeResourceList(100, 1000), makeResourceList(10, 100)),
+			updatePods: [][2]*v1.Pod{
+				{
+					makePodWithStatus(makePod("t1-p3", "ns1", 100, 30, 0, midPriority, "t1-p3", "node-a"), v1.PodPending),
+					makePodWithStatus(makePod("t1-p3", "ns1", 100, 30, 0, midPriority, "t1-p3", "node-a"), v1.PodRunning),
+				},
+				{
+					makePodWithStatus(makePod("t1-p4", "ns1", 100, 20, 0, midPriority, "t1-p4", "node-a"), v1.PodPending),
+					makePodWithStatus(makePod("t1-p4", "ns1", 100, 20, 0, midPriority, "t1-p4", "node-a"), v1.PodPending),
+				},
+			},
+			ns: []string{"ns1"},
+			expected: map[string]*ElasticQuotaInfo{
+				"ns1": {
+					Namespace: "ns1",
+					pods:      sets.New("t1-p3"),
+					Max: &framework.Resource{
+						MilliCPU: 100,
+						Memory:   1000,
+					},
+					Min: &framework.Resource{
+						MilliCPU: 10,
+						Memory:   100,
+					},
+					Used: &framework.Resource{
+						MilliCPU: 30,
+						Memory:   100,
+						ScalarResources: map[v1.ResourceName]int64{
+							ResourceGPU: 0,
+						},
+					},
+				},
+			},
+		},
+		{
+			name:         "Update Pod resized in place",
+			elasticQuota: makeEQ("ns1", "t1-eq1", makeResourceList(100, 1000), makeResourceList(10, 100)),
+			updatePods: [][2]*v1.Pod{
+				{
+					makePodWithStatus(makePod("t1-p5", "ns1", 100, 30, 0, midPriority, "t1-p5", "node-a"), v1.PodRunning),
+					makePodWithStatus(makePod("t1-p5", "ns1", 200, 50, 0, midPriority, "t1-p5", "node-a"), v1.PodRunning),
+				},
+			},
+			ns: []string{"ns1"},
+			expected: map[string]*ElasticQuotaInfo{
+				"ns1": {
+					Namespace: "ns1",
+					pods:      sets.New("t1-p5"),
+					Max: &framework.Resource{
+						MilliCPU: 100,
+						Memory:   1000,
+					},
+					Min: &framework.Resource{
+						MilliCPU: 10,
+						Memory:   100,
+					},
+					Used: &framework.Resource{
+						MilliCPU: 50,
+						Memory:   200,
+						ScalarResources: map[v1.ResourceName]int64{
+							ResourceGPU: 0,
+						},
+					},
+				},
+			},
+		},
 		{
 			name:         "Update Pod With Pod Status PodPending and PodFailed",
 			elasticQuota: makeEQ("ns1", "t1-eq1", makeResourceList(100, 1000), makeResourceList(10, 100)),
@@ -1464,6 +1810,7 @@ func TestUpdatePod(t *testing.T) {
 			cs := &CapacityScheduling{
 				elasticQuotaInfos: map[string]*ElasticQuotaInfo{},
 				fh:                fwk,
+				accountingPolicy:  tt.policy,
 			}
 			cs.addElasticQuota(tt.elasticQuota)
 			for _, pods := range tt.updatePods {
@@ -1598,6 +1945,366 @@ func TestDeletePod(t *testing.T) {
 	}
 }
 
+func TestElasticQuotasPerNamespace(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	registeredPlugins := []tf.RegisterPluginFunc{
+		tf.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+		tf.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+	}
+	fwk, err := tf.NewFramework(
+		ctx, registeredPlugins, "",
+		frameworkruntime.WithPodNominator(testutil.NewPodNominator(nil)),
+		frameworkruntime.WithSnapshotSharedLister(testutil.NewFakeSharedLister(make([]*v1.Pod, 0), make([]*v1.Node, 0))),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	batch := makeEQ("ns1", "batch", makeResourceList(100, 1000), makeResourceList(10, 100))
+	batch.Spec.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "batch"}}
+	critical := makeEQ("ns1", "critical", makeResourceList(100, 1000), makeResourceList(10, 100))
+	critical.Spec.PriorityClassNames = []string{"critical"}
+	cs := &CapacityScheduling{
+		elasticQuotaInfos: map[string]*ElasticQuotaInfo{},
+		fh:                fwk,
+	}
+	cs.addElasticQuota(makeEQ("ns1", "default", makeResourceList(100, 1000), makeResourceList(10, 100)))
+	cs.addElasticQuota(batch)
+	cs.addElasticQuota(critical)
+
+	batchPod := makePod("t1-p1", "ns1", 50, 10, 0, midPriority, "t1-p1", "node-a")
+	batchPod.Labels = map[string]string{"tier": "batch"}
+	criticalPod := makePod("t1-p2", "ns1", 50, 20, 0, midPriority, "t1-p2", "node-a")
+	criticalPod.Spec.PriorityClassName = "critical"
+	pods := []*v1.Pod{
+		batchPod,
+		criticalPod,
+		makePod("t1-p3", "ns1", 50, 30, 0, midPriority, "t1-p3", "node-a"),
+	}
+	for _, pod := range pods {
+		cs.addPod(pod)
+	}
+
+	for key, want := range map[string]int64{"ns1/batch": 10, "ns1/critical": 20, "ns1": 30} {
+		info := cs.elasticQuotaInfos[key]
+		if info == nil {
+			t.Errorf("expected ElasticQuotaInfo %v, got none", key)
+			continue
+		}
+		if info.Used.MilliCPU != want {
+			t.Errorf("expected %v to use %v milli CPU, got %v", key, want, info.Used.MilliCPU)
+		}
+	}
+	if _, info := cs.forPod(makePod("t1-p4", "ns2", 50, 40, 0, midPriority, "t1-p4", "node-a")); info != nil {
+		t.Errorf("expected no ElasticQuota in ns2, got %v", info)
+	}
+
+	both := makePod("t1-p5", "ns1", 50, 10, 0, midPriority, "t1-p5", "node-a")
+	both.Labels = map[string]string{"tier": "batch"}
+	both.Spec.PriorityClassName = "critical"
+	if key, _ := cs.forPod(both); key != "ns1/batch" {
+		t.Errorf("expected %v to count against the first quota selecting it, ns1/batch, got %v", both.Name, key)
+	}
+
+	cs.deleteElasticQuota(batch)
+	if key, _ := cs.forPod(batchPod); key != "ns1" {
+		t.Errorf("expected %v to count against ns1 once its quota is deleted, got %v", batchPod.Name, key)
+	}
+}
+
+func TestSnapshotElasticQuota(t *testing.T) {
+	cs := &CapacityScheduling{
+		elasticQuotaInfos: ElasticQuotaInfos{
+			"ns1": newElasticQuotaInfo("ns1", makeResourceList(0, 1000), makeResourceList(0, 2000), nil),
+			"ns2": newElasticQuotaInfo("ns2", makeResourceList(0, 1000), makeResourceList(0, 2000), nil),
+		},
+	}
+
+	first := cs.snapshotElasticQuota()
+	firstNs1, firstNs2 := first.elasticQuotaInfos["ns1"], first.elasticQuotaInfos["ns2"]
+	pod := makePod("t1-p1", "ns1", 100, 0, 0, midPriority, "t1-p1", "node-a")
+	if got := cs.Reserve(context.TODO(), framework.NewCycleState(), pod, "node-a"); !got.IsSuccess() {
+		t.Fatalf("expected success, got %v", got.Message())
+	}
+	second := cs.snapshotElasticQuota()
+	if second.elasticQuotaInfos["ns1"].Used.Memory != 100 {
+		t.Errorf("expected the snapshot to use 100 of ns1, got %v", second.elasticQuotaInfos["ns1"].Used.Memory)
+	}
+	if second.totals.used.Memory != 100 || first.totals.used.Memory != 0 {
+		t.Errorf("expected the totals of the snapshots to use 100 and 0, got %v and %v", second.totals.used.Memory, first.totals.used.Memory)
+	}
+	if firstNs1.Used.Memory != 0 {
+		t.Errorf("expected the info of ns1 the previous cycle holds to use none, got %v", firstNs1.Used.Memory)
+	}
+	if second.elasticQuotaInfos["ns2"] != firstNs2 {
+		t.Errorf("expected ns2 not to be copied again")
+	}
+
+	// Once bound, the pod reserved already changes nothing.
+	cs.addPod(pod)
+	if len(cs.changedElasticQuotas) != 0 {
+		t.Errorf("expected no quota to change, got %v", sets.List(cs.changedElasticQuotas))
+	}
+	if third := cs.snapshotElasticQuota(); third.elasticQuotaInfos["ns1"] != second.elasticQuotaInfos["ns1"] {
+		t.Errorf("expected ns1 not to be copied again")
+	}
+
+	cs.deleteElasticQuota(makeEQ("ns2", "eq2", makeResourceList(0, 2000), makeResourceList(0, 1000)))
+	fourth := cs.snapshotElasticQuota()
+	if fourth.elasticQuotaInfos["ns2"] != nil {
+		t.Errorf("expected ns2 to be deleted from the snapshot")
+	}
+	if fourth.totals.min.Memory != 1000 {
+		t.Errorf("expected the totals to leave the min of ns2 out, got %v", fourth.totals.min.Memory)
+	}
+	if firstNs2.Min.Memory != 1000 {
+		t.Errorf("expected the info of ns2 the previous cycle holds to stay as it was")
+	}
+}
+
+func TestUpdateElasticQuotaChanges(t *testing.T) {
+	cs := &CapacityScheduling{elasticQuotaInfos: map[string]*ElasticQuotaInfo{}}
+	root := makeEQ("root", "root", makeResourceList(100, 1000), makeResourceList(10, 100))
+	child := makeEQ("ns1", "child", makeResourceList(100, 1000), makeResourceList(10, 100))
+	child.Spec.Parent = &v1alpha1.ElasticQuotaReference{Namespace: "root"}
+	cs.addElasticQuota(root)
+	cs.addElasticQuota(child)
+	other := makeEQ("ns2", "other", makeResourceList(100, 1000), makeResourceList(10, 100))
+	cs.addElasticQuota(other)
+	cs.addPod(makePod("t1-p1", "ns1", 50, 10, 0, midPriority, "t1-p1", "node-a"))
+	cs.snapshotElasticQuota()
+
+	// The controller reports the usage in the status.
+	reported := child.DeepCopy()
+	reported.Status.Used = makeResourceList(10, 50)
+	cs.updateElasticQuota(child, reported)
+	if len(cs.changedElasticQuotas) != 0 {
+		t.Errorf("expected no quota to change on an update of the status, got %v", sets.List(cs.changedElasticQuotas))
+	}
+
+	// Only the min changes: the quota and its ancestors are copied again, keeping their usage.
+	raised := reported.DeepCopy()
+	raised.Spec.Min = makeResourceList(20, 200)
+	cs.updateElasticQuota(reported, raised)
+	if want := []string{"ns1", "root"}; !reflect.DeepEqual(sets.List(cs.changedElasticQuotas), want) {
+		t.Errorf("expected %v to change on an update of the min, got %v", want, sets.List(cs.changedElasticQuotas))
+	}
+	snapshot := cs.snapshotElasticQuota()
+	if got := snapshot.elasticQuotaInfos["ns1"]; got.Min.Memory != 200 || got.Used.Memory != 50 {
+		t.Errorf("expected ns1 to have min 200 and use 50, got %v and %v", got.Min.Memory, got.Used.Memory)
+	}
+	if got := snapshot.elasticQuotaInfos["root"].descendantsUsed; got == nil || got.Memory != 50 {
+		t.Errorf("expected the descendants of root to use 50, got %v", got)
+	}
+
+	// The min of a root changes: the totals are computed again.
+	raisedOther := other.DeepCopy()
+	raisedOther.Spec.Min = makeResourceList(20, 200)
+	cs.updateElasticQuota(other, raisedOther)
+	if got := cs.snapshotElasticQuota().elasticQuotaTotals().min.Memory; got != 300 {
+		t.Errorf("expected the totals of the min of the roots to be 300, got %v", got)
+	}
+
+	// The parent changes: the trees are computed again.
+	orphan := raised.DeepCopy()
+	orphan.Spec.Parent = nil
+	cs.updateElasticQuota(raised, orphan)
+	if want := []string{"ns1", "ns2", "root"}; !reflect.DeepEqual(sets.List(cs.changedElasticQuotas), want) {
+		t.Errorf("expected %v to change on an update of the parent, got %v", want, sets.List(cs.changedElasticQuotas))
+	}
+	if got := cs.elasticQuotaInfos["root"].descendantsUsed; got != nil && got.Memory != 0 {
+		t.Errorf("expected the descendants of root to use none, got %v", got.Memory)
+	}
+}
+
+func TestElasticQuotaSnapshotStateClone(t *testing.T) {
+	cs := &CapacityScheduling{
+		elasticQuotaInfos: ElasticQuotaInfos{
+			"ns1": newElasticQuotaInfo("ns1", makeResourceList(0, 1000), makeResourceList(0, 2000), makeResourceList(0, 100)),
+			"ns2": newElasticQuotaInfo("ns2", makeResourceList(0, 1000), makeResourceList(0, 2000), nil),
+		},
+	}
+	snapshot := cs.snapshotElasticQuota()
+	clone := snapshot.Clone().(*ElasticQuotaSnapshotState)
+
+	state := framework.NewCycleState()
+	state.Write(ElasticQuotaSnapshotKey, clone)
+	pod := makePod("t1-p2", "ns1", 100, 0, 0, midPriority, "t1-p2", "node-a")
+	podInfo, err := framework.NewPodInfo(pod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cs.AddPod(context.TODO(), state, pod, podInfo, framework.NewNodeInfo()); !got.IsSuccess() {
+		t.Fatalf("expected success, got %v", got.Message())
+	}
+
+	if clone.elasticQuotaInfos["ns1"].Used.Memory != 200 {
+		t.Errorf("expected the clone to use 200 of ns1, got %v", clone.elasticQuotaInfos["ns1"].Used.Memory)
+	}
+	if snapshot.elasticQuotaInfos["ns1"].Used.Memory != 100 || cs.snapshot["ns1"].Used.Memory != 100 {
+		t.Errorf("expected the snapshot to still use 100 of ns1")
+	}
+	if clone.elasticQuotaInfos["ns2"] != snapshot.elasticQuotaInfos["ns2"] {
+		t.Errorf("expected ns2 to stay shared")
+	}
+
+	cloneOfClone := clone.Clone().(*ElasticQuotaSnapshotState)
+	if cloneOfClone.elasticQuotaInfos["ns1"] == clone.elasticQuotaInfos["ns1"] {
+		t.Errorf("expected ns1, changed by the clone, to be copied")
+	}
+	if cloneOfClone.elasticQuotaInfos["ns1"].Used.Memory != 200 {
+		t.Errorf("expected the clone of the clone to use 200 of ns1, got %v", cloneOfClone.elasticQuotaInfos["ns1"].Used.Memory)
+	}
+}
+
+func TestElasticQuotaSnapshotStateAncestors(t *testing.T) {
+	team := newElasticQuotaInfo("team", makeResourceList(0, 1000), makeResourceList(0, 2000), nil)
+	team.Parent = "dept"
+	cs := &CapacityScheduling{
+		elasticQuotaInfos: ElasticQuotaInfos{
+			"dept": newElasticQuotaInfo("dept", makeResourceList(0, 1000), makeResourceList(0, 2000), nil),
+			"team": team,
+		},
+	}
+	if got := cs.Reserve(context.TODO(), framework.NewCycleState(), makePod("p1", "team", 100, 0, 0, midPriority, "p1", "node-a"), "node-a"); !got.IsSuccess() {
+		t.Fatalf("expected success, got %v", got.Message())
+	}
+	snapshot := cs.snapshotElasticQuota()
+	if got := snapshot.elasticQuotaInfos["dept"].subtree().Used.Memory; got != 100 {
+		t.Errorf("expected the tree of dept to use 100, got %v", got)
+	}
+
+	// Removing a pod of the team from a state copies the department, whose tree uses less.
+	state := framework.NewCycleState()
+	state.Write(ElasticQuotaSnapshotKey, snapshot)
+	podInfo, err := framework.NewPodInfo(makePod("p1", "team", 100, 0, 0, midPriority, "p1", "node-a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cs.RemovePod(context.TODO(), state, nil, podInfo, framework.NewNodeInfo()); !got.IsSuccess() {
+		t.Fatalf("expected success, got %v", got.Message())
+	}
+	if got := snapshot.elasticQuotaInfos["dept"].subtree().Used.Memory; got != 0 {
+		t.Errorf("expected the tree of dept to use 0 in the state, got %v", got)
+	}
+	if got := cs.snapshot["dept"].subtree().Used.Memory; got != 100 {
+		t.Errorf("expected the tree of dept to still use 100 in the snapshot of the plugin, got %v", got)
+	}
+}
+
+func TestPreFilterNominatedPods(t *testing.T) {
+	res := map[v1.ResourceName]string{v1.ResourceMemory: "2000", v1.ResourcePods: "10"}
+	nominatedPod := makePod("t1-p2", "ns1", 600, 0, 0, highPriority, "t1-p2", "")
+	nominatedPod.Status.NominatedNodeName = "node-a"
+	podNominatedElsewhere := makePod("t1-p3", "ns1", 600, 0, 0, highPriority, "t1-p3", "")
+	podNominatedElsewhere.Status.NominatedNodeName = "node-b"
+	podOfOtherQuota := makePod("t2-p1", "ns2", 600, 0, 0, highPriority, "t2-p1", "")
+	podOfOtherQuota.Status.NominatedNodeName = "node-a"
+
+	tests := []struct {
+		name          string
+		pod           *v1.Pod
+		nominatedPod  *v1.Pod
+		pendingPod    *v1.Pod
+		nominatedNode string
+		// otherUsed is the usage of ns2, whose min is 100, if it has a quota.
+		otherUsed *int64
+		expected  fwk.Code
+	}{
+		{
+			name:         "pod nominated in its status",
+			pod:          makePod("t1-p1", "ns1", 500, 0, 0, midPriority, "t1-p1", ""),
+			nominatedPod: nominatedPod,
+			pendingPod:   nominatedPod,
+			expected:     fwk.Unschedulable,
+		},
+		{
+			name:          "pod nominated by preemption",
+			pod:           makePod("t1-p1", "ns1", 500, 0, 0, midPriority, "t1-p1", ""),
+			nominatedPod:  nominatedPod,
+			nominatedNode: "node-a",
+			expected:      fwk.Unschedulable,
+		},
+		{
+			name:         "pod of another quota within its min",
+			pod:          makePod("t1-p1", "ns1", 500, 0, 0, midPriority, "t1-p1", ""),
+			nominatedPod: podOfOtherQuota,
+			pendingPod:   podOfOtherQuota,
+			otherUsed:    ptr.To[int64](50),
+			expected:     fwk.Unschedulable,
+		},
+		{
+			name:         "pod of another quota over its min",
+			pod:          makePod("t1-p1", "ns1", 500, 0, 0, midPriority, "t1-p1", ""),
+			nominatedPod: podOfOtherQuota,
+			pendingPod:   podOfOtherQuota,
+			otherUsed:    ptr.To[int64](200),
+			expected:     fwk.Success,
+		},
+		{
+			name:         "pod nominated to a node not in the snapshot",
+			pod:          makePod("t1-p1", "ns1", 500, 0, 0, midPriority, "t1-p1", ""),
+			nominatedPod: podNominatedElsewhere,
+			pendingPod:   podNominatedElsewhere,
+			expected:     fwk.Success,
+		},
+		{
+			name:         "pod itself nominated",
+			pod:          nominatedPod,
+			nominatedPod: nominatedPod,
+			pendingPod:   nominatedPod,
+			expected:     fwk.Success,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			metrics.Register()
+			nominator := testutil.NewPodNominator(nil)
+			podInfo, err := framework.NewPodInfo(tt.nominatedPod)
+			if err != nil {
+				t.Fatal(err)
+			}
+			nominator.AddNominatedPod(klog.FromContext(ctx), podInfo, &fwk.NominatingInfo{NominatingMode: fwk.ModeNoop})
+
+			nodes := []*v1.Node{st.MakeNode().Name("node-a").Capacity(res).Obj()}
+			fwk, err := tf.NewFramework(
+				ctx, makeRegisteredPlugin(), "",
+				frameworkruntime.WithPodNominator(nominator),
+				frameworkruntime.WithSnapshotSharedLister(testutil.NewFakeSharedLister(nil, nodes)),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			cs := &CapacityScheduling{
+				elasticQuotaInfos: ElasticQuotaInfos{
+					"ns1": newElasticQuotaInfo("ns1", makeResourceList(0, 1000), makeResourceList(0, 1000), nil),
+				},
+				fh: fwk,
+			}
+			if tt.otherUsed != nil {
+				cs.elasticQuotaInfos["ns2"] = newElasticQuotaInfo("ns2", makeResourceList(0, 100), makeResourceList(0, 1000), makeResourceList(0, *tt.otherUsed))
+			}
+			cs.indexElasticQuotas()
+			if tt.pendingPod != nil {
+				cs.addPendingPod(tt.pendingPod)
+			}
+			if tt.nominatedNode != "" {
+				cs.nominatePod(tt.nominatedPod, tt.nominatedNode)
+			}
+
+			if _, got := cs.PreFilter(ctx, framework.NewCycleState(), tt.pod, nil); got.Code() != tt.expected {
+				t.Errorf("expected %v, got %v : %v", tt.expected, got.Code(), got.Message())
+			}
+		})
+	}
+}
+
 func makeUnschedulableNodeStatusReader() *framework.NodeToStatus {
 	nodeStatusReader := framework.NewDefaultNodeToStatus()
 	nodeStatusReader.Set("node-a", fwk.NewStatus(fwk.Unschedulable))
@@ -0,0 +1,429 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capacityscheduling
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	v1 "k8s.io/api/core/v1"
+	policy "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	quota "k8s.io/apiserver/pkg/quota/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/klog/v2"
+	fwk "k8s.io/kube-scheduler/framework"
+	schedulerconfig "k8s.io/kubernetes/pkg/scheduler/apis/config"
+	schedulerscheme "k8s.io/kubernetes/pkg/scheduler/apis/config/scheme"
+	schedulerconfigv1 "k8s.io/kubernetes/pkg/scheduler/apis/config/v1"
+	internalcache "k8s.io/kubernetes/pkg/scheduler/backend/cache"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/defaultbinder"
+	plfeature "k8s.io/kubernetes/pkg/scheduler/framework/plugins/feature"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/noderesources"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/queuesort"
+	frameworkruntime "k8s.io/kubernetes/pkg/scheduler/framework/runtime"
+	"k8s.io/kubernetes/pkg/scheduler/metrics"
+
+	"sigs.k8s.io/scheduler-plugins/apis/config"
+	"sigs.k8s.io/scheduler-plugins/apis/config/validation"
+	"sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
+	"sigs.k8s.io/scheduler-plugins/pkg/util"
+)
+
+const (
+	OutputText = "text"
+	OutputJSON = "json"
+)
+
+// DryRunQuota is the arithmetic of an ElasticQuota the pod counts against, directly or as an ancestor of
+// its quota. Used includes the usage of the descendants of the quota.
+type DryRunQuota struct {
+	Key  string          `json:"key"`
+	Min  v1.ResourceList `json:"min,omitempty"`
+	Max  v1.ResourceList `json:"max,omitempty"`
+	Used v1.ResourceList `json:"used"`
+	// UsedWithRequest is Used along with the request of the pod and of the pods of its quota nominated
+	// before it, which PreFilter compares to Max.
+	UsedWithRequest v1.ResourceList `json:"usedWithRequest"`
+}
+
+// DryRunNode tells whether the pod fits on a node as is, or else which pods preemption would evict
+// to make room for it.
+type DryRunNode struct {
+	Name string `json:"name"`
+	Fits bool   `json:"fits"`
+	// Victims are the pods preemption would evict, by namespace and name, if the pod does not fit.
+	Victims          []string `json:"victims,omitempty"`
+	NumPDBViolations int      `json:"numPDBViolations,omitempty"`
+	// Reason tells why the pod does not fit, or why no victims are found.
+	Reason string `json:"reason,omitempty"`
+}
+
+// DryRunReport is the outcome of the admission of a pod by the CapacityScheduling plugin, with the quota
+// arithmetic behind it and the candidate victims of preemption on each node.
+type DryRunReport struct {
+	Pod          string `json:"pod"`
+	ElasticQuota string `json:"elasticQuota,omitempty"`
+	// Verdict is the code of the status of PreFilter, and Reasons its reasons.
+	Verdict string          `json:"verdict"`
+	Reasons []string        `json:"reasons,omitempty"`
+	Request v1.ResourceList `json:"request"`
+	// Quotas are the quota of the pod followed by its ancestors.
+	Quotas []DryRunQuota `json:"quotas,omitempty"`
+	// TotalUsed is the usage of all the quotas along with the request of the pod and of the pods
+	// nominated before it, which PreFilter compares to TotalMin, the sum of the min of the roots of the
+	// quota trees.
+	TotalUsed v1.ResourceList `json:"totalUsed,omitempty"`
+	TotalMin  v1.ResourceList `json:"totalMin,omitempty"`
+	// Eligible tells whether the pod may preempt others, and IneligibleReason why not.
+	Eligible         bool         `json:"eligible"`
+	IneligibleReason string       `json:"ineligibleReason,omitempty"`
+	Nodes            []DryRunNode `json:"nodes,omitempty"`
+}
+
+// DryRun simulates the admission of the pod by the CapacityScheduling plugin with the given args against
+// the given snapshot: it runs PreFilter, then on each node the Filter of the plugin along with the one of
+// NodeResourcesFit, and SelectVictimsOnNode where the pod does not fit. The other plugins of the scheduler,
+// e.g. taints and affinities, are not simulated.
+func DryRun(ctx context.Context, snap DryRunSnapshot, pod *v1.Pod, args *config.CapacitySchedulingArgs) (DryRunReport, error) {
+	pod = withUID(pod)
+	report := DryRunReport{Pod: pod.Namespace + "/" + pod.Name}
+	if args == nil {
+		args = &config.CapacitySchedulingArgs{}
+	}
+	if err := validation.ValidateCapacitySchedulingArgs(args, nil); err != nil {
+		return report, err
+	}
+
+	logger := klog.FromContext(ctx).WithValues("plugin", Name)
+	c := &CapacityScheduling{
+		logger:            logger,
+		elasticQuotaInfos: NewElasticQuotaInfos(),
+		accountingPolicy:  config.ElasticQuotaAccountingBoundNonTerminal,
+		borrowingPolicy:   config.ElasticQuotaBorrowingFirstCome,
+	}
+	if args.AccountingPolicy != nil {
+		c.accountingPolicy = *args.AccountingPolicy
+	}
+	if args.BorrowingPolicy != nil {
+		c.borrowingPolicy = *args.BorrowingPolicy
+	}
+	if c.borrowingPolicy == config.ElasticQuotaBorrowingFairShare {
+		c.pendingPods = make(map[string]*v1.Pod)
+	}
+
+	elasticQuotas := make(map[string]*v1alpha1.ElasticQuota, len(snap.ElasticQuotas))
+	for i := range snap.ElasticQuotas {
+		eq := &snap.ElasticQuotas[i]
+		key := elasticQuotaKey(eq)
+		elasticQuotas[key] = eq
+		c.elasticQuotaInfos[key] = newElasticQuotaInfoFor(eq)
+	}
+	c.indexElasticQuotas()
+
+	nodes := make([]*v1.Node, 0, len(snap.Nodes))
+	nodeIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for i := range snap.Nodes {
+		nodes = append(nodes, &snap.Nodes[i])
+		if err := nodeIndexer.Add(&snap.Nodes[i]); err != nil {
+			return report, err
+		}
+	}
+	c.nodeLister = corelisters.NewNodeLister(nodeIndexer)
+	c.elasticQuotaTreesChanged()
+
+	nominator := make(dryRunNominator)
+	var assignedPods []*v1.Pod
+	for i := range snap.Pods {
+		p := withUID(&snap.Pods[i])
+		if p.Namespace == pod.Namespace && p.Name == pod.Name {
+			continue
+		}
+		switch {
+		case assignedPod(p):
+			if p.Status.Phase == v1.PodSucceeded || p.Status.Phase == v1.PodFailed {
+				continue
+			}
+			assignedPods = append(assignedPods, p)
+			if !util.PodCountsTowardsElasticQuota(p, c.accountingPolicy) {
+				continue
+			}
+			if key, info := c.forPod(p); info != nil {
+				if err := c.addPodToElasticQuota(key, p, p.Spec.NodeName); err != nil {
+					return report, err
+				}
+			}
+		case pendingPod(p):
+			c.addPendingPod(p)
+			if p.Status.NominatedNodeName != "" {
+				podInfo, err := framework.NewPodInfo(p)
+				if err != nil {
+					return report, err
+				}
+				nominator.AddNominatedPod(logger, podInfo, &fwk.NominatingInfo{NominatingMode: fwk.ModeNoop})
+			}
+		}
+	}
+	fh, err := newDryRunFramework(ctx, c, nominator, internalcache.NewSnapshot(assignedPods, nodes))
+	if err != nil {
+		return report, err
+	}
+
+	state := framework.NewCycleState()
+	_, status, _ := fh.RunPreFilterPlugins(ctx, state, pod)
+	report.Verdict = status.Code().String()
+	if !status.IsSuccess() {
+		report.Reasons = status.Reasons()
+	}
+	report.Request = nonZero(util.PodRequests(pod))
+
+	preFilterState, err := getPreFilterState(state)
+	if err != nil {
+		return report, err
+	}
+	snapshotState, err := getElasticQuotaSnapshotState(state)
+	if err != nil {
+		return report, err
+	}
+	elasticQuotaInfos := snapshotState.elasticQuotaInfos
+	if key, info := snapshotState.forPod(pod); info != nil {
+		report.ElasticQuota = key
+		requestInEQ := util.ResourceList(&preFilterState.nominatedPodsReqInEQWithPodReq)
+		visited := make(map[string]bool)
+		for info := elasticQuotaInfos[key]; info != nil && !visited[key]; info = elasticQuotaInfos[key] {
+			visited[key] = true
+			used := util.ResourceList(info.subtree().Used)
+			report.Quotas = append(report.Quotas, DryRunQuota{
+				Key:             key,
+				Min:             elasticQuotas[key].Spec.Min,
+				Max:             elasticQuotas[key].Spec.Max,
+				Used:            nonZero(used),
+				UsedWithRequest: nonZero(quota.Add(used, requestInEQ)),
+			})
+			key = elasticQuotaInfos.parentKey(info)
+		}
+
+		totals := snapshotState.elasticQuotaTotals()
+		totalUsed := quota.Add(util.ResourceList(totals.used), util.ResourceList(&preFilterState.nominatedPodsReqWithPodReq))
+		report.TotalUsed, report.TotalMin = nonZero(totalUsed), nonZero(util.ResourceList(totals.min))
+	}
+
+	p := &preemptor{logger: logger, fh: fh, state: state}
+	report.Eligible, report.IneligibleReason = p.PodEligibleToPreemptOthers(ctx, pod, nil)
+
+	var pdbs []*policy.PodDisruptionBudget
+	for i := range snap.PodDisruptionBudgets {
+		pdbs = append(pdbs, &snap.PodDisruptionBudgets[i])
+	}
+	nodeInfos, err := fh.SnapshotSharedLister().NodeInfos().List()
+	if err != nil {
+		return report, err
+	}
+	sort.Slice(nodeInfos, func(i, j int) bool { return nodeInfos[i].Node().Name < nodeInfos[j].Node().Name })
+	for _, nodeInfo := range nodeInfos {
+		if nodeInfo.Node() == nil {
+			continue
+		}
+		node := DryRunNode{Name: nodeInfo.Node().Name}
+		if status.IsSuccess() {
+			s := fh.RunFilterPluginsWithNominatedPods(ctx, state, pod, nodeInfo)
+			if node.Fits = s.IsSuccess(); node.Fits {
+				report.Nodes = append(report.Nodes, node)
+				continue
+			}
+			node.Reason = strings.Join(s.Reasons(), "; ")
+		}
+		if report.Eligible {
+			nodeState := state.Clone()
+			p.state = nodeState
+			victims, numPDBViolations, s := p.SelectVictimsOnNode(ctx, nodeState, pod, nodeInfo.Snapshot(), pdbs)
+			if s.IsSuccess() {
+				for _, victim := range victims {
+					node.Victims = append(node.Victims, victim.Namespace+"/"+victim.Name)
+				}
+				node.NumPDBViolations = numPDBViolations
+			} else {
+				node.Reason = strings.Join(s.Reasons(), "; ")
+			}
+		}
+		report.Nodes = append(report.Nodes, node)
+	}
+	return report, nil
+}
+
+// withUID returns the pod, or a copy of it with a UID made of its namespace and name if it has none,
+// as is the case of hand-written dumps. The caches of the scheduler key the pods by UID.
+func withUID(pod *v1.Pod) *v1.Pod {
+	if pod.UID != "" {
+		return pod
+	}
+	pod = pod.DeepCopy()
+	pod.UID = types.UID(pod.Namespace + "/" + pod.Name)
+	return pod
+}
+
+// nonZero returns the resources of the list with a quantity other than zero.
+func nonZero(resources v1.ResourceList) v1.ResourceList {
+	result := v1.ResourceList{}
+	for name, quant := range resources {
+		if !quant.IsZero() {
+			result[name] = quant
+		}
+	}
+	return result
+}
+
+// newDryRunFramework returns the framework running the given plugin along with NodeResourcesFit, with the
+// given nominator and snapshot of the cluster. It is built the way the scheduler builds its profiles, so
+// that the dry run does not depend on the testing helpers of the scheduler.
+func newDryRunFramework(ctx context.Context, c *CapacityScheduling, nominator fwk.PodNominator, snapshot fwk.SharedLister) (framework.Framework, error) {
+	// NodeResourcesFit gets the default args of the latest version of the scheduler configuration.
+	gvk := schedulerconfigv1.SchemeGroupVersion.WithKind(noderesources.Name + "Args")
+	fitArgs, _, err := schedulerscheme.Codecs.UniversalDecoder().Decode(nil, &gvk, nil)
+	if err != nil {
+		return nil, err
+	}
+	registry := frameworkruntime.Registry{
+		queuesort.Name:     queuesort.New,
+		defaultbinder.Name: defaultbinder.New,
+		noderesources.Name: func(ctx context.Context, _ runtime.Object, fh fwk.Handle) (fwk.Plugin, error) {
+			return noderesources.NewFit(ctx, fitArgs, fh, plfeature.Features{})
+		},
+		Name: func(_ context.Context, _ runtime.Object, fh fwk.Handle) (fwk.Plugin, error) {
+			c.fh = fh
+			return c, nil
+		},
+	}
+	plugins := []schedulerconfig.Plugin{{Name: noderesources.Name}, {Name: Name}}
+	profile := &schedulerconfig.KubeSchedulerProfile{
+		SchedulerName: "dry-run",
+		Plugins: &schedulerconfig.Plugins{
+			QueueSort: schedulerconfig.PluginSet{Enabled: []schedulerconfig.Plugin{{Name: queuesort.Name}}},
+			PreFilter: schedulerconfig.PluginSet{Enabled: plugins},
+			Filter:    schedulerconfig.PluginSet{Enabled: plugins},
+			Bind:      schedulerconfig.PluginSet{Enabled: []schedulerconfig.Plugin{{Name: defaultbinder.Name}}},
+		},
+	}
+
+	// the framework records the duration of the extension points, outside of the scheduler too
+	metrics.Register()
+	return frameworkruntime.NewFramework(ctx, registry, profile,
+		frameworkruntime.WithEventRecorder(&events.FakeRecorder{}),
+		frameworkruntime.WithPodNominator(nominator),
+		frameworkruntime.WithSnapshotSharedLister(snapshot),
+	)
+}
+
+// dryRunNominator is the nominator of the dry run, which only ever adds the pods nominated in the snapshot.
+type dryRunNominator map[string][]fwk.PodInfo
+
+func (n dryRunNominator) AddNominatedPod(_ klog.Logger, pod fwk.PodInfo, nominatingInfo *fwk.NominatingInfo) {
+	nodeName := pod.GetPod().Status.NominatedNodeName
+	if nominatingInfo.Mode() == fwk.ModeOverride {
+		nodeName = nominatingInfo.NominatedNodeName
+	}
+	if nodeName == "" {
+		return
+	}
+	n.DeleteNominatedPodIfExists(pod.GetPod())
+	n[nodeName] = append(n[nodeName], pod)
+}
+
+func (n dryRunNominator) DeleteNominatedPodIfExists(pod *v1.Pod) {
+	for nodeName, pods := range n {
+		n[nodeName] = slices.DeleteFunc(pods, func(p fwk.PodInfo) bool { return p.GetPod().UID == pod.UID })
+	}
+}
+
+func (n dryRunNominator) UpdateNominatedPod(logger klog.Logger, _ *v1.Pod, newPodInfo fwk.PodInfo) {
+	n.AddNominatedPod(logger, newPodInfo, &fwk.NominatingInfo{NominatingMode: fwk.ModeNoop})
+}
+
+func (n dryRunNominator) NominatedPodsForNode(nodeName string) []fwk.PodInfo {
+	return slices.Clone(n[nodeName])
+}
+
+// WriteDryRunReport writes the given DryRunReport to the given writer, using the given format, either
+// OutputText or OutputJSON.
+func WriteDryRunReport(w io.Writer, report DryRunReport, format string) error {
+	switch format {
+	case OutputJSON:
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	case OutputText, "":
+		return writeDryRunText(w, report)
+	default:
+		return fmt.Errorf("unsupported output format %q", format)
+	}
+}
+
+func writeDryRunText(w io.Writer, report DryRunReport) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(tw, "POD\t%s\n", report.Pod)
+	fmt.Fprintf(tw, "ELASTICQUOTA\t%s\n", orNone(report.ElasticQuota))
+	fmt.Fprintf(tw, "REQUEST\t%s\n", formatResources(report.Request))
+	fmt.Fprintf(tw, "VERDICT\t%s\n", report.Verdict)
+	for _, reason := range report.Reasons {
+		fmt.Fprintf(tw, "\t%s\n", reason)
+	}
+	if len(report.Quotas) > 0 {
+		fmt.Fprintf(tw, "\nQUOTA\tMIN\tMAX\tUSED\tUSED WITH REQUEST\n")
+		for _, q := range report.Quotas {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", q.Key, formatResources(q.Min), formatResources(q.Max), formatResources(q.Used), formatResources(q.UsedWithRequest))
+		}
+		fmt.Fprintf(tw, "TOTAL\t%s\t\t\t%s\n", formatResources(report.TotalMin), formatResources(report.TotalUsed))
+	}
+	if !report.Eligible {
+		fmt.Fprintf(tw, "\nPREEMPTION\t%s\n", report.IneligibleReason)
+	}
+	fmt.Fprintf(tw, "\nNODE\tFITS\tVICTIMS\tPDB VIOLATIONS\tREASON\n")
+	for _, node := range report.Nodes {
+		fmt.Fprintf(tw, "%s\t%t\t%s\t%d\t%s\n", node.Name, node.Fits, orNone(strings.Join(node.Victims, ",")), node.NumPDBViolations, node.Reason)
+	}
+	return tw.Flush()
+}
+
+// formatResources formats the resource list as comma-separated name=quantity pairs, sorted by name.
+func formatResources(resources v1.ResourceList) string {
+	items := make([]string, 0, len(resources))
+	for name, quant := range resources {
+		items = append(items, fmt.Sprintf("%s=%s", name, quant.String()))
+	}
+	sort.Strings(items)
+	return orNone(strings.Join(items, ","))
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capacityscheduling
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	v1 "k8s.io/api/core/v1"
+	policy "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
+)
+
+// DryRunSnapshot is the cluster state a DryRunReport is computed from.
+type DryRunSnapshot struct {
+	ElasticQuotas        []v1alpha1.ElasticQuota
+	Nodes                []v1.Node
+	Pods                 []v1.Pod
+	PodDisruptionBudgets []policy.PodDisruptionBudget
+}
+
+// LoadDryRunSnapshotFromCluster lists all the objects needed to compute a DryRunReport from the cluster.
+func LoadDryRunSnapshotFromCluster(ctx context.Context, cli client.Reader) (DryRunSnapshot, error) {
+	var snap DryRunSnapshot
+
+	eqList := v1alpha1.ElasticQuotaList{}
+	if err := cli.List(ctx, &eqList); err != nil {
+		return snap, fmt.Errorf("cannot list ElasticQuotas: %w", err)
+	}
+	nodeList := v1.NodeList{}
+	if err := cli.List(ctx, &nodeList); err != nil {
+		return snap, fmt.Errorf("cannot list Nodes: %w", err)
+	}
+	podList := v1.PodList{}
+	if err := cli.List(ctx, &podList); err != nil {
+		return snap, fmt.Errorf("cannot list Pods: %w", err)
+	}
+	pdbList := policy.PodDisruptionBudgetList{}
+	if err := cli.List(ctx, &pdbList); err != nil {
+		return snap, fmt.Errorf("cannot list PodDisruptionBudgets: %w", err)
+	}
+
+	snap.ElasticQuotas = eqList.Items
+	snap.Nodes = nodeList.Items
+	snap.Pods = podList.Items
+	snap.PodDisruptionBudgets = pdbList.Items
+	return snap, nil
+}
+
+// LoadDryRunSnapshotFromFiles reads the objects needed to compute a DryRunReport from YAML or JSON dumps,
+// like the ones produced by `kubectl get -o yaml`. Each file can contain many documents, and each document
+// can be either a single object or a List. Objects of kinds other than ElasticQuota, Node, Pod and
+// PodDisruptionBudget are ignored.
+func LoadDryRunSnapshotFromFiles(paths ...string) (DryRunSnapshot, error) {
+	var snap DryRunSnapshot
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return snap, err
+		}
+		if err := snap.load(bytes.NewReader(data)); err != nil {
+			return snap, fmt.Errorf("cannot load %q: %w", path, err)
+		}
+	}
+	return snap, nil
+}
+
+// DryRunScheme knows about all the objects a DryRunSnapshot can be made of.
+func DryRunScheme() *runtime.Scheme {
+	return scheme
+}
+
+func (snap *DryRunSnapshot) load(r io.Reader) error {
+	decoder := serializer.NewCodecFactory(scheme).UniversalDeserializer()
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(r))
+	for {
+		doc, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if len(bytes.TrimSpace(doc)) == 0 {
+			continue
+		}
+		if err := snap.decode(decoder, doc); err != nil {
+			return err
+		}
+	}
+}
+
+func (snap *DryRunSnapshot) decode(decoder runtime.Decoder, data []byte) error {
+	obj, _, err := decoder.Decode(data, nil, nil)
+	if err != nil {
+		if runtime.IsNotRegisteredError(err) {
+			return nil
+		}
+		return err
+	}
+
+	switch obj := obj.(type) {
+	case *v1alpha1.ElasticQuota:
+		snap.ElasticQuotas = append(snap.ElasticQuotas, *obj)
+	case *v1alpha1.ElasticQuotaList:
+		snap.ElasticQuotas = append(snap.ElasticQuotas, obj.Items...)
+	case *v1.Node:
+		snap.Nodes = append(snap.Nodes, *obj)
+	case *v1.NodeList:
+		snap.Nodes = append(snap.Nodes, obj.Items...)
+	case *v1.Pod:
+		snap.Pods = append(snap.Pods, *obj)
+	case *v1.PodList:
+		snap.Pods = append(snap.Pods, obj.Items...)
+	case *policy.PodDisruptionBudget:
+		snap.PodDisruptionBudgets = append(snap.PodDisruptionBudgets, *obj)
+	case *policy.PodDisruptionBudgetList:
+		snap.PodDisruptionBudgets = append(snap.PodDisruptionBudgets, obj.Items...)
+	case *v1.List:
+		for _, item := range obj.Items {
+			if err := snap.decode(decoder, item.Raw); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,250 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capacityscheduling
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	gocmp "github.com/google/go-cmp/cmp"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	fwk "k8s.io/kube-scheduler/framework"
+	st "k8s.io/kubernetes/pkg/scheduler/testing"
+
+	"sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
+)
+
+func TestDryRun(t *testing.T) {
+	res := map[v1.ResourceName]string{v1.ResourceMemory: "150", v1.ResourcePods: "10"}
+	tests := []struct {
+		name          string
+		pod           *v1.Pod
+		pods          []*v1.Pod
+		nodes         []*v1.Node
+		elasticQuotas []*v1alpha1.ElasticQuota
+		wantVerdict   fwk.Code
+		wantReason    string
+		wantQuotas    []DryRunQuota
+		wantNodes     []DryRunNode
+	}{
+		{
+			name: "fits",
+			pod:  makePod("t1-p", "ns1", 50, 0, 0, highPriority, "t1-p", ""),
+			pods: []*v1.Pod{
+				makePod("t1-p1", "ns1", 50, 0, 0, midPriority, "t1-p1", "node-a"),
+			},
+			nodes: []*v1.Node{
+				st.MakeNode().Name("node-a").Capacity(res).Obj(),
+			},
+			elasticQuotas: []*v1alpha1.ElasticQuota{
+				makeEQ("ns1", "eq1", v1.ResourceList{v1.ResourceMemory: resource.MustParse("200")}, v1.ResourceList{v1.ResourceMemory: resource.MustParse("100")}),
+			},
+			wantVerdict: fwk.Success,
+			wantQuotas: []DryRunQuota{
+				{
+					Key:             "ns1",
+					Min:             v1.ResourceList{v1.ResourceMemory: resource.MustParse("100")},
+					Max:             v1.ResourceList{v1.ResourceMemory: resource.MustParse("200")},
+					Used:            v1.ResourceList{v1.ResourceMemory: resource.MustParse("50")},
+					UsedWithRequest: v1.ResourceList{v1.ResourceMemory: resource.MustParse("100")},
+				},
+			},
+			wantNodes: []DryRunNode{
+				{Name: "node-a", Fits: true},
+			},
+		},
+		{
+			name: "in-namespace preemption",
+			pod:  makePod("t1-p", "ns1", 50, 0, 0, highPriority, "t1-p", ""),
+			pods: []*v1.Pod{
+				makePod("t1-p1", "ns1", 50, 0, 0, midPriority, "t1-p1", "node-a"),
+				makePod("t1-p2", "ns2", 50, 0, 0, midPriority, "t1-p2", "node-a"),
+				makePod("t1-p3", "ns2", 50, 0, 0, midPriority, "t1-p3", "node-a"),
+			},
+			nodes: []*v1.Node{
+				st.MakeNode().Name("node-a").Capacity(res).Obj(),
+			},
+			elasticQuotas: []*v1alpha1.ElasticQuota{
+				makeEQ("ns1", "eq1", v1.ResourceList{v1.ResourceMemory: resource.MustParse("200")}, v1.ResourceList{v1.ResourceMemory: resource.MustParse("50")}),
+				makeEQ("ns2", "eq2", v1.ResourceList{v1.ResourceMemory: resource.MustParse("200")}, v1.ResourceList{v1.ResourceMemory: resource.MustParse("200")}),
+			},
+			wantVerdict: fwk.Success,
+			wantQuotas: []DryRunQuota{
+				{
+					Key:             "ns1",
+					Min:             v1.ResourceList{v1.ResourceMemory: resource.MustParse("50")},
+					Max:             v1.ResourceList{v1.ResourceMemory: resource.MustParse("200")},
+					Used:            v1.ResourceList{v1.ResourceMemory: resource.MustParse("50")},
+					UsedWithRequest: v1.ResourceList{v1.ResourceMemory: resource.MustParse("100")},
+				},
+			},
+			wantNodes: []DryRunNode{
+				{Name: "node-a", Victims: []string{"ns1/t1-p1"}, Reason: "Insufficient memory"},
+			},
+		},
+		{
+			name: "more than max",
+			pod:  makePod("t1-p", "ns1", 50, 0, 0, highPriority, "t1-p", ""),
+			pods: []*v1.Pod{
+				makePod("t1-p1", "ns1", 50, 0, 0, midPriority, "t1-p1", "node-a"),
+			},
+			nodes: []*v1.Node{
+				st.MakeNode().Name("node-a").Capacity(res).Obj(),
+			},
+			elasticQuotas: []*v1alpha1.ElasticQuota{
+				makeEQ("ns1", "eq1", v1.ResourceList{v1.ResourceMemory: resource.MustParse("60")}, v1.ResourceList{v1.ResourceMemory: resource.MustParse("50")}),
+			},
+			wantVerdict: fwk.Unschedulable,
+			wantReason:  "Pod ns1/t1-p is rejected in PreFilter because ElasticQuota ns1 is more than Max",
+			wantQuotas: []DryRunQuota{
+				{
+					Key:             "ns1",
+					Min:             v1.ResourceList{v1.ResourceMemory: resource.MustParse("50")},
+					Max:             v1.ResourceList{v1.ResourceMemory: resource.MustParse("60")},
+					Used:            v1.ResourceList{v1.ResourceMemory: resource.MustParse("50")},
+					UsedWithRequest: v1.ResourceList{v1.ResourceMemory: resource.MustParse("100")},
+				},
+			},
+			wantNodes: []DryRunNode{
+				{Name: "node-a", Victims: []string{"ns1/t1-p1"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var snap DryRunSnapshot
+			for _, eq := range tt.elasticQuotas {
+				snap.ElasticQuotas = append(snap.ElasticQuotas, *eq)
+			}
+			for _, p := range tt.pods {
+				snap.Pods = append(snap.Pods, *p)
+			}
+			for _, n := range tt.nodes {
+				snap.Nodes = append(snap.Nodes, *n)
+			}
+
+			report, err := DryRun(context.Background(), snap, tt.pod, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if report.Verdict != tt.wantVerdict.String() {
+				t.Errorf("expected verdict %v, got %v (%v)", tt.wantVerdict, report.Verdict, report.Reasons)
+			}
+			if tt.wantReason != "" && (len(report.Reasons) == 0 || report.Reasons[0] != tt.wantReason) {
+				t.Errorf("expected reason %q, got %q", tt.wantReason, report.Reasons)
+			}
+			if diff := gocmp.Diff(tt.wantQuotas, report.Quotas); diff != "" {
+				t.Errorf("unexpected quotas (-want, +got): %s", diff)
+			}
+			if diff := gocmp.Diff(tt.wantNodes, report.Nodes); diff != "" {
+				t.Errorf("unexpected nodes (-want, +got): %s", diff)
+			}
+
+			var out bytes.Buffer
+			if err := WriteDryRunReport(&out, report, OutputText); err != nil {
+				t.Fatal(err)
+			}
+			if !slices.ContainsFunc(strings.Split(out.String(), "\n"), func(line string) bool {
+				return slices.Equal(strings.Fields(line), []string{"VERDICT", tt.wantVerdict.String()})
+			}) {
+				t.Errorf("expected the verdict in the text output, got:\n%s", out.String())
+			}
+		})
+	}
+}
+
+const testDryRunSnapshotYAML = `
+apiVersion: scheduling.x-k8s.io/v1alpha1
+kind: ElasticQuota
+metadata:
+  name: eq1
+  namespace: ns1
+spec:
+  min:
+    memory: 50
+  max:
+    memory: 200
+---
+apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: Node
+  metadata:
+    name: node-a
+  status:
+    allocatable:
+      memory: 150
+      pods: 10
+- apiVersion: v1
+  kind: Pod
+  metadata:
+    name: t1-p1
+    namespace: ns1
+  spec:
+    nodeName: node-a
+    containers:
+    - name: pause
+      image: pause
+      resources:
+        requests:
+          memory: 50
+- apiVersion: policy/v1
+  kind: PodDisruptionBudget
+  metadata:
+    name: pdb1
+    namespace: ns1
+  spec:
+    minAvailable: 1
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: ignored
+`
+
+func TestLoadDryRunSnapshotFromFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.yaml")
+	if err := os.WriteFile(path, []byte(testDryRunSnapshotYAML), 0600); err != nil {
+		t.Fatal(err)
+	}
+	snap, err := LoadDryRunSnapshotFromFiles(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snap.ElasticQuotas) != 1 || len(snap.Nodes) != 1 || len(snap.Pods) != 1 || len(snap.PodDisruptionBudgets) != 1 {
+		t.Errorf("unexpected snapshot: %d ElasticQuotas, %d Nodes, %d Pods, %d PodDisruptionBudgets",
+			len(snap.ElasticQuotas), len(snap.Nodes), len(snap.Pods), len(snap.PodDisruptionBudgets))
+	}
+
+	// the pods of the dump have no UID
+	pod := makePod("t1-p", "ns1", 50, 0, 0, highPriority, "", "")
+	report, err := DryRun(context.Background(), snap, pod, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantNodes := []DryRunNode{{Name: "node-a", Victims: []string{"ns1/t1-p1"}}}
+	if diff := gocmp.Diff(wantNodes, report.Nodes); diff != "" {
+		t.Errorf("unexpected nodes (-want, +got): %s", diff)
+	}
+}
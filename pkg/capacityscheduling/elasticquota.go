@@ -18,11 +18,16 @@ package capacityscheduling
 
 import (
 	"math"
+	"slices"
+	"strings"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/util/sets"
+	quota "k8s.io/apiserver/pkg/quota/v1"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	"sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
 	"sigs.k8s.io/scheduler-plugins/pkg/util"
 )
 
@@ -45,27 +50,347 @@ func (e ElasticQuotaInfos) clone() ElasticQuotaInfos {
 	return elasticQuotas
 }
 
-func (e ElasticQuotaInfos) aggregatedUsedOverMinWith(podRequest framework.Resource) bool {
-	used := framework.NewResource(nil)
-	min := framework.NewResource(nil)
+// elasticQuotaTotals are the usage of all the quotas and the min of the roots of the quota trees, kept up
+// to date as pods come and go so that they are checked without going through every quota. The min of a
+// quota with a parent is part of the min of its parent, so it is not counted again.
+type elasticQuotaTotals struct {
+	used *framework.Resource
+	// min only changes along with the trees of quotas, when the totals are computed again.
+	min *framework.Resource
+}
+
+func newElasticQuotaTotals(e ElasticQuotaInfos) elasticQuotaTotals {
+	totals := elasticQuotaTotals{used: framework.NewResource(nil), min: framework.NewResource(nil)}
+	for _, info := range e {
+		addRequest(totals.used, *info.Used, 1)
+		if e.parent(info) == nil {
+			addRequest(totals.min, *info.Min, 1)
+		}
+	}
+	return totals
+}
+
+func (t elasticQuotaTotals) clone() elasticQuotaTotals {
+	return elasticQuotaTotals{used: t.used.Clone(), min: t.min}
+}
+
+// addPod adds the request of the pod, times sign, to the usage of all the quotas.
+func (t elasticQuotaTotals) addPod(pod *v1.Pod, sign int64) {
+	addRequest(t.used, *computePodResourceRequest(pod), sign)
+}
+
+// updatePod replaces the request of oldPod by the one of newPod in the usage of all the quotas.
+func (t elasticQuotaTotals) updatePod(oldPod, newPod *v1.Pod) {
+	t.addPod(oldPod, -1)
+	t.addPod(newPod, 1)
+}
+
+// usedOverMinWith returns whether the usage of all the quotas with the pod request exceeds the min of the
+// roots of the quota trees.
+func (t elasticQuotaTotals) usedOverMinWith(podRequest *framework.Resource) bool {
+	used := t.used.Clone()
+	addRequest(used, *podRequest, 1)
+	return cmp(used, t.min, LowerBoundOfMin)
+}
+
+// elasticQuotaSelectors are the keys of the quotas with a selector, by namespace and in order, so that the
+// quota of a pod is found without going through the quotas of the other namespaces.
+type elasticQuotaSelectors map[string][]string
+
+func newElasticQuotaSelectors(e ElasticQuotaInfos) elasticQuotaSelectors {
+	selectors := make(elasticQuotaSelectors)
+	for key, info := range e {
+		if info.selector != nil {
+			selectors[info.Namespace] = append(selectors[info.Namespace], key)
+		}
+	}
+	for _, keys := range selectors {
+		slices.Sort(keys)
+	}
+	return selectors
+}
+
+// forPod returns the key and the info in e of the quota the pod counts against: among the quotas of its
+// namespace, the first one by key whose selector matches the pod, or else the one without selector.
+func (s elasticQuotaSelectors) forPod(e ElasticQuotaInfos, pod *v1.Pod) (string, *ElasticQuotaInfo) {
+	for _, key := range s[pod.Namespace] {
+		if info := e[key]; info != nil && util.ElasticQuotaMatchesPod(info.selector, pod) {
+			return key, info
+		}
+	}
+	return pod.Namespace, e[pod.Namespace]
+}
 
-	for _, elasticQuotaInfo := range e {
-		used.Add(util.ResourceList(elasticQuotaInfo.Used))
-		min.Add(util.ResourceList(elasticQuotaInfo.Min))
+// parent returns the parent of the given quota, if any.
+func (e ElasticQuotaInfos) parent(info *ElasticQuotaInfo) *ElasticQuotaInfo {
+	if key := e.parentKey(info); key != "" {
+		return e[key]
 	}
+	return nil
+}
 
-	used.Add(util.ResourceList(&podRequest))
-	return cmp(used, min, LowerBoundOfMin)
+// parentKey returns the key of the parent of the given quota, if any. A parent named after a quota without
+// selector is found by its namespace.
+func (e ElasticQuotaInfos) parentKey(info *ElasticQuotaInfo) string {
+	if info.Parent == "" {
+		return ""
+	}
+	if e[info.Parent] != nil {
+		return info.Parent
+	}
+	if namespace, _, found := strings.Cut(info.Parent, "/"); found && e[namespace] != nil {
+		return namespace
+	}
+	return ""
+}
+
+// rootKey returns the key of the root of the tree of the quota of the given key.
+func (e ElasticQuotaInfos) rootKey(key string) string {
+	visited := sets.New[string]()
+	for info := e[key]; info != nil && !visited.Has(key); info = e[key] {
+		visited.Insert(key)
+		parent := e.parentKey(info)
+		if parent == "" {
+			break
+		}
+		key = parent
+	}
+	return key
+}
+
+// path returns the quota of the given key followed by its ancestors, up to the root of its tree.
+func (e ElasticQuotaInfos) path(key string) []*ElasticQuotaInfo {
+	var path []*ElasticQuotaInfo
+	for _, k := range e.pathKeys(key) {
+		path = append(path, e[k])
+	}
+	return path
+}
+
+// pathKeys returns the key of the quota of the given key followed by the keys of its ancestors, up to the
+// root of its tree, or nil if there is no such quota.
+func (e ElasticQuotaInfos) pathKeys(key string) []string {
+	var keys []string
+	visited := sets.New[*ElasticQuotaInfo]()
+	for info := e[key]; info != nil && !visited.Has(info); info = e[key] {
+		visited.Insert(info)
+		keys = append(keys, key)
+		if key = e.parentKey(info); key == "" {
+			break
+		}
+	}
+	return keys
+}
+
+// computeDescendantsUsed computes the usage of the descendants of every quota from scratch, once the trees
+// of quotas changed or the usage of the quotas was set other than by addPod, deletePod and updatePod.
+func (e ElasticQuotaInfos) computeDescendantsUsed() {
+	for _, info := range e {
+		info.descendantsUsed = nil
+	}
+	for key, info := range e {
+		for _, ancestor := range e.path(key)[1:] {
+			ancestor.reserveDescendantsResource(*info.Used)
+		}
+	}
+}
+
+// addPod adds the pod to the quota of the given key, and its request to the usage of the descendants of
+// the ancestors of the quota. It returns whether the pod was added, i.e. did not count already.
+func (e ElasticQuotaInfos) addPod(key string, pod *v1.Pod) (bool, error) {
+	info := e[key]
+	if info == nil || info.hasPod(pod) {
+		return false, nil
+	}
+	if err := info.addPodIfNotPresent(pod); err != nil {
+		return false, err
+	}
+	for _, ancestor := range e.path(key)[1:] {
+		ancestor.reserveDescendantsResource(*computePodResourceRequest(pod))
+	}
+	return true, nil
+}
+
+// deletePod deletes the pod from the quota of the given key, and its request from the usage of the
+// descendants of the ancestors of the quota. It returns whether the pod was deleted.
+func (e ElasticQuotaInfos) deletePod(key string, pod *v1.Pod) (bool, error) {
+	info := e[key]
+	if info == nil || !info.hasPod(pod) {
+		return false, nil
+	}
+	if err := info.deletePodIfPresent(pod); err != nil {
+		return false, err
+	}
+	for _, ancestor := range e.path(key)[1:] {
+		ancestor.unreserveDescendantsResource(*computePodResourceRequest(pod))
+	}
+	return true, nil
+}
+
+// updatePod replaces the requests of oldPod by the ones of newPod in the quota of the given key and in the
+// usage of the descendants of its ancestors. It returns whether the pod counts against the quota.
+func (e ElasticQuotaInfos) updatePod(key string, oldPod, newPod *v1.Pod) (bool, error) {
+	info := e[key]
+	if info == nil || !info.hasPod(newPod) {
+		return false, nil
+	}
+	if err := info.updatePodIfPresent(oldPod, newPod); err != nil {
+		return false, err
+	}
+	for _, ancestor := range e.path(key)[1:] {
+		ancestor.unreserveDescendantsResource(*computePodResourceRequest(oldPod))
+		ancestor.reserveDescendantsResource(*computePodResourceRequest(newPod))
+	}
+	return true, nil
+}
+
+// usedOverMaxWith returns whether the pod request exceeds the max of the quota of the given key, or of
+// any of its ancestors.
+func (e ElasticQuotaInfos) usedOverMaxWith(key string, podRequest *framework.Resource) bool {
+	for _, info := range e.path(key) {
+		if info.subtree().usedOverMaxWith(podRequest) {
+			return true
+		}
+	}
+	return false
+}
+
+// reclaimable returns whether a pod of the quota of <preemptorKey> requesting <podRequest> may preempt
+// the pods of the quota of <victimKey>. Below the closest common ancestor of both quotas, the branch of
+// the preemptor must stay within its min with the request, while the branch of the victim and every
+// quota down to the victim must be over their min, i.e. borrow from the branch of the preemptor.
+// The quotas without ancestors share the whole cluster.
+func (e ElasticQuotaInfos) reclaimable(preemptorKey, victimKey string, podRequest *framework.Resource) bool {
+	preemptorPath := e.path(preemptorKey)
+	preemptorAncestors := sets.New(preemptorPath...)
+
+	var victimBranch, common *ElasticQuotaInfo
+	for _, info := range e.path(victimKey) {
+		if preemptorAncestors.Has(info) {
+			common = info
+			break
+		}
+		if !info.subtree().usedOverMin() {
+			return false
+		}
+		victimBranch = info
+	}
+	var preemptorBranch *ElasticQuotaInfo
+	for _, info := range preemptorPath {
+		if info == common {
+			break
+		}
+		preemptorBranch = info
+	}
+	// One of the quotas is an ancestor of the other.
+	if victimBranch == nil || preemptorBranch == nil {
+		return false
+	}
+	return !preemptorBranch.subtree().usedOverMinWith(podRequest)
+}
+
+// borrowing returns, by the key of the root of each tree of quotas, what the tree borrows over the min of
+// its root with <podRequest> added to the tree of <rootKey>, along with the total min the trees leave
+// unused, which those borrowing share.
+func (e ElasticQuotaInfos) borrowing(rootKey string, podRequest *framework.Resource) (map[string]v1.ResourceList, v1.ResourceList) {
+	borrowed := make(map[string]v1.ResourceList)
+	idle := v1.ResourceList{}
+	for key, info := range e {
+		if e.parentKey(info) != "" {
+			continue
+		}
+		used := info.subtree().Used.Clone()
+		if key == rootKey && podRequest != nil {
+			used.Add(util.ResourceList(podRequest))
+		}
+		usedList, minList := util.ResourceList(used), util.ResourceList(info.Min)
+		borrowed[key] = util.ElasticQuotaBorrowed(usedList, minList)
+		idle = quota.Add(idle, util.ElasticQuotaIdle(usedList, minList))
+	}
+	return borrowed, idle
+}
+
+// overFairShareWith returns whether the tree of the quota of the given key borrows more than its fair
+// share of the min the trees leave unused with <podRequest>, while the trees of the keys of <waiting> wait
+// to borrow as well. The trees borrowing or waiting share it in proportion to their weight, by their
+// dominant share of it.
+func (e ElasticQuotaInfos) overFairShareWith(key string, podRequest *framework.Resource, waiting sets.Set[string]) bool {
+	root := e.rootKey(key)
+	if e[root] == nil || len(waiting) == 0 || len(waiting) == 1 && waiting.Has(root) {
+		return false
+	}
+	borrowed, idle := e.borrowing(root, podRequest)
+	if borrowed[root] == nil {
+		return false
+	}
+	var totalWeight int64
+	for other, info := range e {
+		if _, isRoot := borrowed[other]; isRoot && (other == root || borrowed[other] != nil || waiting.Has(other)) {
+			totalWeight += info.weight()
+		}
+	}
+	return dominantShare(borrowed[root], idle)*float64(totalWeight) > float64(e[root].weight())
+}
+
+// rebalanceable returns whether, when sharing fairly the min the trees of quotas leave unused, a pod of
+// the quota of <preemptorKey> requesting <podRequest> may preempt the pods of the quota of <victimKey>:
+// the tree of the victim borrows more than its fair share, while the tree of the preemptor stays within
+// its own with the request.
+func (e ElasticQuotaInfos) rebalanceable(preemptorKey, victimKey string, podRequest *framework.Resource, waiting sets.Set[string]) bool {
+	preemptorRoot, victimRoot := e.rootKey(preemptorKey), e.rootKey(victimKey)
+	if preemptorRoot == victimRoot {
+		return false
+	}
+	competing := waiting.Clone().Insert(preemptorRoot)
+	return !e.overFairShareWith(preemptorKey, podRequest, competing) && e.overFairShareWith(victimKey, nil, competing)
+}
+
+// dominantShare returns the largest fraction of CPU, memory or any extended resource of <total> that
+// <used> takes, e.g. GPUs. A resource used out of none is an infinite share.
+func dominantShare(used, total v1.ResourceList) float64 {
+	var share float64
+	for name, quant := range used {
+		if name == v1.ResourcePods || name == v1.ResourceEphemeralStorage || quant.Sign() <= 0 {
+			continue
+		}
+		t := total[name]
+		if t.Sign() <= 0 {
+			return math.Inf(1)
+		}
+		if s := quant.AsApproximateFloat64() / t.AsApproximateFloat64(); s > share {
+			share = s
+		}
+	}
+	return share
+}
+
+// elasticQuotaKey returns the key of the ElasticQuota in ElasticQuotaInfos: its namespace if it has no
+// selector, or else its namespace and name.
+func elasticQuotaKey(eq *v1alpha1.ElasticQuota) string {
+	if !util.ElasticQuotaHasSelector(eq) {
+		return eq.Namespace
+	}
+	return eq.Namespace + "/" + eq.Name
 }
 
 // ElasticQuotaInfo is a wrapper to a ElasticQuota with information.
-// Each namespace can only have one ElasticQuota.
+// A namespace may have several ElasticQuotas selecting different pods, and one without selector.
 type ElasticQuotaInfo struct {
 	Namespace string
-	pods      sets.Set[string]
-	Min       *framework.Resource
-	Max       *framework.Resource
-	Used      *framework.Resource
+	// Parent is the key of the parent quota, if any.
+	Parent string
+	// Weight is the weight of the quota when the min left unused is shared fairly, 1 if unset.
+	Weight int64
+	// selector is the ElasticQuota if it only applies to the pods it selects.
+	selector *v1alpha1.ElasticQuota
+	pods     sets.Set[string]
+	Min      *framework.Resource
+	Max      *framework.Resource
+	Used     *framework.Resource
+	// descendantsUsed is the usage of all the descendants of the quota, nil until any of them uses anything.
+	descendantsUsed *framework.Resource
+	// pools are the pools of nodes the quota scopes guarantees and limits to.
+	pools []v1alpha1.ElasticQuotaPool
 }
 
 func newElasticQuotaInfo(namespace string, min, max, used v1.ResourceList) *ElasticQuotaInfo {
@@ -86,24 +411,56 @@ func newElasticQuotaInfo(namespace string, min, max, used v1.ResourceList) *Elas
 	return elasticQuotaInfo
 }
 
-func (e *ElasticQuotaInfo) reserveResource(request framework.Resource) {
-	e.Used.Memory += request.Memory
-	e.Used.MilliCPU += request.MilliCPU
-	e.Used.EphemeralStorage += request.EphemeralStorage
-	e.Used.AllowedPodNumber += request.AllowedPodNumber
-	for name, value := range request.ScalarResources {
-		e.Used.SetScalar(name, e.Used.ScalarResources[name]+value)
+// weight returns the weight of the quota when the min left unused is shared fairly.
+func (e *ElasticQuotaInfo) weight() int64 {
+	if e.Weight <= 0 {
+		return 1
 	}
+	return e.Weight
+}
+
+func (e *ElasticQuotaInfo) reserveResource(request framework.Resource) {
+	addRequest(e.Used, request, 1)
 }
 
 func (e *ElasticQuotaInfo) unreserveResource(request framework.Resource) {
-	e.Used.Memory -= request.Memory
-	e.Used.MilliCPU -= request.MilliCPU
-	e.Used.EphemeralStorage -= request.EphemeralStorage
-	e.Used.AllowedPodNumber -= request.AllowedPodNumber
+	addRequest(e.Used, request, -1)
+}
+
+func (e *ElasticQuotaInfo) reserveDescendantsResource(request framework.Resource) {
+	if e.descendantsUsed == nil {
+		e.descendantsUsed = framework.NewResource(nil)
+	}
+	addRequest(e.descendantsUsed, request, 1)
+}
+
+func (e *ElasticQuotaInfo) unreserveDescendantsResource(request framework.Resource) {
+	if e.descendantsUsed == nil {
+		e.descendantsUsed = framework.NewResource(nil)
+	}
+	addRequest(e.descendantsUsed, request, -1)
+}
+
+// addRequest adds the request, times sign, to used.
+func addRequest(used *framework.Resource, request framework.Resource, sign int64) {
+	used.Memory += sign * request.Memory
+	used.MilliCPU += sign * request.MilliCPU
+	used.EphemeralStorage += sign * request.EphemeralStorage
+	used.AllowedPodNumber += int(sign) * request.AllowedPodNumber
 	for name, value := range request.ScalarResources {
-		e.Used.SetScalar(name, e.Used.ScalarResources[name]-value)
+		used.SetScalar(name, used.ScalarResources[name]+sign*value)
+	}
+}
+
+// subtree returns the quota with the usage of all its descendants added to its own, or the quota itself
+// if they use nothing.
+func (e *ElasticQuotaInfo) subtree() *ElasticQuotaInfo {
+	if e.descendantsUsed == nil {
+		return e
 	}
+	used := e.Used.Clone()
+	addRequest(used, *e.descendantsUsed, 1)
+	return &ElasticQuotaInfo{Namespace: e.Namespace, Parent: e.Parent, Weight: e.Weight, Min: e.Min, Max: e.Max, Used: used}
 }
 
 func (e *ElasticQuotaInfo) usedOverMinWith(podRequest *framework.Resource) bool {
@@ -133,6 +490,10 @@ func (e *ElasticQuotaInfo) usedOverMin() bool {
 func (e *ElasticQuotaInfo) clone() *ElasticQuotaInfo {
 	newEQInfo := &ElasticQuotaInfo{
 		Namespace: e.Namespace,
+		Parent:    e.Parent,
+		Weight:    e.Weight,
+		selector:  e.selector,
+		pools:     e.pools,
 		pods:      sets.New[string](),
 	}
 
@@ -145,6 +506,9 @@ func (e *ElasticQuotaInfo) clone() *ElasticQuotaInfo {
 	if e.Used != nil {
 		newEQInfo.Used = e.Used.Clone()
 	}
+	if e.descendantsUsed != nil {
+		newEQInfo.descendantsUsed = e.descendantsUsed.Clone()
+	}
 	for pod := range e.pods {
 		newEQInfo.pods.Insert(pod)
 	}
@@ -152,6 +516,12 @@ func (e *ElasticQuotaInfo) clone() *ElasticQuotaInfo {
 	return newEQInfo
 }
 
+// hasPod returns whether the pod counts against the quota.
+func (e *ElasticQuotaInfo) hasPod(pod *v1.Pod) bool {
+	key, err := framework.GetPodKey(pod)
+	return err == nil && e.pods.Has(key)
+}
+
 func (e *ElasticQuotaInfo) addPodIfNotPresent(pod *v1.Pod) error {
 	key, err := framework.GetPodKey(pod)
 	if err != nil {
@@ -186,6 +556,24 @@ func (e *ElasticQuotaInfo) deletePodIfPresent(pod *v1.Pod) error {
 	return nil
 }
 
+// updatePodIfPresent replaces the requests of oldPod by the ones of newPod, e.g. once the pod was resized
+// in place.
+func (e *ElasticQuotaInfo) updatePodIfPresent(oldPod, newPod *v1.Pod) error {
+	key, err := framework.GetPodKey(newPod)
+	if err != nil {
+		return err
+	}
+
+	if !e.pods.Has(key) {
+		return nil
+	}
+
+	e.unreserveResource(*computePodResourceRequest(oldPod))
+	e.reserveResource(*computePodResourceRequest(newPod))
+
+	return nil
+}
+
 func cmp(x, y *framework.Resource, bound int64) bool {
 	return cmp2(x, &framework.Resource{}, y, bound)
 }
@@ -20,6 +20,7 @@ import (
 	"reflect"
 	"testing"
 
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/util/sets"
 
 	v1 "k8s.io/api/core/v1"
@@ -630,3 +631,254 @@ func TestNewElasticQuotaInfo(t *testing.T) {
 		})
 	}
 }
+
+func TestElasticQuotaDescendantsUsed(t *testing.T) {
+	infos := NewElasticQuotaInfos()
+	for _, q := range []struct{ namespace, parent string }{
+		{namespace: "dept"},
+		{namespace: "team", parent: "dept"},
+		{namespace: "squad", parent: "team"},
+	} {
+		info := newElasticQuotaInfo(q.namespace, nil, nil, nil)
+		info.Parent = q.parent
+		infos[q.namespace] = info
+	}
+	pod := makePod("p", "squad", 0, 1000, 0, 0, "p", "node-a")
+	resized := makePod("p", "squad", 0, 3000, 0, 0, "p", "node-a")
+	wantSubtree := func(step string, want map[string]int64) {
+		t.Helper()
+		for key, milli := range want {
+			if got := infos[key].subtree().Used.MilliCPU; got != milli {
+				t.Errorf("%s: subtree of %v uses %v milli cpu, want %v", step, key, got, milli)
+			}
+		}
+		// The usage maintained pod by pod is the one computed from scratch.
+		fresh := infos.clone()
+		fresh.computeDescendantsUsed()
+		for key := range infos {
+			if got, want := infos[key].subtree().Used.MilliCPU, fresh[key].subtree().Used.MilliCPU; got != want {
+				t.Errorf("%s: subtree of %v uses %v milli cpu, want %v as computed from scratch", step, key, got, want)
+			}
+		}
+	}
+
+	if _, err := infos.addPod("squad", pod); err != nil {
+		t.Fatal(err)
+	}
+	wantSubtree("added", map[string]int64{"dept": 1000, "team": 1000, "squad": 1000})
+	if _, err := infos.addPod("squad", pod); err != nil {
+		t.Fatal(err)
+	}
+	wantSubtree("added twice", map[string]int64{"dept": 1000, "team": 1000, "squad": 1000})
+	if _, err := infos.updatePod("squad", pod, resized); err != nil {
+		t.Fatal(err)
+	}
+	wantSubtree("resized", map[string]int64{"dept": 3000, "team": 3000, "squad": 3000})
+	if _, err := infos.deletePod("squad", resized); err != nil {
+		t.Fatal(err)
+	}
+	wantSubtree("deleted", map[string]int64{"dept": 0, "team": 0, "squad": 0})
+}
+
+func TestElasticQuotaTree(t *testing.T) {
+	cpu := func(milli int64) v1.ResourceList {
+		return v1.ResourceList{v1.ResourceCPU: *resource.NewMilliQuantity(milli, resource.DecimalSI)}
+	}
+	// dept-a and dept-b are the roots of two trees, with two teams and one team respectively.
+	newInfos := func(used map[string]int64) ElasticQuotaInfos {
+		infos := NewElasticQuotaInfos()
+		for _, q := range []struct {
+			namespace, parent string
+			min, max          v1.ResourceList
+		}{
+			{namespace: "dept-a", min: cpu(10000), max: cpu(20000)},
+			{namespace: "team-a1", parent: "dept-a", min: cpu(5000)},
+			{namespace: "team-a2", parent: "dept-a", min: cpu(5000)},
+			{namespace: "dept-b", min: cpu(10000)},
+			{namespace: "team-b1", parent: "dept-b", min: cpu(10000)},
+		} {
+			info := newElasticQuotaInfo(q.namespace, q.min, q.max, cpu(used[q.namespace]))
+			info.Parent = q.parent
+			infos[q.namespace] = info
+		}
+		infos.computeDescendantsUsed()
+		return infos
+	}
+	request := func(milli int64) *framework.Resource {
+		return &framework.Resource{MilliCPU: milli}
+	}
+
+	infos := newInfos(map[string]int64{"team-a1": 8000, "team-b1": 10000})
+	if got := newElasticQuotaTotals(infos).usedOverMinWith(request(2000)); got {
+		t.Error("Expected the usage within the min of the roots")
+	}
+	if got := newElasticQuotaTotals(infos).usedOverMinWith(request(3000)); !got {
+		t.Error("Expected the usage over the min of the roots")
+	}
+	if got := infos.usedOverMaxWith("team-a1", request(12000)); got {
+		t.Error("Expected the usage within the max of the department")
+	}
+	if got := infos.usedOverMaxWith("team-a1", request(13000)); !got {
+		t.Error("Expected the usage over the max of the department")
+	}
+
+	tests := []struct {
+		name      string
+		used      map[string]int64
+		preemptor string
+		victim    string
+		expected  bool
+	}{
+		{
+			name:      "sibling borrowing the min of the preemptor",
+			used:      map[string]int64{"team-a1": 8000},
+			preemptor: "team-a2",
+			victim:    "team-a1",
+			expected:  true,
+		},
+		{
+			name:      "department within its min does not borrow from other departments",
+			used:      map[string]int64{"team-a1": 8000, "team-b1": 10000},
+			preemptor: "team-b1",
+			victim:    "team-a1",
+			expected:  false,
+		},
+		{
+			name:      "department over its min borrows from other departments",
+			used:      map[string]int64{"team-a1": 14000, "team-b1": 4000},
+			preemptor: "team-b1",
+			victim:    "team-a1",
+			expected:  true,
+		},
+		{
+			name:      "victim within its own min in a department over its min",
+			used:      map[string]int64{"team-a1": 14000, "team-a2": 2000, "team-b1": 4000},
+			preemptor: "team-b1",
+			victim:    "team-a2",
+			expected:  false,
+		},
+		{
+			name:      "team over its min reclaims for its department",
+			used:      map[string]int64{"team-a2": 6000, "team-b1": 14000},
+			preemptor: "team-a2",
+			victim:    "team-b1",
+			expected:  true,
+		},
+		{
+			name:      "ancestor of the preemptor",
+			used:      map[string]int64{"dept-a": 12000},
+			preemptor: "team-a2",
+			victim:    "dept-a",
+			expected:  false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			infos := newInfos(tt.used)
+			if got := infos.reclaimable(tt.preemptor, tt.victim, request(1000)); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestElasticQuotaFairShare(t *testing.T) {
+	const gpu = "nvidia.com/gpu"
+	resources := func(milliCPU, gpus int64) v1.ResourceList {
+		return v1.ResourceList{
+			v1.ResourceCPU: *resource.NewMilliQuantity(milliCPU, resource.DecimalSI),
+			gpu:            *resource.NewQuantity(gpus, resource.DecimalSI),
+		}
+	}
+	// quota-a and quota-b borrow the min quota-c leaves unused, quota-b with three times the weight of quota-a.
+	newInfos := func(used map[string]int64) ElasticQuotaInfos {
+		infos := NewElasticQuotaInfos()
+		for _, q := range []struct {
+			namespace string
+			weight    int64
+			min       v1.ResourceList
+		}{
+			{namespace: "quota-a", min: resources(10000, 0)},
+			{namespace: "quota-b", weight: 3, min: resources(10000, 0)},
+			{namespace: "quota-c", min: resources(10000, 4)},
+		} {
+			info := newElasticQuotaInfo(q.namespace, q.min, nil, resources(used[q.namespace], used[q.namespace+"/gpu"]))
+			info.Weight = q.weight
+			infos[q.namespace] = info
+		}
+		return infos
+	}
+
+	tests := []struct {
+		name     string
+		used     map[string]int64
+		key      string
+		request  *framework.Resource
+		waiting  sets.Set[string]
+		expected bool
+	}{
+		{
+			name:     "over its fair share while others wait",
+			used:     map[string]int64{"quota-a": 14000, "quota-b": 10000},
+			key:      "quota-a",
+			waiting:  sets.New("quota-b"),
+			expected: true,
+		},
+		{
+			name: "over its fair share while nobody waits",
+			used: map[string]int64{"quota-a": 14000, "quota-b": 10000},
+			key:  "quota-a",
+		},
+		{
+			name:    "within its fair share",
+			used:    map[string]int64{"quota-a": 12000, "quota-b": 10000},
+			key:     "quota-a",
+			waiting: sets.New("quota-b"),
+		},
+		{
+			name:     "over its fair share with the request",
+			used:     map[string]int64{"quota-a": 12000, "quota-b": 10000},
+			key:      "quota-a",
+			request:  &framework.Resource{MilliCPU: 1000},
+			waiting:  sets.New("quota-b"),
+			expected: true,
+		},
+		{
+			name:    "within its fair share by its weight",
+			used:    map[string]int64{"quota-a": 10000, "quota-b": 16000},
+			key:     "quota-b",
+			waiting: sets.New("quota-a"),
+		},
+		{
+			name:    "within its min",
+			used:    map[string]int64{"quota-a": 8000},
+			key:     "quota-a",
+			request: &framework.Resource{MilliCPU: 2000},
+			waiting: sets.New("quota-b"),
+		},
+		{
+			name:     "over its fair share by its dominant resource",
+			used:     map[string]int64{"quota-a": 10000, "quota-a/gpu": 2},
+			key:      "quota-a",
+			waiting:  sets.New("quota-b"),
+			expected: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			infos := newInfos(tt.used)
+			if got := infos.overFairShareWith(tt.key, tt.request, tt.waiting); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	infos := newInfos(map[string]int64{"quota-a": 14000, "quota-b": 10000})
+	if got := infos.rebalanceable("quota-b", "quota-a", &framework.Resource{MilliCPU: 1000}, sets.New[string]()); !got {
+		t.Error("Expected quota-b to preempt quota-a borrowing over its fair share")
+	}
+	infos = newInfos(map[string]int64{"quota-a": 10000, "quota-b": 16000})
+	if got := infos.rebalanceable("quota-a", "quota-b", &framework.Resource{MilliCPU: 1000}, sets.New[string]()); got {
+		t.Error("Expected quota-a not to preempt quota-b borrowing within its fair share")
+	}
+}
@@ -0,0 +1,241 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capacityscheduling
+
+import (
+	"maps"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	quota "k8s.io/apiserver/pkg/quota/v1"
+
+	"sigs.k8s.io/scheduler-plugins/pkg/util"
+)
+
+// elasticQuotaPool is a pool of nodes ElasticQuotas scope guarantees and limits to, along with the usage
+// of the quotas on its nodes.
+type elasticQuotaPool struct {
+	selector labels.Selector
+	// min and max are the ones of the quotas naming the pool, by key, and totalMin the sum of min.
+	min      map[string]v1.ResourceList
+	max      map[string]v1.ResourceList
+	totalMin v1.ResourceList
+	// used is the usage of the quotas on the nodes of the pool, by key, whether they name the pool or not,
+	// and totalUsed the sum of used.
+	used      map[string]v1.ResourceList
+	totalUsed v1.ResourceList
+}
+
+// elasticQuotaPools are the pools the ElasticQuotas name, by name.
+type elasticQuotaPools map[string]*elasticQuotaPool
+
+// newElasticQuotaPools returns the pools the given quotas name, without usage, or nil if no quota names a
+// pool. A pool selects the nodes the first quota naming it, by key, selects.
+func newElasticQuotaPools(elasticQuotaInfos ElasticQuotaInfos) elasticQuotaPools {
+	var keys []string
+	for key, info := range elasticQuotaInfos {
+		if len(info.pools) > 0 {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	sort.Strings(keys)
+
+	pools := make(elasticQuotaPools)
+	for _, key := range keys {
+		for _, p := range elasticQuotaInfos[key].pools {
+			pool := pools[p.Name]
+			if pool == nil {
+				pool = &elasticQuotaPool{
+					selector: nodeSelectorAsSelector(p.NodeSelector),
+					min:      make(map[string]v1.ResourceList),
+					max:      make(map[string]v1.ResourceList),
+					used:     make(map[string]v1.ResourceList),
+				}
+				pools[p.Name] = pool
+			}
+			if p.Min != nil {
+				pool.min[key] = p.Min
+				pool.totalMin = quota.Add(pool.totalMin, p.Min)
+			}
+			if p.Max != nil {
+				pool.max[key] = p.Max
+			}
+		}
+	}
+	return pools
+}
+
+// nodeSelectorAsSelector returns the selector of the nodes of a pool. A pool without selector selects all
+// the nodes, and a pool with an invalid one none.
+func nodeSelectorAsSelector(nodeSelector *metav1.LabelSelector) labels.Selector {
+	if nodeSelector == nil {
+		return labels.Everything()
+	}
+	selector, err := metav1.LabelSelectorAsSelector(nodeSelector)
+	if err != nil {
+		return labels.Nothing()
+	}
+	return selector
+}
+
+func (p elasticQuotaPools) clone() elasticQuotaPools {
+	if p == nil {
+		return nil
+	}
+	pools := make(elasticQuotaPools, len(p))
+	for name, pool := range p {
+		clone := *pool
+		clone.used = maps.Clone(pool.used)
+		pools[name] = &clone
+	}
+	return pools
+}
+
+// addPod counts the requests of the pod against the quota of the given key in the pools of the node with
+// the given labels.
+func (p elasticQuotaPools) addPod(key string, pod *v1.Pod, nodeLabels map[string]string) {
+	p.updatePod(key, pod, nodeLabels, quota.Add)
+}
+
+// removePod no longer counts the requests of the pod against the quota of the given key in the pools of the
+// node with the given labels.
+func (p elasticQuotaPools) removePod(key string, pod *v1.Pod, nodeLabels map[string]string) {
+	p.updatePod(key, pod, nodeLabels, quota.Subtract)
+}
+
+func (p elasticQuotaPools) updatePod(key string, pod *v1.Pod, nodeLabels map[string]string,
+	update func(a, b v1.ResourceList) v1.ResourceList) {
+	if len(p) == 0 || pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+		return
+	}
+	if names := p.matching(nodeLabels); len(names) > 0 {
+		p.update(names, key, util.PodRequests(pod), update)
+	}
+}
+
+// matching returns the names of the pools of the node with the given labels.
+func (p elasticQuotaPools) matching(nodeLabels map[string]string) []string {
+	var names []string
+	for name, pool := range p {
+		if pool.selector.Matches(labels.Set(nodeLabels)) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// update updates the usage of the quota of the given key in the pools of the given names with the request.
+func (p elasticQuotaPools) update(names []string, key string, request v1.ResourceList,
+	update func(a, b v1.ResourceList) v1.ResourceList) {
+	for _, name := range names {
+		if pool := p[name]; pool != nil {
+			// The results are new lists, so that clones may share the previous ones.
+			pool.used[key] = update(pool.used[key], request)
+			pool.totalUsed = update(pool.totalUsed, request)
+		}
+	}
+}
+
+// usedOverMaxWith returns the name of a pool of the node with the given labels in which the usage of the
+// quota of the given key with <podRequest> exceeds its max, if any.
+func (p elasticQuotaPools) usedOverMaxWith(key string, podRequest v1.ResourceList, nodeLabels map[string]string) string {
+	for _, name := range p.names() {
+		pool := p[name]
+		max, ok := pool.max[key]
+		if !ok || !pool.selector.Matches(labels.Set(nodeLabels)) {
+			continue
+		}
+		if exceedsFor(quota.Add(pool.used[key], podRequest), max, podRequest) {
+			return name
+		}
+	}
+	return ""
+}
+
+// aggregatedUsedOverMinWith returns the name of a pool of the node with the given labels in which the usage
+// of all the quotas with <podRequest> exceeds the sum of their min of the pool, if any.
+func (p elasticQuotaPools) aggregatedUsedOverMinWith(podRequest v1.ResourceList, nodeLabels map[string]string) string {
+	for _, name := range p.names() {
+		pool := p[name]
+		if len(pool.min) == 0 || !pool.selector.Matches(labels.Set(nodeLabels)) {
+			continue
+		}
+		if exceedsFor(quota.Add(pool.totalUsed, podRequest), pool.totalMin, podRequest) {
+			return name
+		}
+	}
+	return ""
+}
+
+// reclaimable returns whether a pod of the quota of <preemptorKey> requesting <podRequest> may preempt the
+// pods of the quota of <victimKey> on the node with the given labels: in a pool of the node, the quota of
+// the preemptor stays within its min of the pool with the request, while the quota of the victim is over
+// its own min of the pool, i.e. borrows from the preemptor, for a resource the min of the preemptor names.
+func (p elasticQuotaPools) reclaimable(preemptorKey, victimKey string, podRequest v1.ResourceList, nodeLabels map[string]string) bool {
+	for _, pool := range p {
+		min, ok := pool.min[preemptorKey]
+		if !ok || !pool.selector.Matches(labels.Set(nodeLabels)) {
+			continue
+		}
+		if exceedsFor(quota.Add(pool.used[preemptorKey], podRequest), min, podRequest) {
+			continue
+		}
+		victimUsed, victimMin := pool.used[victimKey], pool.min[victimKey]
+		for name := range min {
+			used := victimUsed[name]
+			if requested(podRequest, name) && used.Cmp(victimMin[name]) > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// names returns the names of the pools, sorted.
+func (p elasticQuotaPools) names() []string {
+	names := make([]string, 0, len(p))
+	for name := range p {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// exceedsFor returns whether <used> exceeds <limit> for a resource <limit> names and <podRequest> asks for.
+// The resources the limit does not name are not limited.
+func exceedsFor(used, limit, podRequest v1.ResourceList) bool {
+	for name, l := range limit {
+		if !requested(podRequest, name) {
+			continue
+		}
+		if u := used[name]; u.Cmp(l) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// requested returns whether the request asks for some of the resource.
+func requested(podRequest v1.ResourceList, name v1.ResourceName) bool {
+	r, ok := podRequest[name]
+	return ok && r.Sign() > 0
+}
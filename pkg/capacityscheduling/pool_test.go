@@ -0,0 +1,145 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capacityscheduling
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	st "k8s.io/kubernetes/pkg/scheduler/testing"
+
+	"sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
+)
+
+func TestElasticQuotaPoolsReclaimable(t *testing.T) {
+	gpus := func(n int64) v1.ResourceList {
+		return v1.ResourceList{ResourceGPU: *resource.NewQuantity(n, resource.DecimalSI)}
+	}
+	pool := v1alpha1.ElasticQuotaPool{
+		Name:         "a",
+		NodeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"gpu": "a"}},
+		Min:          gpus(2),
+	}
+	eq1 := makeEQ("ns1", "eq1", nil, nil)
+	eq1.Spec.Pools = []v1alpha1.ElasticQuotaPool{pool}
+	eq2 := makeEQ("ns2", "eq2", nil, nil)
+	eq2.Spec.Pools = []v1alpha1.ElasticQuotaPool{pool}
+	elasticQuotaInfos := ElasticQuotaInfos{
+		"ns1": newElasticQuotaInfoFor(eq1),
+		"ns2": newElasticQuotaInfoFor(eq2),
+		"ns3": newElasticQuotaInfoFor(makeEQ("ns3", "eq3", nil, nil)),
+	}
+	nodes := []*v1.Node{
+		st.MakeNode().Name("node-a").Label("gpu", "a").Obj(),
+		st.MakeNode().Name("node-b").Label("gpu", "b").Obj(),
+	}
+	victim := makePod("ns2-p1", "ns2", 0, 0, 4, midPriority, "ns2-p1", "node-a")
+	pods := []*v1.Pod{
+		victim,
+		makePod("ns3-p1", "ns3", 0, 0, 1, midPriority, "ns3-p1", "node-a"),
+	}
+	pools := newElasticQuotaPools(elasticQuotaInfos)
+	for _, pod := range pods {
+		pools.addPod(pod.Namespace, pod, nodes[0].Labels)
+	}
+	labelsA, labelsB := nodes[0].Labels, nodes[1].Labels
+
+	tests := []struct {
+		name         string
+		preemptorKey string
+		victimKey    string
+		podRequest   v1.ResourceList
+		nodeLabels   map[string]string
+		want         bool
+	}{
+		{
+			name:         "victim over its min of the pool",
+			preemptorKey: "ns1",
+			victimKey:    "ns2",
+			podRequest:   gpus(1),
+			nodeLabels:   labelsA,
+			want:         true,
+		},
+		{
+			name:         "victim without min of the pool",
+			preemptorKey: "ns1",
+			victimKey:    "ns3",
+			podRequest:   gpus(1),
+			nodeLabels:   labelsA,
+			want:         true,
+		},
+		{
+			name:         "preemptor over its min of the pool",
+			preemptorKey: "ns1",
+			victimKey:    "ns2",
+			podRequest:   gpus(3),
+			nodeLabels:   labelsA,
+			want:         false,
+		},
+		{
+			name:         "preemptor without min of the pool",
+			preemptorKey: "ns3",
+			victimKey:    "ns2",
+			podRequest:   gpus(1),
+			nodeLabels:   labelsA,
+			want:         false,
+		},
+		{
+			name:         "preemptor already borrowing in the pool",
+			preemptorKey: "ns2",
+			victimKey:    "ns1",
+			podRequest:   gpus(1),
+			nodeLabels:   labelsA,
+			want:         false,
+		},
+		{
+			name:         "node out of the pool",
+			preemptorKey: "ns1",
+			victimKey:    "ns2",
+			podRequest:   gpus(1),
+			nodeLabels:   labelsB,
+			want:         false,
+		},
+		{
+			name:         "resource the pool does not guarantee",
+			preemptorKey: "ns1",
+			victimKey:    "ns2",
+			podRequest:   makeResourceList(100, 100),
+			nodeLabels:   labelsA,
+			want:         false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pools.reclaimable(tt.preemptorKey, tt.victimKey, tt.podRequest, tt.nodeLabels); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	// Once the victim is removed, the clone no longer borrows from the preemptor, unlike the original.
+	clone := pools.clone()
+	clone.removePod("ns2", victim, labelsA)
+	if clone.reclaimable("ns1", "ns2", gpus(1), labelsA) {
+		t.Errorf("expected the victim removed not to be reclaimable")
+	}
+	if !pools.reclaimable("ns1", "ns2", gpus(1), labelsA) {
+		t.Errorf("expected the original pools to be left unchanged")
+	}
+}
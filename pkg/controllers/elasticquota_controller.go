@@ -17,37 +17,63 @@ limitations under the License.
 package controllers
 
 import (
+	"cmp"
 	"context"
 	"fmt"
+	"math/big"
+	"slices"
+	"strings"
 
 	v1 "k8s.io/api/core/v1"
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	apierrs "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	quota "k8s.io/apiserver/pkg/quota/v1"
 	"k8s.io/client-go/tools/record"
+	corev1helpers "k8s.io/component-helpers/scheduling/corev1"
 
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/scheduler-plugins/apis/config"
 	schedv1alpha1 "sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
+	"sigs.k8s.io/scheduler-plugins/pkg/util"
 )
 
+// maxPodsAtRisk bounds the number of pods listed in the status of an ElasticQuota.
+const maxPodsAtRisk = 100
+
 type ElasticQuotaReconciler struct {
 	recorder record.EventRecorder
 
 	client.Client
 	Scheme  *runtime.Scheme
 	Workers int
+	// AccountingPolicy tells which pods count towards the usage of their ElasticQuota.
+	AccountingPolicy config.ElasticQuotaAccountingPolicy
 }
 
+// lendingRequest is the request of the pass computing what the ElasticQuotas of all the namespaces borrow
+// and lend out from their usage. The queue holds it at most once, so that the changes of usage while it waits
+// lead to a single pass.
+var lendingRequest = ctrl.Request{NamespacedName: types.NamespacedName{Name: "elasticquota-lending"}}
+
 // +kubebuilder:rbac:groups=scheduling.x-k8s.io,resources=elasticquota,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=scheduling.x-k8s.io,resources=elasticquota/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=scheduling.x-k8s.io,resources=elasticquota/finalizers,verbs=update
 func (r *ElasticQuotaReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	if req == lendingRequest {
+		return ctrl.Result{}, r.reconcileLending(ctx)
+	}
+
 	log := log.FromContext(ctx)
 	log.Info("reconciling")
 	eqList := &schedv1alpha1.ElasticQuotaList{}
@@ -59,33 +85,84 @@ func (r *ElasticQuotaReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		log.V(3).Error(err, "Unable to retrieve elasticquota")
 		return ctrl.Result{}, err
 	}
+	eqs := eqList.Items
 
-	// TODO: When elastic quota supports multiple instances in a namespace, modify this
-	if len(eqList.Items) == 0 {
+	if len(eqs) == 0 {
 		log.V(5).Info("no elasticquota found")
 		return ctrl.Result{}, nil
 	}
 
-	eq := &eqList.Items[0]
-	used, err := r.computeElasticQuotaUsed(ctx, req.Namespace, eq)
+	usedByEQ, podsByEQ, err := r.computeElasticQuotasUsed(ctx, req.Namespace, eqs)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
 
-	// Ignore this loop if the usage value has not changed
-	if apiequality.Semantic.DeepEqual(used, eq.Status.Used) {
-		return ctrl.Result{}, nil
+	for i := range eqs {
+		eq := &eqs[i]
+		for j := range eqs {
+			if j != i && util.ElasticQuotasOverlap(eq, &eqs[j]) {
+				r.recorder.Event(eq, v1.EventTypeWarning, "Overlapping",
+					fmt.Sprintf("Elastic Quota %s selects pods of Elastic Quota %s as well", eq.Name, eqs[j].Name))
+			}
+		}
+
+		// What the quota borrows and lends out is left to the lending pass, which the change of usage triggers.
+		status := *eq.Status.DeepCopy()
+		status.Used = usedByEQ[eq.Name]
+		status.PodsAtRisk = podsAtRisk(podsByEQ[eq.Name], eq.Spec.Min)
+		if err := r.updateElasticQuotaStatus(ctx, eq, status); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	return ctrl.Result{}, nil
+}
+
+// reconcileLending updates what the ElasticQuotas of all the namespaces borrow and lend out, from the usage
+// their status reports.
+func (r *ElasticQuotaReconciler) reconcileLending(ctx context.Context) error {
+	log := log.FromContext(ctx)
+	log.Info("reconciling lending")
+	eqList := &schedv1alpha1.ElasticQuotaList{}
+	if err := r.List(ctx, eqList); err != nil {
+		log.V(3).Error(err, "Unable to retrieve elasticquota")
+		return err
+	}
+	eqs := eqList.Items
+
+	used := make(map[types.NamespacedName]v1.ResourceList, len(eqs))
+	for i := range eqs {
+		used[elasticQuotaKey(&eqs[i])] = eqs[i].Status.Used
+	}
+	borrowed, lentOut := computeElasticQuotasLending(eqs, used)
+
+	for i := range eqs {
+		eq := &eqs[i]
+		status := *eq.Status.DeepCopy()
+		status.Borrowed = borrowed[elasticQuotaKey(eq)]
+		status.LentOut = lentOut[elasticQuotaKey(eq)]
+		if err := r.updateElasticQuotaStatus(ctx, eq, status); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateElasticQuotaStatus patches the status of the ElasticQuota if it changed.
+func (r *ElasticQuotaReconciler) updateElasticQuotaStatus(ctx context.Context, eq *schedv1alpha1.ElasticQuota, status schedv1alpha1.ElasticQuotaStatus) error {
+	// Ignore this quota if the status has not changed
+	if apiequality.Semantic.DeepEqual(status, eq.Status) {
+		return nil
 	}
 
 	// create a usage object that is based on the elastic quota version that will handle updates
 	// by default, we set used to the current status
 	newEQ := eq.DeepCopy()
-	newEQ.Status.Used = used
-	if err = r.patchElasticQuota(ctx, eq, newEQ); err != nil {
-		return ctrl.Result{}, err
+	newEQ.Status = status
+	if err := r.patchElasticQuota(ctx, eq, newEQ); err != nil {
+		return err
 	}
-	r.recorder.Event(eq, v1.EventTypeNormal, "Synced", fmt.Sprintf("Elastic Quota %s synced successfully", req.NamespacedName))
-	return ctrl.Result{}, nil
+	r.recorder.Event(eq, v1.EventTypeNormal, "Synced", fmt.Sprintf("Elastic Quota %s/%s synced successfully", eq.Namespace, eq.Name))
+	return nil
 }
 
 func (r *ElasticQuotaReconciler) patchElasticQuota(ctx context.Context, old, new *schedv1alpha1.ElasticQuota) error {
@@ -93,65 +170,183 @@ func (r *ElasticQuotaReconciler) patchElasticQuota(ctx context.Context, old, new
 	return r.Status().Patch(ctx, new, patch)
 }
 
-func (r *ElasticQuotaReconciler) computeElasticQuotaUsed(ctx context.Context, namespace string, eq *schedv1alpha1.ElasticQuota) (v1.ResourceList, error) {
-	used := newZeroUsed(eq)
+// computeElasticQuotasUsed returns, by name, the usage of the given ElasticQuotas of the namespace and the
+// pods counting towards it. Each pod counts against the quota selecting it, if any, or else against the
+// quota without selector.
+func (r *ElasticQuotaReconciler) computeElasticQuotasUsed(ctx context.Context, namespace string, eqs []schedv1alpha1.ElasticQuota) (map[string]v1.ResourceList, map[string][]*v1.Pod, error) {
+	usedByEQ := make(map[string]v1.ResourceList, len(eqs))
+	podsByEQ := make(map[string][]*v1.Pod, len(eqs))
+	for i := range eqs {
+		usedByEQ[eqs[i].Name] = newZeroUsed(&eqs[i])
+	}
 	podList := &v1.PodList{}
 	if err := r.List(ctx, podList, client.InNamespace(namespace)); err != nil {
-		return nil, err
-	}
-
-	for _, p := range podList.Items {
-		if p.Status.Phase == v1.PodRunning {
-			used = quota.Add(used, computePodResourceRequest(&p))
-		}
-	}
-	return used, nil
-}
-
-// computePodResourceRequest returns a v1.ResourceList that covers the largest
-// width in each resource dimension. Because init-containers run sequentially, we collect
-// the max in each dimension iteratively. In contrast, we sum the resource vectors for
-// regular containers since they run simultaneously.
-//
-// If Pod Overhead is specified and the feature gate is set, the resources defined for Overhead
-// are added to the calculated Resource request sum
-//
-// Example:
-//
-// Pod:
-//
-//	InitContainers
-//	  IC1:
-//	    CPU: 2
-//	    Memory: 1G
-//	  IC2:
-//	    CPU: 2
-//	    Memory: 3G
-//	Containers
-//	  C1:
-//	    CPU: 2
-//	    Memory: 1G
-//	  C2:
-//	    CPU: 1
-//	    Memory: 1G
-//
-// Result: CPU: 3, Memory: 3G
-func computePodResourceRequest(pod *v1.Pod) v1.ResourceList {
-	result := v1.ResourceList{}
-	for _, container := range pod.Spec.Containers {
-		result = quota.Add(result, container.Resources.Requests)
+		return nil, nil, err
+	}
+
+	for i := range podList.Items {
+		p := &podList.Items[i]
+		if !util.PodCountsTowardsElasticQuota(p, r.AccountingPolicy) {
+			continue
+		}
+		if eq := util.FindElasticQuota(eqs, p); eq != nil {
+			usedByEQ[eq.Name] = quota.Add(usedByEQ[eq.Name], computePodResourceRequest(p))
+			podsByEQ[eq.Name] = append(podsByEQ[eq.Name], p)
+		}
+	}
+	return usedByEQ, podsByEQ, nil
+}
+
+// elasticQuotaKey returns the key of the ElasticQuota.
+func elasticQuotaKey(eq *schedv1alpha1.ElasticQuota) types.NamespacedName {
+	return types.NamespacedName{Namespace: eq.Namespace, Name: eq.Name}
+}
+
+// elasticQuotaParent returns the key of the parent of the ElasticQuota among the given ones, or the zero
+// key if it has none. A parent without name, or not found by name, is the quota without selector of its
+// namespace, the same as the CapacityScheduling plugin finds it.
+func elasticQuotaParent(eqs map[types.NamespacedName]*schedv1alpha1.ElasticQuota, eq *schedv1alpha1.ElasticQuota) types.NamespacedName {
+	parent := eq.Spec.Parent
+	if parent == nil {
+		return types.NamespacedName{}
+	}
+	if key := (types.NamespacedName{Namespace: parent.Namespace, Name: parent.Name}); eqs[key] != nil {
+		return key
 	}
-	initRes := v1.ResourceList{}
-	// take max_resource for init_containers
-	for _, container := range pod.Spec.InitContainers {
-		initRes = quota.Max(initRes, container.Resources.Requests)
+	for key, other := range eqs {
+		if other.Namespace == parent.Namespace && !util.ElasticQuotaHasSelector(other) {
+			return key
+		}
+	}
+	return types.NamespacedName{}
+}
+
+// computeElasticQuotasLending returns, by key, what the given ElasticQuotas of all the namespaces borrow
+// and lend out given their usage. A quota borrows what its usage and the one of its descendants exceed its
+// min, and lends the unused part of its min to its siblings, or to the other quotas without parent for
+// the roots of the trees, in proportion to the unused min of each.
+func computeElasticQuotasLending(eqs []schedv1alpha1.ElasticQuota, used map[types.NamespacedName]v1.ResourceList) (map[types.NamespacedName]v1.ResourceList, map[types.NamespacedName]v1.ResourceList) {
+	byKey := make(map[types.NamespacedName]*schedv1alpha1.ElasticQuota, len(eqs))
+	for i := range eqs {
+		byKey[elasticQuotaKey(&eqs[i])] = &eqs[i]
+	}
+	parents := make(map[types.NamespacedName]types.NamespacedName, len(eqs))
+	for key, eq := range byKey {
+		parents[key] = elasticQuotaParent(byKey, eq)
 	}
-	// If Overhead is being utilized, add to the total requests for the pod
-	if pod.Spec.Overhead != nil {
-		quota.Add(result, pod.Spec.Overhead)
+
+	// The usage of a quota counts against its ancestors as well.
+	subtreeUsed := make(map[types.NamespacedName]v1.ResourceList, len(eqs))
+	for key := range byKey {
+		subtreeUsed[key] = quota.Add(subtreeUsed[key], used[key])
+		visited := sets.New(key)
+		for parent := parents[key]; parent != (types.NamespacedName{}) && !visited.Has(parent); parent = parents[parent] {
+			visited.Insert(parent)
+			subtreeUsed[parent] = quota.Add(subtreeUsed[parent], used[key])
+		}
 	}
-	// take max_resource for init_containers and containers
-	return quota.Max(result, initRes)
+
+	borrowed := make(map[types.NamespacedName]v1.ResourceList, len(eqs))
+	idle := make(map[types.NamespacedName]v1.ResourceList, len(eqs))
+	children := make(map[types.NamespacedName][]types.NamespacedName)
+	for key, eq := range byKey {
+		borrowed[key] = util.ElasticQuotaBorrowed(subtreeUsed[key], eq.Spec.Min)
+		idle[key] = util.ElasticQuotaIdle(subtreeUsed[key], eq.Spec.Min)
+		children[parents[key]] = append(children[parents[key]], key)
+	}
+
+	lentOut := make(map[types.NamespacedName]v1.ResourceList, len(eqs))
+	for _, siblings := range children {
+		totalBorrowed, totalIdle := v1.ResourceList{}, v1.ResourceList{}
+		for _, key := range siblings {
+			totalBorrowed = quota.Add(totalBorrowed, borrowed[key])
+			totalIdle = quota.Add(totalIdle, idle[key])
+		}
+		for _, key := range siblings {
+			lentOut[key] = lendIdle(idle[key], totalIdle, totalBorrowed)
+		}
+	}
+	return borrowed, lentOut
+}
+
+// lendIdle returns the part of the unused min <idle> lent out when siblings borrow <totalBorrowed> from
+// the <totalIdle> unused min of all of them, in proportion to <idle>. CPU is split by millicores and the
+// other resources by units.
+func lendIdle(idle, totalIdle, totalBorrowed v1.ResourceList) v1.ResourceList {
+	var lent v1.ResourceList
+	for name, quant := range idle {
+		borrowed := totalBorrowed[name]
+		if borrowed.Sign() <= 0 {
+			continue
+		}
+		share := quant.DeepCopy()
+		if total := totalIdle[name]; borrowed.Cmp(total) < 0 {
+			value := func(q resource.Quantity) int64 {
+				if name == v1.ResourceCPU {
+					return q.MilliValue()
+				}
+				return q.Value()
+			}
+			x := new(big.Int).Mul(big.NewInt(value(quant)), big.NewInt(value(borrowed)))
+			x.Quo(x, big.NewInt(value(total)))
+			if name == v1.ResourceCPU {
+				share = *resource.NewMilliQuantity(x.Int64(), quant.Format)
+			} else {
+				share = *resource.NewQuantity(x.Int64(), quant.Format)
+			}
+		}
+		if share.IsZero() {
+			continue
+		}
+		if lent == nil {
+			lent = v1.ResourceList{}
+		}
+		lent[name] = share
+	}
+	return lent
+}
+
+// podsAtRisk returns the names of the pods running beyond the min of their ElasticQuota, once ranked the
+// way preemption reprieves them: by decreasing priority, then by start time, the pods not started yet
+// last. A pod fitting within the min along with the pods ranked before it is guaranteed.
+func podsAtRisk(pods []*v1.Pod, min v1.ResourceList) []string {
+	slices.SortFunc(pods, func(a, b *v1.Pod) int {
+		if pa, pb := corev1helpers.PodPriority(a), corev1helpers.PodPriority(b); pa != pb {
+			return cmp.Compare(pb, pa)
+		}
+		sa, sb := a.Status.StartTime, b.Status.StartTime
+		switch {
+		case sa == nil && sb != nil:
+			return 1
+		case sa != nil && sb == nil:
+			return -1
+		case sa != nil && !sa.Equal(sb):
+			return sa.Compare(sb.Time)
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	var atRisk []string
+	guaranteed := v1.ResourceList{}
+	for _, p := range pods {
+		used := quota.Add(guaranteed, computePodResourceRequest(p))
+		if util.ElasticQuotaBorrowed(used, min) == nil {
+			guaranteed = used
+			continue
+		}
+		atRisk = append(atRisk, p.Name)
+		if len(atRisk) == maxPodsAtRisk {
+			break
+		}
+	}
+	return atRisk
+}
+
+// computePodResourceRequest returns the requests of the pod counting towards its ElasticQuota, the
+// same as the CapacityScheduling plugin's: the largest of the sum of its containers and sidecars, and of
+// each init container along with the sidecars started before it, plus its overhead.
+func computePodResourceRequest(pod *v1.Pod) v1.ResourceList {
+	return util.PodRequests(pod)
 }
 
 // newZeroUsed will return the zero value of the union of min and max
@@ -172,7 +367,31 @@ func (r *ElasticQuotaReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	r.recorder = mgr.GetEventRecorderFor("ElasticQuotaController")
 	return ctrl.NewControllerManagedBy(mgr).
 		Watches(&v1.Pod{}, &handler.EnqueueRequestForObject{}).
-		For(&schedv1alpha1.ElasticQuota{}).
+		// The updates of the status alone, including the ones of the controller, change no usage.
+		For(&schedv1alpha1.ElasticQuota{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&schedv1alpha1.ElasticQuota{}, handler.EnqueueRequestsFromMapFunc(toLendingRequest), builder.WithPredicates(lendingChanged)).
 		WithOptions(controller.Options{MaxConcurrentReconciles: r.Workers}).
 		Complete(r)
 }
+
+// lendingChanged filters the events of the ElasticQuotas which change what the quotas borrow and lend out:
+// all but the updates of neither the spec nor the usage, such as the ones of the lending pass itself.
+var lendingChanged = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		oldEQ, ok := e.ObjectOld.(*schedv1alpha1.ElasticQuota)
+		if !ok {
+			return true
+		}
+		newEQ, ok := e.ObjectNew.(*schedv1alpha1.ElasticQuota)
+		if !ok {
+			return true
+		}
+		return oldEQ.Generation != newEQ.Generation || !quota.Equals(oldEQ.Status.Used, newEQ.Status.Used)
+	},
+}
+
+// toLendingRequest enqueues the lending pass, since what a quota uses changes what the others borrow and lend
+// out.
+func toLendingRequest(context.Context, client.Object) []ctrl.Request {
+	return []ctrl.Request{lendingRequest}
+}
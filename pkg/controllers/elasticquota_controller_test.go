@@ -20,11 +20,13 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"slices"
 	"testing"
 	"time"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
@@ -37,6 +39,8 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/scheduler-plugins/apis/config"
 	"sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
 	testutil "sigs.k8s.io/scheduler-plugins/test/integration"
 )
@@ -45,6 +49,7 @@ func TestElasticQuotaController_Run(t *testing.T) {
 	ctx := context.TODO()
 	cases := []struct {
 		name          string
+		policy        config.ElasticQuotaAccountingPolicy
 		elasticQuotas []*v1alpha1.ElasticQuota
 		pods          []*v1.Pod
 		want          []*v1alpha1.ElasticQuota
@@ -57,14 +62,16 @@ func TestElasticQuotaController_Run(t *testing.T) {
 					Max(testutil.MakeResourceList().CPU(5).Mem(15).GPU(1).Obj()).Obj(),
 			},
 			pods: []*v1.Pod{
-				testutil.MakePod("t1-ns1", "pod1").Phase(v1.PodRunning).Container(
+				testutil.MakePod("t1-ns1", "pod1").Phase(v1.PodRunning).Node("node-a").Container(
 					testutil.MakeResourceList().CPU(1).Mem(2).GPU(1).Obj()).Obj(),
 				testutil.MakePod("t1-ns1", "pod2").Phase(v1.PodPending).Container(
 					testutil.MakeResourceList().CPU(1).Mem(2).GPU(0).Obj()).Obj(),
 			},
 			want: []*v1alpha1.ElasticQuota{
 				testutil.MakeEQ("t1-ns1", "t1-eq1").
-					Used(testutil.MakeResourceList().CPU(1).Mem(2).GPU(1).Obj()).Obj(),
+					Used(testutil.MakeResourceList().CPU(1).Mem(2).GPU(1).Obj()).
+					Borrowed(testutil.MakeResourceList().GPU(1).Obj()).
+					PodsAtRisk("pod1").Obj(),
 			},
 		},
 		{
@@ -77,13 +84,13 @@ func TestElasticQuotaController_Run(t *testing.T) {
 
 			pods: []*v1.Pod{
 				// CPU: 2, Mem: 4
-				testutil.MakePod("t2-ns1", "pod1").Phase(v1.PodRunning).
+				testutil.MakePod("t2-ns1", "pod1").Phase(v1.PodRunning).Node("node-a").
 					Container(
 						testutil.MakeResourceList().CPU(1).Mem(2).Obj()).
 					Container(
 						testutil.MakeResourceList().CPU(1).Mem(2).Obj()).Obj(),
 				// CPU: 3, Mem: 3
-				testutil.MakePod("t2-ns1", "pod2").Phase(v1.PodRunning).
+				testutil.MakePod("t2-ns1", "pod2").Phase(v1.PodRunning).Node("node-a").
 					InitContainerRequest(
 						testutil.MakeResourceList().CPU(2).Mem(1).Obj()).
 					InitContainerRequest(
@@ -95,7 +102,9 @@ func TestElasticQuotaController_Run(t *testing.T) {
 			},
 			want: []*v1alpha1.ElasticQuota{
 				testutil.MakeEQ("t2-ns1", "t2-eq1").
-					Used(testutil.MakeResourceList().CPU(5).Mem(7).Obj()).Obj(),
+					Used(testutil.MakeResourceList().CPU(5).Mem(7).Obj()).
+					Borrowed(testutil.MakeResourceList().CPU(2).Mem(2).Obj()).
+					PodsAtRisk("pod2").Obj(),
 			},
 		},
 		{
@@ -110,17 +119,17 @@ func TestElasticQuotaController_Run(t *testing.T) {
 			},
 			pods: []*v1.Pod{
 				// CPU: 2, Mem: 4
-				testutil.MakePod("t3-ns1", "pod1").Phase(v1.PodRunning).
+				testutil.MakePod("t3-ns1", "pod1").Phase(v1.PodRunning).Node("node-a").
 					Container(testutil.MakeResourceList().CPU(1).Mem(2).GPU(1).Obj()).
 					Container(testutil.MakeResourceList().CPU(1).Mem(2).Obj()).Obj(),
 				// CPU: 3, Mem: 3
-				testutil.MakePod("t3-ns1", "pod1").Phase(v1.PodPending).
+				testutil.MakePod("t3-ns1", "pod1").Phase(v1.PodPending).Node("node-a").
 					InitContainerRequest(testutil.MakeResourceList().CPU(2).Mem(1).Obj()).
 					InitContainerRequest(testutil.MakeResourceList().CPU(2).Mem(3).Obj()).
 					Container(testutil.MakeResourceList().CPU(2).Mem(1).Obj()).
 					Container(testutil.MakeResourceList().CPU(1).Mem(1).Obj()).Obj(),
 				// CPU: 4, Mem: 3
-				testutil.MakePod("t3-ns2", "pod2").Phase(v1.PodRunning).
+				testutil.MakePod("t3-ns2", "pod2").Phase(v1.PodRunning).Node("node-a").
 					InitContainerRequest(testutil.MakeResourceList().CPU(2).Mem(1).Obj()).
 					InitContainerRequest(testutil.MakeResourceList().CPU(2).Mem(3).Obj()).
 					Container(testutil.MakeResourceList().CPU(3).Mem(1).Obj()).
@@ -130,7 +139,9 @@ func TestElasticQuotaController_Run(t *testing.T) {
 				testutil.MakeEQ("t3-ns1", "t3-eq1").
 					Used(testutil.MakeResourceList().CPU(3).Mem(3).Obj()).Obj(),
 				testutil.MakeEQ("t3-ns2", "t3-eq2").
-					Used(testutil.MakeResourceList().CPU(4).Mem(3).Obj()).Obj(),
+					Used(testutil.MakeResourceList().CPU(4).Mem(3).Obj()).
+					Borrowed(testutil.MakeResourceList().CPU(1).Obj()).
+					PodsAtRisk("pod2").Obj(),
 			},
 		},
 		{
@@ -181,11 +192,149 @@ func TestElasticQuotaController_Run(t *testing.T) {
 					Used(testutil.MakeResourceList().CPU(0).Mem(0).GPU(0).Obj()).Obj(),
 			},
 		},
+		{
+			name:   "bound pods count once running under the Running accounting policy",
+			policy: config.ElasticQuotaAccountingRunning,
+			elasticQuotas: []*v1alpha1.ElasticQuota{
+				testutil.MakeEQ("t8-ns1", "t8-eq1").
+					Min(testutil.MakeResourceList().CPU(3).Mem(5).Obj()).
+					Max(testutil.MakeResourceList().CPU(5).Mem(15).Obj()).Obj(),
+			},
+			pods: []*v1.Pod{
+				testutil.MakePod("t8-ns1", "pod1").Phase(v1.PodRunning).Node("node-a").
+					Container(testutil.MakeResourceList().CPU(1).Mem(2).Obj()).Obj(),
+				testutil.MakePod("t8-ns1", "pod2").Phase(v1.PodPending).Node("node-a").
+					Container(testutil.MakeResourceList().CPU(2).Mem(3).Obj()).Obj(),
+			},
+			want: []*v1alpha1.ElasticQuota{
+				testutil.MakeEQ("t8-ns1", "t8-eq1").
+					Used(testutil.MakeResourceList().CPU(1).Mem(2).Obj()).Obj(),
+			},
+		},
+		{
+			name: "restartable init containers run along with the containers",
+			elasticQuotas: []*v1alpha1.ElasticQuota{
+				testutil.MakeEQ("t9-ns1", "t9-eq1").
+					Min(testutil.MakeResourceList().CPU(3).Mem(5).Obj()).
+					Max(testutil.MakeResourceList().CPU(5).Mem(15).Obj()).Obj(),
+			},
+			pods: []*v1.Pod{
+				// CPU: 3, Mem: 4
+				testutil.MakePod("t9-ns1", "pod1").Phase(v1.PodPending).Node("node-a").
+					SidecarRequest(testutil.MakeResourceList().CPU(1).Mem(1).Obj()).
+					InitContainerRequest(testutil.MakeResourceList().CPU(1).Mem(3).Obj()).
+					Container(testutil.MakeResourceList().CPU(2).Mem(1).Obj()).Obj(),
+				// Succeeded pods do not count.
+				testutil.MakePod("t9-ns1", "pod2").Phase(v1.PodSucceeded).Node("node-a").
+					Container(testutil.MakeResourceList().CPU(2).Mem(3).Obj()).Obj(),
+			},
+			want: []*v1alpha1.ElasticQuota{
+				testutil.MakeEQ("t9-ns1", "t9-eq1").
+					Used(testutil.MakeResourceList().CPU(3).Mem(4).Obj()).Obj(),
+			},
+		},
+		{
+			name: "borrowed, lent out and pods at risk",
+			elasticQuotas: []*v1alpha1.ElasticQuota{
+				testutil.MakeEQ("t10-ns1", "t10-eq1").
+					Min(testutil.MakeResourceList().CPU(3).Mem(10).Obj()).
+					Max(testutil.MakeResourceList().CPU(10).Mem(20).Obj()).Obj(),
+				testutil.MakeEQ("t10-ns2", "t10-eq2").
+					Min(testutil.MakeResourceList().CPU(4).Mem(10).Obj()).
+					Max(testutil.MakeResourceList().CPU(10).Mem(20).Obj()).Obj(),
+				testutil.MakeEQ("t10-ns3", "t10-eq3").
+					Min(testutil.MakeResourceList().CPU(1).Mem(10).Obj()).
+					Max(testutil.MakeResourceList().CPU(10).Mem(20).Obj()).Obj(),
+			},
+			pods: []*v1.Pod{
+				testutil.MakePod("t10-ns1", "high").Phase(v1.PodRunning).Node("node-a").Priority(100).
+					Container(testutil.MakeResourceList().CPU(2).Mem(1).Obj()).Obj(),
+				testutil.MakePod("t10-ns1", "low").Phase(v1.PodRunning).Node("node-a").Priority(0).
+					Container(testutil.MakeResourceList().CPU(2).Mem(1).Obj()).Obj(),
+				testutil.MakePod("t10-ns1", "mid").Phase(v1.PodRunning).Node("node-a").Priority(10).
+					Container(testutil.MakeResourceList().CPU(1).Mem(1).Obj()).Obj(),
+				testutil.MakePod("t10-ns2", "pod1").Phase(v1.PodRunning).Node("node-a").
+					Container(testutil.MakeResourceList().CPU(1).Mem(1).Obj()).Obj(),
+			},
+			want: []*v1alpha1.ElasticQuota{
+				testutil.MakeEQ("t10-ns1", "t10-eq1").
+					Used(testutil.MakeResourceList().CPU(5).Mem(3).Obj()).
+					Borrowed(testutil.MakeResourceList().CPU(2).Obj()).
+					PodsAtRisk("low").Obj(),
+				// The 2 CPUs borrowed are split between the 3 and 1 CPUs left unused.
+				testutil.MakeEQ("t10-ns2", "t10-eq2").
+					Used(testutil.MakeResourceList().CPU(1).Mem(1).Obj()).
+					LentOut(v1.ResourceList{v1.ResourceCPU: resource.MustParse("1500m")}).Obj(),
+				testutil.MakeEQ("t10-ns3", "t10-eq3").
+					Used(testutil.MakeResourceList().CPU(0).Mem(0).Obj()).
+					LentOut(v1.ResourceList{v1.ResourceCPU: resource.MustParse("500m")}).Obj(),
+			},
+		},
+		{
+			name: "siblings lend to each other first",
+			elasticQuotas: []*v1alpha1.ElasticQuota{
+				testutil.MakeEQ("t11-ns1", "t11-eq1").
+					Min(testutil.MakeResourceList().CPU(4).Mem(10).Obj()).
+					Max(testutil.MakeResourceList().CPU(10).Mem(20).Obj()).Obj(),
+				testutil.MakeEQ("t11-ns2", "t11-eq2").Parent("t11-ns1", "t11-eq1").
+					Min(testutil.MakeResourceList().CPU(2).Mem(5).Obj()).
+					Max(testutil.MakeResourceList().CPU(10).Mem(20).Obj()).Obj(),
+				testutil.MakeEQ("t11-ns3", "t11-eq3").Parent("t11-ns1", "t11-eq1").
+					Min(testutil.MakeResourceList().CPU(2).Mem(5).Obj()).
+					Max(testutil.MakeResourceList().CPU(10).Mem(20).Obj()).Obj(),
+				testutil.MakeEQ("t11-ns4", "t11-eq4").
+					Min(testutil.MakeResourceList().CPU(4).Mem(10).Obj()).
+					Max(testutil.MakeResourceList().CPU(10).Mem(20).Obj()).Obj(),
+			},
+			pods: []*v1.Pod{
+				testutil.MakePod("t11-ns2", "pod1").Phase(v1.PodRunning).Node("node-a").
+					Container(testutil.MakeResourceList().CPU(3).Mem(1).Obj()).Obj(),
+			},
+			want: []*v1alpha1.ElasticQuota{
+				// The usage of its children counts against the parent, which stays within its min.
+				testutil.MakeEQ("t11-ns1", "t11-eq1").
+					Used(testutil.MakeResourceList().CPU(0).Mem(0).Obj()).Obj(),
+				testutil.MakeEQ("t11-ns2", "t11-eq2").
+					Used(testutil.MakeResourceList().CPU(3).Mem(1).Obj()).
+					Borrowed(testutil.MakeResourceList().CPU(1).Obj()).
+					PodsAtRisk("pod1").Obj(),
+				testutil.MakeEQ("t11-ns3", "t11-eq3").
+					Used(testutil.MakeResourceList().CPU(0).Mem(0).Obj()).
+					LentOut(testutil.MakeResourceList().CPU(1).Obj()).Obj(),
+				testutil.MakeEQ("t11-ns4", "t11-eq4").
+					Used(testutil.MakeResourceList().CPU(0).Mem(0).Obj()).Obj(),
+			},
+		},
+		{
+			name: "several quotas in a namespace",
+			elasticQuotas: []*v1alpha1.ElasticQuota{
+				testutil.MakeEQ("t7-ns1", "t7-eq1").
+					Min(testutil.MakeResourceList().CPU(3).Mem(5).Obj()).
+					Max(testutil.MakeResourceList().CPU(5).Mem(15).Obj()).Obj(),
+				testutil.MakeEQ("t7-ns1", "t7-eq2").
+					Selector(map[string]string{"tier": "batch"}).
+					Min(testutil.MakeResourceList().CPU(3).Mem(5).Obj()).
+					Max(testutil.MakeResourceList().CPU(5).Mem(15).Obj()).Obj(),
+			},
+			pods: []*v1.Pod{
+				testutil.MakePod("t7-ns1", "pod1").Phase(v1.PodRunning).Node("node-a").
+					Container(testutil.MakeResourceList().CPU(1).Mem(2).Obj()).Obj(),
+				testutil.MakePod("t7-ns1", "pod2").Phase(v1.PodRunning).Node("node-a").Label("tier", "batch").
+					Container(testutil.MakeResourceList().CPU(2).Mem(3).Obj()).Obj(),
+			},
+			want: []*v1alpha1.ElasticQuota{
+				testutil.MakeEQ("t7-ns1", "t7-eq1").
+					Used(testutil.MakeResourceList().CPU(1).Mem(2).Obj()).Obj(),
+				testutil.MakeEQ("t7-ns1", "t7-eq2").
+					Used(testutil.MakeResourceList().CPU(2).Mem(3).Obj()).Obj(),
+			},
+		},
 	}
 
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
 			controller, kClient := setUpEQ(ctx, t, c.elasticQuotas, c.pods)
+			controller.AccountingPolicy = c.policy
 			for _, pod := range c.pods {
 				if _, err := controller.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{
 					Namespace: pod.Namespace,
@@ -203,6 +352,9 @@ func TestElasticQuotaController_Run(t *testing.T) {
 					t.Errorf("reconcile: (%v)", err)
 				}
 			}
+			if _, err := controller.Reconcile(ctx, lendingRequest); err != nil {
+				t.Errorf("reconcile lending: (%v)", err)
+			}
 			err := wait.PollUntilContextTimeout(ctx, 200*time.Millisecond, 1*time.Second, false, func(ctx context.Context) (done bool, err error) {
 				for _, v := range c.want {
 					eq := &v1alpha1.ElasticQuota{
@@ -218,6 +370,15 @@ func TestElasticQuotaController_Run(t *testing.T) {
 					if !quota.Equals(eq.Status.Used, v.Status.Used) {
 						return false, fmt.Errorf("%v: want %v, got %v", c.name, v.Status.Used, eq.Status.Used)
 					}
+					if !quota.Equals(eq.Status.Borrowed, v.Status.Borrowed) {
+						return false, fmt.Errorf("%v: want borrowed %v, got %v", c.name, v.Status.Borrowed, eq.Status.Borrowed)
+					}
+					if !quota.Equals(eq.Status.LentOut, v.Status.LentOut) {
+						return false, fmt.Errorf("%v: want lent out %v, got %v", c.name, v.Status.LentOut, eq.Status.LentOut)
+					}
+					if !slices.Equal(eq.Status.PodsAtRisk, v.Status.PodsAtRisk) {
+						return false, fmt.Errorf("%v: want pods at risk %v, got %v", c.name, v.Status.PodsAtRisk, eq.Status.PodsAtRisk)
+					}
 				}
 				return true, nil
 			})
@@ -229,6 +390,42 @@ func TestElasticQuotaController_Run(t *testing.T) {
 	}
 }
 
+func TestLendingChanged(t *testing.T) {
+	eq := testutil.MakeEQ("ns1", "eq1").
+		Min(testutil.MakeResourceList().CPU(3).Obj()).
+		Used(testutil.MakeResourceList().CPU(1).Obj()).Obj()
+	cases := []struct {
+		name   string
+		update func(eq *v1alpha1.ElasticQuota)
+		want   bool
+	}{
+		{
+			name:   "spec changed",
+			update: func(eq *v1alpha1.ElasticQuota) { eq.Generation++ },
+			want:   true,
+		},
+		{
+			name:   "used changed",
+			update: func(eq *v1alpha1.ElasticQuota) { eq.Status.Used = testutil.MakeResourceList().CPU(2).Obj() },
+			want:   true,
+		},
+		{
+			name:   "lent out changed",
+			update: func(eq *v1alpha1.ElasticQuota) { eq.Status.LentOut = testutil.MakeResourceList().CPU(1).Obj() },
+			want:   false,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			newEQ := eq.DeepCopy()
+			c.update(newEQ)
+			if got := lendingChanged.Update(event.UpdateEvent{ObjectOld: eq, ObjectNew: newEQ}); got != c.want {
+				t.Errorf("want %v, got %v", c.want, got)
+			}
+		})
+	}
+}
+
 func setUpEQ(ctx context.Context,
 	t *testing.T,
 	eqs []*v1alpha1.ElasticQuota,
@@ -261,7 +458,7 @@ func setUpEQ(ctx context.Context,
 	controller := &ElasticQuotaReconciler{
 		Client:   client,
 		Scheme:   s,
-		recorder: record.NewFakeRecorder(3),
+		recorder: record.NewFakeRecorder(10),
 	}
 
 	return controller, client
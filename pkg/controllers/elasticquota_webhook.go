@@ -0,0 +1,322 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	quota "k8s.io/apiserver/pkg/quota/v1"
+	"k8s.io/utils/ptr"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	schedv1alpha1 "sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
+	"sigs.k8s.io/scheduler-plugins/pkg/util"
+)
+
+// ElasticQuotaWebhook defaults and validates the ElasticQuotas created or updated, so that they keep the
+// invariants the CapacityScheduling plugin and the ElasticQuota controller rely on.
+type ElasticQuotaWebhook struct {
+	client.Client
+}
+
+var _ admission.CustomDefaulter = &ElasticQuotaWebhook{}
+var _ admission.CustomValidator = &ElasticQuotaWebhook{}
+
+// +kubebuilder:webhook:path=/mutate-scheduling-x-k8s-io-v1alpha1-elasticquota,mutating=true,failurePolicy=fail,sideEffects=None,groups=scheduling.x-k8s.io,resources=elasticquotas,verbs=create;update,versions=v1alpha1,name=melasticquota.scheduling.x-k8s.io,admissionReviewVersions=v1
+// +kubebuilder:webhook:path=/validate-scheduling-x-k8s-io-v1alpha1-elasticquota,mutating=false,failurePolicy=fail,sideEffects=None,groups=scheduling.x-k8s.io,resources=elasticquotas,verbs=create;update,versions=v1alpha1,name=velasticquota.scheduling.x-k8s.io,admissionReviewVersions=v1
+func (w *ElasticQuotaWebhook) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&schedv1alpha1.ElasticQuota{}).
+		WithDefaulter(w).
+		WithValidator(w).
+		Complete()
+}
+
+// Default sets the weight of the ElasticQuota to 1 unless set.
+func (w *ElasticQuotaWebhook) Default(ctx context.Context, obj runtime.Object) error {
+	eq, ok := obj.(*schedv1alpha1.ElasticQuota)
+	if !ok {
+		return fmt.Errorf("expected an ElasticQuota, got %T", obj)
+	}
+	if eq.Spec.Weight == nil {
+		eq.Spec.Weight = ptr.To[int32](1)
+	}
+	return nil
+}
+
+func (w *ElasticQuotaWebhook) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	eq, ok := obj.(*schedv1alpha1.ElasticQuota)
+	if !ok {
+		return nil, fmt.Errorf("expected an ElasticQuota, got %T", obj)
+	}
+	return w.validate(ctx, eq)
+}
+
+func (w *ElasticQuotaWebhook) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	eq, ok := newObj.(*schedv1alpha1.ElasticQuota)
+	if !ok {
+		return nil, fmt.Errorf("expected an ElasticQuota, got %T", newObj)
+	}
+	return w.validate(ctx, eq)
+}
+
+func (w *ElasticQuotaWebhook) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validate checks the ElasticQuota on its own, then along with the other ElasticQuotas of the cluster, and
+// warns if the min of the quotas exceeds the allocatable capacity of the nodes.
+func (w *ElasticQuotaWebhook) validate(ctx context.Context, eq *schedv1alpha1.ElasticQuota) (admission.Warnings, error) {
+	allErrs := validateElasticQuotaSpec(&eq.Spec, field.NewPath("spec"))
+
+	eqList := &schedv1alpha1.ElasticQuotaList{}
+	if err := w.List(ctx, eqList); err != nil {
+		return nil, err
+	}
+	eqs := make(map[types.NamespacedName]*schedv1alpha1.ElasticQuota, len(eqList.Items)+1)
+	for i := range eqList.Items {
+		eqs[elasticQuotaKey(&eqList.Items[i])] = &eqList.Items[i]
+	}
+	eqs[elasticQuotaKey(eq)] = eq
+
+	errs, warnings := validateElasticQuotaAmong(eq, eqs)
+	allErrs = append(allErrs, errs...)
+	if len(allErrs) != 0 {
+		return warnings, apierrs.NewInvalid(schedv1alpha1.SchemeGroupVersion.WithKind("ElasticQuota").GroupKind(), eq.Name, allErrs)
+	}
+
+	nodeList := &v1.NodeList{}
+	if err := w.List(ctx, nodeList); err != nil {
+		return warnings, err
+	}
+	return append(warnings, elasticQuotaCapacityWarnings(eqs, nodeList.Items)...), nil
+}
+
+// validateElasticQuotaSpec checks the quantities, the selectors and the pools of the ElasticQuota.
+func validateElasticQuotaSpec(spec *schedv1alpha1.ElasticQuotaSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := validateNonNegativeResources(spec.Min, fldPath.Child("min"))
+	allErrs = append(allErrs, validateNonNegativeResources(spec.Max, fldPath.Child("max"))...)
+	for _, name := range sortedResourceNames(spec.Min) {
+		min := spec.Min[name]
+		if spec.Max == nil {
+			continue
+		}
+		max, ok := spec.Max[name]
+		if ok && min.Cmp(max) > 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("min").Key(string(name)), min.String(), fmt.Sprintf("must be less than or equal to max %s", max.String())))
+		} else if !ok && min.Sign() > 0 && isStandardResource(name) {
+			// The max of CPU, memory and ephemeral storage not named in a max otherwise set is zero.
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("min").Key(string(name)), min.String(), "must be named in max as well, which allows none of it otherwise"))
+		}
+	}
+
+	if spec.Selector != nil {
+		allErrs = append(allErrs, metav1validation.ValidateLabelSelector(spec.Selector, metav1validation.LabelSelectorValidationOptions{}, fldPath.Child("selector"))...)
+	}
+
+	names := sets.New[string]()
+	for i, pool := range spec.Pools {
+		poolPath := fldPath.Child("pools").Index(i)
+		if pool.Name == "" {
+			allErrs = append(allErrs, field.Required(poolPath.Child("name"), ""))
+		} else if names.Has(pool.Name) {
+			allErrs = append(allErrs, field.Duplicate(poolPath.Child("name"), pool.Name))
+		}
+		names.Insert(pool.Name)
+		if pool.NodeSelector != nil {
+			allErrs = append(allErrs, metav1validation.ValidateLabelSelector(pool.NodeSelector, metav1validation.LabelSelectorValidationOptions{}, poolPath.Child("nodeSelector"))...)
+		}
+		allErrs = append(allErrs, validateNonNegativeResources(pool.Min, poolPath.Child("min"))...)
+		allErrs = append(allErrs, validateNonNegativeResources(pool.Max, poolPath.Child("max"))...)
+		for _, name := range sortedResourceNames(pool.Min) {
+			min := pool.Min[name]
+			// The resources a pool does not name in its max are not limited in the pool.
+			if max, ok := pool.Max[name]; ok && min.Cmp(max) > 0 {
+				allErrs = append(allErrs, field.Invalid(poolPath.Child("min").Key(string(name)), min.String(), fmt.Sprintf("must be less than or equal to max %s", max.String())))
+			}
+		}
+	}
+	return allErrs
+}
+
+// validateElasticQuotaAmong checks the ElasticQuota along with the given ElasticQuotas of the cluster, by
+// key, which include it: a namespace has at most one quota without selector, the selectors of its quotas
+// do not overlap, the quotas naming the same pool select the same nodes, and the parents of a quota do
+// not form a cycle. It warns about what the CapacityScheduling plugin tolerates but likely is a mistake.
+func validateElasticQuotaAmong(eq *schedv1alpha1.ElasticQuota, eqs map[types.NamespacedName]*schedv1alpha1.ElasticQuota) (field.ErrorList, admission.Warnings) {
+	var allErrs field.ErrorList
+	var warnings admission.Warnings
+	specPath := field.NewPath("spec")
+	key := elasticQuotaKey(eq)
+
+	for _, otherKey := range sortedElasticQuotaKeys(eqs) {
+		other := eqs[otherKey]
+		if otherKey == key || !util.ElasticQuotasOverlap(eq, other) {
+			continue
+		}
+		if !util.ElasticQuotaHasSelector(eq) {
+			allErrs = append(allErrs, field.Forbidden(specPath, fmt.Sprintf("namespace %s already has ElasticQuota %s without selector nor priority class", eq.Namespace, other.Name)))
+		} else {
+			// A pod selected by both would count against the first one by name, whichever the user meant.
+			allErrs = append(allErrs, field.Forbidden(specPath, fmt.Sprintf("ElasticQuota %s of the same namespace may select the same pods; a label key or the priority classes must tell them apart", other.Name)))
+		}
+	}
+
+	if eq.Spec.Parent != nil {
+		parentPath := specPath.Child("parent")
+		parentKey := elasticQuotaParent(eqs, eq)
+		switch {
+		case parentKey == (types.NamespacedName{}):
+			warnings = append(warnings, fmt.Sprintf("parent ElasticQuota %s/%s not found; the quota has no parent until it is created", eq.Spec.Parent.Namespace, eq.Spec.Parent.Name))
+		case parentKey == key:
+			allErrs = append(allErrs, field.Invalid(parentPath, *eq.Spec.Parent, "must not be the quota itself"))
+		case elasticQuotaAncestors(eqs, parentKey).Has(key):
+			allErrs = append(allErrs, field.Invalid(parentPath, *eq.Spec.Parent, "must not be a descendant of the quota"))
+		default:
+			childrenMin := v1.ResourceList{}
+			for _, otherKey := range sortedElasticQuotaKeys(eqs) {
+				if elasticQuotaParent(eqs, eqs[otherKey]) == parentKey {
+					childrenMin = quota.Add(childrenMin, eqs[otherKey].Spec.Min)
+				}
+			}
+			for _, name := range exceededResources(childrenMin, eqs[parentKey].Spec.Min) {
+				warnings = append(warnings, fmt.Sprintf("the min of the children of parent ElasticQuota %s exceeds its min of %s", parentKey, name))
+			}
+		}
+	}
+
+	// The plugin takes the nodes of a pool from the first quota naming it.
+	for i, pool := range eq.Spec.Pools {
+		for _, otherKey := range sortedElasticQuotaKeys(eqs) {
+			if otherKey == key {
+				continue
+			}
+			pools := eqs[otherKey].Spec.Pools
+			j := slices.IndexFunc(pools, func(p schedv1alpha1.ElasticQuotaPool) bool { return p.Name == pool.Name })
+			if j >= 0 && !apiequality.Semantic.DeepEqual(pool.NodeSelector, pools[j].NodeSelector) {
+				allErrs = append(allErrs, field.Forbidden(specPath.Child("pools").Index(i).Child("nodeSelector"), fmt.Sprintf("must be the node selector of pool %s of ElasticQuota %s", pool.Name, otherKey)))
+				break
+			}
+		}
+	}
+
+	poolsMin := v1.ResourceList{}
+	for _, pool := range eq.Spec.Pools {
+		poolsMin = quota.Add(poolsMin, pool.Min)
+	}
+	for _, name := range exceededResources(poolsMin, eq.Spec.Min) {
+		warnings = append(warnings, fmt.Sprintf("the min of the pools exceeds the min of %s of the quota, which still applies", name))
+	}
+	return allErrs, warnings
+}
+
+// elasticQuotaAncestors returns the keys of the given ElasticQuota and of its ancestors.
+func elasticQuotaAncestors(eqs map[types.NamespacedName]*schedv1alpha1.ElasticQuota, key types.NamespacedName) sets.Set[types.NamespacedName] {
+	ancestors := sets.New[types.NamespacedName]()
+	for eq := eqs[key]; eq != nil && !ancestors.Has(key); eq = eqs[key] {
+		ancestors.Insert(key)
+		key = elasticQuotaParent(eqs, eq)
+	}
+	return ancestors
+}
+
+// elasticQuotaCapacityWarnings warns if the min of the given ElasticQuotas without parent, which the
+// quotas are guaranteed, exceeds the allocatable capacity of the given nodes.
+func elasticQuotaCapacityWarnings(eqs map[types.NamespacedName]*schedv1alpha1.ElasticQuota, nodes []v1.Node) admission.Warnings {
+	min := v1.ResourceList{}
+	for _, key := range sortedElasticQuotaKeys(eqs) {
+		if elasticQuotaParent(eqs, eqs[key]) == (types.NamespacedName{}) {
+			min = quota.Add(min, eqs[key].Spec.Min)
+		}
+	}
+	allocatable := v1.ResourceList{}
+	for i := range nodes {
+		allocatable = quota.Add(allocatable, nodes[i].Status.Allocatable)
+	}
+	var warnings admission.Warnings
+	for _, name := range exceededResources(min, allocatable) {
+		total, capacity := min[name], allocatable[name]
+		warnings = append(warnings, fmt.Sprintf("the sum of the min of %s of the ElasticQuotas, %s, exceeds the allocatable capacity of the cluster, %s", name, total.String(), capacity.String()))
+	}
+	return warnings
+}
+
+// validateNonNegativeResources checks that the quantities of the resource list are not negative.
+func validateNonNegativeResources(resources v1.ResourceList, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	for _, name := range sortedResourceNames(resources) {
+		if q := resources[name]; q.Sign() < 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Key(string(name)), q.String(), "must be greater than or equal to 0"))
+		}
+	}
+	return allErrs
+}
+
+// exceededResources returns the names of the resources of <a> whose quantity is larger than in <b>, where
+// a resource missing from <b> has a quantity of zero.
+func exceededResources(a, b v1.ResourceList) []v1.ResourceName {
+	var names []v1.ResourceName
+	for _, name := range sortedResourceNames(a) {
+		q, limit := a[name], b[name]
+		if q.Cmp(limit) > 0 {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// isStandardResource returns whether the resource is one the CapacityScheduling plugin treats as zero
+// when missing from a max, unlike the extended resources.
+func isStandardResource(name v1.ResourceName) bool {
+	return name == v1.ResourceCPU || name == v1.ResourceMemory || name == v1.ResourceEphemeralStorage
+}
+
+func sortedResourceNames(resources v1.ResourceList) []v1.ResourceName {
+	names := make([]v1.ResourceName, 0, len(resources))
+	for name := range resources {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func sortedElasticQuotaKeys(eqs map[types.NamespacedName]*schedv1alpha1.ElasticQuota) []types.NamespacedName {
+	keys := make([]types.NamespacedName, 0, len(eqs))
+	for key := range eqs {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b types.NamespacedName) int {
+		if a.Namespace != b.Namespace {
+			return strings.Compare(a.Namespace, b.Namespace)
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	return keys
+}
@@ -0,0 +1,280 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
+	testutil "sigs.k8s.io/scheduler-plugins/test/integration"
+)
+
+func TestElasticQuotaWebhookDefault(t *testing.T) {
+	w := &ElasticQuotaWebhook{}
+	eq := testutil.MakeEQ("ns1", "eq1").Obj()
+	if err := w.Default(context.TODO(), eq); err != nil {
+		t.Fatal(err)
+	}
+	if eq.Spec.Weight == nil || *eq.Spec.Weight != 1 {
+		t.Errorf("expected weight 1, got %v", eq.Spec.Weight)
+	}
+
+	eq.Spec.Weight = ptr.To[int32](3)
+	if err := w.Default(context.TODO(), eq); err != nil {
+		t.Fatal(err)
+	}
+	if *eq.Spec.Weight != 3 {
+		t.Errorf("expected weight 3, got %v", *eq.Spec.Weight)
+	}
+}
+
+func TestElasticQuotaWebhookValidate(t *testing.T) {
+	cases := []struct {
+		name          string
+		elasticQuotas []*v1alpha1.ElasticQuota
+		nodes         []*v1.Node
+		eq            *v1alpha1.ElasticQuota
+		wantFields    []string
+		wantWarnings  int
+	}{
+		{
+			name: "valid quota",
+			nodes: []*v1.Node{
+				makeNode("node-a", testutil.MakeResourceList().CPU(10).Mem(20).Obj()),
+			},
+			eq: testutil.MakeEQ("ns1", "eq1").
+				Min(testutil.MakeResourceList().CPU(3).Mem(5).Obj()).
+				Max(testutil.MakeResourceList().CPU(5).Mem(15).GPU(1).Obj()).Obj(),
+		},
+		{
+			name: "min more than max",
+			eq: testutil.MakeEQ("ns1", "eq1").
+				Min(testutil.MakeResourceList().CPU(6).Mem(5).Obj()).
+				Max(testutil.MakeResourceList().CPU(5).Mem(15).Obj()).Obj(),
+			wantFields: []string{"spec.min[cpu]"},
+		},
+		{
+			name: "negative quantities",
+			eq: testutil.MakeEQ("ns1", "eq1").
+				Min(v1.ResourceList{v1.ResourceCPU: resource.MustParse("-1")}).
+				Max(v1.ResourceList{v1.ResourceCPU: resource.MustParse("2"), v1.ResourceMemory: resource.MustParse("-1Gi")}).Obj(),
+			wantFields: []string{"spec.min[cpu]", "spec.max[memory]"},
+		},
+		{
+			name: "min of a resource the max does not name",
+			eq: testutil.MakeEQ("ns1", "eq1").
+				Min(testutil.MakeResourceList().CPU(3).Mem(5).GPU(1).Obj()).
+				Max(testutil.MakeResourceList().CPU(5).Obj()).Obj(),
+			wantFields: []string{"spec.min[memory]"},
+		},
+		{
+			name: "second quota without selector in the namespace",
+			elasticQuotas: []*v1alpha1.ElasticQuota{
+				testutil.MakeEQ("ns1", "eq1").Obj(),
+			},
+			eq:         testutil.MakeEQ("ns1", "eq2").Obj(),
+			wantFields: []string{"spec"},
+		},
+		{
+			name: "overlapping selectors",
+			elasticQuotas: []*v1alpha1.ElasticQuota{
+				testutil.MakeEQ("ns1", "eq1").Selector(map[string]string{"app": "a"}).Obj(),
+			},
+			eq:         testutil.MakeEQ("ns1", "eq2").Selector(map[string]string{"tier": "batch"}).Obj(),
+			wantFields: []string{"spec"},
+		},
+		{
+			name: "selectors told apart by a label",
+			elasticQuotas: []*v1alpha1.ElasticQuota{
+				testutil.MakeEQ("ns1", "eq1").Selector(map[string]string{"tier": "a"}).Obj(),
+			},
+			eq: testutil.MakeEQ("ns1", "eq2").Selector(map[string]string{"tier": "batch"}).Obj(),
+		},
+		{
+			name: "invalid selector",
+			eq: testutil.MakeEQ("ns1", "eq1").
+				Selector(map[string]string{"app": "a b"}).Obj(),
+			wantFields: []string{"spec.selector.matchLabels"},
+		},
+		{
+			name: "parent is the quota itself",
+			eq: testutil.MakeEQ("ns1", "eq1").
+				Parent("ns1", "eq1").Obj(),
+			wantFields: []string{"spec.parent"},
+		},
+		{
+			name: "parents form a cycle",
+			elasticQuotas: []*v1alpha1.ElasticQuota{
+				testutil.MakeEQ("ns2", "eq2").Parent("ns1", "eq1").Obj(),
+			},
+			eq: testutil.MakeEQ("ns1", "eq1").
+				Parent("ns2", "eq2").Obj(),
+			wantFields: []string{"spec.parent"},
+		},
+		{
+			name: "parent not found",
+			eq: testutil.MakeEQ("ns1", "eq1").
+				Parent("ns2", "eq2").Obj(),
+			wantWarnings: 1,
+		},
+		{
+			name: "min of the children more than the min of the parent",
+			elasticQuotas: []*v1alpha1.ElasticQuota{
+				testutil.MakeEQ("ns0", "root").
+					Min(testutil.MakeResourceList().CPU(4).Obj()).
+					Max(testutil.MakeResourceList().CPU(8).Obj()).Obj(),
+				testutil.MakeEQ("ns1", "eq1").Parent("ns0", "root").
+					Min(testutil.MakeResourceList().CPU(3).Obj()).
+					Max(testutil.MakeResourceList().CPU(8).Obj()).Obj(),
+			},
+			nodes: []*v1.Node{
+				makeNode("node-a", testutil.MakeResourceList().CPU(10).Obj()),
+			},
+			eq: testutil.MakeEQ("ns2", "eq2").Parent("ns0", "root").
+				Min(testutil.MakeResourceList().CPU(2).Obj()).
+				Max(testutil.MakeResourceList().CPU(8).Obj()).Obj(),
+			wantWarnings: 1,
+		},
+		{
+			name: "pools",
+			eq: &v1alpha1.ElasticQuota{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "eq1"},
+				Spec: v1alpha1.ElasticQuotaSpec{
+					Pools: []v1alpha1.ElasticQuotaPool{
+						{
+							Name: "gpu",
+							Min:  testutil.MakeResourceList().GPU(2).Obj(),
+							Max:  testutil.MakeResourceList().GPU(1).Obj(),
+						},
+						{
+							Name:         "gpu",
+							NodeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"pool": "a b"}},
+						},
+					},
+				},
+			},
+			wantFields:   []string{"spec.pools[0].min[nvidia.com/gpu]", "spec.pools[1].name", "spec.pools[1].nodeSelector.matchLabels"},
+			wantWarnings: 1,
+		},
+		{
+			name: "pool selecting other nodes",
+			elasticQuotas: []*v1alpha1.ElasticQuota{
+				{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "eq1"},
+					Spec: v1alpha1.ElasticQuotaSpec{
+						Pools: []v1alpha1.ElasticQuotaPool{{
+							Name:         "gpu",
+							NodeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"gpu": "a100"}},
+						}},
+					},
+				},
+			},
+			eq: &v1alpha1.ElasticQuota{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns2", Name: "eq2"},
+				Spec: v1alpha1.ElasticQuotaSpec{
+					Pools: []v1alpha1.ElasticQuotaPool{
+						{
+							Name:         "gpu",
+							NodeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"gpu": "h100"}},
+						},
+						{
+							Name:         "cpu",
+							NodeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"cpu": "arm"}},
+						},
+					},
+				},
+			},
+			wantFields: []string{"spec.pools[0].nodeSelector"},
+		},
+		{
+			name: "min more than the capacity of the cluster",
+			elasticQuotas: []*v1alpha1.ElasticQuota{
+				testutil.MakeEQ("ns1", "eq1").
+					Min(testutil.MakeResourceList().CPU(6).Mem(5).Obj()).
+					Max(testutil.MakeResourceList().CPU(10).Mem(10).Obj()).Obj(),
+			},
+			nodes: []*v1.Node{
+				makeNode("node-a", testutil.MakeResourceList().CPU(5).Mem(10).Obj()),
+				makeNode("node-b", testutil.MakeResourceList().CPU(5).Mem(10).Obj()),
+			},
+			eq: testutil.MakeEQ("ns2", "eq2").
+				Min(testutil.MakeResourceList().CPU(6).Mem(5).Obj()).
+				Max(testutil.MakeResourceList().CPU(10).Mem(10).Obj()).Obj(),
+			wantWarnings: 1,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctx := context.TODO()
+			var objs []client.Object
+			for _, eq := range c.elasticQuotas {
+				objs = append(objs, eq)
+			}
+			for _, node := range c.nodes {
+				objs = append(objs, node)
+			}
+			s := scheme.Scheme
+			utilruntime.Must(v1alpha1.AddToScheme(s))
+			w := &ElasticQuotaWebhook{Client: fake.NewClientBuilder().WithScheme(s).WithObjects(objs...).Build()}
+
+			warnings, err := w.ValidateCreate(ctx, c.eq)
+			if got := invalidFields(t, err); !slices.Equal(got, c.wantFields) {
+				t.Errorf("expected invalid fields %v, got %v (%v)", c.wantFields, got, err)
+			}
+			if len(warnings) != c.wantWarnings {
+				t.Errorf("expected %d warnings, got %q", c.wantWarnings, warnings)
+			}
+		})
+	}
+}
+
+// invalidFields returns the fields the Invalid error reports, or nil for no error.
+func invalidFields(t *testing.T, err error) []string {
+	if err == nil {
+		return nil
+	}
+	var statusErr *apierrs.StatusError
+	if !errors.As(err, &statusErr) || !apierrs.IsInvalid(err) {
+		t.Fatalf("expected an Invalid error, got %v", err)
+	}
+	var fields []string
+	for _, cause := range statusErr.ErrStatus.Details.Causes {
+		fields = append(fields, cause.Field)
+	}
+	return fields
+}
+
+func makeNode(name string, allocatable v1.ResourceList) *v1.Node {
+	return &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status:     v1.NodeStatus{Allocatable: allocatable},
+	}
+}
@@ -19,6 +19,7 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -26,6 +27,8 @@ import (
 	"github.com/go-logr/logr"
 	v1 "k8s.io/api/core/v1"
 	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -53,6 +56,7 @@ type PodGroupReconciler struct {
 // +kubebuilder:rbac:groups=scheduling.x-k8s.io,resources=podgroups,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=scheduling.x-k8s.io,resources=podgroups/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=scheduling.x-k8s.io,resources=podgroups/finalizers,verbs=update
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -98,14 +102,25 @@ func (r *PodGroupReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		log.Error(err, "List pods for group failed")
 		return ctrl.Result{}, err
 	}
-	pods := podList.Items
+	pods, err := r.podGroupMembers(ctx, pg, podList.Items)
+	if err != nil {
+		log.Error(err, "Filter pods for group failed")
+		return ctrl.Result{}, err
+	}
 
 	pgCopy := pg.DeepCopy()
+	if pg.Spec.MaxMember != nil {
+		members := make([]*v1.Pod, len(pods))
+		for i := range pods {
+			members[i] = &pods[i]
+		}
+		pgCopy.Status.Desired, pgCopy.Status.Admitted = util.GetElasticMemberCounts(pg, members)
+	}
 	switch pgCopy.Status.Phase {
 	case "":
 		pgCopy.Status.Phase = schedv1alpha1.PodGroupPending
 	case schedv1alpha1.PodGroupPending:
-		if len(pods) >= int(pg.Spec.MinMember) {
+		if len(pods) >= int(pg.Spec.MinMember) && rolesSatisfied(pg, pods) {
 			pgCopy.Status.Phase = schedv1alpha1.PodGroupScheduling
 			if len(pods) > 0 {
 				fillOccupiedObj(pgCopy, &pods[0])
@@ -113,27 +128,27 @@ func (r *PodGroupReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		}
 	default:
 		pgCopy.Status.Running, pgCopy.Status.Succeeded, pgCopy.Status.Failed = getCurrentPodStats(pods)
-		if len(pods) < int(pg.Spec.MinMember) {
+		if len(pods) < int(pg.Spec.MinMember) || !rolesSatisfied(pg, pods) {
 			pgCopy.Status.Phase = schedv1alpha1.PodGroupPending
 			break
 		}
 
-		if pgCopy.Status.Succeeded+pgCopy.Status.Running < pg.Spec.MinMember {
-			pgCopy.Status.Phase = schedv1alpha1.PodGroupScheduling
-		}
-
-		if pgCopy.Status.Succeeded+pgCopy.Status.Running >= pg.Spec.MinMember {
+		if pgCopy.Status.Succeeded+pgCopy.Status.Running >= pg.Spec.MinMember &&
+			rolesSatisfied(pg, pods, v1.PodRunning, v1.PodSucceeded) {
 			pgCopy.Status.Phase = schedv1alpha1.PodGroupRunning
+		} else {
+			pgCopy.Status.Phase = schedv1alpha1.PodGroupScheduling
 		}
 		// Final state of pod group
 		if pgCopy.Status.Failed != 0 &&
 			pgCopy.Status.Failed+pgCopy.Status.Running+pgCopy.Status.Succeeded >= pg.Spec.MinMember {
 			pgCopy.Status.Phase = schedv1alpha1.PodGroupFailed
 		}
-		if pgCopy.Status.Succeeded >= pg.Spec.MinMember {
+		if pgCopy.Status.Succeeded >= pg.Spec.MinMember && rolesSatisfied(pg, pods, v1.PodSucceeded) {
 			pgCopy.Status.Phase = schedv1alpha1.PodGroupFinished
 		}
 	}
+	setConditions(pgCopy, pods)
 
 	return r.patchPodGroup(ctx, pg, pgCopy)
 }
@@ -170,6 +185,68 @@ func getCurrentPodStats(pods []v1.Pod) (int32, int32, int32) {
 	return running, succeeded, failed
 }
 
+// rolesSatisfied returns whether the pods in any of the given phases, or all the pods
+// if no phase is given, satisfy the minMember of each role of pg.
+func rolesSatisfied(pg *schedv1alpha1.PodGroup, pods []v1.Pod, phases ...v1.PodPhase) bool {
+	if len(pg.Spec.Roles) == 0 {
+		return true
+	}
+	var selected []*v1.Pod
+	for i := range pods {
+		if len(phases) == 0 || slices.Contains(phases, pods[i].Status.Phase) {
+			selected = append(selected, &pods[i])
+		}
+	}
+	return util.CheckPodGroupRoles(pg, selected) == nil
+}
+
+// setConditions keeps the conditions of pg consistent with its pods: QuorumReached follows the number
+// of pods, Scheduled becomes true once minMember pods are bound and false when pg is pending again, and
+// Backoff is cleared when pg becomes scheduled.
+func setConditions(pg *schedv1alpha1.PodGroup, pods []v1.Pod) {
+	quorum := metav1.Condition{
+		Type:    schedv1alpha1.PodGroupQuorumReached,
+		Status:  metav1.ConditionFalse,
+		Reason:  schedv1alpha1.PodGroupReasonNotEnoughPods,
+		Message: fmt.Sprintf("%v pods, minMember of group: %v", len(pods), pg.Spec.MinMember),
+	}
+	if len(pods) >= int(pg.Spec.MinMember) && rolesSatisfied(pg, pods) {
+		quorum.Status, quorum.Reason = metav1.ConditionTrue, schedv1alpha1.PodGroupReasonQuorumReached
+	}
+	meta.SetStatusCondition(&pg.Status.Conditions, quorum)
+
+	var bound int32
+	for i := range pods {
+		if pods[i].Spec.NodeName != "" {
+			bound++
+		}
+	}
+	scheduled := meta.FindStatusCondition(pg.Status.Conditions, schedv1alpha1.PodGroupScheduled)
+	switch {
+	case bound >= pg.Spec.MinMember:
+		if scheduled == nil || scheduled.Status != metav1.ConditionTrue {
+			meta.SetStatusCondition(&pg.Status.Conditions, metav1.Condition{
+				Type:    schedv1alpha1.PodGroupScheduled,
+				Status:  metav1.ConditionTrue,
+				Reason:  schedv1alpha1.PodGroupReasonScheduled,
+				Message: fmt.Sprintf("the quorum of %v members was scheduled", pg.Spec.MinMember),
+			})
+			meta.SetStatusCondition(&pg.Status.Conditions, metav1.Condition{
+				Type:   schedv1alpha1.PodGroupBackoff,
+				Status: metav1.ConditionFalse,
+				Reason: schedv1alpha1.PodGroupReasonScheduled,
+			})
+		}
+	case scheduled == nil || pg.Status.Phase == schedv1alpha1.PodGroupPending:
+		meta.SetStatusCondition(&pg.Status.Conditions, metav1.Condition{
+			Type:    schedv1alpha1.PodGroupScheduled,
+			Status:  metav1.ConditionFalse,
+			Reason:  schedv1alpha1.PodGroupReasonNotScheduled,
+			Message: fmt.Sprintf("%v members scheduled, minMember of group: %v", bound, pg.Spec.MinMember),
+		})
+	}
+}
+
 func fillOccupiedObj(pg *schedv1alpha1.PodGroup, pod *v1.Pod) {
 	if len(pod.OwnerReferences) == 0 {
 		return
@@ -197,6 +274,34 @@ func (r *PodGroupReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Complete(r)
 }
 
+// podGroupMembers returns the given pods which belong to pg: those of its namespace, plus those of
+// the namespaces allowing it which refer to it by their pod-group-namespace label.
+func (r *PodGroupReconciler) podGroupMembers(ctx context.Context, pg *schedv1alpha1.PodGroup, pods []v1.Pod) ([]v1.Pod, error) {
+	allowed := map[string]bool{pg.Namespace: true}
+	var members []v1.Pod
+	for _, pod := range pods {
+		if util.GetPodGroupNamespace(&pod) != pg.Namespace {
+			continue
+		}
+		ok, checked := allowed[pod.Namespace]
+		if !checked {
+			ns := &v1.Namespace{}
+			if err := r.Get(ctx, types.NamespacedName{Name: pod.Namespace}, ns); err != nil {
+				if !apierrs.IsNotFound(err) {
+					return nil, err
+				}
+			} else {
+				ok = util.AllowsPodGroupNamespace(ns, pg.Namespace)
+			}
+			allowed[pod.Namespace] = ok
+		}
+		if ok {
+			members = append(members, pod)
+		}
+	}
+	return members, nil
+}
+
 func (r *PodGroupReconciler) podToPodGroup(ctx context.Context, obj client.Object) []ctrl.Request {
 	pod, ok := obj.(*v1.Pod)
 	if !ok {
@@ -211,7 +316,7 @@ func (r *PodGroupReconciler) podToPodGroup(ctx context.Context, obj client.Objec
 
 	return []ctrl.Request{{
 		NamespacedName: types.NamespacedName{
-			Namespace: pod.Namespace,
+			Namespace: util.GetPodGroupNamespace(pod),
 			Name:      pgName,
 		}}}
 }
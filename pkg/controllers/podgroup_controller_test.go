@@ -18,11 +18,13 @@ package controllers
 
 import (
 	"context"
+	"fmt"
 	"sort"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -195,6 +197,315 @@ func Test_Run(t *testing.T) {
 	}
 }
 
+func TestReconcileRoles(t *testing.T) {
+	ctx := context.TODO()
+	type rolePod struct {
+		role  string
+		phase v1.PodPhase
+	}
+	cases := []struct {
+		name              string
+		pods              []rolePod
+		previousPhase     v1alpha1.PodGroupPhase
+		desiredGroupPhase v1alpha1.PodGroupPhase
+	}{
+		{
+			name: "workers without launcher keep pending",
+			pods: []rolePod{
+				{"worker", v1.PodPending}, {"worker", v1.PodPending}, {"worker", v1.PodPending},
+			},
+			previousPhase:     v1alpha1.PodGroupPending,
+			desiredGroupPhase: v1alpha1.PodGroupPending,
+		},
+		{
+			name: "all roles present convert from pending to scheduling",
+			pods: []rolePod{
+				{"launcher", v1.PodPending}, {"worker", v1.PodPending}, {"worker", v1.PodPending},
+			},
+			previousPhase:     v1alpha1.PodGroupPending,
+			desiredGroupPhase: v1alpha1.PodGroupScheduling,
+		},
+		{
+			name: "running workers without running launcher keep scheduling",
+			pods: []rolePod{
+				{"launcher", v1.PodPending}, {"worker", v1.PodRunning}, {"worker", v1.PodRunning}, {"worker", v1.PodRunning},
+			},
+			previousPhase:     v1alpha1.PodGroupScheduling,
+			desiredGroupPhase: v1alpha1.PodGroupScheduling,
+		},
+		{
+			name: "all roles running",
+			pods: []rolePod{
+				{"launcher", v1.PodRunning}, {"worker", v1.PodRunning}, {"worker", v1.PodRunning},
+			},
+			previousPhase:     v1alpha1.PodGroupScheduling,
+			desiredGroupPhase: v1alpha1.PodGroupRunning,
+		},
+		{
+			name: "succeeded workers with running launcher keep running",
+			pods: []rolePod{
+				{"launcher", v1.PodRunning}, {"worker", v1.PodSucceeded}, {"worker", v1.PodSucceeded}, {"worker", v1.PodSucceeded},
+			},
+			previousPhase:     v1alpha1.PodGroupRunning,
+			desiredGroupPhase: v1alpha1.PodGroupRunning,
+		},
+		{
+			name: "launcher missing from the group convert from running to pending",
+			pods: []rolePod{
+				{"worker", v1.PodRunning}, {"worker", v1.PodRunning}, {"worker", v1.PodRunning},
+			},
+			previousPhase:     v1alpha1.PodGroupRunning,
+			desiredGroupPhase: v1alpha1.PodGroupPending,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := scheme.Scheme
+			pg := makePG("pg", 3, c.previousPhase, nil)
+			pg.Spec.Roles = []v1alpha1.PodGroupRole{
+				{Name: "launcher", MinMember: 1, Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"role": "launcher"}}},
+				{Name: "worker", MinMember: 2, Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"role": "worker"}}},
+			}
+			s.AddKnownTypes(v1alpha1.SchemeGroupVersion, pg)
+			objs := []runtime.Object{pg}
+			for i, rp := range c.pods {
+				pod := st.MakePod().Namespace("default").Name(fmt.Sprintf("pod%d", i)).
+					Label(v1alpha1.PodGroupLabel, "pg").Label("role", rp.role).Phase(rp.phase).Obj()
+				objs = append(objs, pod)
+			}
+			kClient := fake.NewClientBuilder().
+				WithScheme(s).
+				WithStatusSubresource(&v1alpha1.PodGroup{}).
+				WithRuntimeObjects(objs...).
+				Build()
+			controller := &PodGroupReconciler{
+				Client:   kClient,
+				Scheme:   s,
+				recorder: record.NewFakeRecorder(3),
+				log:      klogr.New().WithName("podGroupTest"),
+			}
+
+			req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "pg"}}
+			if _, err := controller.Reconcile(ctx, req); err != nil {
+				t.Fatalf("reconcile: (%v)", err)
+			}
+			if err := kClient.Get(ctx, client.ObjectKeyFromObject(pg), pg); err != nil {
+				t.Fatal(err)
+			}
+			if pg.Status.Phase != c.desiredGroupPhase {
+				t.Fatalf("want %v, got %v", c.desiredGroupPhase, pg.Status.Phase)
+			}
+		})
+	}
+}
+
+func TestReconcileElasticCounts(t *testing.T) {
+	ctx := context.TODO()
+	s := scheme.Scheme
+	pg := makePG("pg", 2, v1alpha1.PodGroupRunning, nil)
+	maxMember := int32(3)
+	pg.Spec.MaxMember = &maxMember
+	s.AddKnownTypes(v1alpha1.SchemeGroupVersion, pg)
+	objs := []runtime.Object{pg}
+	for i, phase := range []v1.PodPhase{v1.PodRunning, v1.PodRunning, v1.PodPending, v1.PodPending, v1.PodFailed} {
+		pod := st.MakePod().Namespace("default").Name(fmt.Sprintf("pod%d", i)).
+			Label(v1alpha1.PodGroupLabel, "pg").Phase(phase)
+		if phase != v1.PodPending {
+			pod = pod.Node("node")
+		}
+		objs = append(objs, pod.Obj())
+	}
+	kClient := fake.NewClientBuilder().
+		WithScheme(s).
+		WithStatusSubresource(&v1alpha1.PodGroup{}).
+		WithRuntimeObjects(objs...).
+		Build()
+	controller := &PodGroupReconciler{
+		Client:   kClient,
+		Scheme:   s,
+		recorder: record.NewFakeRecorder(3),
+		log:      klogr.New().WithName("podGroupTest"),
+	}
+
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "pg"}}
+	if _, err := controller.Reconcile(ctx, req); err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+	if err := kClient.Get(ctx, client.ObjectKeyFromObject(pg), pg); err != nil {
+		t.Fatal(err)
+	}
+	if pg.Status.Desired != 3 || pg.Status.Admitted != 2 {
+		t.Fatalf("want desired 3 and admitted 2, got %v and %v", pg.Status.Desired, pg.Status.Admitted)
+	}
+}
+
+func TestReconcileCrossNamespaceMembers(t *testing.T) {
+	ctx := context.TODO()
+	s := scheme.Scheme
+	pg := makePG("pg", 3, v1alpha1.PodGroupPending, nil)
+	s.AddKnownTypes(v1alpha1.SchemeGroupVersion, pg)
+	objs := []runtime.Object{
+		pg,
+		&v1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:        "executor",
+			Annotations: map[string]string{v1alpha1.AllowedPodGroupNamespacesAnnotation: "default"},
+		}},
+		&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "other"}},
+		st.MakePod().Namespace("default").Name("driver").Label(v1alpha1.PodGroupLabel, "pg").Obj(),
+		st.MakePod().Namespace("executor").Name("executor").Label(v1alpha1.PodGroupLabel, "pg").
+			Label(v1alpha1.PodGroupNamespaceLabel, "default").Obj(),
+		// Not a member: its namespace does not allow the PodGroup.
+		st.MakePod().Namespace("other").Name("intruder").Label(v1alpha1.PodGroupLabel, "pg").
+			Label(v1alpha1.PodGroupNamespaceLabel, "default").Obj(),
+		// Not a member: it belongs to the PodGroup of the same name in its own namespace.
+		st.MakePod().Namespace("executor").Name("local").Label(v1alpha1.PodGroupLabel, "pg").Obj(),
+	}
+	kClient := fake.NewClientBuilder().
+		WithScheme(s).
+		WithStatusSubresource(&v1alpha1.PodGroup{}).
+		WithRuntimeObjects(objs...).
+		Build()
+	controller := &PodGroupReconciler{
+		Client:   kClient,
+		Scheme:   s,
+		recorder: record.NewFakeRecorder(3),
+		log:      klogr.New().WithName("podGroupTest"),
+	}
+
+	members, err := controller.podGroupMembers(ctx, pg, podsOf(t, kClient))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, p := range members {
+		got = append(got, p.Namespace+"/"+p.Name)
+	}
+	sort.Strings(got)
+	if diff := cmp.Diff([]string{"default/driver", "executor/executor"}, got); diff != "" {
+		t.Errorf("Unexpected members (-want,+got):\n%s", diff)
+	}
+
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "pg"}}
+	if _, err := controller.Reconcile(ctx, req); err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+	if err := kClient.Get(ctx, client.ObjectKeyFromObject(pg), pg); err != nil {
+		t.Fatal(err)
+	}
+	// Two members are short of the minMember of 3.
+	if pg.Status.Phase != v1alpha1.PodGroupPending {
+		t.Errorf("want phase %v, got %v", v1alpha1.PodGroupPending, pg.Status.Phase)
+	}
+
+	reqs := controller.podToPodGroup(ctx, objs[3].(*v1.Pod))
+	if len(reqs) != 1 || reqs[0].NamespacedName != req.NamespacedName {
+		t.Errorf("want a pod referring to the PodGroup of another namespace to enqueue %v, got %v", req, reqs)
+	}
+}
+
+func podsOf(t *testing.T, c client.Client) []v1.Pod {
+	podList := &v1.PodList{}
+	if err := c.List(context.TODO(), podList); err != nil {
+		t.Fatal(err)
+	}
+	return podList.Items
+}
+
+func TestReconcileConditions(t *testing.T) {
+	ctx := context.TODO()
+	backoff := metav1.Condition{
+		Type:               v1alpha1.PodGroupBackoff,
+		Status:             metav1.ConditionTrue,
+		Reason:             v1alpha1.PodGroupReasonRejected,
+		LastTransitionTime: metav1.Now(),
+	}
+	cases := []struct {
+		name          string
+		pods          int
+		bound         int
+		previousPhase v1alpha1.PodGroupPhase
+		conditions    []metav1.Condition
+		want          map[string]metav1.ConditionStatus
+	}{
+		{
+			name:          "not enough pods",
+			pods:          1,
+			previousPhase: v1alpha1.PodGroupPending,
+			want: map[string]metav1.ConditionStatus{
+				v1alpha1.PodGroupQuorumReached: metav1.ConditionFalse,
+				v1alpha1.PodGroupScheduled:     metav1.ConditionFalse,
+			},
+		},
+		{
+			name:          "quorum reached but not scheduled",
+			pods:          3,
+			bound:         1,
+			previousPhase: v1alpha1.PodGroupScheduling,
+			conditions:    []metav1.Condition{backoff},
+			want: map[string]metav1.ConditionStatus{
+				v1alpha1.PodGroupQuorumReached: metav1.ConditionTrue,
+				v1alpha1.PodGroupScheduled:     metav1.ConditionFalse,
+				v1alpha1.PodGroupBackoff:       metav1.ConditionTrue,
+			},
+		},
+		{
+			name:          "scheduled clears backoff",
+			pods:          3,
+			bound:         2,
+			previousPhase: v1alpha1.PodGroupScheduling,
+			conditions:    []metav1.Condition{backoff},
+			want: map[string]metav1.ConditionStatus{
+				v1alpha1.PodGroupQuorumReached: metav1.ConditionTrue,
+				v1alpha1.PodGroupScheduled:     metav1.ConditionTrue,
+				v1alpha1.PodGroupBackoff:       metav1.ConditionFalse,
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := scheme.Scheme
+			pg := makePG("pg", 2, c.previousPhase, nil)
+			pg.Status.Conditions = c.conditions
+			s.AddKnownTypes(v1alpha1.SchemeGroupVersion, pg)
+			objs := []runtime.Object{pg}
+			for i := 0; i < c.pods; i++ {
+				pod := st.MakePod().Namespace("default").Name(fmt.Sprintf("pod%d", i)).
+					Label(v1alpha1.PodGroupLabel, "pg").Phase(v1.PodPending)
+				if i < c.bound {
+					pod = pod.Node("node")
+				}
+				objs = append(objs, pod.Obj())
+			}
+			kClient := fake.NewClientBuilder().
+				WithScheme(s).
+				WithStatusSubresource(&v1alpha1.PodGroup{}).
+				WithRuntimeObjects(objs...).
+				Build()
+			controller := &PodGroupReconciler{
+				Client:   kClient,
+				Scheme:   s,
+				recorder: record.NewFakeRecorder(3),
+				log:      klogr.New().WithName("podGroupTest"),
+			}
+
+			req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "pg"}}
+			if _, err := controller.Reconcile(ctx, req); err != nil {
+				t.Fatalf("reconcile: (%v)", err)
+			}
+			if err := kClient.Get(ctx, client.ObjectKeyFromObject(pg), pg); err != nil {
+				t.Fatal(err)
+			}
+			got := map[string]metav1.ConditionStatus{}
+			for _, cond := range pg.Status.Conditions {
+				got[cond.Type] = cond.Status
+			}
+			if diff := cmp.Diff(c.want, got); diff != "" {
+				t.Errorf("Unexpected conditions (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestFillGroupStatusOccupied(t *testing.T) {
 	ctx := context.TODO()
 	cases := []struct {
@@ -0,0 +1,162 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	schedv1alpha1 "sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
+)
+
+// PodGroupWebhook defaults and validates the PodGroups created or updated, so that they keep the
+// invariants the Coscheduling plugin relies on.
+type PodGroupWebhook struct{}
+
+var _ admission.CustomDefaulter = &PodGroupWebhook{}
+var _ admission.CustomValidator = &PodGroupWebhook{}
+
+// +kubebuilder:webhook:path=/mutate-scheduling-x-k8s-io-v1alpha1-podgroup,mutating=true,failurePolicy=fail,sideEffects=None,groups=scheduling.x-k8s.io,resources=podgroups,verbs=create;update,versions=v1alpha1,name=mpodgroup.scheduling.x-k8s.io,admissionReviewVersions=v1
+// +kubebuilder:webhook:path=/validate-scheduling-x-k8s-io-v1alpha1-podgroup,mutating=false,failurePolicy=fail,sideEffects=None,groups=scheduling.x-k8s.io,resources=podgroups,verbs=create;update,versions=v1alpha1,name=vpodgroup.scheduling.x-k8s.io,admissionReviewVersions=v1
+func (w *PodGroupWebhook) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&schedv1alpha1.PodGroup{}).
+		WithDefaulter(w).
+		WithValidator(w).
+		Complete()
+}
+
+// Default sets the MinMember of the PodGroup to 1 and the mode of its topology constraint to Required,
+// unless set.
+func (w *PodGroupWebhook) Default(ctx context.Context, obj runtime.Object) error {
+	pg, ok := obj.(*schedv1alpha1.PodGroup)
+	if !ok {
+		return fmt.Errorf("expected a PodGroup, got %T", obj)
+	}
+	if pg.Spec.MinMember == 0 {
+		pg.Spec.MinMember = 1
+	}
+	if tc := pg.Spec.TopologyConstraint; tc != nil && tc.Mode == "" {
+		tc.Mode = schedv1alpha1.TopologyConstraintRequired
+	}
+	return nil
+}
+
+func (w *PodGroupWebhook) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	pg, ok := obj.(*schedv1alpha1.PodGroup)
+	if !ok {
+		return nil, fmt.Errorf("expected a PodGroup, got %T", obj)
+	}
+	return validatePodGroup(pg)
+}
+
+func (w *PodGroupWebhook) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	pg, ok := newObj.(*schedv1alpha1.PodGroup)
+	if !ok {
+		return nil, fmt.Errorf("expected a PodGroup, got %T", newObj)
+	}
+	return validatePodGroup(pg)
+}
+
+func (w *PodGroupWebhook) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func validatePodGroup(pg *schedv1alpha1.PodGroup) (admission.Warnings, error) {
+	allErrs, warnings := validatePodGroupSpec(&pg.Spec, field.NewPath("spec"))
+	if len(allErrs) != 0 {
+		return warnings, apierrs.NewInvalid(schedv1alpha1.SchemeGroupVersion.WithKind("PodGroup").GroupKind(), pg.Name, allErrs)
+	}
+	return warnings, nil
+}
+
+// validatePodGroupSpec checks the number of members, the resources, the roles and the topology constraint
+// of the PodGroup.
+func validatePodGroupSpec(spec *schedv1alpha1.PodGroupSpec, fldPath *field.Path) (field.ErrorList, admission.Warnings) {
+	var allErrs field.ErrorList
+	var warnings admission.Warnings
+
+	if spec.MinMember < 1 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("minMember"), spec.MinMember, "must be greater than or equal to 1"))
+	}
+	if spec.MaxMember != nil && *spec.MaxMember < spec.MinMember {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("maxMember"), *spec.MaxMember, "must be greater than or equal to minMember"))
+	}
+	if spec.ScaleUpWindowSeconds != nil && *spec.ScaleUpWindowSeconds < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("scaleUpWindowSeconds"), *spec.ScaleUpWindowSeconds, "must be greater than or equal to 0"))
+	}
+	if spec.ScheduleTimeoutSeconds != nil && *spec.ScheduleTimeoutSeconds < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("scheduleTimeoutSeconds"), *spec.ScheduleTimeoutSeconds, "must be greater than or equal to 0"))
+	}
+
+	allErrs = append(allErrs, validateNonNegativeResources(spec.MinResources, fldPath.Child("minResources"))...)
+	// The Coscheduling plugin counts MinMember pods in the minimum resources of the pod group.
+	if pods, ok := spec.MinResources[v1.ResourcePods]; ok && pods.Value() != int64(spec.MinMember) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("minResources").Key(string(v1.ResourcePods)), pods.String(), "must be equal to minMember"))
+	}
+
+	names := sets.New[string]()
+	var rolesMinMember int32
+	for i, role := range spec.Roles {
+		rolePath := fldPath.Child("roles").Index(i)
+		if role.Name == "" {
+			allErrs = append(allErrs, field.Required(rolePath.Child("name"), ""))
+		} else if names.Has(role.Name) {
+			allErrs = append(allErrs, field.Duplicate(rolePath.Child("name"), role.Name))
+		}
+		names.Insert(role.Name)
+		if role.MinMember < 0 {
+			allErrs = append(allErrs, field.Invalid(rolePath.Child("minMember"), role.MinMember, "must be greater than or equal to 0"))
+		}
+		if role.Selector != nil {
+			allErrs = append(allErrs, metav1validation.ValidateLabelSelector(role.Selector, metav1validation.LabelSelectorValidationOptions{}, rolePath.Child("selector"))...)
+		}
+		rolesMinMember += role.MinMember
+	}
+	// A member may belong to more than one role, hence only a warning.
+	if spec.MaxMember != nil && rolesMinMember > *spec.MaxMember {
+		warnings = append(warnings, fmt.Sprintf("the sum of the minMember of the roles, %d, exceeds maxMember %d; the pod group runs only if its roles share members", rolesMinMember, *spec.MaxMember))
+	}
+
+	if tc := spec.TopologyConstraint; tc != nil {
+		tcPath := fldPath.Child("topologyConstraint")
+		if tc.TopologyKey == "" {
+			allErrs = append(allErrs, field.Required(tcPath.Child("topologyKey"), ""))
+		} else {
+			for _, msg := range validation.IsQualifiedName(tc.TopologyKey) {
+				allErrs = append(allErrs, field.Invalid(tcPath.Child("topologyKey"), tc.TopologyKey, msg))
+			}
+		}
+		switch tc.Mode {
+		case schedv1alpha1.TopologyConstraintRequired, schedv1alpha1.TopologyConstraintPreferred:
+		default:
+			allErrs = append(allErrs, field.NotSupported(tcPath.Child("mode"), tc.Mode,
+				[]schedv1alpha1.TopologyConstraintMode{schedv1alpha1.TopologyConstraintRequired, schedv1alpha1.TopologyConstraintPreferred}))
+		}
+	}
+	return allErrs, warnings
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	"sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
+)
+
+func TestPodGroupWebhookDefault(t *testing.T) {
+	w := &PodGroupWebhook{}
+	pg := makePG("pg1", 0, "", nil)
+	pg.Spec.TopologyConstraint = &v1alpha1.PodGroupTopologyConstraint{TopologyKey: "topology.kubernetes.io/zone"}
+	if err := w.Default(context.TODO(), pg); err != nil {
+		t.Fatal(err)
+	}
+	if pg.Spec.MinMember != 1 {
+		t.Errorf("expected minMember 1, got %d", pg.Spec.MinMember)
+	}
+	if pg.Spec.TopologyConstraint.Mode != v1alpha1.TopologyConstraintRequired {
+		t.Errorf("expected mode Required, got %q", pg.Spec.TopologyConstraint.Mode)
+	}
+}
+
+func TestPodGroupWebhookValidate(t *testing.T) {
+	cases := []struct {
+		name         string
+		spec         v1alpha1.PodGroupSpec
+		wantFields   []string
+		wantWarnings int
+	}{
+		{
+			name: "valid pod group",
+			spec: v1alpha1.PodGroupSpec{
+				MinMember:    2,
+				MaxMember:    ptr.To[int32](4),
+				MinResources: v1.ResourceList{v1.ResourceCPU: resource.MustParse("2"), v1.ResourcePods: resource.MustParse("2")},
+				Roles: []v1alpha1.PodGroupRole{
+					{Name: "driver", MinMember: 1, Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"role": "driver"}}},
+					{Name: "worker", MinMember: 1},
+				},
+				TopologyConstraint: &v1alpha1.PodGroupTopologyConstraint{TopologyKey: "topology.kubernetes.io/zone", Mode: v1alpha1.TopologyConstraintPreferred},
+			},
+		},
+		{
+			name:       "minMember less than 1",
+			spec:       v1alpha1.PodGroupSpec{MinMember: 0},
+			wantFields: []string{"spec.minMember"},
+		},
+		{
+			name:       "maxMember less than minMember",
+			spec:       v1alpha1.PodGroupSpec{MinMember: 3, MaxMember: ptr.To[int32](2)},
+			wantFields: []string{"spec.maxMember"},
+		},
+		{
+			name: "negative durations",
+			spec: v1alpha1.PodGroupSpec{
+				MinMember:              1,
+				ScaleUpWindowSeconds:   ptr.To[int32](-1),
+				ScheduleTimeoutSeconds: ptr.To[int32](-1),
+			},
+			wantFields: []string{"spec.scaleUpWindowSeconds", "spec.scheduleTimeoutSeconds"},
+		},
+		{
+			name: "minResources",
+			spec: v1alpha1.PodGroupSpec{
+				MinMember:    2,
+				MinResources: v1.ResourceList{v1.ResourceCPU: resource.MustParse("-1"), v1.ResourcePods: resource.MustParse("3")},
+			},
+			wantFields: []string{"spec.minResources[cpu]", "spec.minResources[pods]"},
+		},
+		{
+			name: "roles",
+			spec: v1alpha1.PodGroupSpec{
+				MinMember: 1,
+				Roles: []v1alpha1.PodGroupRole{
+					{Name: "worker", MinMember: -1},
+					{Name: "worker", Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"role": "a b"}}},
+					{MinMember: 1},
+				},
+			},
+			wantFields: []string{"spec.roles[0].minMember", "spec.roles[1].name", "spec.roles[1].selector.matchLabels", "spec.roles[2].name"},
+		},
+		{
+			name: "minMember of the roles more than maxMember",
+			spec: v1alpha1.PodGroupSpec{
+				MinMember: 2,
+				MaxMember: ptr.To[int32](2),
+				Roles: []v1alpha1.PodGroupRole{
+					{Name: "driver", MinMember: 1},
+					{Name: "worker", MinMember: 2},
+				},
+			},
+			wantWarnings: 1,
+		},
+		{
+			name: "topology constraint",
+			spec: v1alpha1.PodGroupSpec{
+				MinMember:          1,
+				TopologyConstraint: &v1alpha1.PodGroupTopologyConstraint{Mode: "Sometimes"},
+			},
+			wantFields: []string{"spec.topologyConstraint.topologyKey", "spec.topologyConstraint.mode"},
+		},
+		{
+			name: "invalid topology key",
+			spec: v1alpha1.PodGroupSpec{
+				MinMember:          1,
+				TopologyConstraint: &v1alpha1.PodGroupTopologyConstraint{TopologyKey: "rack id", Mode: v1alpha1.TopologyConstraintRequired},
+			},
+			wantFields: []string{"spec.topologyConstraint.topologyKey"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			w := &PodGroupWebhook{}
+			pg := makePG("pg1", 0, "", nil)
+			pg.Spec = c.spec
+
+			warnings, err := w.ValidateCreate(context.TODO(), pg)
+			if got := invalidFields(t, err); !slices.Equal(got, c.wantFields) {
+				t.Errorf("expected invalid fields %v, got %v (%v)", c.wantFields, got, err)
+			}
+			if len(warnings) != c.wantWarnings {
+				t.Errorf("expected %d warnings, got %q", c.wantWarnings, warnings)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	schedv1alpha1 "sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
+)
+
+// WorkloadPodWebhook labels the pods created for the workloads whose PodGroups the
+// WorkloadPodGroupReconcilers create, with the name of the PodGroup, so that they join it without
+// the pod templates of the workloads carrying the pod group label. The pods of a Job controlled by
+// a JobSet join the PodGroup of the JobSet. The pods already labeled are left alone.
+type WorkloadPodWebhook struct {
+	client.Client
+	// Kinds are the kinds of the workloads whose PodGroups are created, among WorkloadJob,
+	// WorkloadStatefulSet and WorkloadJobSet.
+	Kinds []string
+}
+
+var _ admission.CustomDefaulter = &WorkloadPodWebhook{}
+
+// +kubebuilder:webhook:path=/mutate--v1-pod,mutating=true,failurePolicy=ignore,sideEffects=None,groups="",resources=pods,verbs=create,versions=v1,name=mpod.scheduling.x-k8s.io,admissionReviewVersions=v1
+func (w *WorkloadPodWebhook) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&v1.Pod{}).
+		WithDefaulter(w).
+		Complete()
+}
+
+// Default sets the pod group label of the pod to the PodGroup of the workload controlling it, if the
+// workload carries the min-available annotation. The pod is admitted unchanged if the workload cannot
+// be read, rather than failing its creation.
+func (w *WorkloadPodWebhook) Default(ctx context.Context, obj runtime.Object) error {
+	pod, ok := obj.(*v1.Pod)
+	if !ok {
+		return fmt.Errorf("expected a Pod, got %T", obj)
+	}
+	if _, ok := pod.Labels[schedv1alpha1.PodGroupLabel]; ok {
+		return nil
+	}
+	namespace := pod.Namespace
+	if namespace == "" {
+		// The namespace of a pod created by a controller is only set in the request.
+		if req, err := admission.RequestFromContext(ctx); err == nil {
+			namespace = req.Namespace
+		}
+	}
+
+	log := log.FromContext(ctx)
+	workload, err := w.workloadOf(ctx, namespace, metav1.GetControllerOf(pod))
+	if err != nil {
+		log.Error(err, "Unable to get the workload of the pod, leaving it without PodGroup", "pod", pod.GenerateName+pod.Name)
+		return nil
+	}
+	if workload == nil {
+		return nil
+	}
+	if _, annotated := workload.GetAnnotations()[schedv1alpha1.MinAvailableAnnotation]; !annotated || workload.GetDeletionTimestamp() != nil {
+		return nil
+	}
+	pgName, _, err := workloadPodGroupMembers(workload)
+	if err != nil {
+		log.Error(err, "Unable to read the pod templates of the workload, leaving the pod without PodGroup", "pod", pod.GenerateName+pod.Name)
+		return nil
+	}
+	if pod.Labels == nil {
+		pod.Labels = map[string]string{}
+	}
+	pod.Labels[schedv1alpha1.PodGroupLabel] = pgName
+	return nil
+}
+
+// workloadOf returns the workload of one of the kinds of the webhook controlled by the given owner,
+// or nil if there is none.
+func (w *WorkloadPodWebhook) workloadOf(ctx context.Context, namespace string, owner *metav1.OwnerReference) (client.Object, error) {
+	if owner == nil {
+		return nil, nil
+	}
+	kinds := sets.New(w.Kinds...)
+	gvk := schema.FromAPIVersionAndKind(owner.APIVersion, owner.Kind)
+	switch {
+	case gvk == appsv1.SchemeGroupVersion.WithKind(WorkloadStatefulSet) && kinds.Has(WorkloadStatefulSet):
+		return w.getWorkload(ctx, namespace, owner.Name, &appsv1.StatefulSet{})
+	case gvk == batchv1.SchemeGroupVersion.WithKind(WorkloadJob) && kinds.HasAny(WorkloadJob, WorkloadJobSet):
+		job := &batchv1.Job{}
+		if obj, err := w.getWorkload(ctx, namespace, owner.Name, job); obj == nil || err != nil {
+			return nil, err
+		}
+		if jobSetOwner := metav1.GetControllerOf(job); jobSetOwner != nil &&
+			schema.FromAPIVersionAndKind(jobSetOwner.APIVersion, jobSetOwner.Kind).GroupKind() == jobSetGVK.GroupKind() {
+			if !kinds.Has(WorkloadJobSet) {
+				return nil, nil
+			}
+			jobSet := &unstructured.Unstructured{}
+			jobSet.SetGroupVersionKind(jobSetGVK)
+			return w.getWorkload(ctx, namespace, jobSetOwner.Name, jobSet)
+		}
+		if !kinds.Has(WorkloadJob) {
+			return nil, nil
+		}
+		return job, nil
+	}
+	return nil, nil
+}
+
+// getWorkload gets the named workload into obj, and returns it, or nil if it does not exist.
+func (w *WorkloadPodWebhook) getWorkload(ctx context.Context, namespace, name string, obj client.Object) (client.Object, error) {
+	if err := w.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, obj); err != nil {
+		if apierrs.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return obj, nil
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
+)
+
+func TestWorkloadPodWebhookDefault(t *testing.T) {
+	s := scheme.Scheme
+	utilruntime.Must(v1alpha1.AddToScheme(s))
+
+	annotated := map[string]string{v1alpha1.MinAvailableAnnotation: ""}
+	controlledBy := func(apiVersion, kind, name string) []metav1.OwnerReference {
+		return []metav1.OwnerReference{{APIVersion: apiVersion, Kind: kind, Name: name, UID: "uid", Controller: ptr.To(true)}}
+	}
+	jobSet := &unstructured.Unstructured{}
+	jobSet.SetGroupVersionKind(jobSetGVK)
+	jobSet.SetNamespace("ns")
+	jobSet.SetName("js")
+	jobSet.SetAnnotations(annotated)
+	objs := []client.Object{
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "job", Annotations: annotated}},
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "plain-job"}},
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "js-worker-0",
+			OwnerReferences: controlledBy("jobset.x-k8s.io/v1alpha2", WorkloadJobSet, "js")}},
+		&appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "sts", Annotations: annotated}},
+		jobSet,
+	}
+	cl := fake.NewClientBuilder().WithScheme(s).WithObjects(objs...).Build()
+
+	cases := []struct {
+		name   string
+		kinds  []string
+		pod    *v1.Pod
+		wantPG string
+	}{
+		{
+			name:   "pod of an annotated job",
+			kinds:  []string{WorkloadJob},
+			pod:    &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", OwnerReferences: controlledBy("batch/v1", WorkloadJob, "job")}},
+			wantPG: "job",
+		},
+		{
+			name:   "pod of an annotated statefulset",
+			kinds:  []string{WorkloadStatefulSet},
+			pod:    &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", OwnerReferences: controlledBy("apps/v1", WorkloadStatefulSet, "sts")}},
+			wantPG: "sts",
+		},
+		{
+			name:   "pod of a job of an annotated jobset",
+			kinds:  []string{WorkloadJobSet},
+			pod:    &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", OwnerReferences: controlledBy("batch/v1", WorkloadJob, "js-worker-0")}},
+			wantPG: "js",
+		},
+		{
+			name:  "pod of a job of a jobset when only jobs are handled",
+			kinds: []string{WorkloadJob},
+			pod:   &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", OwnerReferences: controlledBy("batch/v1", WorkloadJob, "js-worker-0")}},
+		},
+		{
+			name:  "pod of a job without annotation",
+			kinds: []string{WorkloadJob},
+			pod:   &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", OwnerReferences: controlledBy("batch/v1", WorkloadJob, "plain-job")}},
+		},
+		{
+			name:  "pod of a kind not handled",
+			kinds: []string{WorkloadJob},
+			pod:   &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", OwnerReferences: controlledBy("apps/v1", WorkloadStatefulSet, "sts")}},
+		},
+		{
+			name:  "pod of a missing job",
+			kinds: []string{WorkloadJob},
+			pod:   &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", OwnerReferences: controlledBy("batch/v1", WorkloadJob, "missing")}},
+		},
+		{
+			name:  "labeled pod is left alone",
+			kinds: []string{WorkloadJob},
+			pod: &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Labels: map[string]string{v1alpha1.PodGroupLabel: "pg"},
+				OwnerReferences: controlledBy("batch/v1", WorkloadJob, "job")}},
+			wantPG: "pg",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			w := &WorkloadPodWebhook{Client: cl, Kinds: c.kinds}
+			if err := w.Default(context.TODO(), c.pod); err != nil {
+				t.Fatal(err)
+			}
+			if got := c.pod.Labels[v1alpha1.PodGroupLabel]; got != c.wantPG {
+				t.Errorf("Want pod group label %q, got %q", c.wantPG, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,296 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	schedv1alpha1 "sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
+	"sigs.k8s.io/scheduler-plugins/pkg/util"
+)
+
+const (
+	// WorkloadJob is the kind of batch/v1 Jobs.
+	WorkloadJob = "Job"
+	// WorkloadStatefulSet is the kind of apps/v1 StatefulSets.
+	WorkloadStatefulSet = "StatefulSet"
+	// WorkloadJobSet is the kind of jobset.x-k8s.io/v1alpha2 JobSets.
+	WorkloadJobSet = "JobSet"
+)
+
+// jobSetGVK is the group, version and kind of JobSets, which are handled as unstructured objects.
+var jobSetGVK = schema.GroupVersionKind{Group: "jobset.x-k8s.io", Version: "v1alpha2", Kind: WorkloadJobSet}
+
+// podGroupMember is a pod template of a workload, and how many pods of it make up the workload.
+type podGroupMember struct {
+	template *v1.PodTemplateSpec
+	count    int32
+}
+
+// WorkloadPodGroupReconciler creates the PodGroup of the workloads of a kind carrying the
+// min-available annotation, keeps it in line with the workload, and deletes it once the annotation
+// is removed. The PodGroup is owned by the workload, so it is garbage-collected along with it.
+// The pod templates of the workload are never modified: patching them would restart the pods of a
+// StatefulSet and is forbidden for a Job. Its pods join the PodGroup if the templates carry the pod
+// group label, or else once the WorkloadPodWebhook labels them.
+type WorkloadPodGroupReconciler struct {
+	client.Client
+	Scheme  *runtime.Scheme
+	Workers int
+	// Kind is the kind of the workloads, one of WorkloadJob, WorkloadStatefulSet and WorkloadJobSet.
+	Kind string
+	// PodsLabeled tells that the WorkloadPodWebhook labels the pods of the workloads.
+	PodsLabeled bool
+}
+
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch
+// +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=jobset.x-k8s.io,resources=jobsets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=scheduling.x-k8s.io,resources=podgroups,verbs=get;list;watch;create;update;patch;delete
+
+// Reconcile creates, updates or deletes the PodGroup of the given workload.
+func (r *WorkloadPodGroupReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+	obj, err := r.newObject()
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.Get(ctx, req.NamespacedName, obj); err != nil {
+		if apierrs.IsNotFound(err) {
+			// The PodGroup is garbage-collected with its owner.
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	pgName, members, err := workloadPodGroupMembers(obj)
+	if err != nil {
+		log.Error(err, "Unable to read the pod templates of the workload")
+		return ctrl.Result{}, nil
+	}
+	pg := &schedv1alpha1.PodGroup{}
+	err = r.Get(ctx, types.NamespacedName{Namespace: req.Namespace, Name: pgName}, pg)
+	if err != nil && !apierrs.IsNotFound(err) {
+		return ctrl.Result{}, err
+	}
+	exists := err == nil
+	if exists && !metav1.IsControlledBy(pg, obj) {
+		log.V(3).Info("PodGroup of the workload is not owned by it, leaving it alone", "podGroup", pgName)
+		return ctrl.Result{}, nil
+	}
+
+	value, annotated := obj.GetAnnotations()[schedv1alpha1.MinAvailableAnnotation]
+	if !annotated || obj.GetDeletionTimestamp() != nil {
+		if exists {
+			log.Info("Deleting PodGroup of the workload", "podGroup", pgName)
+			return ctrl.Result{}, client.IgnoreNotFound(r.Delete(ctx, pg))
+		}
+		return ctrl.Result{}, nil
+	}
+	spec, err := podGroupSpec(value, members)
+	if err != nil {
+		log.Error(err, "Invalid annotation", "annotation", schedv1alpha1.MinAvailableAnnotation)
+		return ctrl.Result{}, nil
+	}
+	for _, m := range members {
+		if !r.PodsLabeled && m.template.Labels[schedv1alpha1.PodGroupLabel] != pgName {
+			log.Info("Pod template of the workload lacks the pod group label and the pod webhook is not served, its pods will not join the PodGroup",
+				"podGroup", pgName, "label", schedv1alpha1.PodGroupLabel)
+			break
+		}
+	}
+
+	if !exists {
+		pg = &schedv1alpha1.PodGroup{
+			ObjectMeta: metav1.ObjectMeta{Namespace: req.Namespace, Name: pgName},
+			Spec:       spec,
+		}
+		if err := controllerutil.SetControllerReference(obj, pg, r.Scheme); err != nil {
+			return ctrl.Result{}, err
+		}
+		log.Info("Creating PodGroup of the workload", "podGroup", pgName, "minMember", spec.MinMember)
+		return ctrl.Result{}, client.IgnoreAlreadyExists(r.Create(ctx, pg))
+	}
+	if pg.Spec.MinMember == spec.MinMember && apiequality.Semantic.DeepEqual(pg.Spec.MinResources, spec.MinResources) {
+		return ctrl.Result{}, nil
+	}
+	pgCopy := pg.DeepCopy()
+	pgCopy.Spec.MinMember = spec.MinMember
+	pgCopy.Spec.MinResources = spec.MinResources
+	log.Info("Updating PodGroup of the workload", "podGroup", pgName, "minMember", spec.MinMember)
+	return ctrl.Result{}, r.Patch(ctx, pgCopy, client.MergeFrom(pg))
+}
+
+// newObject returns an empty object of the kind of the reconciler.
+func (r *WorkloadPodGroupReconciler) newObject() (client.Object, error) {
+	switch r.Kind {
+	case WorkloadJob:
+		return &batchv1.Job{}, nil
+	case WorkloadStatefulSet:
+		return &appsv1.StatefulSet{}, nil
+	case WorkloadJobSet:
+		u := &unstructured.Unstructured{}
+		u.SetGroupVersionKind(jobSetGVK)
+		return u, nil
+	}
+	return nil, fmt.Errorf("unsupported workload kind %q", r.Kind)
+}
+
+// workloadPodGroupMembers returns the name of the PodGroup of the given workload and its pod templates.
+// The name is the pod group label of the first pod template, if set, or else the name of the workload.
+func workloadPodGroupMembers(obj client.Object) (string, []podGroupMember, error) {
+	var members []podGroupMember
+	switch w := obj.(type) {
+	case *batchv1.Job:
+		count := int32(1)
+		if w.Spec.Parallelism != nil {
+			count = *w.Spec.Parallelism
+		}
+		// No more pods than completions run at the same time.
+		if w.Spec.Completions != nil && *w.Spec.Completions < count {
+			count = *w.Spec.Completions
+		}
+		members = append(members, podGroupMember{template: &w.Spec.Template, count: count})
+	case *appsv1.StatefulSet:
+		count := int32(1)
+		if w.Spec.Replicas != nil {
+			count = *w.Spec.Replicas
+		}
+		members = append(members, podGroupMember{template: &w.Spec.Template, count: count})
+	case *unstructured.Unstructured:
+		replicatedJobs, _, err := unstructured.NestedSlice(w.Object, "spec", "replicatedJobs")
+		if err != nil {
+			return "", nil, err
+		}
+		for _, rj := range replicatedJobs {
+			m, ok := rj.(map[string]interface{})
+			if !ok {
+				return "", nil, fmt.Errorf("unexpected replicated job %v", rj)
+			}
+			replicas, found, err := unstructured.NestedInt64(m, "replicas")
+			if err != nil {
+				return "", nil, err
+			} else if !found {
+				replicas = 1
+			}
+			parallelism, found, err := unstructured.NestedInt64(m, "template", "spec", "parallelism")
+			if err != nil {
+				return "", nil, err
+			} else if !found {
+				parallelism = 1
+			}
+			raw, _, err := unstructured.NestedMap(m, "template", "spec", "template")
+			if err != nil {
+				return "", nil, err
+			}
+			template := &v1.PodTemplateSpec{}
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(raw, template); err != nil {
+				return "", nil, err
+			}
+			members = append(members, podGroupMember{template: template, count: int32(replicas * parallelism)})
+		}
+	default:
+		return "", nil, fmt.Errorf("unsupported workload %T", obj)
+	}
+
+	name := obj.GetName()
+	if len(members) != 0 {
+		if label := members[0].template.Labels[schedv1alpha1.PodGroupLabel]; label != "" {
+			name = label
+		}
+	}
+	return name, members, nil
+}
+
+// podGroupSpec returns the spec of a PodGroup made of the given members. Its minMember is the given
+// min-available value if not empty, bounded by the number of members so that the PodGroup can be
+// scheduled, or else the number of members; its minResources are the requests of the first minMember
+// members.
+func podGroupSpec(minAvailable string, members []podGroupMember) (schedv1alpha1.PodGroupSpec, error) {
+	var total int32
+	for _, m := range members {
+		total += m.count
+	}
+	minMember := total
+	if minAvailable != "" {
+		n, err := strconv.ParseInt(minAvailable, 10, 32)
+		if err != nil || n < 1 {
+			return schedv1alpha1.PodGroupSpec{}, fmt.Errorf("min-available must be a positive integer, got %q", minAvailable)
+		}
+		minMember = min(int32(n), total)
+	}
+	if minMember < 1 {
+		minMember = 1
+	}
+
+	minResources := v1.ResourceList{}
+	left := minMember
+	for _, m := range members {
+		if left == 0 {
+			break
+		}
+		count := min(m.count, left)
+		left -= count
+		for name, quant := range util.PodRequests(&v1.Pod{Spec: m.template.Spec}) {
+			if quant.IsZero() {
+				continue
+			}
+			total := quant.DeepCopy()
+			total.Mul(int64(count))
+			sum := minResources[name]
+			sum.Add(total)
+			minResources[name] = sum
+		}
+	}
+	spec := schedv1alpha1.PodGroupSpec{MinMember: minMember}
+	if len(minResources) != 0 {
+		spec.MinResources = minResources
+	}
+	return spec, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *WorkloadPodGroupReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	obj, err := r.newObject()
+	if err != nil {
+		return err
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("podgroup-" + r.Kind).
+		For(obj).
+		Owns(&schedv1alpha1.PodGroup{}).
+		WithOptions(controller.Options{MaxConcurrentReconciles: r.Workers}).
+		Complete(r)
+}
@@ -0,0 +1,236 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
+)
+
+func TestReconcileWorkloadPodGroup(t *testing.T) {
+	ctx := context.TODO()
+	s := scheme.Scheme
+	utilruntime.Must(v1alpha1.AddToScheme(s))
+
+	template := func(cpu string, podGroup string) v1.PodTemplateSpec {
+		t := v1.PodTemplateSpec{Spec: v1.PodSpec{Containers: []v1.Container{{
+			Name:      "c",
+			Resources: v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse(cpu)}},
+		}}}}
+		if podGroup != "" {
+			t.Labels = map[string]string{v1alpha1.PodGroupLabel: podGroup}
+		}
+		return t
+	}
+	annotated := func(value string) map[string]string {
+		return map[string]string{v1alpha1.MinAvailableAnnotation: value}
+	}
+	jobSet := &unstructured.Unstructured{}
+	jobSet.SetGroupVersionKind(jobSetGVK)
+	jobSet.SetNamespace("ns")
+	jobSet.SetName("js")
+	jobSet.SetUID("js")
+	jobSet.SetAnnotations(annotated(""))
+	jobTemplate := func(replicas, parallelism int64) map[string]interface{} {
+		return map[string]interface{}{
+			"replicas": replicas,
+			"template": map[string]interface{}{"spec": map[string]interface{}{
+				"parallelism": parallelism,
+				"template": map[string]interface{}{"spec": map[string]interface{}{
+					"containers": []interface{}{map[string]interface{}{
+						"name":      "c",
+						"resources": map[string]interface{}{"requests": map[string]interface{}{"cpu": "1"}},
+					}},
+				}},
+			}},
+		}
+	}
+	utilruntime.Must(unstructured.SetNestedSlice(jobSet.Object,
+		[]interface{}{jobTemplate(1, 1), jobTemplate(2, 2)}, "spec", "replicatedJobs"))
+
+	cases := []struct {
+		name     string
+		kind     string
+		workload client.Object
+		existing *v1alpha1.PodGroup
+		// pgName is the expected PodGroup, none if empty.
+		pgName           string
+		wantMinMember    int32
+		wantMinResources v1.ResourceList
+		wantOwned        bool
+	}{
+		{
+			name: "job derives minMember from parallelism and completions",
+			kind: WorkloadJob,
+			workload: &batchv1.Job{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "job", UID: "job", Annotations: annotated("")},
+				Spec:       batchv1.JobSpec{Parallelism: ptr.To[int32](4), Completions: ptr.To[int32](3), Template: template("500m", "")},
+			},
+			pgName:           "job",
+			wantMinMember:    3,
+			wantMinResources: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1500m")},
+			wantOwned:        true,
+		},
+		{
+			name: "statefulset with min-available and pod group label",
+			kind: WorkloadStatefulSet,
+			workload: &appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "sts", UID: "sts", Annotations: annotated("2")},
+				Spec:       appsv1.StatefulSetSpec{Replicas: ptr.To[int32](5), Template: template("1", "pg")},
+			},
+			pgName:           "pg",
+			wantMinMember:    2,
+			wantMinResources: v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")},
+			wantOwned:        true,
+		},
+		{
+			name: "min-available above the pods of the workload is bounded by them",
+			kind: WorkloadStatefulSet,
+			workload: &appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "sts", UID: "sts", Annotations: annotated("8")},
+				Spec:       appsv1.StatefulSetSpec{Replicas: ptr.To[int32](3), Template: template("1", "")},
+			},
+			pgName:           "sts",
+			wantMinMember:    3,
+			wantMinResources: v1.ResourceList{v1.ResourceCPU: resource.MustParse("3")},
+			wantOwned:        true,
+		},
+		{
+			name:             "jobset sums its replicated jobs",
+			kind:             WorkloadJobSet,
+			workload:         jobSet,
+			pgName:           "js",
+			wantMinMember:    5,
+			wantMinResources: v1.ResourceList{v1.ResourceCPU: resource.MustParse("5")},
+			wantOwned:        true,
+		},
+		{
+			name: "owned PodGroup is updated",
+			kind: WorkloadJob,
+			workload: &batchv1.Job{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "job", UID: "job", Annotations: annotated("")},
+				Spec:       batchv1.JobSpec{Parallelism: ptr.To[int32](2), Template: template("1", "")},
+			},
+			existing: &v1alpha1.PodGroup{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "job", OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: "batch/v1", Kind: "Job", Name: "job", UID: "job", Controller: ptr.To(true),
+				}}},
+				Spec: v1alpha1.PodGroupSpec{MinMember: 1},
+			},
+			pgName:           "job",
+			wantMinMember:    2,
+			wantMinResources: v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")},
+			wantOwned:        true,
+		},
+		{
+			name: "PodGroup not owned by the workload is left alone",
+			kind: WorkloadJob,
+			workload: &batchv1.Job{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "job", UID: "job", Annotations: annotated("")},
+				Spec:       batchv1.JobSpec{Parallelism: ptr.To[int32](2), Template: template("1", "")},
+			},
+			existing: &v1alpha1.PodGroup{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "job"},
+				Spec:       v1alpha1.PodGroupSpec{MinMember: 1},
+			},
+			pgName:        "job",
+			wantMinMember: 1,
+		},
+		{
+			name: "owned PodGroup is deleted once the annotation is removed",
+			kind: WorkloadJob,
+			workload: &batchv1.Job{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "job", UID: "job"},
+				Spec:       batchv1.JobSpec{Template: template("1", "")},
+			},
+			existing: &v1alpha1.PodGroup{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "job", OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: "batch/v1", Kind: "Job", Name: "job", UID: "job", Controller: ptr.To(true),
+				}}},
+				Spec: v1alpha1.PodGroupSpec{MinMember: 1},
+			},
+		},
+		{
+			name: "invalid min-available creates nothing",
+			kind: WorkloadJob,
+			workload: &batchv1.Job{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "job", UID: "job", Annotations: annotated("zero")},
+				Spec:       batchv1.JobSpec{Template: template("1", "")},
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			builder := fake.NewClientBuilder().WithScheme(s).WithObjects(c.workload)
+			if c.existing != nil {
+				builder = builder.WithObjects(c.existing)
+			}
+			cl := builder.Build()
+			r := &WorkloadPodGroupReconciler{Client: cl, Scheme: s, Kind: c.kind}
+			key := types.NamespacedName{Namespace: c.workload.GetNamespace(), Name: c.workload.GetName()}
+			if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key}); err != nil {
+				t.Fatal(err)
+			}
+
+			var pgs v1alpha1.PodGroupList
+			if err := cl.List(ctx, &pgs); err != nil {
+				t.Fatal(err)
+			}
+			if c.pgName == "" {
+				if len(pgs.Items) != 0 {
+					t.Errorf("Want no PodGroup, got %v", pgs.Items)
+				}
+				return
+			}
+			pg := &v1alpha1.PodGroup{}
+			if err := cl.Get(ctx, types.NamespacedName{Namespace: "ns", Name: c.pgName}, pg); err != nil {
+				if apierrs.IsNotFound(err) {
+					t.Fatalf("Want PodGroup %v, got none", c.pgName)
+				}
+				t.Fatal(err)
+			}
+			if pg.Spec.MinMember != c.wantMinMember {
+				t.Errorf("Want minMember %v, got %v", c.wantMinMember, pg.Spec.MinMember)
+			}
+			for name, want := range c.wantMinResources {
+				if got := pg.Spec.MinResources[name]; got.Cmp(want) != 0 {
+					t.Errorf("Want minResources[%v] %v, got %v", name, want.String(), got.String())
+				}
+			}
+			if owned := metav1.IsControlledBy(pg, c.workload); owned != c.wantOwned {
+				t.Errorf("Want owned %v, got %v", c.wantOwned, owned)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"context"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
+)
+
+// conditionsWriter records the conditions to set on PodGroups and patches them in the background,
+// so that the scheduling cycle never waits for the API server.
+type conditionsWriter struct {
+	client client.Client
+	lock   sync.Mutex
+	// pending stores the conditions not written yet, by PodGroup namespace/name.
+	pending map[types.NamespacedName][]metav1.Condition
+	queue   workqueue.TypedRateLimitingInterface[types.NamespacedName]
+}
+
+func newConditionsWriter(c client.Client) *conditionsWriter {
+	return &conditionsWriter{
+		client:  c,
+		pending: map[types.NamespacedName][]metav1.Condition{},
+		queue: workqueue.NewTypedRateLimitingQueueWithConfig(
+			workqueue.DefaultTypedControllerRateLimiter[types.NamespacedName](),
+			workqueue.TypedRateLimitingQueueConfig[types.NamespacedName]{Name: "podgroup-conditions"},
+		),
+	}
+}
+
+// SetPodGroupConditions records the given conditions to set on the status of the given PodGroup. They are
+// patched in the background by WritePodGroupConditions, a later condition replacing an earlier one of the
+// same type. A Backoff condition which is true records the time of the last failure: its last transition
+// time is also refreshed when its reason or message changes.
+func (pgMgr *PodGroupManager) SetPodGroupConditions(ctx context.Context, pg *v1alpha1.PodGroup, conditions ...metav1.Condition) {
+	pgMgr.conditions.record(types.NamespacedName{Namespace: pg.Namespace, Name: pg.Name}, conditions...)
+}
+
+// WritePodGroupConditions patches the conditions recorded by SetPodGroupConditions until ctx is done.
+// Failures are retried with backoff, unless newer conditions of the same type were recorded meanwhile.
+func (pgMgr *PodGroupManager) WritePodGroupConditions(ctx context.Context) {
+	go func() {
+		<-ctx.Done()
+		pgMgr.conditions.queue.ShutDown()
+	}()
+	for pgMgr.conditions.processNext(ctx) {
+	}
+}
+
+// record merges <conditions> into the pending conditions of the PodGroup <key> and queues it.
+func (w *conditionsWriter) record(key types.NamespacedName, conditions ...metav1.Condition) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	pending := w.pending[key]
+	for _, c := range conditions {
+		pending = replaceCondition(pending, c)
+	}
+	w.pending[key] = pending
+	w.queue.Add(key)
+}
+
+// retry records again the <conditions> of the PodGroup <key> whose write failed, unless newer
+// conditions of the same type were recorded meanwhile, and queues it with backoff.
+func (w *conditionsWriter) retry(key types.NamespacedName, conditions []metav1.Condition) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	pending := w.pending[key]
+	for _, c := range conditions {
+		if meta.FindStatusCondition(pending, c.Type) == nil {
+			pending = append(pending, c)
+		}
+	}
+	w.pending[key] = pending
+	w.queue.AddRateLimited(key)
+}
+
+// processNext writes the pending conditions of the next PodGroup in the queue. It returns false
+// once the queue is shut down.
+func (w *conditionsWriter) processNext(ctx context.Context) bool {
+	key, shutdown := w.queue.Get()
+	if shutdown {
+		return false
+	}
+	defer w.queue.Done(key)
+
+	w.lock.Lock()
+	conditions := w.pending[key]
+	delete(w.pending, key)
+	w.lock.Unlock()
+	if len(conditions) == 0 {
+		w.queue.Forget(key)
+		return true
+	}
+
+	if err := w.write(ctx, key, conditions); err != nil {
+		klog.FromContext(ctx).Error(err, "Failed to patch the conditions of PodGroup", "podGroup", key)
+		w.retry(key, conditions)
+		return true
+	}
+	w.queue.Forget(key)
+	return true
+}
+
+// write sets <conditions> on the status of the PodGroup <key>, and patches it if any of them changed.
+// PodGroups which no longer exist are ignored.
+func (w *conditionsWriter) write(ctx context.Context, key types.NamespacedName, conditions []metav1.Condition) error {
+	var pg v1alpha1.PodGroup
+	if err := w.client.Get(ctx, key, &pg); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	pgCopy := pg.DeepCopy()
+	changed := false
+	for _, c := range conditions {
+		if existing := meta.FindStatusCondition(pgCopy.Status.Conditions, c.Type); existing != nil &&
+			c.Type == v1alpha1.PodGroupBackoff && c.Status == metav1.ConditionTrue &&
+			(existing.Reason != c.Reason || existing.Message != c.Message) {
+			meta.RemoveStatusCondition(&pgCopy.Status.Conditions, c.Type)
+		}
+		changed = meta.SetStatusCondition(&pgCopy.Status.Conditions, c) || changed
+	}
+	if !changed {
+		return nil
+	}
+	return client.IgnoreNotFound(w.client.Status().Patch(ctx, pgCopy, client.MergeFrom(&pg)))
+}
+
+// replaceCondition returns <conditions> with <c> replacing the condition of the same type, if any.
+func replaceCondition(conditions []metav1.Condition, c metav1.Condition) []metav1.Condition {
+	for i := range conditions {
+		if conditions[i].Type == c.Type {
+			conditions[i] = c
+			return conditions
+		}
+	}
+	return append(conditions, c)
+}
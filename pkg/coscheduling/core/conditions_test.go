@@ -0,0 +1,161 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
+	tu "sigs.k8s.io/scheduler-plugins/test/util"
+)
+
+func TestSetPodGroupConditions(t *testing.T) {
+	ctx := context.Background()
+	lastFailure := metav1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second))
+	pg := tu.MakePodGroup().Name("pg1").Namespace("ns").MinMember(2).Obj()
+	pg.Status.Conditions = []metav1.Condition{{
+		Type:               v1alpha1.PodGroupBackoff,
+		Status:             metav1.ConditionTrue,
+		Reason:             v1alpha1.PodGroupReasonRejected,
+		Message:            "pod p1 is unschedulable",
+		LastTransitionTime: lastFailure,
+	}}
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	client := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(&v1alpha1.PodGroup{}).
+		WithRuntimeObjects(pg).
+		Build()
+	pgMgr := &PodGroupManager{client: client, conditions: newConditionsWriter(client)}
+	defer pgMgr.conditions.queue.ShutDown()
+
+	backoff := func(msg string) metav1.Condition {
+		return metav1.Condition{
+			Type:    v1alpha1.PodGroupBackoff,
+			Status:  metav1.ConditionTrue,
+			Reason:  v1alpha1.PodGroupReasonRejected,
+			Message: msg,
+		}
+	}
+	steps := []struct {
+		name        string
+		conditions  []metav1.Condition
+		wantStatus  metav1.ConditionStatus
+		wantMessage string
+		wantRefresh bool
+	}{
+		{
+			name:        "same failure keeps its time",
+			conditions:  []metav1.Condition{backoff("pod p1 is unschedulable")},
+			wantStatus:  metav1.ConditionTrue,
+			wantMessage: "pod p1 is unschedulable",
+		},
+		{
+			name:        "new failure refreshes the time",
+			conditions:  []metav1.Condition{backoff("pod p2 is unschedulable")},
+			wantStatus:  metav1.ConditionTrue,
+			wantMessage: "pod p2 is unschedulable",
+			wantRefresh: true,
+		},
+	}
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			var current v1alpha1.PodGroup
+			if err := client.Get(ctx, types.NamespacedName{Namespace: "ns", Name: "pg1"}, &current); err != nil {
+				t.Fatal(err)
+			}
+			previous := meta.FindStatusCondition(current.Status.Conditions, v1alpha1.PodGroupBackoff).LastTransitionTime
+
+			for _, c := range step.conditions {
+				pgMgr.SetPodGroupConditions(ctx, &current, c)
+			}
+			if pgMgr.conditions.queue.Len() != 1 {
+				t.Fatalf("queued PodGroups = %v, want 1", pgMgr.conditions.queue.Len())
+			}
+			pgMgr.conditions.processNext(ctx)
+
+			var got v1alpha1.PodGroup
+			if err := client.Get(ctx, types.NamespacedName{Namespace: "ns", Name: "pg1"}, &got); err != nil {
+				t.Fatal(err)
+			}
+			cond := meta.FindStatusCondition(got.Status.Conditions, v1alpha1.PodGroupBackoff)
+			if cond == nil || cond.Status != step.wantStatus || cond.Message != step.wantMessage {
+				t.Fatalf("Backoff condition = %+v, want status %v and message %q", cond, step.wantStatus, step.wantMessage)
+			}
+			if refreshed := !cond.LastTransitionTime.Equal(&previous); refreshed != step.wantRefresh {
+				t.Errorf("Backoff condition refreshed = %v, want %v", refreshed, step.wantRefresh)
+			}
+		})
+	}
+}
+
+func TestSetPodGroupConditionsMerges(t *testing.T) {
+	ctx := context.Background()
+	pg := tu.MakePodGroup().Name("pg1").Namespace("ns").MinMember(2).Obj()
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	client := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(&v1alpha1.PodGroup{}).
+		WithRuntimeObjects(pg).
+		Build()
+	pgMgr := &PodGroupManager{client: client, conditions: newConditionsWriter(client)}
+	defer pgMgr.conditions.queue.ShutDown()
+
+	for _, msg := range []string{"pod p1 is unschedulable", "pod p2 is unschedulable"} {
+		pgMgr.SetPodGroupConditions(ctx, pg, metav1.Condition{
+			Type:    v1alpha1.PodGroupBackoff,
+			Status:  metav1.ConditionTrue,
+			Reason:  v1alpha1.PodGroupReasonRejected,
+			Message: msg,
+		})
+	}
+
+	// nothing is written until the writer runs
+	var got v1alpha1.PodGroup
+	if err := client.Get(ctx, types.NamespacedName{Namespace: "ns", Name: "pg1"}, &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Status.Conditions) != 0 {
+		t.Fatalf("conditions written synchronously: %+v", got.Status.Conditions)
+	}
+	if pgMgr.conditions.queue.Len() != 1 {
+		t.Fatalf("queued PodGroups = %v, want 1", pgMgr.conditions.queue.Len())
+	}
+
+	pgMgr.conditions.processNext(ctx)
+	if err := client.Get(ctx, types.NamespacedName{Namespace: "ns", Name: "pg1"}, &got); err != nil {
+		t.Fatal(err)
+	}
+	cond := meta.FindStatusCondition(got.Status.Conditions, v1alpha1.PodGroupBackoff)
+	if cond == nil || cond.Message != "pod p2 is unschedulable" {
+		t.Errorf("Backoff condition = %+v, want the last one recorded", cond)
+	}
+}
@@ -26,7 +26,6 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 	informerv1 "k8s.io/client-go/informers/core/v1"
@@ -70,12 +69,21 @@ type Manager interface {
 	Unreserve(context.Context, *corev1.Pod)
 	GetPodGroup(context.Context, *corev1.Pod) (string, *v1alpha1.PodGroup)
 	GetAssignedPodCount(string) int
+	QuorumReached(context.Context, string, *v1alpha1.PodGroup) bool
 	GetCreationTimestamp(context.Context, *corev1.Pod, time.Time) time.Time
 	DeletePermittedPodGroup(context.Context, string)
 	ActivateSiblings(ctx context.Context, pod *corev1.Pod, state fwk.CycleState)
 	BackoffPodGroup(string, time.Duration)
 	MarkPodGroupScheduleFailure(string)
 	ClearPodGroupScheduleFailure(string)
+	GetTopologyDomain(context.Context, string, *v1alpha1.PodGroup, *corev1.Pod) (string, error)
+	RecordTopologyDomain(context.Context, *corev1.Pod, string)
+	ResetTopologyDomain(string)
+	ListPodGroupPods(*v1alpha1.PodGroup) ([]*corev1.Pod, error)
+	ReserveCapacity(context.Context, string, *v1alpha1.PodGroup, *corev1.Pod, string)
+	ReleaseCapacity(string)
+	ReservedCapacity(string, int32) map[string]corev1.ResourceList
+	SetPodGroupConditions(context.Context, *v1alpha1.PodGroup, ...metav1.Condition)
 }
 
 // PodGroupManager defines the scheduling operation called
@@ -96,8 +104,26 @@ type PodGroupManager struct {
 	lastFailedSchedulePG sync.Map
 	// podLister is pod lister
 	podLister listerv1.PodLister
+	// podIndexer is the indexer of the pod informer, which indexes pods by PodGroupIndex.
+	podIndexer cache.Indexer
+	// namespaceLister is namespace lister, used to find the namespaces allowing their pods to belong to
+	// podgroups of other namespaces. Pods only belong to podgroups of their own namespace if nil.
+	namespaceLister listerv1.NamespaceLister
 	// assignedPodsByPG stores the pods assumed or bound for podgroups
 	assignedPodsByPG map[string]sets.Set[string]
+	// topologyDomainByPG stores the topology domain chosen for podgroups with a topology constraint
+	topologyDomainByPG map[string]string
+	// failedTopologyDomainsByPG stores the topology domains podgroups were recently rejected from
+	failedTopologyDomainsByPG map[string]sets.Set[string]
+	// simulatePlacement enables PreFilter to simulate packing the pending members of podgroups
+	// instead of checking their minResources against the free resources of the cluster.
+	simulatePlacement bool
+	// reserveCapacity enables setting capacity aside for the remaining members of podgroups waiting in permit.
+	reserveCapacity bool
+	// reservedPG stores the reservations of podgroups, until the first of their members waiting times out.
+	reservedPG *gocache.Cache
+	// conditions writes the conditions of podgroups in the background.
+	conditions *conditionsWriter
 	sync.RWMutex
 }
 
@@ -117,9 +143,9 @@ func AddPodFactory(pgMgr *PodGroupManager) func(obj interface{}) {
 		pgMgr.RWMutex.Lock()
 		defer pgMgr.RWMutex.Unlock()
 		if assigned, exist := pgMgr.assignedPodsByPG[pgFullName]; exist {
-			assigned.Insert(p.Name)
+			assigned.Insert(memberName(p))
 		} else {
-			pgMgr.assignedPodsByPG[pgFullName] = sets.New(p.Name)
+			pgMgr.assignedPodsByPG[pgFullName] = sets.New(memberName(p))
 		}
 	}
 }
@@ -133,8 +159,19 @@ func NewPodGroupManager(client client.Client, snapshotSharedLister fwk.SharedLis
 		podLister:            podInformer.Lister(),
 		permittedPG:          gocache.New(3*time.Second, 3*time.Second),
 		backedOffPG:          gocache.New(10*time.Second, 10*time.Second),
+		reservedPG:           gocache.New(10*time.Second, 10*time.Second),
 		// lastFailedSchedulePG is a sync.Map, zero-value ready.
-		assignedPodsByPG: map[string]sets.Set[string]{},
+		assignedPodsByPG:          map[string]sets.Set[string]{},
+		topologyDomainByPG:        map[string]string{},
+		failedTopologyDomainsByPG: map[string]sets.Set[string]{},
+		conditions:                newConditionsWriter(client),
+	}
+	// The index may already exist if several profiles share the informer.
+	podIndexer := podInformer.Informer().GetIndexer()
+	if _, ok := podIndexer.GetIndexers()[PodGroupIndex]; ok {
+		pgMgr.podIndexer = podIndexer
+	} else if err := podInformer.Informer().AddIndexers(cache.Indexers{PodGroupIndex: podGroupIndexFunc}); err == nil {
+		pgMgr.podIndexer = podIndexer
 	}
 	podInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
 		AddFunc: AddPodFactory(pgMgr),
@@ -171,6 +208,53 @@ func (pgMgr *PodGroupManager) GetAssignedPodCount(pgName string) int {
 	return len(pgMgr.assignedPodsByPG[pgName])
 }
 
+// EnablePlacementSimulation makes PreFilter simulate packing the pending members of a PodGroup
+// onto the cluster, see CheckPodGroupPlacement, instead of checking its minResources.
+func (pgMgr *PodGroupManager) EnablePlacementSimulation() {
+	pgMgr.simulatePlacement = true
+}
+
+// QuorumReached returns whether the pods of the given PodGroup that have been assigned nodes
+// satisfy both its minMember and the minMember of each of its roles.
+func (pgMgr *PodGroupManager) QuorumReached(ctx context.Context, pgFullName string, pg *v1alpha1.PodGroup) bool {
+	pgMgr.RWMutex.RLock()
+	defer pgMgr.RWMutex.RUnlock()
+	return pgMgr.quorumReached(ctx, pg, pgMgr.assignedPodsByPG[pgFullName], nil)
+}
+
+// quorumReached checks the quorum of pg against the assigned pods, plus the given pod if not nil.
+// The caller must hold the lock.
+func (pgMgr *PodGroupManager) quorumReached(ctx context.Context, pg *v1alpha1.PodGroup, assigned sets.Set[string], pod *corev1.Pod) bool {
+	if len(assigned) < int(pg.Spec.MinMember) {
+		return false
+	}
+	if len(pg.Spec.Roles) == 0 {
+		return true
+	}
+
+	lh := klog.FromContext(ctx)
+	pods, err := pgMgr.ListPodGroupPods(pg)
+	if err != nil {
+		lh.Error(err, "Failed to obtain pods belong to a PodGroup", "podGroup", klog.KObj(pg))
+		return false
+	}
+	// The given pod may be not yet visible in the lister, add it explicitly.
+	var assignedPods []*corev1.Pod
+	for _, p := range pods {
+		if assigned.Has(memberName(p)) && (pod == nil || memberName(p) != memberName(pod)) {
+			assignedPods = append(assignedPods, p)
+		}
+	}
+	if pod != nil {
+		assignedPods = append(assignedPods, pod)
+	}
+	if err := util.CheckPodGroupRoles(pg, assignedPods); err != nil {
+		lh.V(4).Info("PodGroup roles not satisfied by assigned pods", "podGroup", klog.KObj(pg), "reason", err.Error())
+		return false
+	}
+	return true
+}
+
 func (pgMgr *PodGroupManager) BackoffPodGroup(pgName string, backoff time.Duration) {
 	if backoff == time.Duration(0) {
 		return
@@ -195,8 +279,8 @@ func (pgMgr *PodGroupManager) ClearPodGroupScheduleFailure(pgName string) {
 // in the given state, with a reserved key "kubernetes.io/pods-to-activate".
 func (pgMgr *PodGroupManager) ActivateSiblings(ctx context.Context, pod *corev1.Pod, state fwk.CycleState) {
 	lh := klog.FromContext(ctx)
-	pgName := util.GetPodGroupLabel(pod)
-	if pgName == "" {
+	pgName, pg := pgMgr.GetPodGroup(ctx, pod)
+	if pg == nil {
 		return
 	}
 
@@ -207,9 +291,7 @@ func (pgMgr *PodGroupManager) ActivateSiblings(ctx context.Context, pod *corev1.
 		return
 	}
 
-	pods, err := pgMgr.podLister.Pods(pod.Namespace).List(
-		labels.SelectorFromSet(labels.Set{v1alpha1.PodGroupLabel: pgName}),
-	)
+	pods, err := pgMgr.ListPodGroupPods(pg)
 	if err != nil {
 		lh.Error(err, "Failed to obtain pods belong to a PodGroup", "podGroup", pgName)
 		return
@@ -239,11 +321,15 @@ func (pgMgr *PodGroupManager) ActivateSiblings(ctx context.Context, pod *corev1.
 // PreFilter filters out a pod if
 // 1. it belongs to a podgroup that was recently denied or
 // 2. the total number of pods in the podgroup is less than the minimum number of pods
-// that is required to be scheduled.
+// that is required to be scheduled or
+// 3. the non-gated pods in the podgroup do not meet the minimum number of pods of each role.
 func (pgMgr *PodGroupManager) PreFilter(ctx context.Context, pod *corev1.Pod) error {
 	lh := klog.FromContext(ctx)
 	lh.V(5).Info("Pre-filter", "pod", klog.KObj(pod))
-	pgFullName, pg := pgMgr.GetPodGroup(ctx, pod)
+	pgFullName, pg, err := pgMgr.getPodGroup(ctx, pod)
+	if err != nil {
+		return err
+	}
 	if pg == nil {
 		return nil
 	}
@@ -252,9 +338,7 @@ func (pgMgr *PodGroupManager) PreFilter(ctx context.Context, pod *corev1.Pod) er
 		return fmt.Errorf("podGroup %v failed recently", pgFullName)
 	}
 
-	pods, err := pgMgr.podLister.Pods(pod.Namespace).List(
-		labels.SelectorFromSet(labels.Set{v1alpha1.PodGroupLabel: util.GetPodGroupLabel(pod)}),
-	)
+	pods, err := pgMgr.ListPodGroupPods(pg)
 	if err != nil {
 		return fmt.Errorf("podLister list pods failed: %w", err)
 	}
@@ -276,6 +360,26 @@ func (pgMgr *PodGroupManager) PreFilter(ctx context.Context, pod *corev1.Pod) er
 		}
 	}
 
+	if len(pg.Spec.Roles) != 0 {
+		var ungated []*corev1.Pod
+		for _, p := range pods {
+			if len(p.Spec.SchedulingGates) == 0 {
+				ungated = append(ungated, p)
+			}
+		}
+		if err := util.CheckPodGroupRoles(pg, ungated); err != nil {
+			return fmt.Errorf("pre-filter pod %v cannot find enough sibling pods: %w", pod.Name, err)
+		}
+	}
+
+	if err := pgMgr.checkScaleUp(pgFullName, pg, pod, pods); err != nil {
+		return err
+	}
+
+	if pgMgr.simulatePlacement {
+		return pgMgr.checkPlacement(ctx, pgFullName, pg, pods)
+	}
+
 	if pg.Spec.MinResources == nil {
 		return nil
 	}
@@ -304,6 +408,71 @@ func (pgMgr *PodGroupManager) PreFilter(ctx context.Context, pod *corev1.Pod) er
 	return nil
 }
 
+// checkScaleUp rejects the given pod if admitting it would take the members of pg beyond its maxMember,
+// or if pg already reached its quorum and its scale-up window is over.
+// Members are admitted once assumed or bound, until they terminate.
+func (pgMgr *PodGroupManager) checkScaleUp(pgFullName string, pg *v1alpha1.PodGroup, pod *corev1.Pod, pods []*corev1.Pod) error {
+	if pg.Spec.MaxMember == nil && pg.Spec.ScaleUpWindowSeconds == nil {
+		return nil
+	}
+	pgMgr.RWMutex.RLock()
+	assigned := pgMgr.assignedPodsByPG[pgFullName]
+	var admitted int32
+	for _, p := range pods {
+		if p.UID == pod.UID || util.IsPodTerminated(p) {
+			continue
+		}
+		if p.Spec.NodeName != "" || assigned.Has(memberName(p)) {
+			admitted++
+		}
+	}
+	pgMgr.RWMutex.RUnlock()
+
+	if pg.Spec.MaxMember != nil && admitted >= *pg.Spec.MaxMember {
+		return fmt.Errorf("podGroup %v already has %v members admitted, maxMember of group: %v",
+			pgFullName, admitted, *pg.Spec.MaxMember)
+	}
+	if admitted >= pg.Spec.MinMember {
+		if end, ok := util.GetScaleUpWindowEnd(pg, pods); ok && time.Now().After(end) {
+			return fmt.Errorf("scale-up window of podGroup %v closed at %v", pgFullName, end.Format(time.RFC3339))
+		}
+	}
+	return nil
+}
+
+// checkPlacement simulates packing the pending members of pg onto the cluster.
+// The result is cached in permittedPG like the minResources check.
+func (pgMgr *PodGroupManager) checkPlacement(ctx context.Context, pgFullName string, pg *v1alpha1.PodGroup, pods []*corev1.Pod) error {
+	if _, ok := pgMgr.permittedPG.Get(pgFullName); ok {
+		return nil
+	}
+
+	nodes, err := pgMgr.snapshotSharedLister.NodeInfos().List()
+	if err != nil {
+		return err
+	}
+
+	pgMgr.RWMutex.RLock()
+	assignedNames := pgMgr.assignedPodsByPG[pgFullName]
+	var assigned, pending []*corev1.Pod
+	for _, p := range pods {
+		switch {
+		case p.Spec.NodeName != "" || assignedNames.Has(memberName(p)):
+			assigned = append(assigned, p)
+		case len(p.Spec.SchedulingGates) == 0:
+			pending = append(pending, p)
+		}
+	}
+	pgMgr.RWMutex.RUnlock()
+
+	if err := CheckPodGroupPlacement(ctx, nodes, pg, assigned, pending); err != nil {
+		klog.FromContext(ctx).Error(err, "Failed to PreFilter", "podGroup", klog.KObj(pg))
+		return err
+	}
+	pgMgr.permittedPG.Add(pgFullName, pgFullName, *pgMgr.scheduleTimeout)
+	return nil
+}
+
 // Permit permits a pod to run, if the minMember match, it would send a signal to chan.
 func (pgMgr *PodGroupManager) Permit(ctx context.Context, state fwk.CycleState, pod *corev1.Pod) Status {
 	pgFullName, pg := pgMgr.GetPodGroup(ctx, pod)
@@ -322,10 +491,10 @@ func (pgMgr *PodGroupManager) Permit(ctx context.Context, state fwk.CycleState,
 		assigned = sets.Set[string]{}
 		pgMgr.assignedPodsByPG[pgFullName] = assigned
 	}
-	assigned.Insert(pod.Name)
+	assigned.Insert(memberName(pod))
 	// The number of pods that have been assigned nodes is calculated from the snapshot.
 	// The current pod in not included in the snapshot during the current scheduling cycle.
-	if len(assigned) >= int(pg.Spec.MinMember) {
+	if pgMgr.quorumReached(ctx, pg, assigned, pod) {
 		return Success
 	}
 
@@ -355,9 +524,10 @@ func (pgMgr *PodGroupManager) Unreserve(ctx context.Context, pod *corev1.Pod) {
 	defer pgMgr.RWMutex.Unlock()
 	assigned, exist := pgMgr.assignedPodsByPG[pgFullName]
 	if exist {
-		assigned.Delete(pod.Name)
+		assigned.Delete(memberName(pod))
 		if len(assigned) == 0 {
 			delete(pgMgr.assignedPodsByPG, pgFullName)
+			delete(pgMgr.topologyDomainByPG, pgFullName)
 		}
 	}
 }
@@ -370,14 +540,14 @@ func (pgMgr *PodGroupManager) GetCreationTimestamp(ctx context.Context, pod *cor
 	if len(pgName) == 0 {
 		return ts
 	}
-	pgFullName := fmt.Sprintf("%v/%v", pod.Namespace, pgName)
+	pgFullName := util.GetPodGroupFullName(pod)
 	// If PodGroup has failed scheduling recently, use the failure timestamp
 	// to prevent head-of-line blocking.
 	if lastFailed, exist := pgMgr.lastFailedSchedulePG.Load(pgFullName); exist {
 		return lastFailed.(time.Time)
 	}
 	var pg v1alpha1.PodGroup
-	if err := pgMgr.client.Get(ctx, types.NamespacedName{Namespace: pod.Namespace, Name: pgName}, &pg); err != nil {
+	if err := pgMgr.client.Get(ctx, types.NamespacedName{Namespace: util.GetPodGroupNamespace(pod), Name: pgName}, &pg); err != nil {
 		return ts
 	}
 	return pg.CreationTimestamp.Time
@@ -389,16 +559,32 @@ func (pgMgr *PodGroupManager) DeletePermittedPodGroup(_ context.Context, pgFullN
 }
 
 // GetPodGroup returns the PodGroup that a Pod belongs to in cache.
+// No PodGroup is returned if it lives in another namespace the namespace of the Pod does not allow.
 func (pgMgr *PodGroupManager) GetPodGroup(ctx context.Context, pod *corev1.Pod) (string, *v1alpha1.PodGroup) {
+	pgFullName, pg, err := pgMgr.getPodGroup(ctx, pod)
+	if err != nil {
+		klog.FromContext(ctx).V(4).Info("Pod cannot belong to its PodGroup", "pod", klog.KObj(pod), "reason", err.Error())
+	}
+	return pgFullName, pg
+}
+
+// getPodGroup is like GetPodGroup, but returns an error if the PodGroup lives in another namespace
+// the namespace of the Pod does not allow.
+func (pgMgr *PodGroupManager) getPodGroup(ctx context.Context, pod *corev1.Pod) (string, *v1alpha1.PodGroup, error) {
 	pgName := util.GetPodGroupLabel(pod)
 	if len(pgName) == 0 {
-		return "", nil
+		return "", nil, nil
+	}
+	pgNamespace := util.GetPodGroupNamespace(pod)
+	pgFullName := fmt.Sprintf("%v/%v", pgNamespace, pgName)
+	if err := pgMgr.checkPodGroupNamespace(pod); err != nil {
+		return pgFullName, nil, err
 	}
 	var pg v1alpha1.PodGroup
-	if err := pgMgr.client.Get(ctx, types.NamespacedName{Namespace: pod.Namespace, Name: pgName}, &pg); err != nil {
-		return fmt.Sprintf("%v/%v", pod.Namespace, pgName), nil
+	if err := pgMgr.client.Get(ctx, types.NamespacedName{Namespace: pgNamespace, Name: pgName}, &pg); err != nil {
+		return pgFullName, nil, nil
 	}
-	return fmt.Sprintf("%v/%v", pod.Namespace, pgName), &pg
+	return pgFullName, &pg, nil
 }
 
 // CheckClusterResource checks if resource capacity of the cluster can satisfy <resourceRequest>.
@@ -24,6 +24,7 @@ import (
 	gocache "github.com/patrickmn/go-cache"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -181,6 +182,110 @@ func TestPreFilter(t *testing.T) {
 			},
 			expectedSuccess: false,
 		},
+		{
+			name: "pod belongs to a pg whose roles are satisfied",
+			pod:  st.MakePod().Name("p1a").Namespace("ns").UID("p1a").Label(v1alpha1.PodGroupLabel, "pg1").Label("role", "launcher").Obj(),
+			pendingPods: []*corev1.Pod{
+				st.MakePod().Name("p1b").Namespace("ns").UID("p1b").Label(v1alpha1.PodGroupLabel, "pg1").Label("role", "worker").Obj(),
+				st.MakePod().Name("p1c").Namespace("ns").UID("p1c").Label(v1alpha1.PodGroupLabel, "pg1").Label("role", "worker").Obj(),
+			},
+			pgs: []*v1alpha1.PodGroup{
+				tu.MakePodGroup().Name("pg1").Namespace("ns").MinMember(3).
+					Role("launcher", 1, map[string]string{"role": "launcher"}).
+					Role("worker", 2, map[string]string{"role": "worker"}).Obj(),
+			},
+			expectedSuccess: true,
+		},
+		{
+			name: "pod belongs to a pg that misses a role",
+			pod:  st.MakePod().Name("p1a").Namespace("ns").UID("p1a").Label(v1alpha1.PodGroupLabel, "pg1").Label("role", "worker").Obj(),
+			pendingPods: []*corev1.Pod{
+				st.MakePod().Name("p1b").Namespace("ns").UID("p1b").Label(v1alpha1.PodGroupLabel, "pg1").Label("role", "worker").Obj(),
+				st.MakePod().Name("p1c").Namespace("ns").UID("p1c").Label(v1alpha1.PodGroupLabel, "pg1").Label("role", "worker").Obj(),
+			},
+			pgs: []*v1alpha1.PodGroup{
+				tu.MakePodGroup().Name("pg1").Namespace("ns").MinMember(3).
+					Role("launcher", 1, map[string]string{"role": "launcher"}).
+					Role("worker", 2, map[string]string{"role": "worker"}).Obj(),
+			},
+			expectedSuccess: false,
+		},
+		{
+			name: "pod belongs to a pg whose role is only met by gated pods",
+			pod:  st.MakePod().Name("p1a").Namespace("ns").UID("p1a").Label(v1alpha1.PodGroupLabel, "pg1").Label("role", "worker").Obj(),
+			pendingPods: []*corev1.Pod{
+				st.MakePod().Name("p1b").Namespace("ns").UID("p1b").Label(v1alpha1.PodGroupLabel, "pg1").Label("role", "worker").Obj(),
+				st.MakePod().Name("p1c").Namespace("ns").UID("p1c").Label(v1alpha1.PodGroupLabel, "pg1").Label("role", "worker").Obj(),
+				st.MakePod().Name("p1d").Namespace("ns").UID("p1d").Label(v1alpha1.PodGroupLabel, "pg1").Label("role", "launcher").
+					SchedulingGates([]string{"foo"}).Obj(),
+			},
+			pgs: []*v1alpha1.PodGroup{
+				tu.MakePodGroup().Name("pg1").Namespace("ns").MinMember(3).
+					Role("launcher", 1, map[string]string{"role": "launcher"}).Obj(),
+			},
+			expectedSuccess: false,
+		},
+		{
+			name: "pod belongs to a pg that reached maxMember",
+			pod:  st.MakePod().Name("p3").Namespace("ns").UID("p3").Label(v1alpha1.PodGroupLabel, "pg1").Obj(),
+			pendingPods: []*corev1.Pod{
+				st.MakePod().Name("p1").Namespace("ns").UID("p1").Label(v1alpha1.PodGroupLabel, "pg1").Node("node-a").Obj(),
+				st.MakePod().Name("p2").Namespace("ns").UID("p2").Label(v1alpha1.PodGroupLabel, "pg1").Node("node-b").Obj(),
+			},
+			pgs: []*v1alpha1.PodGroup{
+				tu.MakePodGroup().Name("pg1").Namespace("ns").MinMember(1).MaxMember(2).Obj(),
+			},
+			expectedSuccess: false,
+		},
+		{
+			name: "pod belongs to a pg below maxMember",
+			pod:  st.MakePod().Name("p3").Namespace("ns").UID("p3").Label(v1alpha1.PodGroupLabel, "pg1").Obj(),
+			pendingPods: []*corev1.Pod{
+				st.MakePod().Name("p1").Namespace("ns").UID("p1").Label(v1alpha1.PodGroupLabel, "pg1").Node("node-a").Obj(),
+				st.MakePod().Name("p2").Namespace("ns").UID("p2").Label(v1alpha1.PodGroupLabel, "pg1").Node("node-b").Obj(),
+			},
+			pgs: []*v1alpha1.PodGroup{
+				tu.MakePodGroup().Name("pg1").Namespace("ns").MinMember(1).MaxMember(3).Obj(),
+			},
+			expectedSuccess: true,
+		},
+		{
+			name: "terminated members do not count against maxMember",
+			pod:  st.MakePod().Name("p3").Namespace("ns").UID("p3").Label(v1alpha1.PodGroupLabel, "pg1").Obj(),
+			pendingPods: []*corev1.Pod{
+				st.MakePod().Name("p1").Namespace("ns").UID("p1").Label(v1alpha1.PodGroupLabel, "pg1").Node("node-a").Obj(),
+				st.MakePod().Name("p2").Namespace("ns").UID("p2").Label(v1alpha1.PodGroupLabel, "pg1").Node("node-b").
+					Phase(corev1.PodFailed).Obj(),
+			},
+			pgs: []*v1alpha1.PodGroup{
+				tu.MakePodGroup().Name("pg1").Namespace("ns").MinMember(1).MaxMember(2).Obj(),
+			},
+			expectedSuccess: true,
+		},
+		{
+			name: "pod belongs to a pg whose scale-up window is over",
+			pod:  st.MakePod().Name("p3").Namespace("ns").UID("p3").Label(v1alpha1.PodGroupLabel, "pg1").Obj(),
+			pendingPods: []*corev1.Pod{
+				st.MakePod().Name("p1").Namespace("ns").UID("p1").Label(v1alpha1.PodGroupLabel, "pg1").Node("node-a").
+					StartTime(metav1.NewTime(time.Now().Add(-time.Hour))).Obj(),
+			},
+			pgs: []*v1alpha1.PodGroup{
+				tu.MakePodGroup().Name("pg1").Namespace("ns").MinMember(1).MaxMember(3).ScaleUpWindowSeconds(60).Obj(),
+			},
+			expectedSuccess: false,
+		},
+		{
+			name: "pod belongs to a pg within its scale-up window",
+			pod:  st.MakePod().Name("p3").Namespace("ns").UID("p3").Label(v1alpha1.PodGroupLabel, "pg1").Obj(),
+			pendingPods: []*corev1.Pod{
+				st.MakePod().Name("p1").Namespace("ns").UID("p1").Label(v1alpha1.PodGroupLabel, "pg1").Node("node-a").
+					StartTime(metav1.NewTime(time.Now().Add(-time.Hour))).Obj(),
+			},
+			pgs: []*v1alpha1.PodGroup{
+				tu.MakePodGroup().Name("pg1").Namespace("ns").MinMember(1).MaxMember(3).ScaleUpWindowSeconds(7200).Obj(),
+			},
+			expectedSuccess: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -214,7 +319,9 @@ func TestPreFilter(t *testing.T) {
 				permittedPG:          newCache(),
 				backedOffPG:          newCache(),
 				// lastFailedSchedulePG is a sync.Map, zero-value ready.
-				assignedPodsByPG: make(map[string]sets.Set[string]),
+				assignedPodsByPG:          make(map[string]sets.Set[string]),
+				topologyDomainByPG:        make(map[string]string),
+				failedTopologyDomainsByPG: make(map[string]sets.Set[string]),
 			}
 
 			informerFactory.Start(ctx.Done())
@@ -284,6 +391,31 @@ func TestPermit(t *testing.T) {
 			},
 			want: Success,
 		},
+		{
+			name: "pod belongs to a pg that has quorum but misses a role",
+			pod:  st.MakePod().Name("p1a").Namespace("ns").UID("p1a").Label(v1alpha1.PodGroupLabel, "pg1").Label("role", "worker").Obj(),
+			existingPods: []*corev1.Pod{
+				st.MakePod().Name("p1b").Namespace("ns").UID("p1b").Label(v1alpha1.PodGroupLabel, "pg1").Label("role", "worker").Node("node").Obj(),
+			},
+			pgs: []*v1alpha1.PodGroup{
+				tu.MakePodGroup().Name("pg1").Namespace("ns").MinMember(2).
+					Role("launcher", 1, map[string]string{"role": "launcher"}).Obj(),
+			},
+			want: Wait,
+		},
+		{
+			name: "pod belongs to a pg whose roles are satisfied",
+			pod:  st.MakePod().Name("p1a").Namespace("ns").UID("p1a").Label(v1alpha1.PodGroupLabel, "pg1").Label("role", "launcher").Obj(),
+			existingPods: []*corev1.Pod{
+				st.MakePod().Name("p1b").Namespace("ns").UID("p1b").Label(v1alpha1.PodGroupLabel, "pg1").Label("role", "worker").Node("node").Obj(),
+			},
+			pgs: []*v1alpha1.PodGroup{
+				tu.MakePodGroup().Name("pg1").Namespace("ns").MinMember(2).
+					Role("launcher", 1, map[string]string{"role": "launcher"}).
+					Role("worker", 1, map[string]string{"role": "worker"}).Obj(),
+			},
+			want: Success,
+		},
 	}
 
 	for _, tt := range tests {
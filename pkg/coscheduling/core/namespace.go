@@ -0,0 +1,140 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	listerv1 "k8s.io/client-go/listers/core/v1"
+
+	"sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
+	"sigs.k8s.io/scheduler-plugins/pkg/util"
+)
+
+// EnableCrossNamespacePodGroups lets pods belong to a PodGroup of another namespace, named by their
+// pod-group-namespace label, as long as their own namespace allows it with its
+// allowed-pod-group-namespaces annotation.
+func (pgMgr *PodGroupManager) EnableCrossNamespacePodGroups(namespaceLister listerv1.NamespaceLister) {
+	pgMgr.namespaceLister = namespaceLister
+}
+
+// checkPodGroupNamespace returns an error if the given pod belongs to a PodGroup of another namespace
+// its own namespace does not allow; otherwise returns nil.
+func (pgMgr *PodGroupManager) checkPodGroupNamespace(pod *corev1.Pod) error {
+	pgNamespace := util.GetPodGroupNamespace(pod)
+	if pgNamespace == pod.Namespace {
+		return nil
+	}
+	if pgMgr.namespaceLister == nil {
+		return fmt.Errorf("pod %v belongs to a podGroup of namespace %v, but cross-namespace podGroups are disabled",
+			GetNamespacedName(pod), pgNamespace)
+	}
+	ns, err := pgMgr.namespaceLister.Get(pod.Namespace)
+	if err != nil {
+		return err
+	}
+	if !util.AllowsPodGroupNamespace(ns, pgNamespace) {
+		return fmt.Errorf("namespace %v does not allow its pods to belong to podGroups of namespace %v", pod.Namespace, pgNamespace)
+	}
+	return nil
+}
+
+// PodGroupIndex is the name of the index of the pod informer by the namespace/name of the PodGroup of the pods.
+const PodGroupIndex = "podGroup"
+
+// podGroupIndexFunc indexes pods by the namespace/name of their PodGroup, whichever namespace they live in.
+func podGroupIndexFunc(obj interface{}) ([]string, error) {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return nil, nil
+	}
+	if pgFullName := util.GetPodGroupFullName(pod); pgFullName != "" {
+		return []string{pgFullName}, nil
+	}
+	return nil, nil
+}
+
+// ListPodGroupPods returns the pods belonging to the given PodGroup: those of its namespace, plus
+// those of the namespaces allowing it which refer to it by their pod-group-namespace label.
+func (pgMgr *PodGroupManager) ListPodGroupPods(pg *v1alpha1.PodGroup) ([]*corev1.Pod, error) {
+	pods, err := pgMgr.podGroupPods(pg)
+	if err != nil {
+		return nil, err
+	}
+	var members []*corev1.Pod
+	// The namespaces other than the one of the PodGroup are only looked up for the pods living there.
+	allowed := map[string]bool{pg.Namespace: true}
+	for _, p := range pods {
+		ok, found := allowed[p.Namespace]
+		if !found {
+			ok = pgMgr.checkPodGroupNamespace(p) == nil
+			allowed[p.Namespace] = ok
+		}
+		if ok {
+			members = append(members, p)
+		}
+	}
+	return members, nil
+}
+
+// podGroupPods returns the pods referring to the given PodGroup, whichever namespace they live in.
+func (pgMgr *PodGroupManager) podGroupPods(pg *v1alpha1.PodGroup) ([]*corev1.Pod, error) {
+	if pgMgr.podIndexer != nil {
+		objs, err := pgMgr.podIndexer.ByIndex(PodGroupIndex, pg.Namespace+"/"+pg.Name)
+		if err == nil {
+			pods := make([]*corev1.Pod, 0, len(objs))
+			for _, obj := range objs {
+				if p, ok := obj.(*corev1.Pod); ok {
+					pods = append(pods, p)
+				}
+			}
+			return pods, nil
+		}
+	}
+
+	// Without the index, e.g. if the informer started before it could be added, look for the label everywhere.
+	selector := labels.SelectorFromSet(labels.Set{v1alpha1.PodGroupLabel: pg.Name})
+	var pods []*corev1.Pod
+	var err error
+	if pgMgr.namespaceLister == nil {
+		pods, err = pgMgr.podLister.Pods(pg.Namespace).List(selector)
+	} else {
+		pods, err = pgMgr.podLister.List(selector)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var matching []*corev1.Pod
+	for _, p := range pods {
+		// A pod may belong to a PodGroup of the same name in another namespace.
+		if util.GetPodGroupNamespace(p) == pg.Namespace {
+			matching = append(matching, p)
+		}
+	}
+	return matching, nil
+}
+
+// memberName returns the name a member of a PodGroup is tracked by in assignedPodsByPG: its name,
+// qualified by its namespace if it lives outside the namespace of the PodGroup.
+func memberName(pod *corev1.Pod) string {
+	if util.GetPodGroupNamespace(pod) != pod.Namespace {
+		return GetNamespacedName(pod)
+	}
+	return pod.Name
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	clientsetfake "k8s.io/client-go/kubernetes/fake"
+	clicache "k8s.io/client-go/tools/cache"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	st "k8s.io/kubernetes/pkg/scheduler/testing"
+
+	"sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
+	tu "sigs.k8s.io/scheduler-plugins/test/util"
+)
+
+func TestCrossNamespacePodGroups(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	scheduleTimeout := 10 * time.Second
+
+	namespaces := []*corev1.Namespace{
+		{ObjectMeta: metav1.ObjectMeta{Name: "driver"}},
+		{ObjectMeta: metav1.ObjectMeta{
+			Name:        "executor",
+			Annotations: map[string]string{v1alpha1.AllowedPodGroupNamespacesAnnotation: "foo, driver"},
+		}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "other"}},
+	}
+	member := func(name, namespace string) *st.PodWrapper {
+		w := st.MakePod().Name(name).UID(name).Namespace(namespace).Label(v1alpha1.PodGroupLabel, "pg")
+		if namespace != "driver" {
+			w = w.Label(v1alpha1.PodGroupNamespaceLabel, "driver")
+		}
+		return w
+	}
+	driver := member("driver", "driver").Obj()
+	executor1 := member("executor-1", "executor").Obj()
+	executor2 := member("executor-2", "executor").Obj()
+	intruder := member("intruder", "other").Obj()
+	// Belongs to the PodGroup of the same name in its own namespace.
+	local := st.MakePod().Name("local").UID("local").Namespace("executor").Label(v1alpha1.PodGroupLabel, "pg").Obj()
+	pods := []*corev1.Pod{driver, executor1, executor2, intruder, local}
+
+	pg := tu.MakePodGroup().Name("pg").Namespace("driver").MinMember(3).Obj()
+	client, err := tu.NewFakeClient(pg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs := clientsetfake.NewSimpleClientset()
+	for _, ns := range namespaces {
+		if _, err := cs.CoreV1().Namespaces().Create(ctx, ns, metav1.CreateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	informerFactory := informers.NewSharedInformerFactory(cs, 0)
+	podInformer := informerFactory.Core().V1().Pods()
+	nsInformer := informerFactory.Core().V1().Namespaces()
+	pgMgr := NewPodGroupManager(client, tu.NewFakeSharedLister(nil, nil), &scheduleTimeout, podInformer)
+	pgMgr.EnableCrossNamespacePodGroups(nsInformer.Lister())
+	informerFactory.Start(ctx.Done())
+	if !clicache.WaitForCacheSync(ctx.Done(), podInformer.Informer().HasSynced, nsInformer.Informer().HasSynced) {
+		t.Fatal("WaitForCacheSync failed")
+	}
+	for _, p := range pods {
+		podInformer.Informer().GetStore().Add(p)
+	}
+
+	checkMembers := func(desc string) {
+		t.Helper()
+		members, err := pgMgr.ListPodGroupPods(pg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, p := range members {
+			got = append(got, GetNamespacedName(p))
+		}
+		sort.Strings(got)
+		if diff := cmp.Diff([]string{"driver/driver", "executor/executor-1", "executor/executor-2"}, got); diff != "" {
+			t.Errorf("Unexpected members %v (-want,+got):\n%s", desc, diff)
+		}
+	}
+	if pgMgr.podIndexer == nil {
+		t.Fatal("the pods are not indexed by PodGroup")
+	}
+	checkMembers("from the index")
+	indexer := pgMgr.podIndexer
+	pgMgr.podIndexer = nil
+	checkMembers("without the index")
+	pgMgr.podIndexer = indexer
+
+	if fullName, got := pgMgr.GetPodGroup(ctx, executor1); fullName != "driver/pg" || got == nil {
+		t.Errorf("GetPodGroup() = %v, %v, want driver/pg", fullName, got)
+	}
+	if _, got := pgMgr.GetPodGroup(ctx, intruder); got != nil {
+		t.Errorf("GetPodGroup() of a pod whose namespace does not allow the PodGroup = %v, want nil", got)
+	}
+
+	if err := pgMgr.PreFilter(ctx, executor1); err != nil {
+		t.Errorf("PreFilter() of an allowed pod = %v, want nil", err)
+	}
+	if err := pgMgr.PreFilter(ctx, intruder); err == nil {
+		t.Error("PreFilter() of a pod whose namespace does not allow the PodGroup succeeded, want an error")
+	}
+
+	for i, step := range []struct {
+		pod  *corev1.Pod
+		want Status
+	}{
+		{pod: driver, want: Wait},
+		{pod: executor1, want: Wait},
+		{pod: intruder, want: PodGroupNotFound},
+		{pod: executor2, want: Success},
+	} {
+		if got := pgMgr.Permit(ctx, &framework.CycleState{}, step.pod); got != step.want {
+			t.Errorf("step %d: Permit(%v) = %v, want %v", i, GetNamespacedName(step.pod), got, step.want)
+		}
+	}
+	if got := pgMgr.GetAssignedPodCount("driver/pg"); got != 3 {
+		t.Errorf("GetAssignedPodCount() = %v, want 3", got)
+	}
+
+	// Without a namespace lister, pods only belong to PodGroups of their own namespace.
+	pgMgr.namespaceLister = nil
+	if _, got := pgMgr.GetPodGroup(ctx, executor1); got != nil {
+		t.Errorf("GetPodGroup() with cross-namespace PodGroups disabled = %v, want nil", got)
+	}
+}
@@ -0,0 +1,324 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	listerv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+	fwk "k8s.io/kube-scheduler/framework"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
+	"sigs.k8s.io/scheduler-plugins/pkg/util"
+)
+
+// shareRefreshInterval is the least time between two computations of the dominant shares, so that a
+// burst of events is handled at once.
+const shareRefreshInterval = time.Second
+
+// GangOrdering ranks the pods of the same priority in the scheduling queue, before the creation or
+// last failure timestamps of their PodGroups do.
+type GangOrdering interface {
+	// Rank returns the rank of the pod, the lower ranks going first. ts is the initial attempt timestamp
+	// of the pod. It is called while sorting the scheduling queue, so it must be cheap.
+	Rank(ctx context.Context, pod *corev1.Pod, ts time.Time) float64
+}
+
+// QueueRanks sorts the pods of the scheduling queue by the ranks of the gang orderings. The ranks are
+// shared by the pods of a PodGroup, so that its members stay together in the queue. They are taken the
+// first time a member is sorted after entering the queue, and kept until a member is queued for a later
+// attempt than any before, so that the order of the pods waiting in the queue does not change under the
+// heap holding them. A requeued PodGroup is ranked again with the shares and aging levels of the moment.
+// A pod without PodGroup is ranked on its own.
+type QueueRanks struct {
+	orderings []GangOrdering
+
+	lock sync.Mutex
+	// ranks holds the queuedRanks of the PodGroups, and of the pods without one.
+	ranks map[rankKey]*queuedRanks
+}
+
+// rankKey is the namespace/name of a PodGroup, or of a pod without PodGroup, which may be the same.
+type rankKey struct {
+	podGroup string
+	pod      string
+}
+
+// queuedRanks are the ranks of a PodGroup, or of a pod without one, for the attempt it is queued for.
+type queuedRanks struct {
+	attempts int
+	ranks    []float64
+	// pods are the namespace/name of the pods sorted with the ranks, which have not left the queue for good.
+	pods sets.Set[string]
+}
+
+// NewQueueRanks returns the QueueRanks of the given gang orderings, in order of precedence.
+func NewQueueRanks(orderings ...GangOrdering) *QueueRanks {
+	return &QueueRanks{orderings: orderings, ranks: map[rankKey]*queuedRanks{}}
+}
+
+// Compare returns a negative number if the first pod goes first, a positive number if the second one
+// goes first and 0 if the gang orderings cannot tell them apart.
+func (q *QueueRanks) Compare(ctx context.Context, podInfo1, podInfo2 fwk.QueuedPodInfo) int {
+	if len(q.orderings) == 0 {
+		return 0
+	}
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	return slices.Compare(q.ranksOf(ctx, podInfo1), q.ranksOf(ctx, podInfo2))
+}
+
+// ranksOf returns the ranks of the PodGroup of the pod, or of the pod without one, for the attempt it is
+// queued for, taking them if needed. It must be called with the lock held.
+func (q *QueueRanks) ranksOf(ctx context.Context, podInfo fwk.QueuedPodInfo) []float64 {
+	pod := podInfo.GetPodInfo().GetPod()
+	key := rankKeyOf(pod)
+	r := q.ranks[key]
+	if r == nil {
+		r = &queuedRanks{attempts: -1, pods: sets.New[string]()}
+		q.ranks[key] = r
+	}
+	r.pods.Insert(GetNamespacedName(pod))
+	if podInfo.GetAttempts() > r.attempts {
+		ranks := make([]float64, len(q.orderings))
+		for i, ordering := range q.orderings {
+			ranks[i] = ordering.Rank(ctx, pod, *podInfo.GetInitialAttemptTimestamp())
+		}
+		r.attempts, r.ranks = podInfo.GetAttempts(), ranks
+	}
+	return r.ranks
+}
+
+// Forget drops the pod, once it has left the scheduling queue for good, and the ranks of its PodGroup
+// once none of the pods sorted with them is left.
+func (q *QueueRanks) Forget(pod *corev1.Pod) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	key := rankKeyOf(pod)
+	if r := q.ranks[key]; r != nil {
+		r.pods.Delete(GetNamespacedName(pod))
+		if r.pods.Len() == 0 {
+			delete(q.ranks, key)
+		}
+	}
+}
+
+func rankKeyOf(pod *corev1.Pod) rankKey {
+	if pgName := util.GetPodGroupFullName(pod); pgName != "" {
+		return rankKey{podGroup: pgName}
+	}
+	return rankKey{pod: GetNamespacedName(pod)}
+}
+
+// agingOrdering puts first the PodGroups which have waited the most intervals since their creation,
+// so that neither their last failures nor the other orderings can hold them back forever.
+type agingOrdering struct {
+	pgMgr    Manager
+	interval time.Duration
+	now      func() time.Time
+}
+
+// NewAgingOrdering returns a GangOrdering raising PodGroups one level for every interval they wait
+// since their creation. Pods without a PodGroup age from their initial attempt.
+func NewAgingOrdering(pgMgr Manager, interval time.Duration) GangOrdering {
+	return &agingOrdering{pgMgr: pgMgr, interval: interval, now: time.Now}
+}
+
+func (o *agingOrdering) Rank(ctx context.Context, pod *corev1.Pod, ts time.Time) float64 {
+	if _, pg := o.pgMgr.GetPodGroup(ctx, pod); pg != nil {
+		ts = pg.CreationTimestamp.Time
+	}
+	return -float64(o.now().Sub(ts) / o.interval)
+}
+
+// EventSource is an informer whose events may change the dominant shares.
+type EventSource interface {
+	AddEventHandler(handler cache.ResourceEventHandler) (cache.ResourceEventHandlerRegistration, error)
+}
+
+// dominantResourceFairness puts first the PodGroups of the quotas with the lowest dominant share,
+// which is the largest fraction of any resource the pods of the quota use: of the min of the
+// ElasticQuota the pods count against, or else of the allocatable resources of the cluster for the
+// pods of a namespace without quota. The shares are computed in the background, on the events of the
+// informers, and Rank only reads the last snapshot of them.
+type dominantResourceFairness struct {
+	client     client.Reader
+	podLister  listerv1.PodLister
+	nodeLister listerv1.NodeLister
+
+	// changed is signaled when the shares may have changed since they were last computed.
+	changed  chan struct{}
+	snapshot atomic.Pointer[shareSnapshot]
+}
+
+// shareSnapshot is the dominant shares at a point in time.
+type shareSnapshot struct {
+	// quotas are the ElasticQuotas by namespace, to find the one a pod counts against.
+	quotas map[string][]v1alpha1.ElasticQuota
+	// shares are keyed by the namespace/name of an ElasticQuota with a min, or else by namespace.
+	shares map[string]float64
+}
+
+// NewDominantResourceFairness returns a GangOrdering by the dominant share of the quotas of the
+// PodGroups, computed again on the events of the given sources until the context is done. The usage
+// of an ElasticQuota is the one reported in its status.
+func NewDominantResourceFairness(ctx context.Context, client client.Reader, podLister listerv1.PodLister,
+	nodeLister listerv1.NodeLister, sources ...EventSource) (GangOrdering, error) {
+	o := newDominantResourceFairness(client, podLister, nodeLister)
+	handler := cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(interface{}) { o.signal() },
+		UpdateFunc: func(interface{}, interface{}) { o.signal() },
+		DeleteFunc: func(interface{}) { o.signal() },
+	}
+	for _, source := range sources {
+		if _, err := source.AddEventHandler(handler); err != nil {
+			return nil, err
+		}
+	}
+	o.signal()
+	go o.run(ctx)
+	return o, nil
+}
+
+func newDominantResourceFairness(client client.Reader, podLister listerv1.PodLister, nodeLister listerv1.NodeLister) *dominantResourceFairness {
+	return &dominantResourceFairness{
+		client:     client,
+		podLister:  podLister,
+		nodeLister: nodeLister,
+		changed:    make(chan struct{}, 1),
+	}
+}
+
+func (o *dominantResourceFairness) Rank(_ context.Context, pod *corev1.Pod, _ time.Time) float64 {
+	snapshot := o.snapshot.Load()
+	if snapshot == nil {
+		return 0
+	}
+	namespace := util.GetPodGroupNamespace(pod)
+	if eq := util.FindElasticQuotaIn(snapshot.quotas[namespace], namespace, pod); eq != nil {
+		if share, ok := snapshot.shares[GetNamespacedName(eq)]; ok {
+			return share
+		}
+	}
+	return snapshot.shares[namespace]
+}
+
+// signal records that the shares may have changed, without blocking.
+func (o *dominantResourceFairness) signal() {
+	select {
+	case o.changed <- struct{}{}:
+	default:
+	}
+}
+
+// run computes the shares again whenever they may have changed, at most once every shareRefreshInterval,
+// until the context is done.
+func (o *dominantResourceFairness) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-o.changed:
+		}
+		o.refresh(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(shareRefreshInterval):
+		}
+	}
+}
+
+// refresh computes the dominant shares from the listers and replaces the snapshot with them.
+func (o *dominantResourceFairness) refresh(ctx context.Context) {
+	lh := klog.FromContext(ctx)
+	snapshot := &shareSnapshot{quotas: map[string][]v1alpha1.ElasticQuota{}, shares: map[string]float64{}}
+
+	capacity := corev1.ResourceList{}
+	nodes, err := o.nodeLister.List(labels.Everything())
+	if err != nil {
+		lh.Error(err, "Failed to list nodes")
+		return
+	}
+	for _, node := range nodes {
+		addResources(capacity, node.Status.Allocatable)
+	}
+	usage := map[string]corev1.ResourceList{}
+	pods, err := o.podLister.List(labels.Everything())
+	if err != nil {
+		lh.Error(err, "Failed to list pods")
+		return
+	}
+	for _, p := range pods {
+		if p.Spec.NodeName == "" || util.IsPodTerminated(p) {
+			continue
+		}
+		if _, ok := usage[p.Namespace]; !ok {
+			usage[p.Namespace] = corev1.ResourceList{}
+		}
+		addResources(usage[p.Namespace], util.PodRequests(p))
+	}
+	for ns, used := range usage {
+		snapshot.shares[ns] = dominantShare(used, capacity)
+	}
+
+	var eqList v1alpha1.ElasticQuotaList
+	if err := o.client.List(ctx, &eqList); err != nil {
+		lh.V(4).Info("Failed to list ElasticQuotas, dominant shares are of the cluster", "err", err)
+	}
+	for _, eq := range eqList.Items {
+		snapshot.quotas[eq.Namespace] = append(snapshot.quotas[eq.Namespace], eq)
+		if len(eq.Spec.Min) != 0 {
+			snapshot.shares[GetNamespacedName(&eq)] = dominantShare(eq.Status.Used, eq.Spec.Min)
+		}
+	}
+	o.snapshot.Store(snapshot)
+}
+
+// dominantShare returns the largest fraction of any resource of total used.
+func dominantShare(used, total corev1.ResourceList) float64 {
+	var share float64
+	for name, quant := range used {
+		t, ok := total[name]
+		if !ok || t.IsZero() {
+			continue
+		}
+		if s := quant.AsApproximateFloat64() / t.AsApproximateFloat64(); s > share {
+			share = s
+		}
+	}
+	return share
+}
+
+// addResources adds the quantities of r to acc.
+func addResources(acc, r corev1.ResourceList) {
+	for name, quant := range r {
+		sum := acc[name]
+		sum.Add(quant)
+		acc[name] = sum
+	}
+}
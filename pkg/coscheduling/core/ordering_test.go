@@ -0,0 +1,282 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"cmp"
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/informers"
+	clientsetfake "k8s.io/client-go/kubernetes/fake"
+	clicache "k8s.io/client-go/tools/cache"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	st "k8s.io/kubernetes/pkg/scheduler/testing"
+
+	"sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
+	tu "sigs.k8s.io/scheduler-plugins/test/util"
+)
+
+func TestAgingOrdering(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	old := tu.MakePodGroup().Name("old").Namespace("ns").MinMember(2).Time(now.Add(-25 * time.Minute)).Obj()
+	young := tu.MakePodGroup().Name("young").Namespace("ns").MinMember(2).Time(now.Add(-15 * time.Minute)).Obj()
+	client, err := tu.NewFakeClient(old, young)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pgMgr := &PodGroupManager{client: client}
+	o := &agingOrdering{pgMgr: pgMgr, interval: 10 * time.Minute, now: func() time.Time { return now }}
+
+	member := func(pg string) *corev1.Pod {
+		return st.MakePod().Name(pg+"-0").Namespace("ns").Label(v1alpha1.PodGroupLabel, pg).Obj()
+	}
+	tests := []struct {
+		name     string
+		pod1     *corev1.Pod
+		ts1      time.Time
+		pod2     *corev1.Pod
+		ts2      time.Time
+		wantSign int
+	}{
+		{
+			name:     "older PodGroup of a higher level goes first",
+			pod1:     member("old"),
+			ts1:      now,
+			pod2:     member("young"),
+			ts2:      now,
+			wantSign: -1,
+		},
+		{
+			name:     "same level",
+			pod1:     member("young"),
+			ts1:      now,
+			pod2:     st.MakePod().Name("p").Namespace("ns").Obj(),
+			ts2:      now.Add(-19 * time.Minute),
+			wantSign: 0,
+		},
+		{
+			name:     "pod without PodGroup ages from its initial attempt",
+			pod1:     member("old"),
+			ts1:      now,
+			pod2:     st.MakePod().Name("p").Namespace("ns").Obj(),
+			ts2:      now.Add(-time.Hour),
+			wantSign: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cmp.Compare(o.Rank(ctx, tt.pod1, tt.ts1), o.Rank(ctx, tt.pod2, tt.ts2)); got != tt.wantSign {
+				t.Errorf("rank comparison = %v, want %v", got, tt.wantSign)
+			}
+		})
+	}
+}
+
+func TestDominantResourceFairness(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	nodes := []runtime.Object{
+		st.MakeNode().Name("node-a").Capacity(map[corev1.ResourceName]string{
+			corev1.ResourceCPU: "10", corev1.ResourceMemory: "100Gi",
+		}).Obj(),
+	}
+	req := func(cpu, mem string) map[corev1.ResourceName]string {
+		return map[corev1.ResourceName]string{corev1.ResourceCPU: cpu, corev1.ResourceMemory: mem}
+	}
+	pods := []*corev1.Pod{
+		// Dominant share of cpu-heavy: 4/10 cpu.
+		st.MakePod().Name("p1").Namespace("cpu-heavy").Node("node-a").Req(req("4", "1Gi")).Obj(),
+		// Dominant share of mem-heavy: 30/100 memory.
+		st.MakePod().Name("p2").Namespace("mem-heavy").Node("node-a").Req(req("1", "30Gi")).Obj(),
+		// Pending and finished pods use nothing.
+		st.MakePod().Name("p3").Namespace("idle").Req(req("8", "1Gi")).Obj(),
+		st.MakePod().Name("p4").Namespace("idle").Node("node-a").Phase(corev1.PodSucceeded).Req(req("8", "1Gi")).Obj(),
+		// Dominant share of quota: 1/2 cpu of its ElasticQuota min, rather than 1/10 cpu of the cluster.
+		st.MakePod().Name("p5").Namespace("quota").Node("node-a").Req(req("1", "1Gi")).Obj(),
+	}
+	eq := &v1alpha1.ElasticQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: "eq", Namespace: "quota"},
+		Spec: v1alpha1.ElasticQuotaSpec{
+			Min: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")},
+		},
+		Status: v1alpha1.ElasticQuotaStatus{
+			Used: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+		},
+	}
+	// The pods of the quota labeled as training have a quota of their own, with a dominant share of 1/10 cpu.
+	training := &v1alpha1.ElasticQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: "training", Namespace: "quota"},
+		Spec: v1alpha1.ElasticQuotaSpec{
+			Min:      corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("10")},
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "training"}},
+		},
+		Status: v1alpha1.ElasticQuotaStatus{
+			Used: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+		},
+	}
+	client, err := tu.NewFakeClient(eq, training)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs := clientsetfake.NewSimpleClientset(nodes...)
+	informerFactory := informers.NewSharedInformerFactory(cs, 0)
+	podInformer := informerFactory.Core().V1().Pods()
+	nodeInformer := informerFactory.Core().V1().Nodes()
+	o := newDominantResourceFairness(client, podInformer.Lister(), nodeInformer.Lister())
+	informerFactory.Start(ctx.Done())
+	if !clicache.WaitForCacheSync(ctx.Done(), podInformer.Informer().HasSynced, nodeInformer.Informer().HasSynced) {
+		t.Fatal("WaitForCacheSync failed")
+	}
+	for _, p := range pods {
+		podInformer.Informer().GetStore().Add(p)
+	}
+
+	member := func(namespace string) *corev1.Pod {
+		return st.MakePod().Name("m").Namespace(namespace).Label(v1alpha1.PodGroupLabel, "pg").Obj()
+	}
+	now := time.Now()
+	if got := o.Rank(ctx, member("cpu-heavy"), now); got != 0 {
+		t.Errorf("Rank() before the shares are computed = %v, want 0", got)
+	}
+	o.refresh(ctx)
+	// From the lowest dominant share to the highest.
+	ordered := []string{"idle", "mem-heavy", "cpu-heavy", "quota"}
+	for i := 0; i+1 < len(ordered); i++ {
+		if r1, r2 := o.Rank(ctx, member(ordered[i]), now), o.Rank(ctx, member(ordered[i+1]), now); r1 >= r2 {
+			t.Errorf("Rank(%v) = %v, want lower than Rank(%v) = %v", ordered[i], r1, ordered[i+1], r2)
+		}
+	}
+	// The members of a cross-namespace PodGroup are charged to the namespace of the PodGroup.
+	crossNamespace := st.MakePod().Name("m").Namespace("idle").Label(v1alpha1.PodGroupLabel, "pg").
+		Label(v1alpha1.PodGroupNamespaceLabel, "quota").Obj()
+	if r1, r2 := o.Rank(ctx, crossNamespace, now), o.Rank(ctx, member("quota"), now); r1 != r2 {
+		t.Errorf("Rank() of members of the same PodGroup = %v and %v, want equal", r1, r2)
+	}
+	// The shares are of the quota the pod counts against, not of its namespace.
+	trainee := st.MakePod().Name("m").Namespace("quota").Label(v1alpha1.PodGroupLabel, "pg").Label("team", "training").Obj()
+	if got, want := o.Rank(ctx, trainee, now), 0.1; got != want {
+		t.Errorf("Rank() of a pod of the training quota = %v, want %v", got, want)
+	}
+}
+
+func TestQueueRanksOfPodGroup(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	o := newDominantResourceFairness(nil, nil, nil)
+	o.snapshot.Store(&shareSnapshot{shares: map[string]float64{"ns": 0.5, "other": 0.3}})
+	q := NewQueueRanks(o)
+	queued := func(name, podGroup string, attempts int) *framework.QueuedPodInfo {
+		return &framework.QueuedPodInfo{
+			PodInfo:                 tu.MustNewPodInfo(t, st.MakePod().Name(name).Namespace("ns").Label(v1alpha1.PodGroupLabel, podGroup).Obj()),
+			InitialAttemptTimestamp: &now,
+			Attempts:                attempts,
+		}
+	}
+	otherQueued := func(name string) *framework.QueuedPodInfo {
+		return &framework.QueuedPodInfo{
+			PodInfo:                 tu.MustNewPodInfo(t, st.MakePod().Name(name).Namespace("other").Label(v1alpha1.PodGroupLabel, "pg").Obj()),
+			InitialAttemptTimestamp: &now,
+		}
+	}
+
+	p1, other := queued("p1", "pg", 0), otherQueued("o1")
+	if got := q.Compare(ctx, p1, other); got <= 0 {
+		t.Fatalf("Compare() = %v, want positive", got)
+	}
+	// The shares are refreshed before the sibling p2 enters the queue: it is sorted with the ranks of its
+	// PodGroup all the same, so that no other gang goes between p1 and p2.
+	o.snapshot.Store(&shareSnapshot{shares: map[string]float64{"ns": 0.1, "other": 0.3}})
+	p2 := queued("p2", "pg", 0)
+	if got := q.Compare(ctx, p1, p2); got != 0 {
+		t.Errorf("Compare() of siblings = %v, want 0", got)
+	}
+	if got := q.Compare(ctx, p2, other); got <= 0 {
+		t.Errorf("Compare() of a sibling queued after the refresh = %v, want positive", got)
+	}
+	// Once a sibling is queued for a later attempt, the PodGroup is ranked again, for all its pods.
+	p2 = queued("p2", "pg", 1)
+	if got := q.Compare(ctx, p2, other); got >= 0 {
+		t.Errorf("Compare() of a requeued sibling = %v, want negative", got)
+	}
+	if got := q.Compare(ctx, p1, p2); got != 0 {
+		t.Errorf("Compare() of siblings after the requeue = %v, want 0", got)
+	}
+	// The ranks of the PodGroup are kept until none of its pods is left.
+	q.Forget(p1.Pod)
+	if _, ok := q.ranks[rankKey{podGroup: "ns/pg"}]; !ok {
+		t.Errorf("ranks of the PodGroup dropped while p2 is queued")
+	}
+	q.Forget(p2.Pod)
+	if _, ok := q.ranks[rankKey{podGroup: "ns/pg"}]; ok {
+		t.Errorf("ranks of the PodGroup kept once its pods are gone")
+	}
+}
+
+// fakeOrdering ranks every pod with its current rank.
+type fakeOrdering struct {
+	rank float64
+}
+
+func (o *fakeOrdering) Rank(context.Context, *corev1.Pod, time.Time) float64 {
+	return o.rank
+}
+
+func TestQueueRanks(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	ordering := &fakeOrdering{rank: 1}
+	q := NewQueueRanks(ordering)
+	queued := func(name string, attempts int) *framework.QueuedPodInfo {
+		return &framework.QueuedPodInfo{
+			PodInfo:                 tu.MustNewPodInfo(t, st.MakePod().Name(name).Namespace("ns").Obj()),
+			InitialAttemptTimestamp: &now,
+			Attempts:                attempts,
+		}
+	}
+
+	p1, p2 := queued("p1", 0), queued("p2", 0)
+	if got := q.Compare(ctx, p1, p2); got != 0 {
+		t.Fatalf("Compare() = %v, want 0", got)
+	}
+	// The ranks of p1 are kept while it waits in the queue, whatever the ordering says by now.
+	ordering.rank = 0
+	p3 := queued("p3", 0)
+	if got := q.Compare(ctx, p1, p2); got != 0 {
+		t.Errorf("Compare() of queued pods = %v, want 0", got)
+	}
+	if got := q.Compare(ctx, p3, p1); got >= 0 {
+		t.Errorf("Compare() of a newly queued pod = %v, want negative", got)
+	}
+	// Once p1 has been attempted and queued again, it is ranked again.
+	p1 = queued("p1", 1)
+	if got := q.Compare(ctx, p1, p2); got >= 0 {
+		t.Errorf("Compare() of a requeued pod = %v, want negative", got)
+	}
+	// A forgotten pod is ranked again.
+	q.Forget(p2.Pod)
+	if got := q.Compare(ctx, p1, p2); got != 0 {
+		t.Errorf("Compare() of a forgotten pod = %v, want 0", got)
+	}
+}
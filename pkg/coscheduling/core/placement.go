@@ -0,0 +1,181 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	corev1helpers "k8s.io/component-helpers/scheduling/corev1"
+	"k8s.io/component-helpers/scheduling/corev1/nodeaffinity"
+	"k8s.io/klog/v2"
+	fwk "k8s.io/kube-scheduler/framework"
+
+	"sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
+	"sigs.k8s.io/scheduler-plugins/pkg/util"
+)
+
+// simulatedNode tracks the free resources and the pods of a node while members are packed onto it.
+type simulatedNode struct {
+	node *corev1.Node
+	free corev1.ResourceList
+	pods []*corev1.Pod
+}
+
+// newSimulatedNodes returns the nodes of <nodeList> with their free resources and their pods.
+func newSimulatedNodes(nodeList []fwk.NodeInfo) []*simulatedNode {
+	var nodes []*simulatedNode
+	for _, info := range nodeList {
+		if info == nil || info.Node() == nil {
+			continue
+		}
+		free := util.ResourceList(info.GetAllocatable())
+		for name, quant := range util.ResourceList(info.GetRequested()) {
+			if name == corev1.ResourcePods {
+				continue
+			}
+			left := free[name]
+			left.Sub(quant)
+			free[name] = left
+		}
+		n := &simulatedNode{node: info.Node(), free: free}
+		for _, podInfo := range info.GetPods() {
+			n.pods = append(n.pods, podInfo.GetPod())
+		}
+		pods := free[corev1.ResourcePods]
+		pods.Sub(*resource.NewQuantity(int64(len(n.pods)), resource.DecimalSI))
+		free[corev1.ResourcePods] = pods
+		nodes = append(nodes, n)
+	}
+	return nodes
+}
+
+// admits returns whether <pod> matches the node selector and required node affinity of the node
+// and tolerates its NoSchedule and NoExecute taints.
+func (n *simulatedNode) admits(logger klog.Logger, pod *corev1.Pod) bool {
+	if match, err := nodeaffinity.GetRequiredNodeAffinity(pod).Match(n.node); err != nil || !match {
+		return false
+	}
+	_, untolerated := corev1helpers.FindMatchingUntoleratedTaint(logger, n.node.Spec.Taints, pod.Spec.Tolerations, isSchedulingTaint, false)
+	return !untolerated
+}
+
+// add accounts <pod>, whose requests are <request>, to the node.
+func (n *simulatedNode) add(pod *corev1.Pod, request corev1.ResourceList) {
+	for name, quant := range request {
+		left := n.free[name]
+		left.Sub(quant)
+		n.free[name] = left
+	}
+	n.pods = append(n.pods, pod)
+}
+
+// remove releases <pod>, whose requests are <request>, from the node.
+func (n *simulatedNode) remove(pod *corev1.Pod, request corev1.ResourceList) {
+	for name, quant := range request {
+		left := n.free[name]
+		left.Add(quant)
+		n.free[name] = left
+	}
+	for i := range n.pods {
+		if n.pods[i] == pod {
+			n.pods = append(n.pods[:i], n.pods[i+1:]...)
+			break
+		}
+	}
+}
+
+// podRequest returns the effective requests of <pod>, including the pod slot it takes.
+func podRequest(pod *corev1.Pod) corev1.ResourceList {
+	req := util.PodRequests(pod)
+	req[corev1.ResourcePods] = *resource.NewQuantity(1, resource.DecimalSI)
+	return req
+}
+
+// sortByRequests returns a copy of <pods> in decreasing order of their cpu, then memory requests,
+// along with the requests of each.
+func sortByRequests(pods []*corev1.Pod) ([]*corev1.Pod, map[*corev1.Pod]corev1.ResourceList) {
+	requests := make(map[*corev1.Pod]corev1.ResourceList, len(pods))
+	for _, pod := range pods {
+		requests[pod] = podRequest(pod)
+	}
+	sorted := make([]*corev1.Pod, len(pods))
+	copy(sorted, pods)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		ri, rj := requests[sorted[i]], requests[sorted[j]]
+		if c := ri.Cpu().Cmp(*rj.Cpu()); c != 0 {
+			return c > 0
+		}
+		return ri.Memory().Cmp(*rj.Memory()) > 0
+	})
+	return sorted, requests
+}
+
+// CheckPodGroupPlacement simulates packing the pending members of <pg> onto <nodeList>, first-fit in
+// decreasing order of their requests, honoring their node selector, required node affinity and the
+// NoSchedule/NoExecute taints of the nodes.
+// It returns an error if the members packed, together with the <assigned> ones, do not reach the
+// minMember of <pg> or of any of its roles; otherwise returns nil.
+// Being a heuristic, the simulation may reject a PodGroup an optimal packing would fit.
+func CheckPodGroupPlacement(ctx context.Context, nodeList []fwk.NodeInfo, pg *v1alpha1.PodGroup, assigned, pending []*corev1.Pod) error {
+	logger := klog.FromContext(ctx)
+	nodes := newSimulatedNodes(nodeList)
+
+	sorted, requests := sortByRequests(pending)
+	placed := append([]*corev1.Pod{}, assigned...)
+	for _, pod := range sorted {
+		for _, n := range nodes {
+			if !n.admits(logger, pod) || !fitsResources(requests[pod], n.free) {
+				continue
+			}
+			n.add(pod, requests[pod])
+			placed = append(placed, pod)
+			break
+		}
+	}
+
+	if len(placed) < int(pg.Spec.MinMember) {
+		return fmt.Errorf("only %v of %v pending pods of podGroup %v can be placed, %v already assigned, minMember of group: %v",
+			len(placed)-len(assigned), len(pending), GetNamespacedName(pg), len(assigned), pg.Spec.MinMember)
+	}
+	if err := util.CheckPodGroupRoles(pg, placed); err != nil {
+		return fmt.Errorf("pods of podGroup %v that can be placed do not satisfy its roles: %w", GetNamespacedName(pg), err)
+	}
+	return nil
+}
+
+// isSchedulingTaint returns whether the taint prevents pods without toleration from being scheduled.
+func isSchedulingTaint(t *corev1.Taint) bool {
+	return t.Effect == corev1.TaintEffectNoSchedule || t.Effect == corev1.TaintEffectNoExecute
+}
+
+// fitsResources returns whether every non-zero quantity of <request> is available in <free>.
+func fitsResources(request, free corev1.ResourceList) bool {
+	for name, quant := range request {
+		if quant.IsZero() {
+			continue
+		}
+		left, ok := free[name]
+		if !ok || left.Cmp(quant) < 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	st "k8s.io/kubernetes/pkg/scheduler/testing"
+
+	"sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
+	tu "sigs.k8s.io/scheduler-plugins/test/util"
+)
+
+func TestCheckPodGroupPlacement(t *testing.T) {
+	capacity := map[corev1.ResourceName]string{
+		corev1.ResourceCPU: "4",
+	}
+	nodes := []*corev1.Node{
+		st.MakeNode().Name("node-a").Capacity(capacity).Obj(),
+		st.MakeNode().Name("node-b").Capacity(capacity).
+			Taints([]corev1.Taint{{Key: "dedicated", Effect: corev1.TaintEffectNoSchedule}}).Obj(),
+		st.MakeNode().Name("node-c").Label("zone", "c").Capacity(capacity).Obj(),
+	}
+	makePod := func(name, cpu string) *st.PodWrapper {
+		return st.MakePod().Name(name).Namespace("ns").Label(v1alpha1.PodGroupLabel, "pg1").
+			Req(map[corev1.ResourceName]string{corev1.ResourceCPU: cpu})
+	}
+
+	tests := []struct {
+		name     string
+		pg       *v1alpha1.PodGroup
+		assigned []*corev1.Pod
+		pending  []*corev1.Pod
+		wantErr  bool
+	}{
+		{
+			name:    "members fit",
+			pg:      tu.MakePodGroup().Name("pg1").Namespace("ns").MinMember(2).Obj(),
+			pending: []*corev1.Pod{makePod("p1", "3").Obj(), makePod("p2", "3").Obj()},
+		},
+		{
+			// The cluster has 12 free cpus in total, but a single member fits per node.
+			name: "members do not fit on untainted nodes",
+			pg:   tu.MakePodGroup().Name("pg1").Namespace("ns").MinMember(3).Obj(),
+			pending: []*corev1.Pod{
+				makePod("p1", "3").Obj(), makePod("p2", "3").Obj(), makePod("p3", "3").Obj(),
+			},
+			wantErr: true,
+		},
+		{
+			name: "members tolerate the taint",
+			pg:   tu.MakePodGroup().Name("pg1").Namespace("ns").MinMember(3).Obj(),
+			pending: []*corev1.Pod{
+				makePod("p1", "3").Toleration("dedicated").Obj(),
+				makePod("p2", "3").Toleration("dedicated").Obj(),
+				makePod("p3", "3").Toleration("dedicated").Obj(),
+			},
+		},
+		{
+			name: "members are restricted by their node selector",
+			pg:   tu.MakePodGroup().Name("pg1").Namespace("ns").MinMember(2).Obj(),
+			pending: []*corev1.Pod{
+				makePod("p1", "3").NodeSelector(map[string]string{"zone": "c"}).Obj(),
+				makePod("p2", "3").NodeSelector(map[string]string{"zone": "c"}).Obj(),
+			},
+			wantErr: true,
+		},
+		{
+			name:     "assigned members count toward minMember",
+			pg:       tu.MakePodGroup().Name("pg1").Namespace("ns").MinMember(2).Obj(),
+			assigned: []*corev1.Pod{makePod("p1", "3").Node("node-a").Obj()},
+			pending:  []*corev1.Pod{makePod("p2", "3").Obj()},
+		},
+		{
+			name: "larger members are packed first",
+			pg:   tu.MakePodGroup().Name("pg1").Namespace("ns").MinMember(4).Obj(),
+			pending: []*corev1.Pod{
+				makePod("p1", "1").Obj(), makePod("p2", "1").Obj(), makePod("p3", "3").Obj(), makePod("p4", "3").Obj(),
+			},
+		},
+		{
+			name: "a role does not fit",
+			pg: tu.MakePodGroup().Name("pg1").Namespace("ns").MinMember(2).
+				Role("launcher", 1, map[string]string{"role": "launcher"}).Obj(),
+			pending: []*corev1.Pod{
+				makePod("p1", "3").Obj(), makePod("p2", "3").Obj(), makePod("p3", "8").Label("role", "launcher").Obj(),
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodeInfos, err := tu.NewFakeSharedLister(tt.assigned, nodes).NodeInfos().List()
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = CheckPodGroupPlacement(context.Background(), nodeInfos, tt.pg, tt.assigned, tt.pending)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckPodGroupPlacement() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
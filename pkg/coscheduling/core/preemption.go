@@ -0,0 +1,252 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	corev1helpers "k8s.io/component-helpers/scheduling/corev1"
+	"k8s.io/klog/v2"
+	fwk "k8s.io/kube-scheduler/framework"
+
+	"sigs.k8s.io/scheduler-plugins/pkg/util"
+)
+
+// Nomination is a pending member of a PodGroup and the node it is nominated to.
+type Nomination struct {
+	Pod      *corev1.Pod
+	NodeName string
+	// Victims are the pods to evict from the node so that the member fits.
+	Victims []*corev1.Pod
+}
+
+// GangPreemption is the outcome of preempting on behalf of a whole PodGroup.
+type GangPreemption struct {
+	// Nominations are the members placed, in the order they were given.
+	Nominations []Nomination
+	// Victims are the pods to evict so that the nominated members fit.
+	Victims []*corev1.Pod
+}
+
+// disruptionBudget is a PodDisruptionBudget and the disruptions it still allows.
+type disruptionBudget struct {
+	pdb      *policyv1.PodDisruptionBudget
+	selector labels.Selector
+	allowed  int32
+}
+
+// SelectGangVictims simulates placing the pending <members> of a PodGroup onto <nodeList>, evicting
+// pods of a lower priority outside the PodGroup where needed, until <needed> members are placed.
+// For each member, a node it fits on as is is preferred; otherwise the node where the victims have
+// the lowest highest priority, then are the fewest, is chosen. Pods whose eviction would violate
+// one of <pdbs> are never chosen as victims, and pods already being deleted are deemed gone.
+// Only the resources, node affinity and taints are simulated here, so the caller is expected to
+// validate the outcome with the Filter plugins before evicting anything.
+// It returns an error, and nothing to evict, if fewer than <needed> members can be placed.
+func SelectGangVictims(ctx context.Context, nodeList []fwk.NodeInfo, members []*corev1.Pod, needed int,
+	pdbs []*policyv1.PodDisruptionBudget) (*GangPreemption, error) {
+	if len(members) == 0 {
+		return nil, fmt.Errorf("no pending pods to preempt for")
+	}
+	logger := klog.FromContext(ctx)
+	pgFullName := util.GetPodGroupFullName(members[0])
+	nodes := newSimulatedNodes(nodeList)
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return nodes[i].node.Name < nodes[j].node.Name
+	})
+	// Pods being deleted, e.g. victims of a previous attempt, are about to free their resources.
+	for _, n := range nodes {
+		for _, p := range append([]*corev1.Pod{}, n.pods...) {
+			if p.DeletionTimestamp != nil {
+				n.remove(p, podRequest(p))
+			}
+		}
+	}
+	budgets := newDisruptionBudgets(pdbs)
+
+	result := &GangPreemption{}
+	for _, member := range members {
+		if len(result.Nominations) >= needed {
+			break
+		}
+		req := podRequest(member)
+		var best *simulatedNode
+		var bestVictims []*corev1.Pod
+		for _, n := range nodes {
+			if !n.admits(logger, member) {
+				continue
+			}
+			victims, ok := n.selectVictims(pgFullName, member, req, budgets)
+			if !ok {
+				continue
+			}
+			if len(victims) == 0 {
+				best, bestVictims = n, nil
+				break
+			}
+			if best == nil || lessVictims(victims, bestVictims) {
+				best, bestVictims = n, victims
+			}
+		}
+		if best == nil {
+			logger.V(5).Info("No node fits the member even after preemption", "pod", klog.KObj(member))
+			continue
+		}
+		for _, victim := range bestVictims {
+			best.remove(victim, podRequest(victim))
+			consumeDisruption(budgets, victim)
+		}
+		best.add(member, req)
+		result.Nominations = append(result.Nominations, Nomination{Pod: member, NodeName: best.node.Name, Victims: bestVictims})
+		result.Victims = append(result.Victims, bestVictims...)
+	}
+
+	if len(result.Nominations) < needed {
+		return nil, fmt.Errorf("only %v of the %v pods needed by podGroup %v can be placed even after preemption",
+			len(result.Nominations), needed, pgFullName)
+	}
+	return result, nil
+}
+
+// selectVictims returns the pods to evict from the node for <pod>, whose requests are <request>,
+// to fit, and whether it can fit at all. Candidates are the pods of a lower priority outside the
+// PodGroup <pgFullName>; the ones of the highest priority are kept as long as the pod still fits.
+func (n *simulatedNode) selectVictims(pgFullName string, pod *corev1.Pod, request corev1.ResourceList,
+	budgets []*disruptionBudget) ([]*corev1.Pod, bool) {
+	if fitsResources(request, n.free) {
+		return nil, true
+	}
+	priority := corev1helpers.PodPriority(pod)
+	free := n.free.DeepCopy()
+	var candidates []*corev1.Pod
+	for _, p := range n.pods {
+		if corev1helpers.PodPriority(p) >= priority || (pgFullName != "" && util.GetPodGroupFullName(p) == pgFullName) {
+			continue
+		}
+		candidates = append(candidates, p)
+		for name, quant := range podRequest(p) {
+			left := free[name]
+			left.Add(quant)
+			free[name] = left
+		}
+	}
+	if !fitsResources(request, free) {
+		return nil, false
+	}
+
+	// Reprieve as many candidates as possible, the most important first.
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return corev1helpers.PodPriority(candidates[i]) > corev1helpers.PodPriority(candidates[j])
+	})
+	disrupted := map[*disruptionBudget]int32{}
+	var victims []*corev1.Pod
+	for _, p := range candidates {
+		req := podRequest(p)
+		left := free.DeepCopy()
+		for name, quant := range req {
+			l := left[name]
+			l.Sub(quant)
+			left[name] = l
+		}
+		if fitsResources(request, left) || !disruptionAllowed(budgets, disrupted, p) {
+			free = left
+			continue
+		}
+		for _, b := range matchingBudgets(budgets, p) {
+			disrupted[b]++
+		}
+		victims = append(victims, p)
+	}
+	if !fitsResources(request, free) {
+		return nil, false
+	}
+	return victims, true
+}
+
+// lessVictims returns whether evicting <a> is cheaper than evicting <b>: the highest priority
+// among the victims is lower, or it is the same and there are fewer victims.
+func lessVictims(a, b []*corev1.Pod) bool {
+	pa, pb := highestPriority(a), highestPriority(b)
+	if pa != pb {
+		return pa < pb
+	}
+	return len(a) < len(b)
+}
+
+// highestPriority returns the highest priority among <pods>.
+func highestPriority(pods []*corev1.Pod) int32 {
+	var highest int32
+	for i, p := range pods {
+		if prio := corev1helpers.PodPriority(p); i == 0 || prio > highest {
+			highest = prio
+		}
+	}
+	return highest
+}
+
+// newDisruptionBudgets returns the budgets of <pdbs>, skipping those with an invalid or empty selector.
+func newDisruptionBudgets(pdbs []*policyv1.PodDisruptionBudget) []*disruptionBudget {
+	var budgets []*disruptionBudget
+	for _, pdb := range pdbs {
+		selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+		if err != nil || selector.Empty() {
+			continue
+		}
+		budgets = append(budgets, &disruptionBudget{pdb: pdb, selector: selector, allowed: pdb.Status.DisruptionsAllowed})
+	}
+	return budgets
+}
+
+// matchingBudgets returns the budgets <pod> counts against. Pods already being disrupted are not
+// counted again.
+func matchingBudgets(budgets []*disruptionBudget, pod *corev1.Pod) []*disruptionBudget {
+	var matching []*disruptionBudget
+	for _, b := range budgets {
+		if b.pdb.Namespace != pod.Namespace || !b.selector.Matches(labels.Set(pod.Labels)) {
+			continue
+		}
+		if _, ok := b.pdb.Status.DisruptedPods[pod.Name]; ok {
+			continue
+		}
+		matching = append(matching, b)
+	}
+	return matching
+}
+
+// disruptionAllowed returns whether evicting <pod> keeps every budget it counts against satisfied,
+// given the evictions already <disrupted>.
+func disruptionAllowed(budgets []*disruptionBudget, disrupted map[*disruptionBudget]int32, pod *corev1.Pod) bool {
+	for _, b := range matchingBudgets(budgets, pod) {
+		if b.allowed-disrupted[b] <= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// consumeDisruption takes the eviction of <pod> off every budget it counts against.
+func consumeDisruption(budgets []*disruptionBudget, pod *corev1.Pod) {
+	for _, b := range matchingBudgets(budgets, pod) {
+		b.allowed--
+	}
+}
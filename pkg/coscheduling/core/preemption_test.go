@@ -0,0 +1,195 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	st "k8s.io/kubernetes/pkg/scheduler/testing"
+
+	"sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
+	tu "sigs.k8s.io/scheduler-plugins/test/util"
+)
+
+func TestSelectGangVictims(t *testing.T) {
+	capacity := map[corev1.ResourceName]string{
+		corev1.ResourceCPU: "4",
+	}
+	nodes := []*corev1.Node{
+		st.MakeNode().Name("node-a").Capacity(capacity).Obj(),
+		st.MakeNode().Name("node-b").Capacity(capacity).Obj(),
+	}
+	member := func(name string) *corev1.Pod {
+		return st.MakePod().Name(name).Namespace("ns").Label(v1alpha1.PodGroupLabel, "pg1").Priority(100).
+			Req(map[corev1.ResourceName]string{corev1.ResourceCPU: "3"}).Obj()
+	}
+	running := func(name, node string, priority int32, cpu string) *st.PodWrapper {
+		return st.MakePod().Name(name).Namespace("ns").Node(node).Priority(priority).
+			Req(map[corev1.ResourceName]string{corev1.ResourceCPU: cpu})
+	}
+	pdb := func(allowed int32) *policyv1.PodDisruptionBudget {
+		return &policyv1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{Name: "pdb", Namespace: "ns"},
+			Spec: policyv1.PodDisruptionBudgetSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+			},
+			Status: policyv1.PodDisruptionBudgetStatus{DisruptionsAllowed: allowed},
+		}
+	}
+
+	tests := []struct {
+		name            string
+		existingPods    []*corev1.Pod
+		members         []*corev1.Pod
+		needed          int
+		pdbs            []*policyv1.PodDisruptionBudget
+		wantNominations map[string]string
+		wantVictims     []string
+		wantErr         bool
+	}{
+		{
+			name:            "members fit without preemption",
+			members:         []*corev1.Pod{member("p1"), member("p2")},
+			needed:          2,
+			wantNominations: map[string]string{"p1": "node-a", "p2": "node-b"},
+		},
+		{
+			name: "lower priority pods are evicted for the whole gang",
+			existingPods: []*corev1.Pod{
+				running("low-a", "node-a", 10, "2").Obj(),
+				running("low-b", "node-b", 10, "2").Obj(),
+			},
+			members:         []*corev1.Pod{member("p1"), member("p2")},
+			needed:          2,
+			wantNominations: map[string]string{"p1": "node-a", "p2": "node-b"},
+			wantVictims:     []string{"low-a", "low-b"},
+		},
+		{
+			name: "node with the lowest priority victims is preferred",
+			existingPods: []*corev1.Pod{
+				running("mid-a", "node-a", 50, "2").Obj(),
+				running("low-b", "node-b", 10, "2").Obj(),
+			},
+			members:         []*corev1.Pod{member("p1")},
+			needed:          1,
+			wantNominations: map[string]string{"p1": "node-b"},
+			wantVictims:     []string{"low-b"},
+		},
+		{
+			name: "pods fitting alongside the member are reprieved",
+			existingPods: []*corev1.Pod{
+				running("low-a1", "node-a", 10, "1").Obj(),
+				running("low-a2", "node-a", 20, "1").Obj(),
+				running("high-b", "node-b", 200, "4").Obj(),
+			},
+			members:         []*corev1.Pod{member("p1")},
+			needed:          1,
+			wantNominations: map[string]string{"p1": "node-a"},
+			wantVictims:     []string{"low-a1"},
+		},
+		{
+			name: "nothing is evicted if the whole gang cannot be placed",
+			existingPods: []*corev1.Pod{
+				running("low-a", "node-a", 10, "2").Obj(),
+				running("high-b", "node-b", 200, "2").Obj(),
+			},
+			members: []*corev1.Pod{member("p1"), member("p2")},
+			needed:  2,
+			wantErr: true,
+		},
+		{
+			name: "members of the same gang are not evicted",
+			existingPods: []*corev1.Pod{
+				running("p0", "node-a", 10, "2").Label(v1alpha1.PodGroupLabel, "pg1").Obj(),
+				running("low-b", "node-b", 10, "2").Obj(),
+			},
+			members: []*corev1.Pod{member("p1"), member("p2")},
+			needed:  2,
+			wantErr: true,
+		},
+		{
+			name: "pods being deleted are not evicted again",
+			existingPods: []*corev1.Pod{
+				running("low-a", "node-a", 10, "2").Terminating().Obj(),
+				running("low-b", "node-b", 10, "2").Obj(),
+			},
+			members:         []*corev1.Pod{member("p1")},
+			needed:          1,
+			wantNominations: map[string]string{"p1": "node-a"},
+		},
+		{
+			name: "pod disruption budgets are respected",
+			existingPods: []*corev1.Pod{
+				running("db-a", "node-a", 10, "2").Label("app", "db").Obj(),
+				running("db-b", "node-b", 10, "2").Label("app", "db").Obj(),
+			},
+			members: []*corev1.Pod{member("p1"), member("p2")},
+			needed:  2,
+			pdbs:    []*policyv1.PodDisruptionBudget{pdb(1)},
+			wantErr: true,
+		},
+		{
+			name: "pod disruption budgets allowing the evictions",
+			existingPods: []*corev1.Pod{
+				running("db-a", "node-a", 10, "2").Label("app", "db").Obj(),
+				running("db-b", "node-b", 10, "2").Label("app", "db").Obj(),
+			},
+			members:         []*corev1.Pod{member("p1"), member("p2")},
+			needed:          2,
+			pdbs:            []*policyv1.PodDisruptionBudget{pdb(2)},
+			wantNominations: map[string]string{"p1": "node-a", "p2": "node-b"},
+			wantVictims:     []string{"db-a", "db-b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodeInfos, err := tu.NewFakeSharedLister(tt.existingPods, nodes).NodeInfos().List()
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := SelectGangVictims(context.Background(), nodeInfos, tt.members, tt.needed, tt.pdbs)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SelectGangVictims() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			nominations := map[string]string{}
+			for _, n := range got.Nominations {
+				nominations[n.Pod.Name] = n.NodeName
+			}
+			if diff := cmp.Diff(tt.wantNominations, nominations); diff != "" {
+				t.Errorf("Unexpected nominations (-want,+got):\n%s", diff)
+			}
+			var victims []string
+			for _, v := range got.Victims {
+				victims = append(victims, v.Name)
+			}
+			sort.Strings(victims)
+			if diff := cmp.Diff(tt.wantVictims, victims); diff != "" {
+				t.Errorf("Unexpected victims (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	corev1helpers "k8s.io/component-helpers/scheduling/corev1"
+	"k8s.io/klog/v2"
+	fwk "k8s.io/kube-scheduler/framework"
+
+	"sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
+	"sigs.k8s.io/scheduler-plugins/pkg/util"
+)
+
+// reservation is the capacity reserved, by node, for the remaining members of a PodGroup.
+type reservation struct {
+	// priority is the one of the members of the PodGroup: pods of a higher priority ignore the reservation.
+	priority int32
+	nodes    map[string]corev1.ResourceList
+	// expiration is when the first member of the PodGroup waiting times out.
+	expiration time.Time
+}
+
+// EnableGangReservation makes ReserveCapacity set capacity aside for the remaining members of the
+// PodGroups waiting in Permit.
+func (pgMgr *PodGroupManager) EnableGangReservation() {
+	pgMgr.reserveCapacity = true
+}
+
+// ReserveCapacity sets capacity aside for the members of pg still needed to reach its minMember,
+// while <pod> waits in Permit on the node <nodeName>. The pending members are packed first-fit onto
+// the nodes by name, within the topology domain of pg if any, beside the capacity reserved for other
+// PodGroups of the same or a higher priority. The reservation replaces the previous one of pg and expires
+// along with the schedule timeout of the first member of pg waiting, unless the gang is rejected before.
+func (pgMgr *PodGroupManager) ReserveCapacity(ctx context.Context, pgFullName string, pg *v1alpha1.PodGroup, pod *corev1.Pod, nodeName string) {
+	if !pgMgr.reserveCapacity || pg == nil {
+		return
+	}
+	lh := klog.FromContext(ctx)
+	nodeInfos, err := pgMgr.snapshotSharedLister.NodeInfos().List()
+	if err != nil {
+		lh.Error(err, "Failed to list nodes", "podGroup", pgFullName)
+		return
+	}
+	pods, err := pgMgr.ListPodGroupPods(pg)
+	if err != nil {
+		lh.Error(err, "Failed to obtain pods belong to a PodGroup", "podGroup", pgFullName)
+		return
+	}
+
+	pgMgr.RWMutex.RLock()
+	assigned := pgMgr.assignedPodsByPG[pgFullName]
+	needed := int(pg.Spec.MinMember) - len(assigned)
+	domain := pgMgr.topologyDomainByPG[pgFullName]
+	var pending []*corev1.Pod
+	for _, p := range pods {
+		if p.UID == pod.UID || p.Spec.NodeName != "" || assigned.Has(memberName(p)) || p.DeletionTimestamp != nil ||
+			len(p.Spec.SchedulingGates) != 0 {
+			continue
+		}
+		pending = append(pending, p)
+	}
+	pgMgr.RWMutex.RUnlock()
+	if needed <= 0 || len(pending) == 0 {
+		pgMgr.ReleaseCapacity(pgFullName)
+		return
+	}
+
+	priority := corev1helpers.PodPriority(pod)
+	others := pgMgr.ReservedCapacity(pgFullName, priority)
+	var nodes []*simulatedNode
+	for _, n := range newSimulatedNodes(nodeInfos) {
+		if domain != "" && n.node.Labels[pg.Spec.TopologyConstraint.TopologyKey] != domain {
+			continue
+		}
+		// The pod is not in the snapshot of the current scheduling cycle yet.
+		if n.node.Name == nodeName {
+			n.add(pod, podRequest(pod))
+		}
+		subtractResources(n.free, others[n.node.Name])
+		nodes = append(nodes, n)
+	}
+	// The order of the snapshot is not stable; the reservation is.
+	slices.SortFunc(nodes, func(a, b *simulatedNode) int {
+		return cmp.Compare(a.node.Name, b.node.Name)
+	})
+
+	reserved := map[string]corev1.ResourceList{}
+	sorted, requests := sortByRequests(pending)
+	for _, p := range sorted {
+		if needed == 0 {
+			break
+		}
+		for _, n := range nodes {
+			if !n.admits(lh, p) || !fitsResources(requests[p], n.free) {
+				continue
+			}
+			n.add(p, requests[p])
+			if _, ok := reserved[n.node.Name]; !ok {
+				reserved[n.node.Name] = corev1.ResourceList{}
+			}
+			addResources(reserved[n.node.Name], requests[p])
+			needed--
+			break
+		}
+	}
+	if len(reserved) == 0 {
+		pgMgr.ReleaseCapacity(pgFullName)
+		return
+	}
+	// The members waiting since the first reservation time out first, and the gang with them.
+	expiration := time.Now().Add(util.GetWaitTimeDuration(pg, pgMgr.scheduleTimeout))
+	if r, found := pgMgr.reservedPG.Get(pgFullName); found && r.(*reservation).expiration.Before(expiration) {
+		expiration = r.(*reservation).expiration
+	}
+	lh.V(4).Info("Reserved capacity for PodGroup", "podGroup", pgFullName, "nodes", len(reserved), "unplaced", needed)
+	pgMgr.reservedPG.Set(pgFullName, &reservation{priority: priority, nodes: reserved, expiration: expiration}, time.Until(expiration))
+}
+
+// ReleaseCapacity releases the capacity reserved for the given PodGroup, if any.
+func (pgMgr *PodGroupManager) ReleaseCapacity(pgFullName string) {
+	if pgMgr.reservedPG != nil {
+		pgMgr.reservedPG.Delete(pgFullName)
+	}
+}
+
+// ReservedCapacity returns, by node, the capacity reserved for the PodGroups other than the given one,
+// which a pod of the given priority must leave aside: the one of the PodGroups of the same or a higher
+// priority.
+func (pgMgr *PodGroupManager) ReservedCapacity(pgFullName string, priority int32) map[string]corev1.ResourceList {
+	if pgMgr.reservedPG == nil {
+		return nil
+	}
+	total := map[string]corev1.ResourceList{}
+	for name, item := range pgMgr.reservedPG.Items() {
+		r := item.Object.(*reservation)
+		if name == pgFullName || r.priority < priority {
+			continue
+		}
+		for nodeName, reserved := range r.nodes {
+			if _, ok := total[nodeName]; !ok {
+				total[nodeName] = corev1.ResourceList{}
+			}
+			addResources(total[nodeName], reserved)
+		}
+	}
+	return total
+}
+
+// FitsBesideReservation returns whether <pod> fits on the node of <nodeInfo> once the <reserved>
+// capacity is set aside.
+func FitsBesideReservation(nodeInfo fwk.NodeInfo, pod *corev1.Pod, reserved corev1.ResourceList) bool {
+	nodes := newSimulatedNodes([]fwk.NodeInfo{nodeInfo})
+	if len(nodes) == 0 {
+		return true
+	}
+	subtractResources(nodes[0].free, reserved)
+	return fitsResources(podRequest(pod), nodes[0].free)
+}
+
+// subtractResources subtracts the quantities of r from acc.
+func subtractResources(acc, r corev1.ResourceList) {
+	for name, quant := range r {
+		left := acc[name]
+		left.Sub(quant)
+		acc[name] = left
+	}
+}
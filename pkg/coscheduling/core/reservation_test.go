@@ -0,0 +1,193 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	gocache "github.com/patrickmn/go-cache"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/informers"
+	clientsetfake "k8s.io/client-go/kubernetes/fake"
+	clicache "k8s.io/client-go/tools/cache"
+	st "k8s.io/kubernetes/pkg/scheduler/testing"
+
+	"sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
+	tu "sigs.k8s.io/scheduler-plugins/test/util"
+)
+
+func TestReserveCapacity(t *testing.T) {
+	const rackKey = "topology.example.com/rack"
+	capacity := map[corev1.ResourceName]string{
+		corev1.ResourceCPU: "4",
+	}
+	nodes := []*corev1.Node{
+		st.MakeNode().Name("node-a").Label(rackKey, "rack-a").Capacity(capacity).Obj(),
+		st.MakeNode().Name("node-b").Label(rackKey, "rack-a").Capacity(capacity).Obj(),
+		st.MakeNode().Name("node-c").Label(rackKey, "rack-c").Capacity(capacity).Obj(),
+	}
+	member := func(name string) *corev1.Pod {
+		return st.MakePod().Name(name).Namespace("ns").UID(name).Label(v1alpha1.PodGroupLabel, "pg").
+			Req(map[corev1.ResourceName]string{corev1.ResourceCPU: "3"}).Obj()
+	}
+
+	tests := []struct {
+		name     string
+		pg       *v1alpha1.PodGroup
+		pods     []*corev1.Pod
+		assigned []string
+		domain   string
+		others   map[string]corev1.ResourceList
+		// othersPriority is the priority of the other PodGroups, the one of the members being 0.
+		othersPriority int32
+		want           map[string]string
+	}{
+		{
+			name: "remaining members",
+			pg:   tu.MakePodGroup().Name("pg").Namespace("ns").MinMember(3).Obj(),
+			pods: []*corev1.Pod{member("p1"), member("p2"), member("p3"), member("p4")},
+			want: map[string]string{"node-b": "3", "node-c": "3"},
+		},
+		{
+			name:     "members already assigned are not reserved for",
+			pg:       tu.MakePodGroup().Name("pg").Namespace("ns").MinMember(3).TopologyConstraint(rackKey, v1alpha1.TopologyConstraintRequired).Obj(),
+			pods:     []*corev1.Pod{member("p1"), member("p2"), member("p3")},
+			assigned: []string{"p2"},
+			domain:   "rack-a",
+			want:     map[string]string{"node-b": "3"},
+		},
+		{
+			name:   "within the topology domain",
+			pg:     tu.MakePodGroup().Name("pg").Namespace("ns").MinMember(3).TopologyConstraint(rackKey, v1alpha1.TopologyConstraintRequired).Obj(),
+			pods:   []*corev1.Pod{member("p1"), member("p2"), member("p3")},
+			domain: "rack-a",
+			want:   map[string]string{"node-b": "3"},
+		},
+		{
+			name: "beside the reservations of other PodGroups",
+			pg:   tu.MakePodGroup().Name("pg").Namespace("ns").MinMember(3).Obj(),
+			pods: []*corev1.Pod{member("p1"), member("p2"), member("p3")},
+			others: map[string]corev1.ResourceList{
+				"node-b": {corev1.ResourceCPU: resource.MustParse("2")},
+			},
+			want: map[string]string{"node-c": "3"},
+		},
+		{
+			name: "regardless of the reservations of other PodGroups of a lower priority",
+			pg:   tu.MakePodGroup().Name("pg").Namespace("ns").MinMember(3).Obj(),
+			pods: []*corev1.Pod{member("p1"), member("p2"), member("p3")},
+			others: map[string]corev1.ResourceList{
+				"node-b": {corev1.ResourceCPU: resource.MustParse("2")},
+			},
+			othersPriority: -1,
+			want:           map[string]string{"node-b": "3", "node-c": "3"},
+		},
+		{
+			name: "quorum reached",
+			pg:   tu.MakePodGroup().Name("pg").Namespace("ns").MinMember(1).Obj(),
+			pods: []*corev1.Pod{member("p1"), member("p2")},
+			want: map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			cs := clientsetfake.NewSimpleClientset()
+			informerFactory := informers.NewSharedInformerFactory(cs, 0)
+			podInformer := informerFactory.Core().V1().Pods()
+			scheduleTimeout := 10 * time.Second
+			pgMgr := &PodGroupManager{
+				snapshotSharedLister: tu.NewFakeSharedLister(nil, nodes),
+				scheduleTimeout:      &scheduleTimeout,
+				podLister:            podInformer.Lister(),
+				assignedPodsByPG:     map[string]sets.Set[string]{"ns/pg": sets.New(append(tt.assigned, "p1")...)},
+				topologyDomainByPG:   map[string]string{},
+				reservedPG:           gocache.New(10*time.Second, 10*time.Second),
+			}
+			pgMgr.EnableGangReservation()
+			if tt.domain != "" {
+				pgMgr.topologyDomainByPG["ns/pg"] = tt.domain
+			}
+			if tt.others != nil {
+				pgMgr.reservedPG.Set("ns/other", &reservation{priority: tt.othersPriority, nodes: tt.others}, time.Minute)
+			}
+			informerFactory.Start(ctx.Done())
+			if !clicache.WaitForCacheSync(ctx.Done(), podInformer.Informer().HasSynced) {
+				t.Fatal("WaitForCacheSync failed")
+			}
+			for _, p := range tt.pods {
+				podInformer.Informer().GetStore().Add(p)
+			}
+
+			// p1 waits on node-a.
+			pgMgr.ReserveCapacity(ctx, "ns/pg", tt.pg, tt.pods[0], "node-a")
+			got := map[string]string{}
+			for nodeName, reserved := range pgMgr.ReservedCapacity("ns/other", tt.othersPriority) {
+				got[nodeName] = reserved.Cpu().String()
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("ReservedCapacity() = %v, want %v", got, tt.want)
+			}
+			for nodeName, cpu := range tt.want {
+				if got[nodeName] != cpu {
+					t.Errorf("ReservedCapacity() = %v, want %v", got, tt.want)
+				}
+			}
+
+			// Another member waiting does not delay the expiration of the reservation.
+			if r, found := pgMgr.reservedPG.Get("ns/pg"); found {
+				expiration := r.(*reservation).expiration
+				time.Sleep(time.Millisecond)
+				pgMgr.ReserveCapacity(ctx, "ns/pg", tt.pg, tt.pods[0], "node-a")
+				if r, _ := pgMgr.reservedPG.Get("ns/pg"); !r.(*reservation).expiration.Equal(expiration) {
+					t.Errorf("expiration = %v after another reservation, want %v", r.(*reservation).expiration, expiration)
+				}
+			}
+
+			pgMgr.ReleaseCapacity("ns/pg")
+			if got := pgMgr.ReservedCapacity("ns/other", tt.othersPriority); len(got) != 0 {
+				t.Errorf("ReservedCapacity() after release = %v, want none", got)
+			}
+		})
+	}
+}
+
+func TestFitsBesideReservation(t *testing.T) {
+	node := st.MakeNode().Name("node-a").Capacity(map[corev1.ResourceName]string{corev1.ResourceCPU: "4"}).Obj()
+	running := st.MakePod().Name("running").Namespace("ns").Node("node-a").
+		Req(map[corev1.ResourceName]string{corev1.ResourceCPU: "1"}).Obj()
+	nodeInfo, err := tu.NewFakeSharedLister([]*corev1.Pod{running}, []*corev1.Node{node}).NodeInfos().Get("node-a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	reserved := corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")}
+	pod := func(cpu string) *corev1.Pod {
+		return st.MakePod().Name("p").Namespace("ns").Req(map[corev1.ResourceName]string{corev1.ResourceCPU: cpu}).Obj()
+	}
+	if !FitsBesideReservation(nodeInfo, pod("1"), reserved) {
+		t.Error("FitsBesideReservation() of a pod fitting beside the reservation = false, want true")
+	}
+	if FitsBesideReservation(nodeInfo, pod("2"), reserved) {
+		t.Error("FitsBesideReservation() of a pod not fitting beside the reservation = true, want false")
+	}
+}
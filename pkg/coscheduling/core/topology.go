@@ -0,0 +1,189 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+	fwk "k8s.io/kube-scheduler/framework"
+
+	"sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
+	"sigs.k8s.io/scheduler-plugins/pkg/util"
+)
+
+// GetTopologyDomain returns the value of the topology key of the domain the members of the given
+// PodGroup are placed within, or "" if the PodGroup has no topology constraint.
+// If no domain was chosen yet, it is the domain of the members already bound if any; otherwise
+// the domain which can host the most copies of the given pod, as long as it can host the
+// quorum, is chosen. Domains the PodGroup was recently rejected from are only considered when
+// no other domain is left.
+// An error is returned if the constraint is required and no domain can host the quorum.
+func (pgMgr *PodGroupManager) GetTopologyDomain(ctx context.Context, pgFullName string, pg *v1alpha1.PodGroup, pod *corev1.Pod) (string, error) {
+	if pg == nil || pg.Spec.TopologyConstraint == nil {
+		return "", nil
+	}
+	lh := klog.FromContext(ctx)
+	key := pg.Spec.TopologyConstraint.TopologyKey
+
+	pgMgr.RWMutex.Lock()
+	defer pgMgr.RWMutex.Unlock()
+	if domain, ok := pgMgr.topologyDomainByPG[pgFullName]; ok {
+		return domain, nil
+	}
+
+	nodes, err := pgMgr.snapshotSharedLister.NodeInfos().List()
+	if err != nil {
+		return "", err
+	}
+
+	capacity := map[string]int{}
+	for _, info := range nodes {
+		if info == nil || info.Node() == nil {
+			continue
+		}
+		domain, ok := info.Node().Labels[key]
+		if !ok {
+			continue
+		}
+		for _, podInfo := range info.GetPods() {
+			if util.GetPodGroupFullName(podInfo.GetPod()) == pgFullName {
+				lh.V(4).Info("Topology domain of PodGroup follows its bound pods", "podGroup", pgFullName, "domain", domain)
+				pgMgr.topologyDomainByPG[pgFullName] = domain
+				return domain, nil
+			}
+		}
+		capacity[domain] += podCapacity(info, pod)
+	}
+
+	needed := int(pg.Spec.MinMember) - len(pgMgr.assignedPodsByPG[pgFullName])
+	if needed < 1 {
+		needed = 1
+	}
+	failed := pgMgr.failedTopologyDomainsByPG[pgFullName]
+	domain, ok := pickTopologyDomain(capacity, needed, failed)
+	if !ok && failed.Len() > 0 {
+		// Every domain able to host the quorum was tried; give them another chance.
+		delete(pgMgr.failedTopologyDomainsByPG, pgFullName)
+		domain, ok = pickTopologyDomain(capacity, needed, nil)
+	}
+	if !ok {
+		if pg.Spec.TopologyConstraint.Mode != v1alpha1.TopologyConstraintPreferred {
+			return "", fmt.Errorf("no topology domain of %v can host %v members of podGroup %v", key, needed, pgFullName)
+		}
+		// Best effort: the domain hosting the most members, if any.
+		domain = largestTopologyDomain(capacity)
+	}
+	lh.V(4).Info("Chose topology domain for PodGroup", "podGroup", pgFullName, "domain", domain, "needed", needed)
+	pgMgr.topologyDomainByPG[pgFullName] = domain
+	return domain, nil
+}
+
+// RecordTopologyDomain records the domain of the given node as the topology domain of the PodGroup
+// of the given pod, unless one was already chosen.
+func (pgMgr *PodGroupManager) RecordTopologyDomain(ctx context.Context, pod *corev1.Pod, nodeName string) {
+	pgFullName, pg := pgMgr.GetPodGroup(ctx, pod)
+	if pg == nil || pg.Spec.TopologyConstraint == nil {
+		return
+	}
+	info, err := pgMgr.snapshotSharedLister.NodeInfos().Get(nodeName)
+	if err != nil || info.Node() == nil {
+		return
+	}
+	domain, ok := info.Node().Labels[pg.Spec.TopologyConstraint.TopologyKey]
+	if !ok {
+		return
+	}
+
+	pgMgr.RWMutex.Lock()
+	defer pgMgr.RWMutex.Unlock()
+	if current, exist := pgMgr.topologyDomainByPG[pgFullName]; !exist || current == "" {
+		pgMgr.topologyDomainByPG[pgFullName] = domain
+	}
+}
+
+// ResetTopologyDomain forgets the topology domain chosen for the given PodGroup, so that another
+// one is chosen on the next attempt. The forgotten domain is avoided for a while.
+func (pgMgr *PodGroupManager) ResetTopologyDomain(pgFullName string) {
+	pgMgr.RWMutex.Lock()
+	defer pgMgr.RWMutex.Unlock()
+	domain, ok := pgMgr.topologyDomainByPG[pgFullName]
+	if !ok {
+		return
+	}
+	delete(pgMgr.topologyDomainByPG, pgFullName)
+	if domain == "" {
+		return
+	}
+	if failed, exist := pgMgr.failedTopologyDomainsByPG[pgFullName]; exist {
+		failed.Insert(domain)
+	} else {
+		pgMgr.failedTopologyDomainsByPG[pgFullName] = sets.New(domain)
+	}
+}
+
+// pickTopologyDomain returns the domain, not in failed, with the largest capacity of at least needed.
+// Ties are broken by the name of the domain.
+func pickTopologyDomain(capacity map[string]int, needed int, failed sets.Set[string]) (string, bool) {
+	var domains []string
+	for domain, c := range capacity {
+		if c >= needed && !failed.Has(domain) {
+			domains = append(domains, domain)
+		}
+	}
+	if len(domains) == 0 {
+		return "", false
+	}
+	sort.Slice(domains, func(i, j int) bool {
+		if capacity[domains[i]] != capacity[domains[j]] {
+			return capacity[domains[i]] > capacity[domains[j]]
+		}
+		return domains[i] < domains[j]
+	})
+	return domains[0], true
+}
+
+// largestTopologyDomain returns the domain with the largest capacity, or "" if there is none.
+func largestTopologyDomain(capacity map[string]int) string {
+	domain, _ := pickTopologyDomain(capacity, 0, nil)
+	return domain
+}
+
+// podCapacity returns how many copies of the given pod fit in the free resources of the given node.
+func podCapacity(info fwk.NodeInfo, pod *corev1.Pod) int {
+	count := info.GetAllocatable().GetAllowedPodNumber() - len(info.GetPods())
+	allocatable := util.ResourceList(info.GetAllocatable())
+	requested := util.ResourceList(info.GetRequested())
+	for name, quant := range util.PodRequests(pod) {
+		if quant.IsZero() || name == corev1.ResourcePods {
+			continue
+		}
+		free := allocatable[name]
+		free.Sub(requested[name])
+		if n := int(free.MilliValue() / quant.MilliValue()); n < count {
+			count = n
+		}
+	}
+	if count < 0 {
+		return 0
+	}
+	return count
+}
@@ -0,0 +1,179 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	st "k8s.io/kubernetes/pkg/scheduler/testing"
+
+	"sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
+	tu "sigs.k8s.io/scheduler-plugins/test/util"
+)
+
+func TestGetTopologyDomain(t *testing.T) {
+	const rackKey = "topology.example.com/rack"
+	capacity := map[corev1.ResourceName]string{
+		corev1.ResourceCPU: "4",
+	}
+	nodes := []*corev1.Node{
+		st.MakeNode().Name("node-a").Label(rackKey, "rack-a").Capacity(capacity).Obj(),
+		st.MakeNode().Name("node-b1").Label(rackKey, "rack-b").Capacity(capacity).Obj(),
+		st.MakeNode().Name("node-b2").Label(rackKey, "rack-b").Capacity(capacity).Obj(),
+		st.MakeNode().Name("node-unlabeled").Capacity(map[corev1.ResourceName]string{corev1.ResourceCPU: "64"}).Obj(),
+	}
+	pod := st.MakePod().Name("p").Namespace("ns").Label(v1alpha1.PodGroupLabel, "pg1").
+		Req(map[corev1.ResourceName]string{corev1.ResourceCPU: "2"}).Obj()
+
+	tests := []struct {
+		name         string
+		pg           *v1alpha1.PodGroup
+		existingPods []*corev1.Pod
+		failed       []string
+		want         string
+		wantErr      bool
+	}{
+		{
+			name: "no topology constraint",
+			pg:   tu.MakePodGroup().Name("pg1").Namespace("ns").MinMember(3).Obj(),
+			want: "",
+		},
+		{
+			name: "domain with the largest capacity",
+			pg: tu.MakePodGroup().Name("pg1").Namespace("ns").MinMember(2).
+				TopologyConstraint(rackKey, v1alpha1.TopologyConstraintRequired).Obj(),
+			want: "rack-b",
+		},
+		{
+			name: "busy domain cannot host the quorum",
+			pg: tu.MakePodGroup().Name("pg1").Namespace("ns").MinMember(3).
+				TopologyConstraint(rackKey, v1alpha1.TopologyConstraintRequired).Obj(),
+			existingPods: []*corev1.Pod{
+				st.MakePod().Name("other").Namespace("ns").Node("node-b1").
+					Req(map[corev1.ResourceName]string{corev1.ResourceCPU: "4"}).Obj(),
+			},
+			wantErr: true,
+		},
+		{
+			name: "no domain can host the quorum, required",
+			pg: tu.MakePodGroup().Name("pg1").Namespace("ns").MinMember(5).
+				TopologyConstraint(rackKey, v1alpha1.TopologyConstraintRequired).Obj(),
+			wantErr: true,
+		},
+		{
+			name: "no domain can host the quorum, preferred",
+			pg: tu.MakePodGroup().Name("pg1").Namespace("ns").MinMember(5).
+				TopologyConstraint(rackKey, v1alpha1.TopologyConstraintPreferred).Obj(),
+			want: "rack-b",
+		},
+		{
+			name: "domain of bound members",
+			pg: tu.MakePodGroup().Name("pg1").Namespace("ns").MinMember(2).
+				TopologyConstraint(rackKey, v1alpha1.TopologyConstraintRequired).Obj(),
+			existingPods: []*corev1.Pod{
+				st.MakePod().Name("p0").Namespace("ns").Node("node-a").Label(v1alpha1.PodGroupLabel, "pg1").Obj(),
+			},
+			want: "rack-a",
+		},
+		{
+			name: "recently failed domain is avoided",
+			pg: tu.MakePodGroup().Name("pg1").Namespace("ns").MinMember(2).
+				TopologyConstraint(rackKey, v1alpha1.TopologyConstraintRequired).Obj(),
+			failed: []string{"rack-b"},
+			want:   "rack-a",
+		},
+		{
+			name: "all domains recently failed",
+			pg: tu.MakePodGroup().Name("pg1").Namespace("ns").MinMember(2).
+				TopologyConstraint(rackKey, v1alpha1.TopologyConstraintRequired).Obj(),
+			failed: []string{"rack-a", "rack-b"},
+			want:   "rack-b",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pgMgr := &PodGroupManager{
+				snapshotSharedLister:      tu.NewFakeSharedLister(tt.existingPods, nodes),
+				assignedPodsByPG:          make(map[string]sets.Set[string]),
+				topologyDomainByPG:        make(map[string]string),
+				failedTopologyDomainsByPG: make(map[string]sets.Set[string]),
+			}
+			if len(tt.failed) != 0 {
+				pgMgr.failedTopologyDomainsByPG["ns/pg1"] = sets.New(tt.failed...)
+			}
+
+			got, err := pgMgr.GetTopologyDomain(context.Background(), "ns/pg1", tt.pg, pod)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetTopologyDomain() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("GetTopologyDomain() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResetTopologyDomain(t *testing.T) {
+	const rackKey = "topology.example.com/rack"
+	capacity := map[corev1.ResourceName]string{
+		corev1.ResourceCPU: "4",
+	}
+	nodes := []*corev1.Node{
+		st.MakeNode().Name("node-a").Label(rackKey, "rack-a").Capacity(capacity).Obj(),
+		st.MakeNode().Name("node-b").Label(rackKey, "rack-b").Capacity(capacity).Obj(),
+	}
+	pod := st.MakePod().Name("p").Namespace("ns").Label(v1alpha1.PodGroupLabel, "pg1").
+		Req(map[corev1.ResourceName]string{corev1.ResourceCPU: "2"}).Obj()
+	pg := tu.MakePodGroup().Name("pg1").Namespace("ns").MinMember(2).
+		TopologyConstraint(rackKey, v1alpha1.TopologyConstraintRequired).Obj()
+
+	pgMgr := &PodGroupManager{
+		snapshotSharedLister:      tu.NewFakeSharedLister(nil, nodes),
+		assignedPodsByPG:          make(map[string]sets.Set[string]),
+		topologyDomainByPG:        make(map[string]string),
+		failedTopologyDomainsByPG: make(map[string]sets.Set[string]),
+	}
+
+	ctx := context.Background()
+	steps := []struct {
+		reset bool
+		want  string
+	}{
+		{want: "rack-a"},
+		// The chosen domain sticks until it is reset.
+		{want: "rack-a"},
+		{reset: true, want: "rack-b"},
+		// Every domain failed recently, start over.
+		{reset: true, want: "rack-a"},
+	}
+	for i, step := range steps {
+		if step.reset {
+			pgMgr.ResetTopologyDomain("ns/pg1")
+		}
+		got, err := pgMgr.GetTopologyDomain(ctx, "ns/pg1", pg, pod)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != step.want {
+			t.Fatalf("step %d: GetTopologyDomain() = %q, want %q", i, got, step.want)
+		}
+	}
+}
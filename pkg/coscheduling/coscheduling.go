@@ -22,12 +22,19 @@ import (
 	"time"
 
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/tools/cache"
 	corev1helpers "k8s.io/component-helpers/scheduling/corev1"
 	"k8s.io/klog/v2"
+	extenderv1 "k8s.io/kube-scheduler/extender/v1"
 	fwk "k8s.io/kube-scheduler/framework"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/kubernetes/pkg/scheduler/framework/preemption"
+	schedutil "k8s.io/kubernetes/pkg/scheduler/util"
 	"sigs.k8s.io/scheduler-plugins/apis/config"
 	"sigs.k8s.io/scheduler-plugins/apis/config/validation"
 	"sigs.k8s.io/scheduler-plugins/apis/scheduling"
@@ -44,10 +51,20 @@ type Coscheduling struct {
 	scheduleTimeout   *time.Duration
 	pgBackoff         *time.Duration
 	pgRejectThreshold float64
+	// gangPreemption enables preempting on behalf of the whole PodGroup in PostFilter.
+	gangPreemption bool
+	// preemptPod evicts a victim of gang preemption. It is set along with gangPreemption.
+	preemptPod func(ctx context.Context, c preemption.Candidate, preemptor, victim *v1.Pod, pluginName string) error
+	// queueRanks order the pods of the same priority in the queue, by the gang orderings, before the timestamps do.
+	queueRanks *core.QueueRanks
+	// gangReservation enables hiding the capacity reserved for PodGroups from other pods in Filter.
+	gangReservation bool
 }
 
 var _ fwk.QueueSortPlugin = &Coscheduling{}
 var _ fwk.PreFilterPlugin = &Coscheduling{}
+var _ fwk.FilterPlugin = &Coscheduling{}
+var _ fwk.ScorePlugin = &Coscheduling{}
 var _ fwk.PostFilterPlugin = &Coscheduling{}
 var _ fwk.PermitPlugin = &Coscheduling{}
 var _ fwk.ReservePlugin = &Coscheduling{}
@@ -57,8 +74,36 @@ var _ fwk.EnqueueExtensions = &Coscheduling{}
 const (
 	// Name is the name of the plugin used in Registry and configurations.
 	Name = "Coscheduling"
+
+	topologyStateKey    = Name + "/topology"
+	reservationStateKey = Name + "/reservation"
+
+	// ErrReasonTopologyDomain is the reason for a node outside the topology domain of the PodGroup.
+	ErrReasonTopologyDomain = "node(s) didn't match the topology domain of the pod group"
+	// ErrReasonReservedCapacity is the reason for a node whose free capacity is reserved for other PodGroups.
+	ErrReasonReservedCapacity = "node(s) had capacity reserved for other pod groups"
 )
 
+// topologyState is the topology domain the members of the PodGroup of the pod are placed within.
+type topologyState struct {
+	key    string
+	domain string
+	mode   v1alpha1.TopologyConstraintMode
+}
+
+func (s *topologyState) Clone() fwk.StateData {
+	return s
+}
+
+// reservationState is the capacity reserved, by node, for the PodGroups other than the one of the pod.
+type reservationState struct {
+	reserved map[string]v1.ResourceList
+}
+
+func (s *reservationState) Clone() fwk.StateData {
+	return s
+}
+
 // New initializes and returns a new Coscheduling plugin.
 func New(ctx context.Context, obj runtime.Object, handle fwk.Handle) (fwk.Plugin, error) {
 
@@ -76,7 +121,7 @@ func New(ctx context.Context, obj runtime.Object, handle fwk.Handle) (fwk.Plugin
 
 	scheme := runtime.NewScheme()
 	_ = v1alpha1.AddToScheme(scheme)
-	c, _, err := util.NewClientWithCachedReader(ctx, handle.KubeConfig(), scheme)
+	c, ccache, err := util.NewClientWithCachedReader(ctx, handle.KubeConfig(), scheme)
 	if err != nil {
 		return nil, err
 	}
@@ -92,12 +137,73 @@ func New(ctx context.Context, obj runtime.Object, handle fwk.Handle) (fwk.Plugin
 		// Keep the podInformer (from frameworkHandle) as the single source of Pods.
 		handle.SharedInformerFactory().Core().V1().Pods(),
 	)
+	if args.SimulatePodGroupPlacement {
+		pgMgr.EnablePlacementSimulation()
+	}
+	pgMgr.EnableCrossNamespacePodGroups(handle.SharedInformerFactory().Core().V1().Namespaces().Lister())
+	go pgMgr.WritePodGroupConditions(ctx)
 	plugin := &Coscheduling{
 		logger:            lh,
 		frameworkHandler:  handle,
 		pgMgr:             pgMgr,
 		scheduleTimeout:   &scheduleTimeDuration,
 		pgRejectThreshold: float64(args.PodGroupRejectPercentage) / 100.0,
+		gangPreemption:    args.EnableGangPreemption,
+		gangReservation:   args.EnableGangReservation,
+	}
+	if args.EnableGangReservation {
+		pgMgr.EnableGangReservation()
+	}
+	var gangOrderings []core.GangOrdering
+	if args.GangAgingSeconds > 0 {
+		gangOrderings = append(gangOrderings,
+			core.NewAgingOrdering(pgMgr, time.Duration(args.GangAgingSeconds)*time.Second))
+	}
+	if args.GangOrderingPolicy != nil && *args.GangOrderingPolicy == config.GangOrderingDominantResourceFairness {
+		elasticQuotaInformer, err := ccache.GetInformer(ctx, &v1alpha1.ElasticQuota{})
+		if err != nil {
+			return nil, err
+		}
+		drf, err := core.NewDominantResourceFairness(
+			ctx,
+			c,
+			handle.SharedInformerFactory().Core().V1().Pods().Lister(),
+			handle.SharedInformerFactory().Core().V1().Nodes().Lister(),
+			handle.SharedInformerFactory().Core().V1().Pods().Informer(),
+			handle.SharedInformerFactory().Core().V1().Nodes().Informer(),
+			elasticQuotaInformer,
+		)
+		if err != nil {
+			return nil, err
+		}
+		gangOrderings = append(gangOrderings, drf)
+	}
+	plugin.queueRanks = core.NewQueueRanks(gangOrderings...)
+	if len(gangOrderings) != 0 {
+		// The ranks of a PodGroup are kept while its pods are queued; drop each pod once it is bound or deleted.
+		_, err := handle.SharedInformerFactory().Core().V1().Pods().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+			UpdateFunc: func(_, newObj interface{}) {
+				if pod, ok := newObj.(*v1.Pod); ok && pod.Spec.NodeName != "" {
+					plugin.queueRanks.Forget(pod)
+				}
+			},
+			DeleteFunc: func(obj interface{}) {
+				if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+					obj = tombstone.Obj
+				}
+				if pod, ok := obj.(*v1.Pod); ok {
+					plugin.queueRanks.Forget(pod)
+				}
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if args.EnableGangPreemption {
+		// The evaluator registers the PodDisruptionBudget informer so that it is started along with the others;
+		// only its PreemptPod is used, to evict the victims the same way the default preemption does.
+		plugin.preemptPod = preemption.NewEvaluator(Name, handle, nil, true).PreemptPod
 	}
 	if args.PodGroupBackoffSeconds < 0 {
 		err := fmt.Errorf("parse arguments failed")
@@ -128,14 +234,20 @@ func (cs *Coscheduling) Name() string {
 
 // Less is used to sort pods in the scheduling queue in the following order.
 // 1. Compare the priorities of Pods.
-// 2. Compare the initialization timestamps of PodGroups or Pods.
-// 3. Compare the keys of PodGroups/Pods: <namespace>/<podname>.
+// 2. Compare the ranks of the PodGroups or Pods by the configured gang orderings, taken when they were queued.
+// 3. Compare the initialization timestamps of PodGroups or Pods.
+// 4. Compare the keys of PodGroups/Pods: <namespace>/<podname>.
 func (cs *Coscheduling) Less(podInfo1, podInfo2 fwk.QueuedPodInfo) bool {
 	prio1 := corev1helpers.PodPriority(podInfo1.GetPodInfo().GetPod())
 	prio2 := corev1helpers.PodPriority(podInfo2.GetPodInfo().GetPod())
 	if prio1 != prio2 {
 		return prio1 > prio2
 	}
+	if cs.queueRanks != nil {
+		if c := cs.queueRanks.Compare(context.TODO(), podInfo1, podInfo2); c != 0 {
+			return c < 0
+		}
+	}
 	creationTime1 := cs.pgMgr.GetCreationTimestamp(context.TODO(), podInfo1.GetPodInfo().GetPod(), *podInfo1.GetInitialAttemptTimestamp())
 	creationTime2 := cs.pgMgr.GetCreationTimestamp(context.TODO(), podInfo2.GetPodInfo().GetPod(), *podInfo2.GetInitialAttemptTimestamp())
 	if creationTime1.Equal(creationTime2) {
@@ -147,6 +259,8 @@ func (cs *Coscheduling) Less(podInfo1, podInfo2 fwk.QueuedPodInfo) bool {
 // PreFilter performs the following validations.
 // 1. Whether the PodGroup that the Pod belongs to is on the deny list.
 // 2. Whether the total number of pods in a PodGroup is less than its `minMember`.
+// 3. Whether a topology domain can host the PodGroup, if it has a topology constraint.
+// It also records the capacity reserved for other PodGroups, for Filter to set it aside.
 func (cs *Coscheduling) PreFilter(ctx context.Context, state fwk.CycleState, pod *v1.Pod, nodes []fwk.NodeInfo) (*fwk.PreFilterResult, *fwk.Status) {
 	lh := klog.FromContext(klog.NewContext(ctx, cs.logger)).WithValues("ExtensionPoint", "PreFilter")
 	// If PreFilter fails, return framework.UnschedulableAndUnresolvable to avoid
@@ -155,9 +269,78 @@ func (cs *Coscheduling) PreFilter(ctx context.Context, state fwk.CycleState, pod
 		lh.Error(err, "PreFilter failed", "pod", klog.KObj(pod))
 		return nil, fwk.NewStatus(fwk.UnschedulableAndUnresolvable, err.Error())
 	}
+
+	if cs.gangReservation {
+		if reserved := cs.pgMgr.ReservedCapacity(util.GetPodGroupFullName(pod), corev1helpers.PodPriority(pod)); len(reserved) != 0 {
+			state.Write(reservationStateKey, &reservationState{reserved: reserved})
+		}
+	}
+
+	pgName, pg := cs.pgMgr.GetPodGroup(ctx, pod)
+	if pg == nil || pg.Spec.TopologyConstraint == nil {
+		return nil, fwk.NewStatus(fwk.Success, "")
+	}
+	domain, err := cs.pgMgr.GetTopologyDomain(ctx, pgName, pg, pod)
+	if err != nil {
+		lh.Error(err, "PreFilter failed", "pod", klog.KObj(pod))
+		return nil, fwk.NewStatus(fwk.UnschedulableAndUnresolvable, err.Error())
+	}
+	if domain != "" {
+		state.Write(topologyStateKey, &topologyState{
+			key:    pg.Spec.TopologyConstraint.TopologyKey,
+			domain: domain,
+			mode:   pg.Spec.TopologyConstraint.Mode,
+		})
+	}
 	return nil, fwk.NewStatus(fwk.Success, "")
 }
 
+// Filter rejects the nodes outside the topology domain of the PodGroup, if its topology constraint is required,
+// and the nodes the pod does not fit on beside the capacity reserved for other PodGroups.
+func (cs *Coscheduling) Filter(ctx context.Context, state fwk.CycleState, pod *v1.Pod, nodeInfo fwk.NodeInfo) *fwk.Status {
+	if c, err := state.Read(reservationStateKey); err == nil {
+		if s, ok := c.(*reservationState); ok {
+			if reserved, ok := s.reserved[nodeInfo.Node().Name]; ok && !core.FitsBesideReservation(nodeInfo, pod, reserved) {
+				return fwk.NewStatus(fwk.Unschedulable, ErrReasonReservedCapacity)
+			}
+		}
+	}
+	s, ok := readTopologyState(state)
+	if !ok || s.mode == v1alpha1.TopologyConstraintPreferred {
+		return nil
+	}
+	if nodeInfo.Node().Labels[s.key] != s.domain {
+		return fwk.NewStatus(fwk.UnschedulableAndUnresolvable, ErrReasonTopologyDomain)
+	}
+	return nil
+}
+
+// Score favors the nodes within the topology domain of the PodGroup, if its topology constraint is preferred.
+func (cs *Coscheduling) Score(ctx context.Context, state fwk.CycleState, pod *v1.Pod, nodeInfo fwk.NodeInfo) (int64, *fwk.Status) {
+	s, ok := readTopologyState(state)
+	if !ok || s.mode != v1alpha1.TopologyConstraintPreferred {
+		return 0, nil
+	}
+	if nodeInfo.Node().Labels[s.key] == s.domain {
+		return fwk.MaxNodeScore, nil
+	}
+	return 0, nil
+}
+
+// ScoreExtensions of the Score plugin.
+func (cs *Coscheduling) ScoreExtensions() fwk.ScoreExtensions {
+	return nil
+}
+
+func readTopologyState(state fwk.CycleState) (*topologyState, bool) {
+	c, err := state.Read(topologyStateKey)
+	if err != nil {
+		return nil, false
+	}
+	s, ok := c.(*topologyState)
+	return s, ok
+}
+
 // PostFilter is used to reject a group of pods if a pod does not pass PreFilter or Filter.
 func (cs *Coscheduling) PostFilter(ctx context.Context, state fwk.CycleState, pod *v1.Pod,
 	filteredNodeStatusReader fwk.NodeToStatusReader) (*fwk.PostFilterResult, *fwk.Status) {
@@ -171,11 +354,19 @@ func (cs *Coscheduling) PostFilter(ctx context.Context, state fwk.CycleState, po
 	// This indicates there are already enough Pods satisfying the PodGroup,
 	// so don't bother to reject the whole PodGroup.
 	assigned := cs.pgMgr.GetAssignedPodCount(pgName)
-	if assigned >= int(pg.Spec.MinMember) {
+	if cs.pgMgr.QuorumReached(ctx, pgName, pg) {
 		lh.V(4).Info("Assigned pods", "podGroup", klog.KObj(pg), "assigned", assigned)
 		return &fwk.PostFilterResult{}, fwk.NewStatus(fwk.Unschedulable)
 	}
 
+	if cs.gangPreemption {
+		result, err := cs.preemptForPodGroup(ctx, state, pod, pgName, pg, assigned, filteredNodeStatusReader)
+		if err == nil {
+			return result, fwk.NewStatus(fwk.Success)
+		}
+		lh.V(4).Info("Gang preemption failed", "podGroup", klog.KObj(pg), "err", err)
+	}
+
 	// If the gap is less than/equal the reject threshold, we may want to try subsequent Pods
 	// to see they can satisfy the PodGroup
 	notAssignedPercentage := float64(int(pg.Spec.MinMember)-assigned) / float64(pg.Spec.MinMember)
@@ -187,16 +378,14 @@ func (cs *Coscheduling) PostFilter(ctx context.Context, state fwk.CycleState, po
 	// It's based on an implicit assumption: if the nth Pod failed,
 	// it's inferrable other Pods belonging to the same PodGroup would be very likely to fail.
 	cs.frameworkHandler.IterateOverWaitingPods(func(waitingPod fwk.WaitingPod) {
-		if waitingPod.GetPod().Namespace == pod.Namespace && util.GetPodGroupLabel(waitingPod.GetPod()) == pg.Name {
+		if util.GetPodGroupFullName(waitingPod.GetPod()) == pgName {
 			lh.V(3).Info("PostFilter rejects the pod", "podGroup", klog.KObj(pg), "pod", klog.KObj(waitingPod.GetPod()))
 			waitingPod.Reject(cs.Name(), "optimistic rejection in PostFilter")
 		}
 	})
 
 	if cs.pgBackoff != nil {
-		pods, err := cs.frameworkHandler.SharedInformerFactory().Core().V1().Pods().Lister().Pods(pod.Namespace).List(
-			labels.SelectorFromSet(labels.Set{v1alpha1.PodGroupLabel: util.GetPodGroupLabel(pod)}),
-		)
+		pods, err := cs.pgMgr.ListPodGroupPods(pg)
 		if err == nil && len(pods) >= int(pg.Spec.MinMember) {
 			cs.pgMgr.BackoffPodGroup(pgName, *cs.pgBackoff)
 		}
@@ -204,8 +393,265 @@ func (cs *Coscheduling) PostFilter(ctx context.Context, state fwk.CycleState, po
 
 	cs.pgMgr.DeletePermittedPodGroup(ctx, pgName)
 	cs.pgMgr.MarkPodGroupScheduleFailure(pgName)
-	return &fwk.PostFilterResult{}, fwk.NewStatus(fwk.Unschedulable,
-		fmt.Sprintf("PodGroup %v gets rejected due to Pod %v is unschedulable even after PostFilter", pgName, pod.Name))
+	cs.pgMgr.ResetTopologyDomain(pgName)
+	cs.pgMgr.ReleaseCapacity(pgName)
+	msg := fmt.Sprintf("PodGroup %v gets rejected due to Pod %v is unschedulable even after PostFilter", pgName, pod.Name)
+	cs.pgMgr.SetPodGroupConditions(ctx, pg, metav1.Condition{
+		Type:    v1alpha1.PodGroupBackoff,
+		Status:  metav1.ConditionTrue,
+		Reason:  v1alpha1.PodGroupReasonRejected,
+		Message: msg,
+	})
+	return &fwk.PostFilterResult{}, fwk.NewStatus(fwk.Unschedulable, msg)
+}
+
+// preemptForPodGroup evicts lower-priority pods so that the pending members of the PodGroup needed
+// to reach its minMember fit at once, and nominates each of them to its node. The siblings of the
+// given pod are moved back to activeQ. Only the nodes where preemption may help the pod, according to
+// <filteredNodeStatusReader>, are considered, and the whole plan is validated with the Filter plugins
+// before anything is evicted. Nothing is evicted if not all of the members can be placed.
+func (cs *Coscheduling) preemptForPodGroup(ctx context.Context, state fwk.CycleState, pod *v1.Pod, pgName string, pg *v1alpha1.PodGroup,
+	assigned int, filteredNodeStatusReader fwk.NodeToStatusReader) (*fwk.PostFilterResult, error) {
+	lh := klog.FromContext(klog.NewContext(ctx, cs.logger)).WithValues("ExtensionPoint", "PostFilter")
+	nodeInfos, err := cs.frameworkHandler.SnapshotSharedLister().NodeInfos().List()
+	if err != nil {
+		return nil, err
+	}
+	// Members already in the snapshot are assumed or bound.
+	placed := map[string]bool{}
+	for _, info := range nodeInfos {
+		for _, podInfo := range info.GetPods() {
+			if p := podInfo.GetPod(); util.GetPodGroupFullName(p) == pgName {
+				placed[core.GetNamespacedName(p)] = true
+			}
+		}
+	}
+	pods, err := cs.pgMgr.ListPodGroupPods(pg)
+	if err != nil {
+		return nil, err
+	}
+	members := []*v1.Pod{pod}
+	for _, p := range pods {
+		if p.UID == pod.UID || p.Spec.NodeName != "" || p.DeletionTimestamp != nil || placed[core.GetNamespacedName(p)] ||
+			len(p.Spec.SchedulingGates) != 0 {
+			continue
+		}
+		members = append(members, p)
+	}
+
+	// Evicting pods does not help on the nodes rejected as UnschedulableAndUnresolvable.
+	candidates, err := filteredNodeStatusReader.NodesForStatusCode(cs.frameworkHandler.SnapshotSharedLister().NodeInfos(), fwk.Unschedulable)
+	if err != nil {
+		return nil, err
+	}
+	pdbs, err := cs.frameworkHandler.SharedInformerFactory().Policy().V1().PodDisruptionBudgets().Lister().List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	preemption, err := core.SelectGangVictims(ctx, candidates, members, int(pg.Spec.MinMember)-assigned, pdbs)
+	if err != nil {
+		return nil, err
+	}
+	if err := cs.validateGangPreemption(ctx, state, pod, preemption); err != nil {
+		return nil, err
+	}
+
+	result := &fwk.PostFilterResult{}
+	siblings := map[string]*v1.Pod{}
+	nodeNames := map[string]string{}
+	for _, n := range preemption.Nominations {
+		nominatingInfo := &fwk.NominatingInfo{NominatedNodeName: n.NodeName, NominatingMode: fwk.ModeOverride}
+		if n.Pod.UID == pod.UID {
+			result.NominatingInfo = nominatingInfo
+			continue
+		}
+		podInfo, err := framework.NewPodInfo(n.Pod)
+		if err != nil {
+			return nil, err
+		}
+		cs.frameworkHandler.AddNominatedPod(lh, podInfo, nominatingInfo)
+		siblings[core.GetNamespacedName(n.Pod)] = n.Pod
+		nodeNames[core.GetNamespacedName(n.Pod)] = n.NodeName
+	}
+	cs.evictGangVictims(pod, pg, preemption)
+	if len(siblings) != 0 {
+		cs.patchNominatedNodeNames(siblings, nodeNames)
+		cs.frameworkHandler.Activate(lh, siblings)
+	}
+	return result, nil
+}
+
+// preFilterRunner is implemented by the scheduling framework. It prepares the cycle state the Filter
+// plugins need to check a sibling of the pod being scheduled.
+type preFilterRunner interface {
+	RunPreFilterPlugins(ctx context.Context, state fwk.CycleState, pod *v1.Pod) (*fwk.PreFilterResult, *fwk.Status, sets.Set[string])
+}
+
+// validateGangPreemption runs the Filter plugins for each member of <preemption> on a copy of the node
+// it is nominated to, with the victims evicted so far removed and the members nominated before it added.
+// The pod being scheduled is checked with a clone of <state>, its siblings with a state their own
+// PreFilter prepares. It returns an error if any member does not pass.
+func (cs *Coscheduling) validateGangPreemption(ctx context.Context, state fwk.CycleState, pod *v1.Pod,
+	preemption *core.GangPreemption) error {
+	lh := klog.FromContext(ctx)
+	runner, ok := cs.frameworkHandler.(preFilterRunner)
+	if !ok {
+		return fmt.Errorf("the framework cannot run the PreFilter plugins for the siblings of pod %v", klog.KObj(pod))
+	}
+	type simulation struct {
+		nodeInfo fwk.NodeInfo
+		removed  []fwk.PodInfo
+		added    []fwk.PodInfo
+	}
+	simulations := map[string]*simulation{}
+	for _, n := range preemption.Nominations {
+		sim, ok := simulations[n.NodeName]
+		if !ok {
+			nodeInfo, err := cs.frameworkHandler.SnapshotSharedLister().NodeInfos().Get(n.NodeName)
+			if err != nil || nodeInfo == nil {
+				return fmt.Errorf("node %v of the preemption plan not found: %v", n.NodeName, err)
+			}
+			sim = &simulation{nodeInfo: nodeInfo.Snapshot()}
+			simulations[n.NodeName] = sim
+			// Like in SelectGangVictims, the pods being deleted are deemed gone.
+			for _, podInfo := range nodeInfo.GetPods() {
+				if p := podInfo.GetPod(); p.DeletionTimestamp != nil {
+					if err := sim.nodeInfo.RemovePod(lh, p); err != nil {
+						return err
+					}
+					sim.removed = append(sim.removed, podInfo)
+				}
+			}
+		}
+		for _, victim := range n.Victims {
+			victimInfo, err := framework.NewPodInfo(victim)
+			if err != nil {
+				return err
+			}
+			if err := sim.nodeInfo.RemovePod(lh, victim); err != nil {
+				return err
+			}
+			sim.removed = append(sim.removed, victimInfo)
+		}
+
+		memberState := state.Clone()
+		if n.Pod.UID != pod.UID {
+			memberState = framework.NewCycleState()
+			result, status, _ := runner.RunPreFilterPlugins(ctx, memberState, n.Pod)
+			if !status.IsSuccess() {
+				return fmt.Errorf("pod %v does not pass PreFilter: %w", klog.KObj(n.Pod), status.AsError())
+			}
+			if !result.AllNodes() && !result.NodeNames.Has(n.NodeName) {
+				return fmt.Errorf("pod %v cannot be placed on node %v", klog.KObj(n.Pod), n.NodeName)
+			}
+		}
+		for _, victimInfo := range sim.removed {
+			if status := cs.frameworkHandler.RunPreFilterExtensionRemovePod(ctx, memberState, n.Pod, victimInfo, sim.nodeInfo); !status.IsSuccess() {
+				return status.AsError()
+			}
+		}
+		for _, memberInfo := range sim.added {
+			if status := cs.frameworkHandler.RunPreFilterExtensionAddPod(ctx, memberState, n.Pod, memberInfo, sim.nodeInfo); !status.IsSuccess() {
+				return status.AsError()
+			}
+		}
+		if status := cs.frameworkHandler.RunFilterPluginsWithNominatedPods(ctx, memberState, n.Pod, sim.nodeInfo); !status.IsSuccess() {
+			return fmt.Errorf("pod %v does not fit on node %v even after preemption: %w", klog.KObj(n.Pod), n.NodeName, status.AsError())
+		}
+
+		memberInfo, err := framework.NewPodInfo(n.Pod)
+		if err != nil {
+			return err
+		}
+		// The members nominated by a previous attempt are already added by the framework.
+		if !isNominatedTo(cs.frameworkHandler, n.Pod, n.NodeName) {
+			sim.nodeInfo.AddPodInfo(memberInfo)
+			sim.added = append(sim.added, memberInfo)
+		}
+	}
+	return nil
+}
+
+// isNominatedTo returns whether <pod> is already nominated to the node <nodeName>.
+func isNominatedTo(nominator fwk.PodNominator, pod *v1.Pod, nodeName string) bool {
+	for _, podInfo := range nominator.NominatedPodsForNode(nodeName) {
+		if podInfo.GetPod().UID == pod.UID {
+			return true
+		}
+	}
+	return false
+}
+
+// gangCandidate is the node a member of a PodGroup is nominated to, and the victims to evict from it.
+type gangCandidate struct {
+	nodeName string
+	victims  []*v1.Pod
+}
+
+func (c *gangCandidate) Victims() *extenderv1.Victims {
+	return &extenderv1.Victims{Pods: c.victims}
+}
+
+func (c *gangCandidate) Name() string {
+	return c.nodeName
+}
+
+// evictGangVictims evicts the victims of <preemption> in the background, the way the asynchronous
+// preemption of the framework does: the victims waiting at Permit are rejected, the others get the
+// DisruptionTarget condition and are deleted. If any eviction fails, <pod> is moved back to activeQ.
+func (cs *Coscheduling) evictGangVictims(pod *v1.Pod, pg *v1alpha1.PodGroup, preemption *core.GangPreemption) {
+	var candidates []*gangCandidate
+	var victims []*v1.Pod
+	for _, n := range preemption.Nominations {
+		c := &gangCandidate{nodeName: n.NodeName, victims: n.Victims}
+		for _, victim := range n.Victims {
+			candidates = append(candidates, c)
+			victims = append(victims, victim)
+		}
+	}
+	if len(victims) == 0 {
+		return
+	}
+
+	// This outlives the scheduling cycle, so it must not use its context.
+	ctx, cancel := context.WithCancel(context.Background())
+	lh := klog.FromContext(klog.NewContext(ctx, cs.logger)).WithValues("ExtensionPoint", "PostFilter")
+	go func() {
+		defer cancel()
+		errs := make([]error, len(victims))
+		cs.frameworkHandler.Parallelizer().Until(ctx, len(victims), func(i int) {
+			errs[i] = cs.preemptPod(ctx, candidates[i], pod, victims[i], cs.Name())
+		}, cs.Name())
+		if err := utilerrors.NewAggregate(errs); err != nil {
+			lh.Error(err, "Evicting the victims of gang preemption", "podGroup", klog.KObj(pg))
+			cs.frameworkHandler.Activate(lh, map[string]*v1.Pod{core.GetNamespacedName(pod): pod})
+			return
+		}
+		lh.V(3).Info("Preempted pods for PodGroup", "podGroup", klog.KObj(pg), "victims", len(victims))
+	}()
+}
+
+// patchNominatedNodeNames sets the nodes the siblings are nominated to, by the namespaced name of the pod,
+// in their status in the background, the way the scheduler does for the pod being scheduled, so that the
+// plugins watching the pods, e.g. CapacityScheduling, know about these nominations as well.
+func (cs *Coscheduling) patchNominatedNodeNames(siblings map[string]*v1.Pod, nodeNames map[string]string) {
+	// This outlives the scheduling cycle, so it must not use its context.
+	ctx, cancel := context.WithCancel(context.Background())
+	lh := klog.FromContext(klog.NewContext(ctx, cs.logger)).WithValues("ExtensionPoint", "PostFilter")
+	go func() {
+		defer cancel()
+		for name, p := range siblings {
+			nodeName := nodeNames[name]
+			if p.Status.NominatedNodeName == nodeName {
+				continue
+			}
+			newStatus := p.Status.DeepCopy()
+			newStatus.NominatedNodeName = nodeName
+			if err := schedutil.PatchPodStatus(ctx, cs.frameworkHandler.ClientSet(), p.Name, p.Namespace, &p.Status, newStatus); err != nil {
+				lh.Error(err, "Setting the nominated node of a sibling", "pod", klog.KObj(p), "node", nodeName)
+			}
+		}
+	}()
 }
 
 // PreFilterExtensions returns a PreFilterExtensions interface if the plugin implements one.
@@ -233,9 +679,11 @@ func (cs *Coscheduling) Permit(ctx context.Context, state fwk.CycleState, pod *v
 		retStatus = fwk.NewStatus(fwk.Wait)
 		// We will also request to move the sibling pods back to activeQ.
 		cs.pgMgr.ActivateSiblings(ctx, pod, state)
+		cs.pgMgr.ReserveCapacity(ctx, util.GetPodGroupFullName(pod), pg, pod, nodeName)
 	case core.Success:
 		pgFullName := util.GetPodGroupFullName(pod)
 		cs.pgMgr.ClearPodGroupScheduleFailure(pgFullName)
+		cs.pgMgr.ReleaseCapacity(pgFullName)
 		cs.frameworkHandler.IterateOverWaitingPods(func(waitingPod fwk.WaitingPod) {
 			if util.GetPodGroupFullName(waitingPod.GetPod()) == pgFullName {
 				lh.V(3).Info("Permit allows", "pod", klog.KObj(waitingPod.GetPod()))
@@ -243,6 +691,7 @@ func (cs *Coscheduling) Permit(ctx context.Context, state fwk.CycleState, pod *v
 			}
 		})
 		lh.V(3).Info("Permit allows", "pod", klog.KObj(pod))
+		// The PodGroup controller sets the Scheduled condition, and clears Backoff, once the members are bound.
 		retStatus = fwk.NewStatus(fwk.Success)
 		waitTime = 0
 	}
@@ -251,7 +700,9 @@ func (cs *Coscheduling) Permit(ctx context.Context, state fwk.CycleState, pod *v
 }
 
 // Reserve is the functions invoked by the framework at "reserve" extension point.
+// It records the topology domain of the node as the one of the PodGroup, if none was chosen yet.
 func (cs *Coscheduling) Reserve(ctx context.Context, state fwk.CycleState, pod *v1.Pod, nodeName string) *fwk.Status {
+	cs.pgMgr.RecordTopologyDomain(ctx, pod, nodeName)
 	return nil
 }
 
@@ -264,11 +715,18 @@ func (cs *Coscheduling) Unreserve(ctx context.Context, state fwk.CycleState, pod
 	}
 	cs.pgMgr.Unreserve(ctx, pod)
 	cs.frameworkHandler.IterateOverWaitingPods(func(waitingPod fwk.WaitingPod) {
-		if waitingPod.GetPod().Namespace == pod.Namespace && util.GetPodGroupLabel(waitingPod.GetPod()) == pg.Name {
+		if util.GetPodGroupFullName(waitingPod.GetPod()) == pgName {
 			lh.V(3).Info("Unreserve rejects", "pod", klog.KObj(waitingPod.GetPod()), "podGroup", klog.KObj(pg))
 			waitingPod.Reject(cs.Name(), "rejection in Unreserve")
 		}
 	})
 	cs.pgMgr.DeletePermittedPodGroup(ctx, pgName)
 	cs.pgMgr.MarkPodGroupScheduleFailure(pgName)
+	cs.pgMgr.ReleaseCapacity(pgName)
+	cs.pgMgr.SetPodGroupConditions(ctx, pg, metav1.Condition{
+		Type:    v1alpha1.PodGroupBackoff,
+		Status:  metav1.ConditionTrue,
+		Reason:  v1alpha1.PodGroupReasonUnreserved,
+		Message: fmt.Sprintf("PodGroup %v gets rejected due to a member timed out in Permit or failed to be bound", pgName),
+	})
 }
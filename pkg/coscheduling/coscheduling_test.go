@@ -26,15 +26,21 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/informers"
 	clientsetfake "k8s.io/client-go/kubernetes/fake"
 	clicache "k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/klog/v2"
 	fwk "k8s.io/kube-scheduler/framework"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
 	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/defaultbinder"
 	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/queuesort"
+	"k8s.io/kubernetes/pkg/scheduler/framework/preemption"
 	fwkruntime "k8s.io/kubernetes/pkg/scheduler/framework/runtime"
+	"k8s.io/kubernetes/pkg/scheduler/metrics"
 	st "k8s.io/kubernetes/pkg/scheduler/testing"
 	tf "k8s.io/kubernetes/pkg/scheduler/testing/framework"
 	"k8s.io/utils/pointer"
@@ -599,6 +605,13 @@ func TestLessAfterScheduleFailure(t *testing.T) {
 	if got := pl.Less(p1, p2); !got {
 		t.Errorf("After clear: expected Less(p1, p2) = true, got false")
 	}
+
+	// With aging, PG1 has waited for an interval unlike PG2, and goes first despite its failure.
+	pgMgr.MarkPodGroupScheduleFailure("ns/pg1")
+	pl.queueRanks = core.NewQueueRanks(core.NewAgingOrdering(pgMgr, 8*time.Second))
+	if got := pl.Less(p1, p2); !got {
+		t.Errorf("With aging: expected Less(p1, p2) = true, got false")
+	}
 }
 
 func TestPostFilter(t *testing.T) {
@@ -876,3 +889,379 @@ func TestPostFilterRejectThreshold(t *testing.T) {
 		})
 	}
 }
+
+func TestTopologyConstraint(t *testing.T) {
+	const rackKey = "topology.example.com/rack"
+	scheduleTimeout := 10 * time.Second
+	capacity := map[v1.ResourceName]string{
+		v1.ResourceCPU: "4",
+	}
+	nodes := []*v1.Node{
+		st.MakeNode().Name("node-a").Label(rackKey, "rack-a").Capacity(capacity).Obj(),
+		st.MakeNode().Name("node-b1").Label(rackKey, "rack-b").Capacity(capacity).Obj(),
+		st.MakeNode().Name("node-b2").Label(rackKey, "rack-b").Capacity(capacity).Obj(),
+		st.MakeNode().Name("node-unlabeled").Capacity(capacity).Obj(),
+	}
+	makePod := func(name string) *v1.Pod {
+		return st.MakePod().Name(name).Namespace("ns").UID(name).Label(v1alpha1.PodGroupLabel, "pg1").
+			Req(map[v1.ResourceName]string{v1.ResourceCPU: "3"}).Obj()
+	}
+
+	tests := []struct {
+		name       string
+		mode       v1alpha1.TopologyConstraintMode
+		wantFilter map[string]fwk.Code
+		wantScore  map[string]int64
+	}{
+		{
+			name: "required",
+			mode: v1alpha1.TopologyConstraintRequired,
+			wantFilter: map[string]fwk.Code{
+				"node-a":         fwk.UnschedulableAndUnresolvable,
+				"node-b1":        fwk.Success,
+				"node-b2":        fwk.Success,
+				"node-unlabeled": fwk.UnschedulableAndUnresolvable,
+			},
+			wantScore: map[string]int64{"node-a": 0, "node-b1": 0, "node-b2": 0, "node-unlabeled": 0},
+		},
+		{
+			name: "preferred",
+			mode: v1alpha1.TopologyConstraintPreferred,
+			wantFilter: map[string]fwk.Code{
+				"node-a":         fwk.Success,
+				"node-b1":        fwk.Success,
+				"node-b2":        fwk.Success,
+				"node-unlabeled": fwk.Success,
+			},
+			wantScore: map[string]int64{"node-a": 0, "node-b1": fwk.MaxNodeScore, "node-b2": fwk.MaxNodeScore, "node-unlabeled": 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			pods := []*v1.Pod{makePod("p1"), makePod("p2")}
+			pg := tu.MakePodGroup().Name("pg1").Namespace("ns").MinMember(2).TopologyConstraint(rackKey, tt.mode).Obj()
+			client, err := tu.NewFakeClient(pg, pods[0], pods[1])
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			cs := clientsetfake.NewSimpleClientset()
+			informerFactory := informers.NewSharedInformerFactory(cs, 0)
+			podInformer := informerFactory.Core().V1().Pods()
+			snapshot := tu.NewFakeSharedLister(nil, nodes)
+			pl := &Coscheduling{
+				pgMgr:           core.NewPodGroupManager(client, snapshot, &scheduleTimeout, podInformer),
+				scheduleTimeout: &scheduleTimeout,
+			}
+			informerFactory.Start(ctx.Done())
+			if !clicache.WaitForCacheSync(ctx.Done(), podInformer.Informer().HasSynced) {
+				t.Fatal("WaitForCacheSync failed")
+			}
+			for _, p := range pods {
+				podInformer.Informer().GetStore().Add(p)
+			}
+
+			state := framework.NewCycleState()
+			if _, status := pl.PreFilter(ctx, state, pods[0], nil); !status.IsSuccess() {
+				t.Fatalf("PreFilter: %v", status)
+			}
+			for _, node := range nodes {
+				nodeInfo, err := snapshot.NodeInfos().Get(node.Name)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := pl.Filter(ctx, state, pods[0], nodeInfo).Code(); got != tt.wantFilter[node.Name] {
+					t.Errorf("Filter(%v) = %v, want %v", node.Name, got, tt.wantFilter[node.Name])
+				}
+				if got, _ := pl.Score(ctx, state, pods[0], nodeInfo); got != tt.wantScore[node.Name] {
+					t.Errorf("Score(%v) = %v, want %v", node.Name, got, tt.wantScore[node.Name])
+				}
+			}
+		})
+	}
+}
+
+func TestGangReservation(t *testing.T) {
+	scheduleTimeout := 10 * time.Second
+	capacity := map[v1.ResourceName]string{
+		v1.ResourceCPU: "4",
+	}
+	nodes := []*v1.Node{
+		st.MakeNode().Name("node-a").Capacity(capacity).Obj(),
+		st.MakeNode().Name("node-b").Capacity(capacity).Obj(),
+	}
+	member := func(name string) *v1.Pod {
+		return st.MakePod().Name(name).Namespace("ns").UID(name).Label(v1alpha1.PodGroupLabel, "pg1").
+			Req(map[v1.ResourceName]string{v1.ResourceCPU: "2"}).Obj()
+	}
+	outsider := func(cpu string) *v1.Pod {
+		return st.MakePod().Name("outsider").Namespace("ns").UID("outsider").
+			Req(map[v1.ResourceName]string{v1.ResourceCPU: cpu}).Obj()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	members := []*v1.Pod{member("m1"), member("m2"), member("m3")}
+	pg := tu.MakePodGroup().Name("pg1").Namespace("ns").MinMember(3).Obj()
+	client, err := tu.NewFakeClient(pg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs := clientsetfake.NewSimpleClientset()
+	informerFactory := informers.NewSharedInformerFactory(cs, 0)
+	podInformer := informerFactory.Core().V1().Pods()
+	snapshot := tu.NewFakeSharedLister(nil, nodes)
+	pgMgr := core.NewPodGroupManager(client, snapshot, &scheduleTimeout, podInformer)
+	pgMgr.EnableGangReservation()
+	pl := &Coscheduling{
+		pgMgr:           pgMgr,
+		scheduleTimeout: &scheduleTimeout,
+		gangReservation: true,
+	}
+	informerFactory.Start(ctx.Done())
+	if !clicache.WaitForCacheSync(ctx.Done(), podInformer.Informer().HasSynced) {
+		t.Fatal("WaitForCacheSync failed")
+	}
+	for _, p := range members {
+		podInformer.Informer().GetStore().Add(p)
+	}
+
+	// m1 waits on node-a: the 2 other members needed are reserved on node-a and node-b.
+	if status, _ := pl.Permit(ctx, framework.NewCycleState(), members[0], "node-a"); status.Code() != fwk.Wait {
+		t.Fatalf("Permit() = %v, want Wait", status)
+	}
+
+	tests := []struct {
+		name string
+		pod  *v1.Pod
+		want map[string]fwk.Code
+	}{
+		{
+			name: "pod outside the PodGroup not fitting beside the reservation",
+			pod:  outsider("3"),
+			want: map[string]fwk.Code{"node-a": fwk.Unschedulable, "node-b": fwk.Unschedulable},
+		},
+		{
+			name: "pod outside the PodGroup fitting beside the reservation",
+			pod:  outsider("2"),
+			want: map[string]fwk.Code{"node-a": fwk.Success, "node-b": fwk.Success},
+		},
+		{
+			name: "pod outside the PodGroup of a higher priority ignoring the reservation",
+			pod:  st.MakePod().Name("outsider").Namespace("ns").UID("outsider").Priority(100).Req(map[v1.ResourceName]string{v1.ResourceCPU: "3"}).Obj(),
+			want: map[string]fwk.Code{"node-a": fwk.Success, "node-b": fwk.Success},
+		},
+		{
+			name: "member of the PodGroup",
+			pod:  members[1],
+			want: map[string]fwk.Code{"node-a": fwk.Success, "node-b": fwk.Success},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := framework.NewCycleState()
+			if _, status := pl.PreFilter(ctx, state, tt.pod, nil); !status.IsSuccess() {
+				t.Fatalf("PreFilter: %v", status)
+			}
+			for _, node := range nodes {
+				nodeInfo, err := snapshot.NodeInfos().Get(node.Name)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := pl.Filter(ctx, state, tt.pod, nodeInfo).Code(); got != tt.want[node.Name] {
+					t.Errorf("Filter(%v) = %v, want %v", node.Name, got, tt.want[node.Name])
+				}
+			}
+		})
+	}
+}
+
+// fakePodActivator records the pods moved back to activeQ.
+type fakePodActivator struct {
+	activated map[string]*v1.Pod
+}
+
+func (a *fakePodActivator) Activate(_ klog.Logger, pods map[string]*v1.Pod) {
+	for k, p := range pods {
+		a.activated[k] = p
+	}
+}
+
+func TestGangPreemption(t *testing.T) {
+	scheduleTimeout := 10 * time.Second
+	capacity := map[v1.ResourceName]string{
+		v1.ResourceCPU: "4",
+	}
+	nodes := []*v1.Node{
+		st.MakeNode().Name("node-a").Capacity(capacity).Obj(),
+		st.MakeNode().Name("node-b").Capacity(capacity).Obj(),
+	}
+	member := func(name string) *v1.Pod {
+		return st.MakePod().Name(name).Namespace("ns").UID(name).Label(v1alpha1.PodGroupLabel, "pg1").Priority(100).
+			Req(map[v1.ResourceName]string{v1.ResourceCPU: "3"}).Obj()
+	}
+	running := func(name, node string, priority int32) *v1.Pod {
+		return st.MakePod().Name(name).Namespace("ns").UID(name).Node(node).Priority(priority).
+			Req(map[v1.ResourceName]string{v1.ResourceCPU: "2"}).Obj()
+	}
+
+	tests := []struct {
+		name         string
+		existingPods []*v1.Pod
+		// statuses are the codes the nodes were filtered out with, Unschedulable if missing.
+		statuses map[string]fwk.Code
+		// filterFailures are the codes a Filter plugin rejects the nodes with.
+		filterFailures map[string]fwk.Code
+		wantCode       fwk.Code
+		wantNominated  string
+		wantVictims    []string
+		wantActivated  []string
+	}{
+		{
+			name:          "victims are evicted for the whole gang",
+			existingPods:  []*v1.Pod{running("low-a", "node-a", 10), running("low-b", "node-b", 10)},
+			wantCode:      fwk.Success,
+			wantNominated: "node-a",
+			wantVictims:   []string{"low-a", "low-b"},
+			wantActivated: []string{"ns/p2"},
+		},
+		{
+			name:         "nothing is evicted if the gang does not fit",
+			existingPods: []*v1.Pod{running("low-a", "node-a", 10), running("high-b", "node-b", 200)},
+			wantCode:     fwk.Unschedulable,
+		},
+		{
+			name:         "nodes unschedulable and unresolvable are not considered",
+			existingPods: []*v1.Pod{running("low-a", "node-a", 10), running("low-b", "node-b", 10)},
+			statuses:     map[string]fwk.Code{"node-b": fwk.UnschedulableAndUnresolvable},
+			wantCode:     fwk.Unschedulable,
+		},
+		{
+			name:           "nothing is evicted if a Filter plugin rejects the plan",
+			existingPods:   []*v1.Pod{running("low-a", "node-a", 10), running("low-b", "node-b", 10)},
+			filterFailures: map[string]fwk.Code{"node-b": fwk.Unschedulable},
+			wantCode:       fwk.Unschedulable,
+		},
+	}
+
+	metrics.Register()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			members := []*v1.Pod{member("p1"), member("p2")}
+			pg := tu.MakePodGroup().Name("pg1").Namespace("ns").MinMember(2).Obj()
+			client, err := tu.NewFakeClient(pg, members[0], members[1])
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var objs []runtime.Object
+			for _, p := range append(tt.existingPods, members...) {
+				objs = append(objs, p)
+			}
+			cs := clientsetfake.NewSimpleClientset(objs...)
+			informerFactory := informers.NewSharedInformerFactory(cs, 0)
+			podInformer := informerFactory.Core().V1().Pods()
+			pdbInformer := informerFactory.Policy().V1().PodDisruptionBudgets()
+			snapshot := tu.NewFakeSharedLister(tt.existingPods, nodes)
+			nominator := tu.NewPodNominator(nil)
+			activator := &fakePodActivator{activated: map[string]*v1.Pod{}}
+			f, err := tf.NewFramework(
+				ctx,
+				[]tf.RegisterPluginFunc{
+					tf.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+					tf.RegisterFilterPlugin("FakeFilter", tf.NewFakeFilterPlugin(tt.filterFailures)),
+					tf.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+				},
+				"default-scheduler",
+				fwkruntime.WithClientSet(cs),
+				fwkruntime.WithInformerFactory(informerFactory),
+				fwkruntime.WithSnapshotSharedLister(snapshot),
+				fwkruntime.WithPodNominator(nominator),
+				fwkruntime.WithPodActivator(activator),
+				fwkruntime.WithWaitingPods(fwkruntime.NewWaitingPodsMap()),
+				fwkruntime.WithEventRecorder(events.NewFakeRecorder(100)),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			pl := &Coscheduling{
+				frameworkHandler: f,
+				pgMgr:            core.NewPodGroupManager(client, snapshot, &scheduleTimeout, podInformer),
+				scheduleTimeout:  &scheduleTimeout,
+				gangPreemption:   true,
+				preemptPod:       preemption.NewEvaluator(Name, f, nil, true).PreemptPod,
+			}
+			informerFactory.Start(ctx.Done())
+			if !clicache.WaitForCacheSync(ctx.Done(), podInformer.Informer().HasSynced, pdbInformer.Informer().HasSynced) {
+				t.Fatal("WaitForCacheSync failed")
+			}
+
+			statuses := framework.NewNodeToStatus(map[string]*fwk.Status{}, fwk.NewStatus(fwk.UnschedulableAndUnresolvable))
+			for _, node := range nodes {
+				code, ok := tt.statuses[node.Name]
+				if !ok {
+					code = fwk.Unschedulable
+				}
+				statuses.Set(node.Name, fwk.NewStatus(code))
+			}
+			result, status := pl.PostFilter(ctx, framework.NewCycleState(), members[0], statuses)
+			if status.Code() != tt.wantCode {
+				t.Fatalf("PostFilter() code = %v, want %v: %v", status.Code(), tt.wantCode, status)
+			}
+			var nominated string
+			if result != nil && result.NominatingInfo != nil {
+				nominated = result.NominatingInfo.NominatedNodeName
+			}
+			if nominated != tt.wantNominated {
+				t.Errorf("Nominated node = %q, want %q", nominated, tt.wantNominated)
+			}
+
+			// The victims are evicted in the background.
+			var victims []string
+			_ = wait.PollUntilContextTimeout(ctx, 10*time.Millisecond, time.Second, true, func(ctx context.Context) (bool, error) {
+				victims = nil
+				for _, p := range tt.existingPods {
+					if _, err := cs.CoreV1().Pods(p.Namespace).Get(ctx, p.Name, metav1.GetOptions{}); err != nil {
+						victims = append(victims, p.Name)
+					}
+				}
+				return len(victims) >= len(tt.wantVictims), nil
+			})
+			if diff := cmp.Diff(tt.wantVictims, victims); diff != "" {
+				t.Errorf("Unexpected victims (-want,+got):\n%s", diff)
+			}
+			var activated []string
+			for k := range activator.activated {
+				activated = append(activated, k)
+			}
+			if diff := cmp.Diff(tt.wantActivated, activated); diff != "" {
+				t.Errorf("Unexpected activated pods (-want,+got):\n%s", diff)
+			}
+			if len(tt.wantActivated) != 0 && len(nominator.NominatedPodsForNode("node-b")) != 1 {
+				t.Errorf("Expected a sibling nominated to node-b, got %v", nominator.NominatedPodsForNode("node-b"))
+			}
+			if len(tt.wantActivated) != 0 {
+				// The status of the sibling is patched in the background.
+				var nominatedNodeName string
+				_ = wait.PollUntilContextTimeout(ctx, 10*time.Millisecond, time.Second, true, func(ctx context.Context) (bool, error) {
+					p2, err := cs.CoreV1().Pods("ns").Get(ctx, "p2", metav1.GetOptions{})
+					if err != nil {
+						return false, err
+					}
+					nominatedNodeName = p2.Status.NominatedNodeName
+					return nominatedNodeName != "", nil
+				})
+				if nominatedNodeName != "node-b" {
+					t.Errorf("Expected the status of the sibling to tell node-b, got %q", nominatedNodeName)
+				}
+			}
+		})
+	}
+}
@@ -20,13 +20,19 @@ package v1alpha1
 
 import (
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/client-go/applyconfigurations/meta/v1"
 )
 
 // ElasticQuotaSpecApplyConfiguration represents a declarative configuration of the ElasticQuotaSpec type for use
 // with apply.
 type ElasticQuotaSpecApplyConfiguration struct {
-	Min *v1.ResourceList `json:"min,omitempty"`
-	Max *v1.ResourceList `json:"max,omitempty"`
+	Min                *v1.ResourceList                         `json:"min,omitempty"`
+	Max                *v1.ResourceList                         `json:"max,omitempty"`
+	Parent             *ElasticQuotaReferenceApplyConfiguration `json:"parent,omitempty"`
+	Selector           *metav1.LabelSelectorApplyConfiguration  `json:"selector,omitempty"`
+	PriorityClassNames []string                                 `json:"priorityClassNames,omitempty"`
+	Weight             *int32                                   `json:"weight,omitempty"`
+	Pools              []ElasticQuotaPoolApplyConfiguration     `json:"pools,omitempty"`
 }
 
 // ElasticQuotaSpecApplyConfiguration constructs a declarative configuration of the ElasticQuotaSpec type for use with
@@ -50,3 +56,50 @@ func (b *ElasticQuotaSpecApplyConfiguration) WithMax(value v1.ResourceList) *Ela
 	b.Max = &value
 	return b
 }
+
+// WithParent sets the Parent field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Parent field is set to the value of the last call.
+func (b *ElasticQuotaSpecApplyConfiguration) WithParent(value *ElasticQuotaReferenceApplyConfiguration) *ElasticQuotaSpecApplyConfiguration {
+	b.Parent = value
+	return b
+}
+
+// WithSelector sets the Selector field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Selector field is set to the value of the last call.
+func (b *ElasticQuotaSpecApplyConfiguration) WithSelector(value *metav1.LabelSelectorApplyConfiguration) *ElasticQuotaSpecApplyConfiguration {
+	b.Selector = value
+	return b
+}
+
+// WithPriorityClassNames adds the given value to the PriorityClassNames field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the PriorityClassNames field.
+func (b *ElasticQuotaSpecApplyConfiguration) WithPriorityClassNames(values ...string) *ElasticQuotaSpecApplyConfiguration {
+	for i := range values {
+		b.PriorityClassNames = append(b.PriorityClassNames, values[i])
+	}
+	return b
+}
+
+// WithWeight sets the Weight field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Weight field is set to the value of the last call.
+func (b *ElasticQuotaSpecApplyConfiguration) WithWeight(value int32) *ElasticQuotaSpecApplyConfiguration {
+	b.Weight = &value
+	return b
+}
+
+// WithPools adds the given value to the Pools field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Pools field.
+func (b *ElasticQuotaSpecApplyConfiguration) WithPools(values ...*ElasticQuotaPoolApplyConfiguration) *ElasticQuotaSpecApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithPools")
+		}
+		b.Pools = append(b.Pools, *values[i])
+	}
+	return b
+}
@@ -25,7 +25,10 @@ import (
 // ElasticQuotaStatusApplyConfiguration represents a declarative configuration of the ElasticQuotaStatus type for use
 // with apply.
 type ElasticQuotaStatusApplyConfiguration struct {
-	Used *v1.ResourceList `json:"used,omitempty"`
+	Used       *v1.ResourceList `json:"used,omitempty"`
+	Borrowed   *v1.ResourceList `json:"borrowed,omitempty"`
+	LentOut    *v1.ResourceList `json:"lentOut,omitempty"`
+	PodsAtRisk []string         `json:"podsAtRisk,omitempty"`
 }
 
 // ElasticQuotaStatusApplyConfiguration constructs a declarative configuration of the ElasticQuotaStatus type for use with
@@ -41,3 +44,29 @@ func (b *ElasticQuotaStatusApplyConfiguration) WithUsed(value v1.ResourceList) *
 	b.Used = &value
 	return b
 }
+
+// WithBorrowed sets the Borrowed field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Borrowed field is set to the value of the last call.
+func (b *ElasticQuotaStatusApplyConfiguration) WithBorrowed(value v1.ResourceList) *ElasticQuotaStatusApplyConfiguration {
+	b.Borrowed = &value
+	return b
+}
+
+// WithLentOut sets the LentOut field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the LentOut field is set to the value of the last call.
+func (b *ElasticQuotaStatusApplyConfiguration) WithLentOut(value v1.ResourceList) *ElasticQuotaStatusApplyConfiguration {
+	b.LentOut = &value
+	return b
+}
+
+// WithPodsAtRisk adds the given value to the PodsAtRisk field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the PodsAtRisk field.
+func (b *ElasticQuotaStatusApplyConfiguration) WithPodsAtRisk(values ...string) *ElasticQuotaStatusApplyConfiguration {
+	for i := range values {
+		b.PodsAtRisk = append(b.PodsAtRisk, values[i])
+	}
+	return b
+}
@@ -25,9 +25,13 @@ import (
 // PodGroupSpecApplyConfiguration represents a declarative configuration of the PodGroupSpec type for use
 // with apply.
 type PodGroupSpecApplyConfiguration struct {
-	MinMember              *int32           `json:"minMember,omitempty"`
-	MinResources           *v1.ResourceList `json:"minResources,omitempty"`
-	ScheduleTimeoutSeconds *int32           `json:"scheduleTimeoutSeconds,omitempty"`
+	MinMember              *int32                                        `json:"minMember,omitempty"`
+	MaxMember              *int32                                        `json:"maxMember,omitempty"`
+	ScaleUpWindowSeconds   *int32                                        `json:"scaleUpWindowSeconds,omitempty"`
+	MinResources           *v1.ResourceList                              `json:"minResources,omitempty"`
+	ScheduleTimeoutSeconds *int32                                        `json:"scheduleTimeoutSeconds,omitempty"`
+	Roles                  []PodGroupRoleApplyConfiguration              `json:"roles,omitempty"`
+	TopologyConstraint     *PodGroupTopologyConstraintApplyConfiguration `json:"topologyConstraint,omitempty"`
 }
 
 // PodGroupSpecApplyConfiguration constructs a declarative configuration of the PodGroupSpec type for use with
@@ -44,6 +48,22 @@ func (b *PodGroupSpecApplyConfiguration) WithMinMember(value int32) *PodGroupSpe
 	return b
 }
 
+// WithMaxMember sets the MaxMember field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the MaxMember field is set to the value of the last call.
+func (b *PodGroupSpecApplyConfiguration) WithMaxMember(value int32) *PodGroupSpecApplyConfiguration {
+	b.MaxMember = &value
+	return b
+}
+
+// WithScaleUpWindowSeconds sets the ScaleUpWindowSeconds field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ScaleUpWindowSeconds field is set to the value of the last call.
+func (b *PodGroupSpecApplyConfiguration) WithScaleUpWindowSeconds(value int32) *PodGroupSpecApplyConfiguration {
+	b.ScaleUpWindowSeconds = &value
+	return b
+}
+
 // WithMinResources sets the MinResources field in the declarative configuration to the given value
 // and returns the receiver, so that objects can be built by chaining "With" function invocations.
 // If called multiple times, the MinResources field is set to the value of the last call.
@@ -59,3 +79,24 @@ func (b *PodGroupSpecApplyConfiguration) WithScheduleTimeoutSeconds(value int32)
 	b.ScheduleTimeoutSeconds = &value
 	return b
 }
+
+// WithRoles adds the given value to the Roles field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Roles field.
+func (b *PodGroupSpecApplyConfiguration) WithRoles(values ...*PodGroupRoleApplyConfiguration) *PodGroupSpecApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithRoles")
+		}
+		b.Roles = append(b.Roles, *values[i])
+	}
+	return b
+}
+
+// WithTopologyConstraint sets the TopologyConstraint field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the TopologyConstraint field is set to the value of the last call.
+func (b *PodGroupSpecApplyConfiguration) WithTopologyConstraint(value *PodGroupTopologyConstraintApplyConfiguration) *PodGroupSpecApplyConfiguration {
+	b.TopologyConstraint = value
+	return b
+}
@@ -19,7 +19,8 @@ limitations under the License.
 package v1alpha1
 
 import (
-	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	v1 "k8s.io/client-go/applyconfigurations/meta/v1"
 	schedulingv1alpha1 "sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
 )
 
@@ -31,7 +32,10 @@ type PodGroupStatusApplyConfiguration struct {
 	Running           *int32                            `json:"running,omitempty"`
 	Succeeded         *int32                            `json:"succeeded,omitempty"`
 	Failed            *int32                            `json:"failed,omitempty"`
-	ScheduleStartTime *v1.Time                          `json:"scheduleStartTime,omitempty"`
+	ScheduleStartTime *metav1.Time                      `json:"scheduleStartTime,omitempty"`
+	Desired           *int32                            `json:"desired,omitempty"`
+	Admitted          *int32                            `json:"admitted,omitempty"`
+	Conditions        []v1.ConditionApplyConfiguration  `json:"conditions,omitempty"`
 }
 
 // PodGroupStatusApplyConfiguration constructs a declarative configuration of the PodGroupStatus type for use with
@@ -83,7 +87,36 @@ func (b *PodGroupStatusApplyConfiguration) WithFailed(value int32) *PodGroupStat
 // WithScheduleStartTime sets the ScheduleStartTime field in the declarative configuration to the given value
 // and returns the receiver, so that objects can be built by chaining "With" function invocations.
 // If called multiple times, the ScheduleStartTime field is set to the value of the last call.
-func (b *PodGroupStatusApplyConfiguration) WithScheduleStartTime(value v1.Time) *PodGroupStatusApplyConfiguration {
+func (b *PodGroupStatusApplyConfiguration) WithScheduleStartTime(value metav1.Time) *PodGroupStatusApplyConfiguration {
 	b.ScheduleStartTime = &value
 	return b
 }
+
+// WithDesired sets the Desired field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Desired field is set to the value of the last call.
+func (b *PodGroupStatusApplyConfiguration) WithDesired(value int32) *PodGroupStatusApplyConfiguration {
+	b.Desired = &value
+	return b
+}
+
+// WithAdmitted sets the Admitted field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Admitted field is set to the value of the last call.
+func (b *PodGroupStatusApplyConfiguration) WithAdmitted(value int32) *PodGroupStatusApplyConfiguration {
+	b.Admitted = &value
+	return b
+}
+
+// WithConditions adds the given value to the Conditions field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Conditions field.
+func (b *PodGroupStatusApplyConfiguration) WithConditions(values ...*v1.ConditionApplyConfiguration) *PodGroupStatusApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithConditions")
+		}
+		b.Conditions = append(b.Conditions, *values[i])
+	}
+	return b
+}
@@ -43,7 +43,8 @@ type Interface interface {
 	// It will be used as the source of truth across the Pod's scheduling cycle.
 	// Over-reserved resources are the resources consumed by pods scheduled to that node after the last update
 	// of NRT pertaining to the same node, pessimistically overallocated on ALL the NUMA zones of the node.
-	// The pod argument is used only for logging purposes.
+	// The pod argument is used for logging purposes, and by the implementations which account the resources
+	// reserved for pods of the same PodGroup differently.
 	// Returns nil if there is no NRT data available for the node named `nodeName`.
 	// Returns a CachedNRTInfo describing the NRT data returned. Meaningful only if `nrt` != nil.
 	GetCachedNRTCopy(ctx context.Context, nodeName string, pod *corev1.Pod) (*topologyv1alpha2.NodeResourceTopology, CachedNRTInfo)
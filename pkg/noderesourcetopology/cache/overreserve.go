@@ -39,6 +39,7 @@ import (
 	"sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/podprovider"
 	"sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/resourcerequests"
 	"sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/stringify"
+	"sigs.k8s.io/scheduler-plugins/pkg/util"
 )
 
 type OverReserve struct {
@@ -57,6 +58,7 @@ type OverReserve struct {
 	podLister              podlisterv1.PodLister
 	resyncMethod           apiconfig.CacheResyncMethod
 	resyncScope            apiconfig.CacheResyncScope
+	gangAccounting         apiconfig.CacheGangAccountingMode
 	isPodRelevant          podprovider.PodFilterFunc
 }
 
@@ -72,8 +74,9 @@ func NewOverReserve(ctx context.Context, lh logr.Logger, cfg *apiconfig.NodeReso
 
 	resyncMethod := getCacheResyncMethod(lh, cfg)
 	resyncScope := getCacheResyncScope(lh, cfg)
+	gangAccounting := getCacheGangAccounting(lh, cfg)
 
-	lh.V(2).Info("initializing", "noderesourcetopologies", len(nrtObjs.Items), "method", resyncMethod, "scope", resyncScope)
+	lh.V(2).Info("initializing", "noderesourcetopologies", len(nrtObjs.Items), "method", resyncMethod, "scope", resyncScope, "gangAccounting", gangAccounting)
 	obj := &OverReserve{
 		lh:                     lh,
 		client:                 client,
@@ -85,6 +88,7 @@ func NewOverReserve(ctx context.Context, lh logr.Logger, cfg *apiconfig.NodeReso
 		nodesWithAttrUpdate:    newCounter(),
 		podLister:              podLister,
 		resyncMethod:           resyncMethod,
+		gangAccounting:         gangAccounting,
 		isPodRelevant:          isPodRelevant,
 	}
 
@@ -107,6 +111,10 @@ func (ov *OverReserve) GetCachedNRTCopy(ctx context.Context, nodeName string, po
 	if ov.nodesWithForeignPods.IsSet(nodeName) {
 		return nil, info
 	}
+	if ov.nrts.IsQuarantined(nodeName) {
+		ov.lh.V(4).Info("NodeTopology quarantined", logging.KeyPod, klog.KObj(pod), logging.KeyNode, nodeName)
+		return nil, info
+	}
 
 	info.Fresh = true
 	nrt := ov.nrts.GetNRTCopyByNodeName(nodeName)
@@ -122,12 +130,24 @@ func (ov *OverReserve) GetCachedNRTCopy(ctx context.Context, nodeName string, po
 	lh := ov.lh.WithValues(logging.KeyPod, logID, logging.KeyPodUID, logging.PodUID(pod), logging.KeyNode, nodeName, logging.KeyGeneration, ov.generation)
 
 	lh.V(6).Info("NRT", "fromcache", stringify.NodeResourceTopologyResources(nrt))
-	nodeAssumedResources.UpdateNRT(nrt, logging.KeyPod, logID)
+	if ov.gangAccounting == apiconfig.CacheGangAccountingPerNUMACell {
+		nodeAssumedResources.UpdateNRTForPodGroup(nrt, util.GetPodGroupFullName(pod), logging.KeyPod, logID)
+	} else {
+		nodeAssumedResources.UpdateNRT(nrt, logging.KeyPod, logID)
+	}
 
 	lh.V(5).Info("NRT", "withassumed", stringify.NodeResourceTopologyResources(nrt))
 	return nrt, info
 }
 
+// SetNRTValidator enables the validation of the NRT data against the node allocatable. Must be called before
+// the cache is used for scheduling decisions. The data already cached is validated immediately.
+func (ov *OverReserve) SetNRTValidator(nv *NRTValidator) {
+	ov.lock.Lock()
+	defer ov.lock.Unlock()
+	ov.nrts.SetValidator(nv)
+}
+
 func (ov *OverReserve) NodeMaybeOverReserved(nodeName string, pod *corev1.Pod) {
 	ov.lock.Lock()
 	defer ov.lock.Unlock()
@@ -185,14 +205,15 @@ type DesyncedNodes struct {
 	Generation        uint64
 	MaybeOverReserved []string
 	ConfigChanged     []string
+	Quarantined       []string
 }
 
 func (rn DesyncedNodes) String() string {
-	return fmt.Sprintf("desyncedNodes{MaybeOverReserved: %v, ConfigChanged: %v}", rn.MaybeOverReserved, rn.ConfigChanged)
+	return fmt.Sprintf("desyncedNodes{MaybeOverReserved: %v, ConfigChanged: %v, Quarantined: %v}", rn.MaybeOverReserved, rn.ConfigChanged, rn.Quarantined)
 }
 
 func (rn DesyncedNodes) Len() int {
-	return len(rn.MaybeOverReserved) + len(rn.ConfigChanged)
+	return len(rn.MaybeOverReserved) + len(rn.ConfigChanged) + len(rn.Quarantined)
 }
 
 func (rn DesyncedNodes) DirtyCount() int {
@@ -209,6 +230,9 @@ func (rn DesyncedNodes) DirtyCount() int {
 //  3. it received a metadata update while at steady state (resource allocation didn't change),
 //     typically at idle time (unloaded node)
 //
+// or
+//  4. its NRT data was found inconsistent with the node allocatable, so it was quarantined
+//
 // This function enables the caller to know the slice of nodes should be considered for resync,
 // avoiding the need to rescan the full node list.
 func (ov *OverReserve) GetDesyncedNodes(lh logr.Logger) DesyncedNodes {
@@ -236,13 +260,18 @@ func (ov *OverReserve) GetDesyncedNodes(lh logr.Logger) DesyncedNodes {
 	configChangeNodes := ov.nodesWithAttrUpdate.Clone()
 	configChangeCount := configChangeNodes.Len()
 
+	// the node allocatable may have changed meanwhile, making the data consistent again
+	ov.nrts.Revalidate()
+	quarantinedNodes := ov.nrts.QuarantinedNodes()
+
 	if nodes.Len() > 0 {
-		lh.V(4).Info("found dirty nodes", "foreign", foreignCount, "discarded", overreservedCount, "configChange", configChangeCount, "total", nodes.Len())
+		lh.V(4).Info("found dirty nodes", "foreign", foreignCount, "discarded", overreservedCount, "configChange", configChangeCount, "quarantined", len(quarantinedNodes), "total", nodes.Len())
 	}
 	return DesyncedNodes{
 		Generation:        ov.generation,
 		MaybeOverReserved: nodes.Keys(),
 		ConfigChanged:     configChangeNodes.Keys(),
+		Quarantined:       quarantinedNodes,
 	}
 }
 
@@ -346,9 +375,33 @@ func (ov *OverReserve) MakeNRTUpdatesForNodes(ctx context.Context, lh_ logr.Logg
 		nrtUpdates = append(nrtUpdates, nrtCandidate)
 	}
 
+	for _, nodeName := range nodes.Quarantined {
+		lh := lh_.WithValues(logging.KeyNode, nodeName)
+
+		nrtCandidate := &topologyv1alpha2.NodeResourceTopology{}
+		if err := ov.client.Get(ctx, types.NamespacedName{Name: nodeName}, nrtCandidate); err != nil {
+			lh.V(2).Info("failed to get NodeTopology", "error", err)
+			continue
+		}
+
+		if nrtCandidate.ResourceVersion == ov.cachedResourceVersion(nodeName) {
+			lh.V(4).Info("NodeTopology quarantined and not changed", "resourceVersion", nrtCandidate.ResourceVersion)
+			continue
+		}
+
+		lh.V(4).Info("overriding cached info", "reason", "quarantined")
+		nrtUpdates = append(nrtUpdates, nrtCandidate)
+	}
+
 	return nrtUpdates
 }
 
+func (ov *OverReserve) cachedResourceVersion(nodeName string) string {
+	ov.lock.Lock()
+	defer ov.lock.Unlock()
+	return ov.nrts.ResourceVersion(nodeName)
+}
+
 // FlushNodes drops all the cached information about a given node, resetting its state clean.
 func (ov *OverReserve) FlushNodes(lh logr.Logger, nrts ...*topologyv1alpha2.NodeResourceTopology) uint64 {
 	ov.lock.Lock()
@@ -426,4 +479,15 @@ func getCacheResyncScope(lh logr.Logger, cfg *apiconfig.NodeResourceTopologyCach
 	return resyncScope
 }
 
+func getCacheGangAccounting(lh logr.Logger, cfg *apiconfig.NodeResourceTopologyCache) apiconfig.CacheGangAccountingMode {
+	var gangAccounting apiconfig.CacheGangAccountingMode
+	if cfg != nil && cfg.GangAccounting != nil {
+		gangAccounting = *cfg.GangAccounting
+	} else { // explicitly set to nil?
+		gangAccounting = apiconfig.CacheGangAccountingPessimistic
+		lh.Info("cache gang accounting missing", "fallback", gangAccounting)
+	}
+	return gangAccounting
+}
+
 func (ov *OverReserve) PostBind(nodeName string, pod *corev1.Pod) {}
@@ -99,6 +99,50 @@ func TestGetCacheResyncMethod(t *testing.T) {
 		})
 	}
 }
+func TestGetCacheGangAccounting(t *testing.T) {
+	gangAccountingPessimistic := apiconfig.CacheGangAccountingPessimistic
+	gangAccountingPerNUMACell := apiconfig.CacheGangAccountingPerNUMACell
+
+	testCases := []struct {
+		description string
+		cfg         *apiconfig.NodeResourceTopologyCache
+		expected    apiconfig.CacheGangAccountingMode
+	}{
+		{
+			description: "nil config",
+			expected:    apiconfig.CacheGangAccountingPessimistic,
+		},
+		{
+			description: "empty config",
+			cfg:         &apiconfig.NodeResourceTopologyCache{},
+			expected:    apiconfig.CacheGangAccountingPessimistic,
+		},
+		{
+			description: "explicit pessimistic",
+			cfg: &apiconfig.NodeResourceTopologyCache{
+				GangAccounting: &gangAccountingPessimistic,
+			},
+			expected: apiconfig.CacheGangAccountingPessimistic,
+		},
+		{
+			description: "explicit PerNUMACell",
+			cfg: &apiconfig.NodeResourceTopologyCache{
+				GangAccounting: &gangAccountingPerNUMACell,
+			},
+			expected: apiconfig.CacheGangAccountingPerNUMACell,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			got := getCacheGangAccounting(klog.Background(), testCase.cfg)
+			if got != testCase.expected {
+				t.Errorf("cache gang accounting got %v expected %v", got, testCase.expected)
+			}
+		})
+	}
+}
+
 func TestInitEmptyLister(t *testing.T) {
 	fakeClient, err := tu.NewFakeClient()
 	if err != nil {
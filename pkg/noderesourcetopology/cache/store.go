@@ -17,20 +17,24 @@ limitations under the License.
 package cache
 
 import (
+	"sort"
 	"strings"
 	"sync"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/util/sets"
+	kubeletconfig "k8s.io/kubernetes/pkg/kubelet/apis/config"
 
 	"github.com/go-logr/logr"
 	topologyv1alpha2 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2"
 	topologyv1alpha2attr "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2/helper/attribute"
+	"github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2/helper/numanode"
 	"github.com/k8stopologyawareschedwg/podfingerprint"
 
 	apiconfig "sigs.k8s.io/scheduler-plugins/apis/config"
 	"sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/logging"
+	"sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/nodeconfig"
 	"sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/stringify"
 	"sigs.k8s.io/scheduler-plugins/pkg/util"
 )
@@ -40,6 +44,14 @@ import (
 type nrtStore struct {
 	data map[string]*topologyv1alpha2.NodeResourceTopology
 	lh   logr.Logger
+	// validator is optional. If nil, the data is never validated.
+	validator *NRTValidator
+	// pending tracks the nodes whose data could not be validated yet, because the node object is not known.
+	pending sets.Set[string]
+	// invalid maps the nodes with inconsistent data to the last inconsistency reported.
+	invalid map[string]string
+	// quarantined tracks the nodes with inconsistent data which must not be used for scheduling.
+	quarantined sets.Set[string]
 }
 
 // newNrtStore creates a new nrtStore and initializes it with copies of the provided Node Resource Topology data.
@@ -50,8 +62,11 @@ func newNrtStore(lh logr.Logger, nrts []topologyv1alpha2.NodeResourceTopology) *
 	}
 	lh.V(6).Info("initialized nrtStore", "objects", len(data))
 	return &nrtStore{
-		data: data,
-		lh:   lh,
+		data:        data,
+		lh:          lh,
+		pending:     sets.New[string](),
+		invalid:     make(map[string]string),
+		quarantined: sets.New[string](),
 	}
 }
 
@@ -124,22 +139,101 @@ func (rs *nrtResourcesStore) Delete(nodeName string) {
 }
 
 // Update adds or replace the Node Resource Topology associated to a node. Always do a copy.
+// If a validator is set, the data is validated against the node allocatable.
 func (nrs *nrtStore) Update(nrt *topologyv1alpha2.NodeResourceTopology) {
 	nrs.data[nrt.Name] = nrt.DeepCopy()
 	nrs.lh.V(5).Info("updated cached NodeTopology", "node", nrt.Name)
+	nrs.validate(nrt)
+}
+
+// SetValidator sets the validator for all the future updates, and validates all the data already stored.
+func (nrs *nrtStore) SetValidator(nv *NRTValidator) {
+	nrs.validator = nv
+	for _, nrt := range nrs.data {
+		nrs.validate(nrt)
+	}
+}
+
+// Revalidate validates again the stored data for nodes which could not be validated yet, or which were
+// found inconsistent; the node allocatable may have changed meanwhile.
+func (nrs *nrtStore) Revalidate() {
+	nodeNames := nrs.pending.Union(sets.KeySet(nrs.invalid))
+	for _, nodeName := range sets.List(nodeNames) {
+		nrt, ok := nrs.data[nodeName]
+		if !ok {
+			continue
+		}
+		nrs.validate(nrt)
+	}
+}
+
+// IsQuarantined returns true if the data for the given node was found inconsistent and must not be used.
+func (nrs *nrtStore) IsQuarantined(nodeName string) bool {
+	return nrs.quarantined.Has(nodeName)
+}
+
+// QuarantinedNodes returns the sorted names of the quarantined nodes.
+func (nrs *nrtStore) QuarantinedNodes() []string {
+	return sets.List(nrs.quarantined)
+}
+
+// ResourceVersion returns the resourceVersion of the stored data for the given node, or empty string if missing.
+func (nrs *nrtStore) ResourceVersion(nodeName string) string {
+	obj, ok := nrs.data[nodeName]
+	if !ok {
+		return ""
+	}
+	return obj.ResourceVersion
+}
+
+func (nrs *nrtStore) validate(nrt *topologyv1alpha2.NodeResourceTopology) {
+	if !nrs.validator.IsEnabled() {
+		return
+	}
+	lh := nrs.lh.WithValues(logging.KeyNode, nrt.Name)
+	node, err := nrs.validator.Validate(nrt)
+	if node == nil {
+		lh.V(4).Info("cannot validate NodeTopology yet", "error", err)
+		nrs.pending.Insert(nrt.Name)
+		return
+	}
+	nrs.pending.Delete(nrt.Name)
+
+	if err == nil {
+		if _, ok := nrs.invalid[nrt.Name]; ok {
+			lh.V(2).Info("NodeTopology consistent with node allocatable", "quarantined", false)
+		}
+		delete(nrs.invalid, nrt.Name)
+		nrs.quarantined.Delete(nrt.Name)
+		return
+	}
+
+	quarantine := nrs.validator.Quarantines()
+	if nrs.invalid[nrt.Name] != err.Error() {
+		// report only once per distinct inconsistency to avoid flooding the events
+		lh.V(2).Info("NodeTopology inconsistent with node allocatable", "error", err.Error(), "quarantined", quarantine)
+		nrs.validator.Report(node, err)
+		nrs.invalid[nrt.Name] = err.Error()
+	}
+	if quarantine {
+		nrs.quarantined.Insert(nrt.Name)
+	}
 }
 
 // resourceStore maps the resource requested by pod by pod namespaed name. It is not thread safe and needs to be protected by a lock.
 type resourceStore struct {
 	// key: namespace + "/" name
 	data map[string]corev1.ResourceList
-	lh   logr.Logger
+	// podGroups maps the pod key to the full name of the PodGroup the pod belongs to, if any
+	podGroups map[string]string
+	lh        logr.Logger
 }
 
 func newResourceStore(lh logr.Logger) *resourceStore {
 	return &resourceStore{
-		data: make(map[string]corev1.ResourceList),
-		lh:   lh,
+		data:      make(map[string]corev1.ResourceList),
+		podGroups: make(map[string]string),
+		lh:        lh,
 	}
 }
 
@@ -159,9 +253,14 @@ func (rs *resourceStore) AddPod(pod *corev1.Pod) bool {
 		// should not happen, so we log with a low level
 		rs.lh.V(4).Info("updating existing entry", "key", key)
 	}
-	resData := util.GetPodEffectiveRequest(pod)
+	resData := util.PodRequests(pod)
 	rs.lh.V(5).Info("resourcestore ADD", stringify.ResourceListToLoggable(resData)...)
 	rs.data[key] = resData
+	if pgName := util.GetPodGroupFullName(pod); pgName != "" {
+		rs.podGroups[key] = pgName
+	} else {
+		delete(rs.podGroups, key)
+	}
 	return ok
 }
 
@@ -175,6 +274,7 @@ func (rs *resourceStore) DeletePod(pod *corev1.Pod) bool {
 	}
 	rs.lh.V(5).Info("resourcestore DEL", stringify.ResourceListToLoggable(rs.data[key])...)
 	delete(rs.data, key)
+	delete(rs.podGroups, key)
 	return ok
 }
 
@@ -182,35 +282,178 @@ func (rs *resourceStore) DeletePod(pod *corev1.Pod) bool {
 // performing pessimistic overallocation across all the NUMA zones.
 func (rs *resourceStore) UpdateNRT(nrt *topologyv1alpha2.NodeResourceTopology, logKeysAndValues ...any) {
 	for key, res := range rs.data {
-		// We cannot predict on which Zone the workload will be placed.
-		// And we should totally not guess. So the only safe (and conservative)
-		// choice is to decrement the available resources from *all* the zones.
-		// This can cause false negatives, but will never cause false positives,
-		// which are much worse.
-		for zi := 0; zi < len(nrt.Zones); zi++ {
-			zone := &nrt.Zones[zi] // shortcut
-			for ri := 0; ri < len(zone.Resources); ri++ {
-				zr := &zone.Resources[ri] // shortcut
-				qty, ok := res[corev1.ResourceName(zr.Name)]
-				if !ok {
-					// this is benign; it is totally possible some resources are not
-					// available on some zones (think PCI devices), hence we don't
-					// even report this error, being an expected condition
-					continue
-				}
-				if zr.Available.Cmp(qty) < 0 {
-					// this should happen rarely, and it is likely caused by
-					// a bug elsewhere.
-					logKeysAndValues = append(logKeysAndValues, "zone", zr.Name, logging.KeyNode, nrt.Name, "available", zr.Available, "requestor", key, "quantity", qty.String())
-					rs.lh.V(3).Info("cannot decrement resource", logKeysAndValues...)
-					zr.Available = resource.Quantity{}
-					continue
-				}
-
-				zr.Available.Sub(qty)
+		rs.deductFromAllZones(nrt, key, res, logKeysAndValues...)
+	}
+}
+
+// UpdateNRTForPodGroup updates the provided Node Resource Topology object with the resources tracked in this store,
+// like UpdateNRT, except for the pods belonging to the given PodGroup (namespace/name). If the node admits pods on
+// a single NUMA zone at pod scope, each sibling takes resources from exactly one zone. So the resources of each
+// sibling, sorted by key, are deducted only from the first zone, by NUMA ID, which can fit it in the availability
+// as reported minus the siblings accounted before it, and from all the zones if there is no such evidence or no
+// such zone. If podGroup is empty, this is equivalent to UpdateNRT.
+func (rs *resourceStore) UpdateNRTForPodGroup(nrt *topologyv1alpha2.NodeResourceTopology, podGroup string, logKeysAndValues ...any) {
+	if podGroup == "" {
+		rs.UpdateNRT(nrt, logKeysAndValues...)
+		return
+	}
+
+	var siblings []string
+	for key := range rs.data {
+		if rs.podGroups[key] == podGroup {
+			siblings = append(siblings, key)
+		}
+	}
+	if len(siblings) == 0 {
+		rs.UpdateNRT(nrt, logKeysAndValues...)
+		return
+	}
+
+	conf := nodeconfig.TopologyManagerFromNodeResourceTopology(rs.lh, nrt)
+	singleNUMAPod := conf.Policy == kubeletconfig.SingleNumaNodeTopologyManagerPolicy && conf.Scope == kubeletconfig.PodTopologyManagerScope
+	zoneResNames := ResourceNamesFromNRT(nrt)
+	zoneIdxs := zoneIndexesByNUMAID(nrt)
+
+	// the siblings must be accounted before the other pods, whose deductions are guesses and no evidence.
+	// Make sure the outcome is stable across calls.
+	sort.Strings(siblings)
+	for _, key := range siblings {
+		res := rs.data[key]
+		zi := -1
+		if singleNUMAPod {
+			zi = firstZoneCanFit(nrt, zoneIdxs, res, zoneResNames)
+		}
+		if zi < 0 {
+			// no evidence, or the sibling fits nowhere and will be rejected anyway: stay conservative
+			rs.deductFromAllZones(nrt, key, res, logKeysAndValues...)
+			continue
+		}
+		rs.lh.V(5).Info("accounting sibling", append(logKeysAndValues, "podGroup", podGroup, "requestor", key, "zone", nrt.Zones[zi].Name)...)
+		rs.deductFromZones(nrt, sets.New(nrt.Zones[zi].Name), key, res, logKeysAndValues...)
+	}
+	for key, res := range rs.data {
+		if rs.podGroups[key] == podGroup {
+			continue
+		}
+		rs.deductFromAllZones(nrt, key, res, logKeysAndValues...)
+	}
+}
+
+// DeductPods returns a copy of the given Node Resource Topology object with the resources requested by the given pods
+// deducted pessimistically from all the NUMA zones, like the OverReserve cache does for the pods reserved on the node
+// since its last resync.
+func DeductPods(lh logr.Logger, nrt *topologyv1alpha2.NodeResourceTopology, pods []*corev1.Pod) *topologyv1alpha2.NodeResourceTopology {
+	rs := newResourceStore(lh)
+	for _, pod := range pods {
+		rs.AddPod(pod)
+	}
+	ret := nrt.DeepCopy()
+	rs.UpdateNRT(ret, logging.KeyNode, nrt.Name)
+	return ret
+}
+
+func (rs *resourceStore) deductFromAllZones(nrt *topologyv1alpha2.NodeResourceTopology, key string, res corev1.ResourceList, logKeysAndValues ...any) {
+	// We cannot predict on which Zone the workload will be placed.
+	// And we should totally not guess. So the only safe (and conservative)
+	// choice is to decrement the available resources from *all* the zones.
+	// This can cause false negatives, but will never cause false positives,
+	// which are much worse.
+	rs.deductFromZones(nrt, nil, key, res, logKeysAndValues...)
+}
+
+// deductFromZones deducts the given resources from the named zones, or from all the zones if zoneNames is nil.
+func (rs *resourceStore) deductFromZones(nrt *topologyv1alpha2.NodeResourceTopology, zoneNames sets.Set[string], key string, res corev1.ResourceList, logKeysAndValues ...any) {
+	for zi := 0; zi < len(nrt.Zones); zi++ {
+		zone := &nrt.Zones[zi] // shortcut
+		if zoneNames != nil && !zoneNames.Has(zone.Name) {
+			continue
+		}
+		for ri := 0; ri < len(zone.Resources); ri++ {
+			zr := &zone.Resources[ri] // shortcut
+			qty, ok := res[corev1.ResourceName(zr.Name)]
+			if !ok {
+				// this is benign; it is totally possible some resources are not
+				// available on some zones (think PCI devices), hence we don't
+				// even report this error, being an expected condition
+				continue
 			}
+			if zr.Available.Cmp(qty) < 0 {
+				// this should happen rarely, and it is likely caused by
+				// a bug elsewhere.
+				logKeysAndValues = append(logKeysAndValues, "zone", zr.Name, logging.KeyNode, nrt.Name, "available", zr.Available, "requestor", key, "quantity", qty.String())
+				rs.lh.V(3).Info("cannot decrement resource", logKeysAndValues...)
+				zr.Available = resource.Quantity{}
+				continue
+			}
+
+			zr.Available.Sub(qty)
+		}
+	}
+}
+
+// zoneIndexesByNUMAID returns the indexes of the NUMA zones of the given object, sorted by NUMA ID. The zones whose
+// name carries no NUMA ID are sorted last, by name.
+func zoneIndexesByNUMAID(nrt *topologyv1alpha2.NodeResourceTopology) []int {
+	type zoneID struct {
+		idx  int
+		id   int
+		name string
+	}
+	ids := make([]zoneID, 0, len(nrt.Zones))
+	for zi := 0; zi < len(nrt.Zones); zi++ {
+		id, err := numanode.NameToID(nrt.Zones[zi].Name)
+		if err != nil {
+			id = -1
+		}
+		ids = append(ids, zoneID{idx: zi, id: id, name: nrt.Zones[zi].Name})
+	}
+	sort.SliceStable(ids, func(i, j int) bool {
+		if (ids[i].id < 0) != (ids[j].id < 0) {
+			return ids[i].id >= 0
+		}
+		if ids[i].id != ids[j].id {
+			return ids[i].id < ids[j].id
+		}
+		return ids[i].name < ids[j].name
+	})
+	idxs := make([]int, 0, len(ids))
+	for _, id := range ids {
+		idxs = append(idxs, id.idx)
+	}
+	return idxs
+}
+
+// firstZoneCanFit returns the index of the first zone, in the given order, which can fit all the given resources,
+// or -1 if none can. Resources not reported by any zone are ignored.
+func firstZoneCanFit(nrt *topologyv1alpha2.NodeResourceTopology, zoneIdxs []int, res corev1.ResourceList, zoneResNames sets.Set[corev1.ResourceName]) int {
+	for _, zi := range zoneIdxs {
+		if zoneCanFit(&nrt.Zones[zi], res, zoneResNames) {
+			return zi
+		}
+	}
+	return -1
+}
+
+func zoneCanFit(zone *topologyv1alpha2.Zone, res corev1.ResourceList, zoneResNames sets.Set[corev1.ResourceName]) bool {
+	for resName, qty := range res {
+		if qty.IsZero() || !zoneResNames.Has(resName) {
+			continue
+		}
+		zr := findZoneResource(zone, resName)
+		if zr == nil || zr.Available.Cmp(qty) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func findZoneResource(zone *topologyv1alpha2.Zone, resName corev1.ResourceName) *topologyv1alpha2.ResourceInfo {
+	for ri := 0; ri < len(zone.Resources); ri++ {
+		if zone.Resources[ri].Name == string(resName) {
+			return &zone.Resources[ri]
 		}
 	}
+	return nil
 }
 
 type counter map[string]int
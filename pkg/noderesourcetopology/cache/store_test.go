@@ -32,6 +32,7 @@ import (
 	podlisterv1 "k8s.io/client-go/listers/core/v1"
 	"k8s.io/klog/v2"
 	apiconfig "sigs.k8s.io/scheduler-plugins/apis/config"
+	"sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
 
 	"github.com/k8stopologyawareschedwg/podfingerprint"
 )
@@ -695,6 +696,228 @@ func TestResourceStoreUpdate(t *testing.T) {
 	}
 }
 
+func TestDeductPods(t *testing.T) {
+	nrt := &topologyv1alpha2.NodeResourceTopology{
+		ObjectMeta:       metav1.ObjectMeta{Name: "node"},
+		TopologyPolicies: []string{string(topologyv1alpha2.SingleNUMANodePodLevel)},
+		Zones: topologyv1alpha2.ZoneList{
+			{
+				Name: "node-0",
+				Type: "Node",
+				Resources: topologyv1alpha2.ResourceInfoList{
+					MakeTopologyResInfo(cpu, "20", "20"),
+				},
+			},
+			{
+				Name: "node-1",
+				Type: "Node",
+				Resources: topologyv1alpha2.ResourceInfoList{
+					MakeTopologyResInfo(cpu, "20", "10"),
+				},
+			},
+		},
+	}
+	makePod := func(name, cpuQty string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: name},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{
+						Name: "cnt",
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{
+								corev1.ResourceCPU: resource.MustParse(cpuQty),
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	got := DeductPods(klog.Background(), nrt, []*corev1.Pod{makePod("pod-0", "4"), makePod("pod-1", "2")})
+
+	for zi, expected := range []string{"14", "4"} {
+		cpuInfo := findResourceInfo(got.Zones[zi].Resources, cpu)
+		if cpuInfo.Available.Cmp(resource.MustParse(expected)) != 0 {
+			t.Errorf("bad availability for resource %q on zone %d: expected %v got %v", cpu, zi, expected, cpuInfo.Available)
+		}
+	}
+	// the original object must be left untouched
+	if cpuInfo := findResourceInfo(nrt.Zones[0].Resources, cpu); cpuInfo.Available.Cmp(resource.MustParse("20")) != 0 {
+		t.Errorf("original object modified: %v", cpuInfo.Available)
+	}
+}
+
+func TestResourceStoreUpdateForPodGroup(t *testing.T) {
+	makeNRT := func(policy topologyv1alpha2.TopologyManagerPolicy) *topologyv1alpha2.NodeResourceTopology {
+		return &topologyv1alpha2.NodeResourceTopology{
+			ObjectMeta:       metav1.ObjectMeta{Name: "node"},
+			TopologyPolicies: []string{string(policy)},
+			Zones: topologyv1alpha2.ZoneList{
+				{
+					Name: "node-0",
+					Type: "Node",
+					Resources: topologyv1alpha2.ResourceInfoList{
+						MakeTopologyResInfo(cpu, "20", "20"),
+						MakeTopologyResInfo(memory, "32Gi", "32Gi"),
+					},
+				},
+				{
+					Name: "node-1",
+					Type: "Node",
+					Resources: topologyv1alpha2.ResourceInfoList{
+						MakeTopologyResInfo(cpu, "20", "20"),
+						MakeTopologyResInfo(memory, "32Gi", "32Gi"),
+						MakeTopologyResInfo(nicName, "8", "8"),
+					},
+				},
+			},
+		}
+	}
+
+	makePod := func(name, podGroup string, res corev1.ResourceList) *corev1.Pod {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "ns-0",
+				Name:      name,
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{
+						Name: "cnt-0",
+						Resources: corev1.ResourceRequirements{
+							Requests: res,
+						},
+					},
+				},
+			},
+		}
+		if podGroup != "" {
+			pod.Labels = map[string]string{
+				v1alpha1.PodGroupLabel: podGroup,
+			}
+		}
+		return pod
+	}
+
+	rs := newResourceStore(klog.Background())
+	rs.AddPod(makePod("pod-0", "pg-0", corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("8"),
+		corev1.ResourceMemory: resource.MustParse("4Gi"),
+	}))
+	rs.AddPod(makePod("pod-1", "pg-0", corev1.ResourceList{
+		corev1.ResourceCPU:           resource.MustParse("8"),
+		corev1.ResourceMemory:        resource.MustParse("4Gi"),
+		corev1.ResourceName(nicName): resource.MustParse("2"),
+	}))
+	rs.AddPod(makePod("pod-2", "pg-1", corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("2"),
+		corev1.ResourceMemory: resource.MustParse("2Gi"),
+	}))
+	rs.AddPod(makePod("pod-3", "", corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("2"),
+		corev1.ResourceMemory: resource.MustParse("2Gi"),
+	}))
+
+	type expectedAvail struct {
+		zone  int
+		name  string
+		value string
+	}
+
+	pessimistic := []expectedAvail{
+		{zone: 0, name: cpu, value: "0"},
+		{zone: 1, name: cpu, value: "0"},
+		{zone: 0, name: memory, value: "20Gi"},
+		{zone: 1, name: memory, value: "20Gi"},
+		{zone: 1, name: nicName, value: "6"},
+	}
+
+	testCases := []struct {
+		description string
+		policy      topologyv1alpha2.TopologyManagerPolicy
+		podGroup    string
+		expected    []expectedAvail
+	}{
+		{
+			description: "no pod group, all pessimistic",
+			policy:      topologyv1alpha2.SingleNUMANodePodLevel,
+			expected:    pessimistic,
+		},
+		{
+			description: "pod group with siblings, each accounted on the first zone which can host it",
+			policy:      topologyv1alpha2.SingleNUMANodePodLevel,
+			podGroup:    "ns-0/pg-0",
+			expected: []expectedAvail{
+				// pod-0 fits first on zone 0, pod-1 only on zone 1 (needs the nic), pod-2 and pod-3 pessimistic everywhere
+				{zone: 0, name: cpu, value: "8"},
+				{zone: 1, name: cpu, value: "8"},
+				{zone: 0, name: memory, value: "24Gi"},
+				{zone: 1, name: memory, value: "24Gi"},
+				{zone: 1, name: nicName, value: "6"},
+			},
+		},
+		{
+			description: "pod group with siblings, container scope gives no evidence",
+			policy:      topologyv1alpha2.SingleNUMANodeContainerLevel,
+			podGroup:    "ns-0/pg-0",
+			expected:    pessimistic,
+		},
+		{
+			description: "pod group without siblings",
+			policy:      topologyv1alpha2.SingleNUMANodePodLevel,
+			podGroup:    "ns-0/pg-2",
+			expected:    pessimistic,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			nrt := makeNRT(tc.policy)
+			rs.UpdateNRTForPodGroup(nrt, tc.podGroup, "logID", "testResourceStoreUpdateForPodGroup")
+
+			for _, exp := range tc.expected {
+				resInfo := findResourceInfo(nrt.Zones[exp.zone].Resources, exp.name)
+				if resInfo == nil {
+					t.Fatalf("expected resource %q on zone %d, but missing", exp.name, exp.zone)
+				}
+				if resInfo.Available.Cmp(resource.MustParse(exp.value)) != 0 {
+					t.Errorf("bad availability for resource %q on zone %d: expected %v got %v", exp.name, exp.zone, exp.value, resInfo.Available)
+				}
+			}
+		})
+	}
+
+	t.Run("siblings fitting every zone, each accounted on one zone by remaining capacity", func(t *testing.T) {
+		rs := newResourceStore(klog.Background())
+		for _, name := range []string{"pod-0", "pod-1"} {
+			rs.AddPod(makePod(name, "pg-0", corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("12"),
+				corev1.ResourceMemory: resource.MustParse("4Gi"),
+			}))
+		}
+		nrt := makeNRT(topologyv1alpha2.SingleNUMANodePodLevel)
+		rs.UpdateNRTForPodGroup(nrt, "ns-0/pg-0", "logID", "testResourceStoreUpdateForPodGroup")
+
+		// pod-0 takes zone 0, which then cannot fit pod-1 anymore
+		for _, exp := range []expectedAvail{
+			{zone: 0, name: cpu, value: "8"},
+			{zone: 1, name: cpu, value: "8"},
+			{zone: 0, name: memory, value: "28Gi"},
+			{zone: 1, name: memory, value: "28Gi"},
+		} {
+			resInfo := findResourceInfo(nrt.Zones[exp.zone].Resources, exp.name)
+			if resInfo == nil {
+				t.Fatalf("expected resource %q on zone %d, but missing", exp.name, exp.zone)
+			}
+			if resInfo.Available.Cmp(resource.MustParse(exp.value)) != 0 {
+				t.Errorf("bad availability for resource %q on zone %d: expected %v got %v", exp.name, exp.zone, exp.value, resInfo.Available)
+			}
+		}
+	})
+}
+
 func TestCheckPodFingerprintForNode(t *testing.T) {
 	tcases := []struct {
 		description string
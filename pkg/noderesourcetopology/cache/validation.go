@@ -0,0 +1,138 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/events"
+
+	topologyv1alpha2 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2"
+	"github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2/helper"
+
+	apiconfig "sigs.k8s.io/scheduler-plugins/apis/config"
+)
+
+const (
+	// EventReasonInconsistentNRT is the reason of the Events emitted on Nodes whose NRT data is inconsistent
+	EventReasonInconsistentNRT = "InconsistentNodeResourceTopology"
+
+	eventActionValidate = "Validate"
+)
+
+// NRTValidator cross-validates the NodeResourceTopology data against the allocatable resources
+// of the corresponding Node, and reports the inconsistencies as Events on the Node.
+type NRTValidator struct {
+	mode       apiconfig.CacheNRTValidationMode
+	nodeLister corelisters.NodeLister
+	recorder   events.EventRecorder
+}
+
+// NewNRTValidator creates a new NRTValidator. The recorder can be nil, in which case no Events are emitted.
+func NewNRTValidator(mode apiconfig.CacheNRTValidationMode, nodeLister corelisters.NodeLister, recorder events.EventRecorder) *NRTValidator {
+	return &NRTValidator{
+		mode:       mode,
+		nodeLister: nodeLister,
+		recorder:   recorder,
+	}
+}
+
+// IsEnabled returns true if the validation is meant to run. Safe to call on nil receivers.
+func (nv *NRTValidator) IsEnabled() bool {
+	return nv != nil && nv.mode != "" && nv.mode != apiconfig.CacheNRTValidationNone
+}
+
+// Quarantines returns true if nodes with inconsistent NRT data should be excluded from scheduling.
+// Safe to call on nil receivers.
+func (nv *NRTValidator) Quarantines() bool {
+	return nv.IsEnabled() && nv.mode == apiconfig.CacheNRTValidationQuarantine
+}
+
+// Validate checks the given NRT object against the allocatable resources of the Node with the same name.
+// Returns the Node object and the validation error, if any. Returns a nil Node if the node can't be found,
+// in which case the NRT data can't be validated yet.
+func (nv *NRTValidator) Validate(nrt *topologyv1alpha2.NodeResourceTopology) (*corev1.Node, error) {
+	node, err := nv.nodeLister.Get(nrt.Name)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+		return nil, nil
+	}
+	return node, checkNRTConsistency(nrt, node.Status.Allocatable)
+}
+
+// Report emits a Warning Event on the given node describing the given inconsistency.
+func (nv *NRTValidator) Report(node *corev1.Node, err error) {
+	if nv.recorder == nil {
+		return
+	}
+	nv.recorder.Eventf(node, nil, corev1.EventTypeWarning, EventReasonInconsistentNRT, eventActionValidate,
+		"NodeResourceTopology data is inconsistent with node allocatable (validation=%s): %v", nv.mode, err)
+}
+
+// checkNRTConsistency returns an error describing all the inconsistencies found in the NUMA zones of the given NRT object
+// compared to the given node allocatable resources, or nil if the data is consistent. The data is inconsistent if
+// - any NUMA zone reports negative availability for any resource;
+// - the sum of the allocatable resources of the NUMA zones exceeds the node allocatable;
+// - any resource reported in the NUMA zones is missing from the node allocatable.
+func checkNRTConsistency(nrt *topologyv1alpha2.NodeResourceTopology, allocatable corev1.ResourceList) error {
+	var issues []string
+	totals := make(corev1.ResourceList)
+	for _, zone := range nrt.Zones {
+		if zone.Type != helper.ZoneTypeNUMANode {
+			continue
+		}
+		for _, resInfo := range zone.Resources {
+			if resInfo.Available.Sign() < 0 {
+				issues = append(issues, fmt.Sprintf("zone %q resource %q: negative availability %s", zone.Name, resInfo.Name, resInfo.Available.String()))
+			}
+			resName := corev1.ResourceName(resInfo.Name)
+			total := totals[resName]
+			total.Add(resInfo.Allocatable)
+			totals[resName] = total
+		}
+	}
+
+	resNames := make([]string, 0, len(totals))
+	for resName := range totals {
+		resNames = append(resNames, string(resName))
+	}
+	sort.Strings(resNames)
+
+	for _, resName := range resNames {
+		total := totals[corev1.ResourceName(resName)]
+		nodeQty, ok := allocatable[corev1.ResourceName(resName)]
+		if !ok {
+			issues = append(issues, fmt.Sprintf("resource %q: missing from node allocatable", resName))
+			continue
+		}
+		if total.Cmp(nodeQty) > 0 {
+			issues = append(issues, fmt.Sprintf("resource %q: NUMA zones allocatable %s exceeds node allocatable %s", resName, total.String(), nodeQty.String()))
+		}
+	}
+	if len(issues) == 0 {
+		return nil
+	}
+	return errors.New(strings.Join(issues, "; "))
+}
@@ -0,0 +1,269 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	topologyv1alpha2 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	k8scache "k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/klog/v2"
+
+	apiconfig "sigs.k8s.io/scheduler-plugins/apis/config"
+	tu "sigs.k8s.io/scheduler-plugins/test/util"
+)
+
+func makeValidationTestNRT(nodeName, allocatableCPU, availableCPU string) *topologyv1alpha2.NodeResourceTopology {
+	nrt := makeTestNRT(nodeName)
+	for zi := range nrt.Zones {
+		for ri := range nrt.Zones[zi].Resources {
+			res := &nrt.Zones[zi].Resources[ri]
+			res.Allocatable = res.Capacity.DeepCopy()
+			if res.Name == cpu {
+				res.Allocatable = resource.MustParse(allocatableCPU)
+				res.Available = resource.MustParse(availableCPU)
+			}
+		}
+	}
+	return nrt
+}
+
+func makeValidationTestNode(nodeName string, allocatable corev1.ResourceList) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: nodeName},
+		Status: corev1.NodeStatus{
+			Allocatable: allocatable,
+		},
+	}
+}
+
+func makeNodeLister(t *testing.T, nodes ...*corev1.Node) (corelisters.NodeLister, k8scache.Indexer) {
+	t.Helper()
+	indexer := k8scache.NewIndexer(k8scache.MetaNamespaceKeyFunc, k8scache.Indexers{})
+	for _, node := range nodes {
+		if err := indexer.Add(node); err != nil {
+			t.Fatalf("cannot add node %q: %v", node.Name, err)
+		}
+	}
+	return corelisters.NewNodeLister(indexer), indexer
+}
+
+func TestCheckNRTConsistency(t *testing.T) {
+	nodeAllocatable := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("64"),
+		corev1.ResourceMemory: resource.MustParse("64Gi"),
+		nicResourceName:       resource.MustParse("16"),
+	}
+
+	testCases := []struct {
+		description  string
+		nrt          *topologyv1alpha2.NodeResourceTopology
+		allocatable  corev1.ResourceList
+		expectedErrs []string
+	}{
+		{
+			description: "consistent",
+			nrt:         makeValidationTestNRT("node1", "30", "30"),
+			allocatable: nodeAllocatable,
+		},
+		{
+			description: "consistent, exact match",
+			nrt:         makeValidationTestNRT("node1", "32", "30"),
+			allocatable: nodeAllocatable,
+		},
+		{
+			description:  "negative availability",
+			nrt:          makeValidationTestNRT("node1", "30", "-2"),
+			allocatable:  nodeAllocatable,
+			expectedErrs: []string{`zone "node-0" resource "cpu": negative availability -2`, `zone "node-1" resource "cpu": negative availability -2`},
+		},
+		{
+			description:  "sum above allocatable",
+			nrt:          makeValidationTestNRT("node1", "40", "30"),
+			allocatable:  nodeAllocatable,
+			expectedErrs: []string{`resource "cpu": NUMA zones allocatable 80 exceeds node allocatable 64`},
+		},
+		{
+			description: "missing resource",
+			nrt:         makeValidationTestNRT("node1", "30", "30"),
+			allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("64"),
+				corev1.ResourceMemory: resource.MustParse("64Gi"),
+			},
+			expectedErrs: []string{`resource "vendor.com/nic1": missing from node allocatable`},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			err := checkNRTConsistency(tc.nrt, tc.allocatable)
+			if len(tc.expectedErrs) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected error, got nil")
+			}
+			for _, expectedErr := range tc.expectedErrs {
+				if !strings.Contains(err.Error(), expectedErr) {
+					t.Errorf("expected error to contain %q, got %q", expectedErr, err.Error())
+				}
+			}
+		})
+	}
+}
+
+func TestNRTStoreValidation(t *testing.T) {
+	nodeAllocatable := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("64"),
+		corev1.ResourceMemory: resource.MustParse("64Gi"),
+		nicResourceName:       resource.MustParse("16"),
+	}
+
+	testCases := []struct {
+		description         string
+		mode                apiconfig.CacheNRTValidationMode
+		expectedQuarantined bool
+		expectedEvents      int
+	}{
+		{
+			description: "none",
+			mode:        apiconfig.CacheNRTValidationNone,
+		},
+		{
+			description:    "warn",
+			mode:           apiconfig.CacheNRTValidationWarn,
+			expectedEvents: 1,
+		},
+		{
+			description:         "quarantine",
+			mode:                apiconfig.CacheNRTValidationQuarantine,
+			expectedQuarantined: true,
+			expectedEvents:      1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			nodeLister, _ := makeNodeLister(t, makeValidationTestNode("node1", nodeAllocatable))
+			recorder := events.NewFakeRecorder(10)
+
+			ns := newNrtStore(klog.Background(), nil)
+			ns.SetValidator(NewNRTValidator(tc.mode, nodeLister, recorder))
+
+			ns.Update(makeValidationTestNRT("node1", "40", "30"))
+			// same inconsistency, must not be reported again
+			ns.Update(makeValidationTestNRT("node1", "40", "30"))
+
+			if got := ns.IsQuarantined("node1"); got != tc.expectedQuarantined {
+				t.Errorf("quarantined: expected %v got %v", tc.expectedQuarantined, got)
+			}
+			if got := len(recorder.Events); got != tc.expectedEvents {
+				t.Errorf("events: expected %d got %d", tc.expectedEvents, got)
+			}
+			if tc.expectedEvents > 0 {
+				ev := <-recorder.Events
+				if !strings.Contains(ev, EventReasonInconsistentNRT) {
+					t.Errorf("unexpected event: %q", ev)
+				}
+			}
+
+			ns.Update(makeValidationTestNRT("node1", "30", "30"))
+			if ns.IsQuarantined("node1") {
+				t.Errorf("node still quarantined after consistent update")
+			}
+		})
+	}
+}
+
+func TestNRTStoreValidationPendingNode(t *testing.T) {
+	nodeLister, indexer := makeNodeLister(t)
+
+	ns := newNrtStore(klog.Background(), nil)
+	ns.SetValidator(NewNRTValidator(apiconfig.CacheNRTValidationQuarantine, nodeLister, nil))
+
+	ns.Update(makeValidationTestNRT("node1", "40", "30"))
+	if ns.IsQuarantined("node1") {
+		t.Fatalf("node quarantined before being validated")
+	}
+
+	err := indexer.Add(makeValidationTestNode("node1", corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("64"),
+		corev1.ResourceMemory: resource.MustParse("64Gi"),
+		nicResourceName:       resource.MustParse("16"),
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ns.Revalidate()
+	if !ns.IsQuarantined("node1") {
+		t.Fatalf("node not quarantined after being validated")
+	}
+
+	// node allocatable updated, the NRT data is now consistent
+	err = indexer.Update(makeValidationTestNode("node1", corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("80"),
+		corev1.ResourceMemory: resource.MustParse("64Gi"),
+		nicResourceName:       resource.MustParse("16"),
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ns.Revalidate()
+	if ns.IsQuarantined("node1") {
+		t.Fatalf("node still quarantined after node allocatable update")
+	}
+}
+
+func TestOverReserveQuarantinedNode(t *testing.T) {
+	nrtObj := makeValidationTestNRT("node1", "40", "30")
+	fakeClient, err := tu.NewFakeClient(nrtObj)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nodeLister, _ := makeNodeLister(t, makeValidationTestNode("node1", corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("64"),
+		corev1.ResourceMemory: resource.MustParse("64Gi"),
+		nicResourceName:       resource.MustParse("16"),
+	}))
+
+	nrtCache := mustOverReserve(t, fakeClient, &fakePodLister{})
+	nrtCache.SetNRTValidator(NewNRTValidator(apiconfig.CacheNRTValidationQuarantine, nodeLister, nil))
+
+	_, info := nrtCache.GetCachedNRTCopy(context.Background(), "node1", &corev1.Pod{})
+	if info.Fresh {
+		t.Errorf("succesfully got quarantined node!")
+	}
+
+	nodes := nrtCache.GetDesyncedNodes(klog.Background())
+	if len(nodes.Quarantined) != 1 || nodes.Quarantined[0] != "node1" {
+		t.Errorf("unexpected quarantined nodes: %v", nodes.Quarantined)
+	}
+}
@@ -0,0 +1,264 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package capacity computes how many more pods of given shapes fit, NUMA-aligned, on the nodes of a cluster,
+// using the same fit semantics of the NodeResourceTopologyMatch Filter.
+package capacity
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/go-logr/logr"
+	topologyv1alpha2 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	nrtcache "sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/cache"
+	"sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/logging"
+	"sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/nodeconfig"
+	numaplacement "sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/numaplacement/v1alpha1"
+	"sigs.k8s.io/scheduler-plugins/pkg/util"
+)
+
+const (
+	// DefaultMaxPods is the pod capacity assumed for the nodes whose Node object is not available,
+	// matching the kubelet default.
+	DefaultMaxPods = 110
+
+	OutputText = "text"
+	OutputJSON = "json"
+)
+
+// NodeReport tells how many more pods of each shape fit on a node.
+type NodeReport struct {
+	Name   string         `json:"name"`
+	Policy string         `json:"policy"`
+	Scope  string         `json:"scope"`
+	Fits   map[string]int `json:"fits"`
+}
+
+// Report tells how many more pods of each shape fit on each node, and in the whole cluster.
+// Each shape is considered in isolation: the counts of different shapes don't add up.
+type Report struct {
+	Shapes []string       `json:"shapes"`
+	Nodes  []NodeReport   `json:"nodes"`
+	Totals map[string]int `json:"totals"`
+}
+
+// Compute computes the Report for the given shapes on the nodes which have NodeResourceTopology data in the given Snapshot.
+// The pods bound to a node but not running yet are not expected to be accounted in the NodeResourceTopology data,
+// so their resources are deducted from all the NUMA zones, like the OverReserve cache does.
+// The node-level resources are computed from the Node allocatable resources and the requests of all the pods bound
+// to the node; if the Node object is missing, they are approximated using the NodeResourceTopology data.
+// The counts are keyed by shape name, so the names of the shapes must be unique.
+func Compute(lh logr.Logger, snap Snapshot, shapes []Shape) (Report, error) {
+	report := Report{
+		Shapes: make([]string, 0, len(shapes)),
+		Totals: make(map[string]int),
+	}
+	for _, shape := range shapes {
+		if _, ok := report.Totals[shape.Name]; ok {
+			return report, fmt.Errorf("duplicate shape %q", shape.Name)
+		}
+		report.Shapes = append(report.Shapes, shape.Name)
+		report.Totals[shape.Name] = 0
+	}
+
+	nodes := make(map[string]*corev1.Node)
+	for idx := range snap.Nodes {
+		nodes[snap.Nodes[idx].Name] = &snap.Nodes[idx]
+	}
+	podsByNode := make(map[string][]*corev1.Pod)
+	for idx := range snap.Pods {
+		pod := &snap.Pods[idx]
+		if pod.Spec.NodeName == "" || isTerminal(pod) {
+			continue
+		}
+		podsByNode[pod.Spec.NodeName] = append(podsByNode[pod.Spec.NodeName], pod)
+	}
+
+	nrts := make([]*topologyv1alpha2.NodeResourceTopology, 0, len(snap.NRTs))
+	for idx := range snap.NRTs {
+		nrts = append(nrts, &snap.NRTs[idx])
+	}
+	sort.Slice(nrts, func(i, j int) bool { return nrts[i].Name < nrts[j].Name })
+
+	for _, nrt := range nrts {
+		nlh := lh.WithValues(logging.KeyNode, nrt.Name)
+		nodeReport, err := computeNode(nlh, nrt, nodes[nrt.Name], podsByNode[nrt.Name], shapes)
+		if err != nil {
+			return report, fmt.Errorf("node %q: %w", nrt.Name, err)
+		}
+		for name, count := range nodeReport.Fits {
+			report.Totals[name] += count
+		}
+		report.Nodes = append(report.Nodes, nodeReport)
+	}
+	return report, nil
+}
+
+func computeNode(lh logr.Logger, nrt *topologyv1alpha2.NodeResourceTopology, node *corev1.Node, pods []*corev1.Pod, shapes []Shape) (NodeReport, error) {
+	var inFlight []*corev1.Pod
+	for _, pod := range pods {
+		if pod.Status.Phase == "" || pod.Status.Phase == corev1.PodPending {
+			inFlight = append(inFlight, pod)
+		}
+	}
+	nrt = nrtcache.DeductPods(lh, nrt, inFlight)
+
+	conf := nodeconfig.TopologyManagerFromNodeResourceTopology(lh, nrt)
+	nodeReport := NodeReport{
+		Name:   nrt.Name,
+		Policy: conf.Policy,
+		Scope:  conf.Scope,
+		Fits:   make(map[string]int),
+	}
+
+	var nodeAllocatable, free corev1.ResourceList
+	if node != nil {
+		nodeAllocatable = node.Status.Allocatable
+		free = nodeAllocatable.DeepCopy()
+		for _, pod := range pods {
+			subtractResources(free, util.PodRequests(pod))
+		}
+	} else {
+		lh.V(2).Info("node object missing, approximating node resources")
+		nodeAllocatable = numaplacement.AllocatableFromNRT(nrt)
+		free = availableFromNRT(nrt)
+	}
+	if _, ok := free[corev1.ResourcePods]; !ok {
+		free[corev1.ResourcePods] = *resource.NewQuantity(DefaultMaxPods, resource.DecimalSI)
+	}
+	subtractResources(free, corev1.ResourceList{
+		corev1.ResourcePods: *resource.NewQuantity(int64(len(pods)), resource.DecimalSI),
+	})
+
+	for _, shape := range shapes {
+		count, err := countFits(lh.WithValues("shape", shape.Name), conf, nodeAllocatable, free.DeepCopy(), numaplacement.NewNUMANodeList(lh, nrt.Zones), shape.Pod)
+		if err != nil {
+			return nodeReport, err
+		}
+		nodeReport.Fits[shape.Name] = count
+	}
+	return nodeReport, nil
+}
+
+// countFits places copies of the given pod on the node until either the node-level resources or the NUMA-aligned
+// resources are exhausted. Each placed pod takes a slot out of the pods resource, so the loop always terminates.
+func countFits(lh logr.Logger, conf nodeconfig.TopologyManager, nodeAllocatable, free corev1.ResourceList, numaNodes numaplacement.NUMANodeList, pod *corev1.Pod) (int, error) {
+	request := util.PodRequests(pod)
+	request[corev1.ResourcePods] = *resource.NewQuantity(1, resource.DecimalSI)
+
+	count := 0
+	for fitsResources(free, request) {
+		plan, err := numaplacement.PlaceOnNUMANodes(lh, conf, nodeAllocatable, numaNodes, pod)
+		if err != nil {
+			return count, err
+		}
+		if !plan.Admit {
+			lh.V(4).Info("cannot place more pods", "reason", plan.Reason, "resource", plan.Resource, "count", count)
+			break
+		}
+		if err := numaplacement.Reserve(lh, numaNodes, plan, pod); err != nil {
+			return count, err
+		}
+		subtractResources(free, request)
+		count++
+	}
+	return count, nil
+}
+
+func fitsResources(free, request corev1.ResourceList) bool {
+	for name, qty := range request {
+		if qty.IsZero() {
+			continue
+		}
+		avail, ok := free[name]
+		if !ok || avail.Cmp(qty) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func subtractResources(free, request corev1.ResourceList) {
+	for name, qty := range request {
+		avail, ok := free[name]
+		if !ok {
+			continue
+		}
+		avail.Sub(qty)
+		free[name] = avail
+	}
+}
+
+func availableFromNRT(nrt *topologyv1alpha2.NodeResourceTopology) corev1.ResourceList {
+	available := make(corev1.ResourceList)
+	for _, zone := range nrt.Zones {
+		for _, resInfo := range zone.Resources {
+			resName := corev1.ResourceName(resInfo.Name)
+			qty := available[resName]
+			qty.Add(resInfo.Available)
+			available[resName] = qty
+		}
+	}
+	return available
+}
+
+func isTerminal(pod *corev1.Pod) bool {
+	return pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed
+}
+
+// Write writes the given Report to the given writer, using the given format, either OutputText or OutputJSON.
+func Write(w io.Writer, report Report, format string) error {
+	switch format {
+	case OutputJSON:
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	case OutputText, "":
+		return writeText(w, report)
+	default:
+		return fmt.Errorf("unsupported output format %q", format)
+	}
+}
+
+func writeText(w io.Writer, report Report) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(tw, "NODE\tPOLICY\tSCOPE\t%s\n", strings.Join(report.Shapes, "\t"))
+	for _, node := range report.Nodes {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", node.Name, node.Policy, node.Scope, joinCounts(report.Shapes, node.Fits))
+	}
+	fmt.Fprintf(tw, "TOTAL\t\t\t%s\n", joinCounts(report.Shapes, report.Totals))
+	return tw.Flush()
+}
+
+func joinCounts(shapes []string, counts map[string]int) string {
+	items := make([]string, 0, len(shapes))
+	for _, shape := range shapes {
+		items = append(items, fmt.Sprintf("%d", counts[shape]))
+	}
+	return strings.Join(items, "\t")
+}
@@ -0,0 +1,263 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capacity
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/klog/v2/ktesting"
+)
+
+const testSnapshotYAML = `
+apiVersion: topology.node.k8s.io/v1alpha2
+kind: NodeResourceTopology
+metadata:
+  name: node1
+topologyPolicies:
+- SingleNUMANodePodLevel
+zones:
+- name: node-0
+  type: Node
+  resources:
+  - name: cpu
+    capacity: "16"
+    allocatable: "16"
+    available: "8"
+  - name: memory
+    capacity: 32Gi
+    allocatable: 32Gi
+    available: 32Gi
+- name: node-1
+  type: Node
+  resources:
+  - name: cpu
+    capacity: "16"
+    allocatable: "16"
+    available: "6"
+  - name: memory
+    capacity: 32Gi
+    allocatable: 32Gi
+    available: 32Gi
+---
+apiVersion: v1
+kind: List
+items:
+- apiVersion: topology.node.k8s.io/v1alpha2
+  kind: NodeResourceTopology
+  metadata:
+    name: node2
+  topologyPolicies:
+  - SingleNUMANodeContainerLevel
+  zones:
+  - name: node-0
+    type: Node
+    resources:
+    - name: cpu
+      capacity: "16"
+      allocatable: "16"
+      available: "16"
+    - name: memory
+      capacity: 32Gi
+      allocatable: 32Gi
+      available: 32Gi
+- apiVersion: v1
+  kind: Node
+  metadata:
+    name: node2
+  status:
+    allocatable:
+      cpu: "16"
+      memory: 32Gi
+      pods: "3"
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: ignored
+- apiVersion: v1
+  kind: Pod
+  metadata:
+    name: pending
+    namespace: ns
+  spec:
+    nodeName: node1
+    containers:
+    - name: cnt
+      resources:
+        requests:
+          cpu: "2"
+          memory: 1Gi
+        limits:
+          cpu: "2"
+          memory: 1Gi
+  status:
+    phase: Pending
+- apiVersion: v1
+  kind: Pod
+  metadata:
+    name: running
+    namespace: ns
+  spec:
+    nodeName: node2
+    containers:
+    - name: cnt
+      resources:
+        requests:
+          cpu: "1"
+          memory: 1Gi
+  status:
+    phase: Running
+- apiVersion: v1
+  kind: Pod
+  metadata:
+    name: completed
+    namespace: ns
+  spec:
+    nodeName: node2
+    containers:
+    - name: cnt
+      resources:
+        requests:
+          cpu: "1"
+  status:
+    phase: Succeeded
+`
+
+func loadTestSnapshot(t *testing.T) Snapshot {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "snapshot.yaml")
+	if err := os.WriteFile(path, []byte(testSnapshotYAML), 0600); err != nil {
+		t.Fatal(err)
+	}
+	snap, err := LoadFromFiles(path)
+	if err != nil {
+		t.Fatalf("cannot load snapshot: %v", err)
+	}
+	return snap
+}
+
+func TestLoadFromFiles(t *testing.T) {
+	snap := loadTestSnapshot(t)
+	if len(snap.NRTs) != 2 || len(snap.Nodes) != 1 || len(snap.Pods) != 3 {
+		t.Errorf("unexpected snapshot: nrts=%d nodes=%d pods=%d", len(snap.NRTs), len(snap.Nodes), len(snap.Pods))
+	}
+}
+
+func TestParseShape(t *testing.T) {
+	shape, err := ParseShape("cpu=4,memory=8Gi")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	res := shape.Pod.Spec.Containers[0].Resources
+	if res.Requests.Cpu().String() != "4" || res.Limits.Memory().String() != "8Gi" {
+		t.Errorf("unexpected resources: %v", res)
+	}
+
+	for _, value := range []string{"", "cpu", "cpu=four"} {
+		if _, err := ParseShape(value); err == nil {
+			t.Errorf("expected error parsing %q", value)
+		}
+	}
+}
+
+func TestCompute(t *testing.T) {
+	shapes := []Shape{}
+	for _, value := range []string{"cpu=2,memory=1Gi", "cpu=6,memory=1Gi"} {
+		shape, err := ParseShape(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		shapes = append(shapes, shape)
+	}
+
+	report, err := Compute(ktesting.NewLogger(t, ktesting.DefaultConfig), loadTestSnapshot(t), shapes)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := Report{
+		Shapes: []string{"cpu=2,memory=1Gi", "cpu=6,memory=1Gi"},
+		Nodes: []NodeReport{
+			{
+				// the pending pod is deducted from all the zones: 6 and 4 CPUs left
+				Name:   "node1",
+				Policy: "single-numa-node",
+				Scope:  "pod",
+				Fits: map[string]int{
+					"cpu=2,memory=1Gi": 5,
+					"cpu=6,memory=1Gi": 1,
+				},
+			},
+			{
+				// the pods capacity (3) minus the running pod leaves room for 2 pods
+				Name:   "node2",
+				Policy: "single-numa-node",
+				Scope:  "container",
+				Fits: map[string]int{
+					"cpu=2,memory=1Gi": 2,
+					"cpu=6,memory=1Gi": 2,
+				},
+			},
+		},
+		Totals: map[string]int{
+			"cpu=2,memory=1Gi": 7,
+			"cpu=6,memory=1Gi": 3,
+		},
+	}
+	if !reflect.DeepEqual(report, expected) {
+		t.Errorf("got %#v expected %#v", report, expected)
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, report, OutputText); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 4 || !strings.HasPrefix(lines[3], "TOTAL") {
+		t.Errorf("unexpected text output:\n%s", buf.String())
+	}
+	if err := Write(&buf, report, "yaml"); err == nil {
+		t.Errorf("expected error for unsupported format")
+	}
+}
+
+func TestShapesFromPods(t *testing.T) {
+	snap := loadTestSnapshot(t)
+	// the same pod name in another namespace must make a distinct shape
+	other := snap.Pods[0].DeepCopy()
+	other.Namespace = snap.Pods[0].Namespace + "-other"
+	shapes := ShapesFromPods(append(snap.Pods, *other))
+
+	names := make(map[string]bool)
+	for _, shape := range shapes {
+		if names[shape.Name] {
+			t.Errorf("duplicate shape name %q", shape.Name)
+		}
+		names[shape.Name] = true
+	}
+	if !names[snap.Pods[0].Namespace+"/"+snap.Pods[0].Name] {
+		t.Errorf("expected shape names as namespace/name, got %v", names)
+	}
+
+	if _, err := Compute(ktesting.NewLogger(t, ktesting.DefaultConfig), snap, append(shapes, shapes[0])); err == nil {
+		t.Errorf("expected error for duplicate shapes")
+	}
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capacity
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+// Shape is a pod whose fit is computed.
+type Shape struct {
+	Name string
+	Pod  *corev1.Pod
+}
+
+// ParseShape creates a Shape from its compact representation, a comma-separated list of resource=quantity pairs,
+// for example "cpu=4,memory=8Gi". The Shape is a Guaranteed pod with a single container requesting the given resources.
+func ParseShape(value string) (Shape, error) {
+	res := corev1.ResourceList{}
+	for _, item := range strings.Split(value, ",") {
+		name, qty, ok := strings.Cut(strings.TrimSpace(item), "=")
+		if !ok || name == "" {
+			return Shape{}, fmt.Errorf("malformed shape item %q: expected resource=quantity", item)
+		}
+		q, err := resource.ParseQuantity(qty)
+		if err != nil {
+			return Shape{}, fmt.Errorf("malformed quantity for resource %q: %w", name, err)
+		}
+		res[corev1.ResourceName(name)] = q
+	}
+	return Shape{
+		Name: value,
+		Pod: &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "shape",
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{
+						Name: "shape",
+						Resources: corev1.ResourceRequirements{
+							Requests: res,
+							Limits:   res,
+						},
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+// ShapesFromPods creates a Shape for each of the given pods, named after the namespace and name of the pod,
+// or just the name if the pod has no namespace.
+func ShapesFromPods(pods []corev1.Pod) []Shape {
+	shapes := make([]Shape, 0, len(pods))
+	for idx := range pods {
+		shapes = append(shapes, Shape{
+			Name: klog.KObj(&pods[idx]).String(),
+			Pod:  &pods[idx],
+		})
+	}
+	return shapes
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capacity
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	topologyv1alpha2 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Scheme knows about all the objects a Snapshot can be made of.
+var Scheme = runtime.NewScheme()
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(Scheme))
+	utilruntime.Must(topologyv1alpha2.AddToScheme(Scheme))
+}
+
+// Snapshot is the cluster state a Report is computed from.
+type Snapshot struct {
+	NRTs  []topologyv1alpha2.NodeResourceTopology
+	Nodes []corev1.Node
+	Pods  []corev1.Pod
+}
+
+// LoadFromCluster lists all the objects needed to compute a Report from the cluster.
+func LoadFromCluster(ctx context.Context, cli ctrlclient.Reader) (Snapshot, error) {
+	var snap Snapshot
+
+	nrtList := topologyv1alpha2.NodeResourceTopologyList{}
+	if err := cli.List(ctx, &nrtList); err != nil {
+		return snap, fmt.Errorf("cannot list NodeResourceTopologies: %w", err)
+	}
+	nodeList := corev1.NodeList{}
+	if err := cli.List(ctx, &nodeList); err != nil {
+		return snap, fmt.Errorf("cannot list Nodes: %w", err)
+	}
+	podList := corev1.PodList{}
+	if err := cli.List(ctx, &podList); err != nil {
+		return snap, fmt.Errorf("cannot list Pods: %w", err)
+	}
+
+	snap.NRTs = nrtList.Items
+	snap.Nodes = nodeList.Items
+	snap.Pods = podList.Items
+	return snap, nil
+}
+
+// LoadFromFiles reads the objects needed to compute a Report from YAML or JSON dumps, like the ones
+// produced by `kubectl get -o yaml`. Each file can contain many documents, and each document can be either
+// a single object or a List. Objects of kinds other than NodeResourceTopology, Node and Pod are ignored.
+func LoadFromFiles(paths ...string) (Snapshot, error) {
+	var snap Snapshot
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return snap, err
+		}
+		if err := snap.load(bytes.NewReader(data)); err != nil {
+			return snap, fmt.Errorf("cannot load %q: %w", path, err)
+		}
+	}
+	return snap, nil
+}
+
+func (snap *Snapshot) load(r io.Reader) error {
+	decoder := serializer.NewCodecFactory(Scheme).UniversalDeserializer()
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(r))
+	for {
+		doc, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if len(bytes.TrimSpace(doc)) == 0 {
+			continue
+		}
+		if err := snap.decode(decoder, doc); err != nil {
+			return err
+		}
+	}
+}
+
+func (snap *Snapshot) decode(decoder runtime.Decoder, data []byte) error {
+	obj, _, err := decoder.Decode(data, nil, nil)
+	if err != nil {
+		if runtime.IsNotRegisteredError(err) {
+			return nil
+		}
+		return err
+	}
+
+	switch obj := obj.(type) {
+	case *topologyv1alpha2.NodeResourceTopology:
+		snap.NRTs = append(snap.NRTs, *obj)
+	case *topologyv1alpha2.NodeResourceTopologyList:
+		snap.NRTs = append(snap.NRTs, obj.Items...)
+	case *corev1.Node:
+		snap.Nodes = append(snap.Nodes, *obj)
+	case *corev1.NodeList:
+		snap.Nodes = append(snap.Nodes, obj.Items...)
+	case *corev1.Pod:
+		snap.Pods = append(snap.Pods, *obj)
+	case *corev1.PodList:
+		snap.Pods = append(snap.Pods, obj.Items...)
+	case *corev1.List:
+		for _, item := range obj.Items {
+			if err := snap.decode(decoder, item.Raw); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -19,19 +19,15 @@ package noderesourcetopology
 import (
 	v1 "k8s.io/api/core/v1"
 	fwk "k8s.io/kube-scheduler/framework"
+	v1helper "k8s.io/kubernetes/pkg/apis/core/v1/helper"
 	"k8s.io/kubernetes/pkg/kubelet/cm/topologymanager/bitmask"
 
 	"github.com/go-logr/logr"
-	"gonum.org/v1/gonum/stat/combin"
 
+	numaplacement "sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/numaplacement/v1alpha1"
 	"sigs.k8s.io/scheduler-plugins/pkg/util"
 )
 
-const (
-	// 255 is max value as defined by ACPI SLIT(System Locality Information Tables), which means unknown/undefined
-	maxDistanceValue = 255
-)
-
 func leastNUMAContainerScopeScore(lh logr.Logger, pod *v1.Pod, info *scoreInfo) (int64, *fwk.Status) {
 	maxNUMANodesCount := 0
 	allContainersMinAvgDistance := true
@@ -71,21 +67,24 @@ func leastNUMAContainerScopeScore(lh logr.Logger, pod *v1.Pod, info *scoreInfo)
 }
 
 func leastNUMAPodScopeScore(lh logr.Logger, pod *v1.Pod, info *scoreInfo) (int64, *fwk.Status) {
-	resources := util.GetPodEffectiveRequest(pod)
+	resources := util.PodRequests(pod)
 	// if a pod requests only non NUMA resources return max score
 	if onlyNonNUMAResources(info.numaNodes, resources) {
 		return fwk.MaxNodeScore, nil
 	}
 
-	numaNodes, isMinAvgDistance := numaNodesRequired(lh, info.qos, info.numaNodes, resources)
+	combination, _ := numaplacement.MinimalCombination(lh, info.qos, info.numaNodes, resources)
 	// pod's resources can't fit onto node, return MinNodeScore
-	if numaNodes == nil {
+	if combination == nil {
 		// score plugin should be running after resource filter plugin so we should always find sufficient amount of NUMA nodes
 		lh.Info("cannot calculate how many NUMA nodes are required")
 		return fwk.MinNodeScore, nil
 	}
 
-	return normalizeScore(numaNodes.Count(), isMinAvgDistance, info.topologyManager.MaxNUMANodes), nil
+	distance := distanceCloseness(info.numaNodes, combination)
+	stranded := strandedRatio(info.numaNodes, resources, combination)
+	lh.V(6).Info("pod scope NUMA combination", "numaCells", len(combination), "distanceCloseness", distance, "strandedRatio", stranded)
+	return normalizeDistanceScore(len(combination), distance, stranded, info.topologyManager.MaxNUMANodes), nil
 }
 
 func normalizeScore(numaNodesCount int, isMinAvgDistance bool, highestNUMAID int) int64 {
@@ -99,135 +98,93 @@ func normalizeScore(numaNodesCount int, isMinAvgDistance bool, highestNUMAID int
 	return score
 }
 
-func minAvgDistanceInCombinations(lh logr.Logger, numaNodes NUMANodeList, numaNodesCombination [][]int) float32 {
-	// max distance for NUMA node
-	var minDistance float32 = maxDistanceValue
-
-	for _, combination := range numaNodesCombination {
-		avgDistance := nodesAvgDistance(lh, numaNodes, combination...)
-		if avgDistance < minDistance {
-			minDistance = avgDistance
-		}
-	}
+// normalizeDistanceScore works like normalizeScore, but replaces the fixed bonus for the optimal distance with a
+// continuous one, which never exceeds the score of a single NUMA node, so the NUMA nodes count still comes first.
+// Within the same NUMA nodes count, the bonus mostly favors combinations whose NUMA nodes are close to each other
+// (distanceCloseness close to 1), then the ones which leave few CPUs and devices stranded (strandedRatio close to 0).
+func normalizeDistanceScore(numaNodesCount int, distanceCloseness, strandedRatio float64, highestNUMAID int) int64 {
+	numaNodeScore := fwk.MaxNodeScore / int64(highestNUMAID)
+	score := fwk.MaxNodeScore - int64(numaNodesCount)*numaNodeScore
 
-	return minDistance
+	distanceWeight := numaNodeScore * 2 / 3
+	strandedWeight := max(numaNodeScore-1-distanceWeight, 0)
+	bonus := float64(distanceWeight)*distanceCloseness + float64(strandedWeight)*(1-strandedRatio)
+	return score + int64(bonus)
 }
 
-func nodesAvgDistance(lh logr.Logger, numaNodes NUMANodeList, nodes ...int) float32 {
-	if len(nodes) == 0 {
-		return maxDistanceValue
+// farDistanceRatio is the ratio between the average distance of the NUMA nodes of a combination and their local
+// distance from which the combination counts as far apart, e.g. NUMA nodes on different sockets, 20 to 10 away.
+const farDistanceRatio = 1.5
+
+// distanceCloseness tells how close to each other the NUMA nodes in the given combination are: 1 when their
+// average distance is the local distance, as for a single NUMA node, down to 0 when it is farDistanceRatio times
+// the local distance or more. A combination of several NUMA nodes whose distances are not all reported in the
+// Costs yields 0, so that nodes without Costs information are never favored over nodes reporting them.
+func distanceCloseness(numaNodes NUMANodeList, combination []int) float64 {
+	if len(combination) <= 1 {
+		return 1
 	}
-
-	var (
-		accu int
-	)
-
-	for _, node1 := range nodes {
-		for _, node2 := range nodes {
-			cost, ok := numaNodes[node1].Costs[numaNodes[node2].NUMAID]
-			// we couldn't read Costs assign maxDistanceValue
-			if !ok {
-				lh.Info("cannot retrieve Costs information", "nodeID", numaNodes[node1].NUMAID)
-				cost = maxDistanceValue
+	localDistance := numaplacement.MaxDistanceValue
+	accu := 0
+	for _, node1 := range combination {
+		for _, node2 := range combination {
+			distance, ok := numaNodes[node1].Costs[numaNodes[node2].NUMAID]
+			if !ok || distance <= 0 {
+				return 0
 			}
-			accu += cost
-		}
-	}
-
-	return float32(accu) / float32(len(nodes)*len(nodes))
-}
-
-func combineResources(numaNodes NUMANodeList, combination []int) v1.ResourceList {
-	resources := v1.ResourceList{}
-	for _, nodeIndex := range combination {
-		for resource, quantity := range numaNodes[nodeIndex].Resources {
-			if value, ok := resources[resource]; ok {
-				value.Add(quantity)
-				resources[resource] = value
-				continue
+			if node1 == node2 {
+				localDistance = min(localDistance, distance)
 			}
-			resources[resource] = quantity
+			accu += distance
 		}
 	}
-
-	return resources
+	avgDistance := float64(accu) / float64(len(combination)*len(combination))
+	excess := avgDistance/float64(localDistance) - 1
+	return min(max(1-excess/(farDistanceRatio-1), 0), 1)
 }
 
-// numaNodesRequired returns bitmask with minimal NUMA nodes required to run given resources
-// or nil when resources can't be fitted onto the worker node
-// second value returned is a boolean indicating if bitmask is optimal from distance perspective
-func numaNodesRequired(lh logr.Logger, qos v1.PodQOSClass, numaNodes NUMANodeList, resources v1.ResourceList) (bitmask.BitMask, bool) {
-	for bitmaskLen := 1; bitmaskLen <= len(numaNodes); bitmaskLen++ {
-		numaNodesCombination := combin.Combinations(len(numaNodes), bitmaskLen)
-		suitableCombination, isMinDistance := findSuitableCombination(lh, qos, numaNodes, resources, numaNodesCombination)
-		// we have found suitable combination for given bitmaskLen
-		if suitableCombination != nil {
-			bm := bitmask.NewEmptyBitMask()
-			for _, nodeIdx := range suitableCombination {
-				bm.Add(numaNodes[nodeIdx].NUMAID)
-			}
-			return bm, isMinDistance
-		}
-	}
-
-	return nil, false
-}
-
-// findSuitableCombination returns combination from numaNodesCombination that can fit resources, otherwise return nil
-// second value returned is a boolean indicating if returned combination is optimal from distance perspective
-// this function will always return combination that provides minimal average distance between nodes in combination
-func findSuitableCombination(lh logr.Logger, qos v1.PodQOSClass, numaNodes NUMANodeList, resources v1.ResourceList, numaNodesCombination [][]int) ([]int, bool) {
-	minAvgDistance := minAvgDistanceInCombinations(lh, numaNodes, numaNodesCombination)
+// strandedRatio returns the average fraction of the CPUs and devices available in the given combination
+// which would be left unused once the given resources are allocated on it.
+func strandedRatio(numaNodes NUMANodeList, resources v1.ResourceList, combination []int) float64 {
+	combinationResources := numaplacement.CombineResources(numaNodes, combination)
 	var (
-		minDistanceCombination []int
-		// init as max distance
-		minDistance float32 = 256
+		accu  float64
+		count int
 	)
-	for _, combination := range numaNodesCombination {
-		if !isValidCombineResources(numaNodes, resources, combination) {
+	for resource, quantity := range resources {
+		if quantity.IsZero() {
 			continue
 		}
-		combinationResources := combineResources(numaNodes, combination)
-		resourcesFit := checkResourcesFit(lh, qos, resources, combinationResources)
-
-		if resourcesFit {
-			distance := nodesAvgDistance(lh, numaNodes, combination...)
-			if distance == minAvgDistance {
-				// return early if we can fit resources into combination and provide minDistance
-				return combination, true
-			}
-			// we don't have to check which combination bitmask has lower value since we are generating them from lowest value
-			if distance < minDistance {
-				minDistance = distance
-				minDistanceCombination = combination
-			}
+		if resource != v1.ResourceCPU && v1helper.IsNativeResource(resource) {
+			continue
 		}
-	}
-
-	return minDistanceCombination, false
-}
-
-func checkResourcesFit(lh logr.Logger, qos v1.PodQOSClass, resources v1.ResourceList, combinationResources v1.ResourceList) bool {
-	for resource, quantity := range resources {
-		if quantity.IsZero() {
-			lh.V(4).Info("ignoring zero-qty resource request", "resource", resource)
+		available, ok := combinationResources[resource]
+		if !ok || available.IsZero() {
 			continue
 		}
-		if combinationQuantity := combinationResources[resource]; !isResourceSetSuitable(qos, resource, quantity, combinationQuantity) {
-			return false
+		left := available.AsApproximateFloat64() - quantity.AsApproximateFloat64()
+		if left > 0 {
+			accu += left / available.AsApproximateFloat64()
 		}
+		count++
 	}
-
-	return true
+	if count == 0 {
+		return 0
+	}
+	return accu / float64(count)
 }
 
-func isValidCombineResources(numaNodes NUMANodeList, resources v1.ResourceList, combination []int) bool {
-	for _, nodeIndex := range combination {
-		for resourceName := range resources {
-			if _, ok := numaNodes[nodeIndex].Resources[resourceName]; !ok {
-				return false
-			}
-		}
+// numaNodesRequired returns bitmask with minimal NUMA nodes required to run given resources
+// or nil when resources can't be fitted onto the worker node
+// second value returned is a boolean indicating if bitmask is optimal from distance perspective
+func numaNodesRequired(lh logr.Logger, qos v1.PodQOSClass, numaNodes NUMANodeList, resources v1.ResourceList) (bitmask.BitMask, bool) {
+	combination, isMinDistance := numaplacement.MinimalCombination(lh, qos, numaNodes, resources)
+	if combination == nil {
+		return nil, false
+	}
+	bm := bitmask.NewEmptyBitMask()
+	for _, nodeIdx := range combination {
+		bm.Add(numaNodes[nodeIdx].NUMAID)
 	}
-	return true
+	return bm, isMinDistance
 }
@@ -18,6 +18,7 @@ package noderesourcetopology
 
 import (
 	"fmt"
+	"math"
 	"testing"
 
 	v1 "k8s.io/api/core/v1"
@@ -755,7 +756,63 @@ func TestNormalizeScore(t *testing.T) {
 	}
 }
 
-func TestMinDistance(t *testing.T) {
+func TestNormalizeDistanceScore(t *testing.T) {
+	tcases := []struct {
+		description       string
+		numaNodes         int
+		distanceCloseness float64
+		strandedRatio     float64
+		expectedScore     int64
+	}{
+		{
+			description:       "1 numa node, nothing stranded",
+			numaNodes:         1,
+			distanceCloseness: 1,
+			expectedScore:     99,
+		},
+		{
+			description:       "1 numa node, half stranded",
+			numaNodes:         1,
+			distanceCloseness: 1,
+			strandedRatio:     0.5,
+			expectedScore:     97,
+		},
+		{
+			description:       "2 numa nodes, close, nothing stranded",
+			numaNodes:         2,
+			distanceCloseness: 0.8,
+			expectedScore:     85,
+		},
+		{
+			description:   "2 numa nodes, far apart, nothing stranded",
+			numaNodes:     2,
+			expectedScore: 79,
+		},
+		{
+			description:   "2 numa nodes, far apart, all stranded",
+			numaNodes:     2,
+			strandedRatio: 1,
+			expectedScore: 76,
+		},
+		{
+			description:   "8 numa nodes, worst case",
+			numaNodes:     8,
+			strandedRatio: 1,
+			expectedScore: 4,
+		},
+	}
+
+	for _, tc := range tcases {
+		t.Run(tc.description, func(t *testing.T) {
+			normalizedScore := normalizeDistanceScore(tc.numaNodes, tc.distanceCloseness, tc.strandedRatio, nodeconfig.DefaultMaxNUMANodes)
+			if normalizedScore != tc.expectedScore {
+				t.Errorf("Expected normalizedScore to be %d not %d", tc.expectedScore, normalizedScore)
+			}
+		})
+	}
+}
+
+func TestDistanceCloseness(t *testing.T) {
 	numaNodes := NUMANodeList{
 		{
 			NUMAID: 0,
@@ -763,7 +820,6 @@ func TestMinDistance(t *testing.T) {
 				0: 10,
 				1: 12,
 				2: 20,
-				3: 20,
 			},
 		},
 		{
@@ -772,7 +828,6 @@ func TestMinDistance(t *testing.T) {
 				0: 12,
 				1: 10,
 				2: 20,
-				3: 20,
 			},
 		},
 		{
@@ -781,131 +836,164 @@ func TestMinDistance(t *testing.T) {
 				0: 20,
 				1: 20,
 				2: 10,
-				3: 12,
 			},
 		},
+	}
+
+	tcases := []struct {
+		description string
+		combination []int
+		numaNodes   NUMANodeList
+		expected    float64
+	}{
 		{
-			NUMAID: 3,
-			Costs: map[int]int{
-				0: 20,
-				1: 20,
-				2: 12,
-				3: 10,
-			},
+			description: "single numa node",
+			combination: []int{2},
+			numaNodes:   numaNodes,
+			expected:    1,
 		},
-	}
-	numaNodesNoCosts := NUMANodeList{
 		{
-			NUMAID: 0,
+			description: "close numa nodes",
+			combination: []int{0, 1},
+			numaNodes:   numaNodes,
+			expected:    0.8,
 		},
 		{
-			NUMAID: 1,
+			description: "far apart numa nodes",
+			combination: []int{0, 2},
+			numaNodes:   numaNodes,
+			expected:    0,
 		},
 		{
-			NUMAID: 2,
+			description: "no costs",
+			combination: []int{0, 1},
+			numaNodes:   NUMANodeList{{NUMAID: 0}, {NUMAID: 1}},
+			expected:    0,
+		},
+		{
+			description: "partial costs",
+			combination: []int{0, 1},
+			numaNodes:   NUMANodeList{{NUMAID: 0, Costs: map[int]int{0: 10, 1: 11}}, {NUMAID: 1}},
+			expected:    0,
+		},
+	}
+	for _, tc := range tcases {
+		t.Run(tc.description, func(t *testing.T) {
+			closeness := distanceCloseness(tc.numaNodes, tc.combination)
+			if math.Abs(closeness-tc.expected) > 1e-6 {
+				t.Errorf("Expected distance closeness to be %f not %f", tc.expected, closeness)
+			}
+		})
+	}
+}
+
+func TestDistanceScoreGap(t *testing.T) {
+	eightNUMANodes := func(withCosts bool) NUMANodeList {
+		numaNodes := make(NUMANodeList, 8)
+		for i := range numaNodes {
+			numaNodes[i].NUMAID = i
+			if !withCosts {
+				continue
+			}
+			numaNodes[i].Costs = map[int]int{}
+			for j := 0; j < 8; j++ {
+				switch {
+				case i == j:
+					numaNodes[i].Costs[j] = 10
+				case i/4 == j/4:
+					// same socket
+					numaNodes[i].Costs[j] = 12
+				default:
+					numaNodes[i].Costs[j] = 32
+				}
+			}
+		}
+		return numaNodes
+	}
+
+	score := func(numaNodes NUMANodeList, combination []int) int64 {
+		return normalizeDistanceScore(len(combination), distanceCloseness(numaNodes, combination), 0, nodeconfig.DefaultMaxNUMANodes)
+	}
+	close := score(eightNUMANodes(true), []int{0, 1})
+	far := score(eightNUMANodes(true), []int{0, 4})
+	noCosts := score(eightNUMANodes(false), []int{0, 1})
+
+	if close-far < 5 {
+		t.Errorf("Expected close NUMA nodes to score clearly higher than far apart ones, got %d and %d", close, far)
+	}
+	if noCosts > far {
+		t.Errorf("Expected NUMA nodes without Costs to score no higher than far apart ones, got %d and %d", noCosts, far)
+	}
+}
+
+func TestStrandedRatio(t *testing.T) {
+	deviceResource := v1.ResourceName("vendor.com/gpu")
+	numaNodes := NUMANodeList{
+		{
+			NUMAID: 0,
+			Resources: v1.ResourceList{
+				v1.ResourceCPU:    *resource.NewQuantity(4, resource.DecimalSI),
+				v1.ResourceMemory: resource.MustParse("4Gi"),
+				deviceResource:    resource.MustParse("2"),
+			},
 		},
 		{
-			NUMAID: 3,
+			NUMAID: 1,
+			Resources: v1.ResourceList{
+				v1.ResourceCPU:    *resource.NewQuantity(4, resource.DecimalSI),
+				v1.ResourceMemory: resource.MustParse("4Gi"),
+				deviceResource:    resource.MustParse("2"),
+			},
 		},
 	}
 
 	tcases := []struct {
 		description  string
-		combinations [][]int
-		numaNodes    NUMANodeList
-		expected     float32
+		combination  []int
+		podResources v1.ResourceList
+		expected     float64
 	}{
 		{
-			description: "single numa node combination",
-			combinations: [][]int{
-				{
-					0,
-				},
-				{
-					1,
-				},
-				{
-					2,
-				},
-				{
-					3,
-				},
+			description: "exact fit",
+			combination: []int{0},
+			podResources: v1.ResourceList{
+				v1.ResourceCPU:    *resource.NewQuantity(4, resource.DecimalSI),
+				v1.ResourceMemory: resource.MustParse("1Gi"),
 			},
-			numaNodes: numaNodes,
-			expected:  10,
+			expected: 0,
 		},
 		{
-			description: "two numa node combination",
-			combinations: [][]int{
-				{
-					0, 1,
-				},
-				{
-					0, 2,
-				},
-				{
-					0, 3,
-				},
-				{
-					1, 2,
-				},
-				{
-					1, 3,
-				},
-				{
-					2, 3,
-				},
+			description: "half of the cpus stranded, memory ignored",
+			combination: []int{0, 1},
+			podResources: v1.ResourceList{
+				v1.ResourceCPU:    *resource.NewQuantity(4, resource.DecimalSI),
+				v1.ResourceMemory: resource.MustParse("8Gi"),
 			},
-			numaNodes: numaNodes,
-			expected:  11,
+			expected: 0.5,
 		},
 		{
-			description: "three numa node combination",
-			combinations: [][]int{
-				{
-					0, 1, 2,
-				},
-				{
-					1, 2, 3,
-				},
-				{
-					0, 2, 3,
-				},
+			description: "cpus and devices",
+			combination: []int{0, 1},
+			podResources: v1.ResourceList{
+				v1.ResourceCPU: *resource.NewQuantity(8, resource.DecimalSI),
+				deviceResource: resource.MustParse("3"),
 			},
-			numaNodes: numaNodes,
-			expected:  14.888889,
+			expected: 0.125,
 		},
 		{
-			description: "two numa node combination, no costs",
-			combinations: [][]int{
-				{
-					0, 1,
-				},
-				{
-					0, 2,
-				},
-				{
-					0, 3,
-				},
-				{
-					1, 2,
-				},
-				{
-					1, 3,
-				},
-				{
-					2, 3,
-				},
+			description: "only memory",
+			combination: []int{0},
+			podResources: v1.ResourceList{
+				v1.ResourceMemory: resource.MustParse("1Gi"),
 			},
-			numaNodes: numaNodesNoCosts,
-			expected:  255,
+			expected: 0,
 		},
 	}
 	for _, tc := range tcases {
 		t.Run(tc.description, func(t *testing.T) {
-			distance := minAvgDistanceInCombinations(klog.Background(), tc.numaNodes, tc.combinations)
-			if distance != tc.expected {
-				t.Errorf("Expected distance to be %f not %f", tc.expected, distance)
+			ratio := strandedRatio(numaNodes, tc.podResources, tc.combination)
+			if math.Abs(ratio-tc.expected) > 1e-6 {
+				t.Errorf("Expected stranded ratio to be %f not %f", tc.expected, ratio)
 			}
 		})
 	}
@@ -0,0 +1,25 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package v1alpha1 exposes the NUMA placement logic of the NodeResourceTopologyMatch plugin,
+// so components other than the scheduler (admission webhooks, capacity planning tools, descheduler policies)
+// can compute the NUMA placement of a pod with exactly the same semantics of the scheduler.
+//
+// The package is versioned because its API is not stable yet: incompatible changes will be introduced
+// in a new package version, while the scheduler plugin will always use the latest one.
+//
+// The entry point is Place, which computes the Plan of a pod on a node described by its NodeResourceTopology object.
+package v1alpha1
@@ -0,0 +1,241 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"github.com/go-logr/logr"
+	"gonum.org/v1/gonum/stat/combin"
+
+	corev1 "k8s.io/api/core/v1"
+	bm "k8s.io/kubernetes/pkg/kubelet/cm/topologymanager/bitmask"
+)
+
+const (
+	// MaxDistanceValue is the max distance value as defined by ACPI SLIT(System Locality Information Tables),
+	// which means unknown/undefined
+	MaxDistanceValue = 255
+)
+
+// SingleNUMANodeFit checks for sufficient resource in any of the given NUMA nodes.
+// returns:
+// - the NUMAID that would be selected by Kubelet,
+// - a boolean which tells if the worker node can satisfy the request in any of its NUMA zones
+// - the reason for reject.
+// the reason for reject is significant only if the worker node is filtered out;
+// the selected numaID is significant only if the worker node is filtered in.
+// The nodeResources are the allocatable resources of the node, which must report all the resources,
+// including the ones which don't expose NUMA affinity.
+func SingleNUMANodeFit(lh logr.Logger, nodeResources corev1.ResourceList, numaNodes NUMANodeList, qos corev1.PodQOSClass, resources corev1.ResourceList) (int, bool, string) {
+	bitmask := bm.NewEmptyBitMask()
+	// set all bits, each bit is a NUMA node, if resources couldn't be aligned
+	// on the NUMA node, bit should be unset
+	bitmask.Fill()
+
+	for resource, quantity := range resources {
+		clh := lh.WithValues("resource", resource)
+		if quantity.IsZero() {
+			// why bother? everything's fine from the perspective of this resource
+			clh.V(4).Info("ignoring zero-qty resource request")
+			continue
+		}
+
+		if _, ok := nodeResources[resource]; !ok {
+			// some resources may not expose NUMA affinity (device plugins, extended resources), but all resources
+			// must be reported at node level; thus, if they are not present at node level, we can safely assume
+			// we don't have the resource at all.
+			clh.V(2).Info("early verdict: cannot meet request")
+			return -1, false, string(resource)
+		}
+
+		// for each requested resource, calculate which NUMA slots are good fits, and then AND with the aggregated bitmask, IOW unset appropriate bit if we can't align resources, or set it
+		// obvious, bits which are not in the NUMA id's range would be unset
+		hasNUMAAffinity := false
+		resourceBitmask := bm.NewEmptyBitMask()
+		for _, numaNode := range numaNodes {
+			nlh := clh.WithValues("numaCell", numaNode.NUMAID)
+			numaQuantity, ok := numaNode.Resources[resource]
+			if !ok {
+				nlh.V(6).Info("missing")
+				continue
+			}
+
+			hasNUMAAffinity = true
+			if !IsResourceSetSuitable(qos, resource, quantity, numaQuantity) {
+				nlh.V(6).Info("discarded", "quantity", quantity.String(), "numaQuantity", numaQuantity.String())
+				continue
+			}
+
+			resourceBitmask.Add(numaNode.NUMAID)
+			nlh.V(6).Info("feasible")
+		}
+
+		// non-native resources or ephemeral-storage may not expose NUMA affinity,
+		// but since they are available at node level, this is fine
+		if !hasNUMAAffinity && IsHostLevelResource(resource) {
+			clh.V(6).Info("resource available at host level (no NUMA affinity)")
+			continue
+		}
+
+		bitmask.And(resourceBitmask)
+		if bitmask.IsEmpty() {
+			lh.V(2).Info("early verdict: cannot find affinity")
+			return -1, false, string(resource)
+		}
+	}
+	// according to TopologyManager, the preferred NUMA affinity, is the narrowest one.
+	// https://github.com/kubernetes/kubernetes/blob/v1.24.0-rc.1/pkg/kubelet/cm/topologymanager/policy.go#L155
+	// in single-numa-node policy all resources should be allocated from a single NUMA,
+	// which means that the lowest NUMA ID (with available resources) is the one to be selected by Kubelet.
+	numaID := bitmask.GetBits()[0]
+
+	// at least one NUMA node is available
+	ret := !bitmask.IsEmpty()
+	lh.V(3).Info("final verdict", "suitable", ret, "numaCell", numaID)
+	return numaID, ret, "generic"
+}
+
+// MinimalCombination returns the indexes in numaNodes of the minimal set of NUMA nodes which can fit the given resources,
+// or nil when resources can't be fitted onto the worker node.
+// second value returned is a boolean indicating if the combination is optimal from distance perspective
+func MinimalCombination(lh logr.Logger, qos corev1.PodQOSClass, numaNodes NUMANodeList, resources corev1.ResourceList) ([]int, bool) {
+	for bitmaskLen := 1; bitmaskLen <= len(numaNodes); bitmaskLen++ {
+		numaNodesCombination := combin.Combinations(len(numaNodes), bitmaskLen)
+		suitableCombination, isMinDistance := FindSuitableCombination(lh, qos, numaNodes, resources, numaNodesCombination)
+		// we have found suitable combination for given bitmaskLen
+		if suitableCombination != nil {
+			return suitableCombination, isMinDistance
+		}
+	}
+
+	return nil, false
+}
+
+// FindSuitableCombination returns combination from numaNodesCombination that can fit resources, otherwise return nil
+// second value returned is a boolean indicating if returned combination is optimal from distance perspective
+// this function will always return combination that provides minimal average distance between nodes in combination
+func FindSuitableCombination(lh logr.Logger, qos corev1.PodQOSClass, numaNodes NUMANodeList, resources corev1.ResourceList, numaNodesCombination [][]int) ([]int, bool) {
+	minAvgDistance := minAvgDistanceInCombinations(lh, numaNodes, numaNodesCombination)
+	var (
+		minDistanceCombination []int
+		// init as max distance
+		minDistance float32 = 256
+	)
+	for _, combination := range numaNodesCombination {
+		if !isValidCombineResources(numaNodes, resources, combination) {
+			continue
+		}
+		combinationResources := CombineResources(numaNodes, combination)
+		resourcesFit := checkResourcesFit(lh, qos, resources, combinationResources)
+
+		if resourcesFit {
+			distance := AverageDistance(lh, numaNodes, combination...)
+			if distance == minAvgDistance {
+				// return early if we can fit resources into combination and provide minDistance
+				return combination, true
+			}
+			// we don't have to check which combination bitmask has lower value since we are generating them from lowest value
+			if distance < minDistance {
+				minDistance = distance
+				minDistanceCombination = combination
+			}
+		}
+	}
+
+	return minDistanceCombination, false
+}
+
+// CombineResources returns the sum of the resources of the NUMA nodes in the given combination.
+func CombineResources(numaNodes NUMANodeList, combination []int) corev1.ResourceList {
+	resources := corev1.ResourceList{}
+	for _, nodeIndex := range combination {
+		for resource, quantity := range numaNodes[nodeIndex].Resources {
+			if value, ok := resources[resource]; ok {
+				value.Add(quantity)
+				resources[resource] = value
+				continue
+			}
+			resources[resource] = quantity
+		}
+	}
+
+	return resources
+}
+
+// AverageDistance returns the average distance between the NUMA nodes at the given indexes in numaNodes.
+// Distances which can't be read from the Costs count as MaxDistanceValue.
+func AverageDistance(lh logr.Logger, numaNodes NUMANodeList, nodes ...int) float32 {
+	if len(nodes) == 0 {
+		return MaxDistanceValue
+	}
+
+	var (
+		accu int
+	)
+
+	for _, node1 := range nodes {
+		for _, node2 := range nodes {
+			cost, ok := numaNodes[node1].Costs[numaNodes[node2].NUMAID]
+			// we couldn't read Costs assign MaxDistanceValue
+			if !ok {
+				lh.Info("cannot retrieve Costs information", "nodeID", numaNodes[node1].NUMAID)
+				cost = MaxDistanceValue
+			}
+			accu += cost
+		}
+	}
+
+	return float32(accu) / float32(len(nodes)*len(nodes))
+}
+
+func minAvgDistanceInCombinations(lh logr.Logger, numaNodes NUMANodeList, numaNodesCombination [][]int) float32 {
+	// max distance for NUMA node
+	var minDistance float32 = MaxDistanceValue
+
+	for _, combination := range numaNodesCombination {
+		avgDistance := AverageDistance(lh, numaNodes, combination...)
+		if avgDistance < minDistance {
+			minDistance = avgDistance
+		}
+	}
+
+	return minDistance
+}
+
+func checkResourcesFit(lh logr.Logger, qos corev1.PodQOSClass, resources corev1.ResourceList, combinationResources corev1.ResourceList) bool {
+	for resource, quantity := range resources {
+		if quantity.IsZero() {
+			lh.V(4).Info("ignoring zero-qty resource request", "resource", resource)
+			continue
+		}
+		if combinationQuantity := combinationResources[resource]; !IsResourceSetSuitable(qos, resource, quantity, combinationQuantity) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isValidCombineResources(numaNodes NUMANodeList, resources corev1.ResourceList, combination []int) bool {
+	for _, nodeIndex := range combination {
+		for resourceName := range resources {
+			if _, ok := numaNodes[nodeIndex].Resources[resourceName]; !ok {
+				return false
+			}
+		}
+	}
+	return true
+}
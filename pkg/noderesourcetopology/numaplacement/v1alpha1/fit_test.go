@@ -0,0 +1,179 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+
+	"k8s.io/klog/v2"
+)
+
+func TestMinDistance(t *testing.T) {
+	numaNodes := NUMANodeList{
+		{
+			NUMAID: 0,
+			Costs: map[int]int{
+				0: 10,
+				1: 12,
+				2: 20,
+				3: 20,
+			},
+		},
+		{
+			NUMAID: 1,
+			Costs: map[int]int{
+				0: 12,
+				1: 10,
+				2: 20,
+				3: 20,
+			},
+		},
+		{
+			NUMAID: 2,
+			Costs: map[int]int{
+				0: 20,
+				1: 20,
+				2: 10,
+				3: 12,
+			},
+		},
+		{
+			NUMAID: 3,
+			Costs: map[int]int{
+				0: 20,
+				1: 20,
+				2: 12,
+				3: 10,
+			},
+		},
+	}
+	numaNodesNoCosts := NUMANodeList{
+		{
+			NUMAID: 0,
+		},
+		{
+			NUMAID: 1,
+		},
+		{
+			NUMAID: 2,
+		},
+		{
+			NUMAID: 3,
+		},
+	}
+
+	tcases := []struct {
+		description  string
+		combinations [][]int
+		numaNodes    NUMANodeList
+		expected     float32
+	}{
+		{
+			description: "single numa node combination",
+			combinations: [][]int{
+				{
+					0,
+				},
+				{
+					1,
+				},
+				{
+					2,
+				},
+				{
+					3,
+				},
+			},
+			numaNodes: numaNodes,
+			expected:  10,
+		},
+		{
+			description: "two numa node combination",
+			combinations: [][]int{
+				{
+					0, 1,
+				},
+				{
+					0, 2,
+				},
+				{
+					0, 3,
+				},
+				{
+					1, 2,
+				},
+				{
+					1, 3,
+				},
+				{
+					2, 3,
+				},
+			},
+			numaNodes: numaNodes,
+			expected:  11,
+		},
+		{
+			description: "three numa node combination",
+			combinations: [][]int{
+				{
+					0, 1, 2,
+				},
+				{
+					1, 2, 3,
+				},
+				{
+					0, 2, 3,
+				},
+			},
+			numaNodes: numaNodes,
+			expected:  14.888889,
+		},
+		{
+			description: "two numa node combination, no costs",
+			combinations: [][]int{
+				{
+					0, 1,
+				},
+				{
+					0, 2,
+				},
+				{
+					0, 3,
+				},
+				{
+					1, 2,
+				},
+				{
+					1, 3,
+				},
+				{
+					2, 3,
+				},
+			},
+			numaNodes: numaNodesNoCosts,
+			expected:  255,
+		},
+	}
+	for _, tc := range tcases {
+		t.Run(tc.description, func(t *testing.T) {
+			distance := minAvgDistanceInCombinations(klog.Background(), tc.numaNodes, tc.combinations)
+			if distance != tc.expected {
+				t.Errorf("Expected distance to be %f not %f", tc.expected, distance)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+
+	"github.com/go-logr/logr"
+	topologyv1alpha2 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2"
+	"github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2/helper"
+	"github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2/helper/numanode"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/stringify"
+)
+
+const (
+	// MaxNUMAID bounds the NUMA IDs taken into account. Zones with an ID of MaxNUMAID or higher are ignored.
+	MaxNUMAID = 64
+)
+
+// NUMANode represents the resources available on a NUMA node, and its distances from the other NUMA nodes.
+type NUMANode struct {
+	NUMAID    int
+	Resources corev1.ResourceList
+	Costs     map[int]int
+}
+
+func (n *NUMANode) WithCosts(costs map[int]int) *NUMANode {
+	n.Costs = costs
+	return n
+}
+
+func (n NUMANode) DeepCopy() NUMANode {
+	ret := NUMANode{
+		NUMAID:    n.NUMAID,
+		Resources: n.Resources.DeepCopy(),
+	}
+	if len(n.Costs) > 0 {
+		ret.Costs = make(map[int]int)
+		for key, val := range n.Costs {
+			ret.Costs[key] = val
+		}
+	}
+	return ret
+}
+
+func (n NUMANode) Equal(o NUMANode) bool {
+	if n.NUMAID != o.NUMAID {
+		return false
+	}
+	if !reflect.DeepEqual(n.Costs, o.Costs) {
+		return false
+	}
+	return equalResourceList(n.Resources, o.Resources)
+}
+
+func equalResourceList(ra, rb corev1.ResourceList) bool {
+	if len(ra) != len(rb) {
+		return false
+	}
+	for key, valA := range ra {
+		valB, ok := rb[key]
+		if !ok {
+			return false
+		}
+		if !valA.Equal(valB) {
+			return false
+		}
+	}
+	return true
+}
+
+type NUMANodeList []NUMANode
+
+func (nnl NUMANodeList) DeepCopy() NUMANodeList {
+	ret := make(NUMANodeList, 0, len(nnl))
+	for idx := 0; idx < len(nnl); idx++ {
+		ret = append(ret, nnl[idx].DeepCopy())
+	}
+	return ret
+}
+
+func (nnl NUMANodeList) Equal(oth NUMANodeList) bool {
+	if len(nnl) != len(oth) {
+		return false
+	}
+	for idx := 0; idx < len(nnl); idx++ {
+		if !nnl[idx].Equal(oth[idx]) {
+			return false
+		}
+	}
+	return true
+}
+
+// NewNUMANodeList creates the NUMANodeList from the NUMA zones found in the given zones,
+// using the available resources of each zone.
+func NewNUMANodeList(lh logr.Logger, zones topologyv1alpha2.ZoneList) NUMANodeList {
+	numaIDToZoneIDx := make([]int, MaxNUMAID)
+	nodes := NUMANodeList{}
+	// filter non Node zones and create idToIdx lookup array
+	for i, zone := range zones {
+		if zone.Type != helper.ZoneTypeNUMANode {
+			continue
+		}
+
+		numaID, err := numanode.NameToID(zone.Name)
+		if err != nil || numaID >= MaxNUMAID {
+			lh.Error(err, "error getting the numaID", "zone", zone.Name, "numaID", numaID)
+			continue
+		}
+
+		numaIDToZoneIDx[numaID] = i
+
+		resources := extractResources(zone)
+		numaItems := []interface{}{"numaCell", numaID}
+		lh.V(6).Info("extracted NUMA resources", stringify.ResourceListToLoggableWithValues(numaItems, resources)...)
+		nodes = append(nodes, NUMANode{NUMAID: numaID, Resources: resources})
+	}
+
+	// iterate over nodes and fill them with Costs
+	for i, node := range nodes {
+		nodes[i] = *node.WithCosts(extractCosts(zones[numaIDToZoneIDx[node.NUMAID]].Costs))
+	}
+
+	return nodes
+}
+
+func extractCosts(costs topologyv1alpha2.CostList) map[int]int {
+	nodeCosts := make(map[int]int)
+
+	// return early if CostList is missing
+	if len(costs) == 0 {
+		return nodeCosts
+	}
+
+	for _, cost := range costs {
+		numaID, err := numanode.NameToID(cost.Name)
+		if err != nil || numaID >= MaxNUMAID {
+			continue
+		}
+		nodeCosts[numaID] = int(cost.Value)
+	}
+
+	return nodeCosts
+}
+
+func extractResources(zone topologyv1alpha2.Zone) corev1.ResourceList {
+	res := make(corev1.ResourceList)
+	for _, resInfo := range zone.Resources {
+		res[corev1.ResourceName(resInfo.Name)] = resInfo.Available.DeepCopy()
+	}
+	return res
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+	"testing"
+
+	topologyv1alpha2 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2"
+	"github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2/helper"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/klog/v2"
+)
+
+func TestNewNUMANodeList(t *testing.T) {
+	makeZone := func(name string, costs ...string) topologyv1alpha2.Zone {
+		zone := topologyv1alpha2.Zone{
+			Name: name,
+			Type: helper.ZoneTypeNUMANode,
+			Resources: topologyv1alpha2.ResourceInfoList{
+				{Name: "cpu", Available: resource.MustParse("4")},
+			},
+		}
+		for _, cost := range costs {
+			zone.Costs = append(zone.Costs, topologyv1alpha2.CostInfo{Name: cost, Value: 10})
+		}
+		return zone
+	}
+
+	testCases := []struct {
+		description string
+		zones       topologyv1alpha2.ZoneList
+		expected    []int
+		costs       map[int]int
+	}{
+		{
+			description: "highest NUMA ID taken into account",
+			zones:       topologyv1alpha2.ZoneList{makeZone("node-63", "node-63")},
+			expected:    []int{63},
+			costs:       map[int]int{63: 10},
+		},
+		{
+			description: "NUMA ID out of range is ignored",
+			zones:       topologyv1alpha2.ZoneList{makeZone("node-0", "node-0", "node-64"), makeZone("node-64", "node-0", "node-64")},
+			expected:    []int{0},
+			costs:       map[int]int{0: 10},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			nodes := NewNUMANodeList(klog.Background(), tc.zones)
+			var got []int
+			for _, node := range nodes {
+				got = append(got, node.NUMAID)
+			}
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Fatalf("expected NUMA IDs %v got %v", tc.expected, got)
+			}
+			if !reflect.DeepEqual(nodes[0].Costs, tc.costs) {
+				t.Errorf("expected costs %v got %v", tc.costs, nodes[0].Costs)
+			}
+		})
+	}
+}
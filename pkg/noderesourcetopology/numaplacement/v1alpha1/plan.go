@@ -0,0 +1,232 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+
+	"github.com/go-logr/logr"
+	topologyv1alpha2 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2"
+
+	corev1 "k8s.io/api/core/v1"
+	v1qos "k8s.io/kubernetes/pkg/apis/core/v1/helper/qos"
+	kubeletconfig "k8s.io/kubernetes/pkg/kubelet/apis/config"
+
+	"sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/logging"
+	"sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/nodeconfig"
+	"sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/resourcerequests"
+	"sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/stringify"
+	"sigs.k8s.io/scheduler-plugins/pkg/util"
+)
+
+// ContainerPlacement is the NUMA node a container is expected to be aligned to.
+type ContainerPlacement struct {
+	Name string
+	// Kind is one of the container kinds defined in the logging package (init, sidecar, app).
+	Kind   string
+	NUMAID int
+}
+
+// Plan is the expected NUMA placement of a pod on a node.
+type Plan struct {
+	// Policy and Scope are the Topology Manager configuration of the node the plan was computed against.
+	Policy string
+	Scope  string
+	// Admit is true if the kubelet is expected to admit the pod.
+	Admit bool
+	// Reason explains why the pod would be rejected. Empty if Admit is true.
+	Reason string
+	// Resource is the resource which could not be aligned. Empty if Admit is true.
+	Resource corev1.ResourceName
+	// NUMAID is the NUMA node the whole pod is expected to be aligned to, in the pod scope.
+	// It is -1 if the plan doesn't constrain the pod as a whole.
+	NUMAID int
+	// Containers lists the NUMA nodes the containers are expected to be aligned to, in the container scope,
+	// in the same order the kubelet admits them.
+	Containers []ContainerPlacement
+}
+
+// Place computes the Plan of the given pod on the node described by the given NodeResourceTopology object,
+// with the same semantics of the NodeResourceTopologyMatch Filter. Only the single-numa-node Topology Manager policy
+// constrains the placement; with any other policy the pod is always admitted.
+// The nodeAllocatable are the allocatable resources of the node. If nil, they are approximated using AllocatableFromNRT.
+// An error is returned only if the resource accounting is inconsistent.
+func Place(lh logr.Logger, nrt *topologyv1alpha2.NodeResourceTopology, nodeAllocatable corev1.ResourceList, pod *corev1.Pod) (Plan, error) {
+	if nodeAllocatable == nil {
+		nodeAllocatable = AllocatableFromNRT(nrt)
+	}
+	conf := nodeconfig.TopologyManagerFromNodeResourceTopology(lh, nrt)
+	return PlaceOnNUMANodes(lh, conf, nodeAllocatable, NewNUMANodeList(lh, nrt.Zones), pod)
+}
+
+// PlaceOnNUMANodes is like Place, but takes the Topology Manager configuration and the NUMA nodes explicitly,
+// so callers can compute the Plan of many pods on the same NUMA nodes, updating them with Reserve.
+// The given numaNodes are not modified.
+func PlaceOnNUMANodes(lh logr.Logger, conf nodeconfig.TopologyManager, nodeAllocatable corev1.ResourceList, numaNodes NUMANodeList, pod *corev1.Pod) (Plan, error) {
+	plan := Plan{
+		Policy: conf.Policy,
+		Scope:  conf.Scope,
+		Admit:  true,
+		NUMAID: -1,
+	}
+
+	qos := v1qos.GetPodQOS(pod)
+	if qos == corev1.PodQOSBestEffort && !resourcerequests.IncludeNonNative(pod) {
+		return plan, nil
+	}
+	if conf.Policy != kubeletconfig.SingleNumaNodeTopologyManagerPolicy {
+		return plan, nil
+	}
+
+	var (
+		ret Plan
+		err error
+	)
+	switch conf.Scope {
+	case kubeletconfig.PodTopologyManagerScope:
+		ret = AlignPod(lh, nodeAllocatable, numaNodes, qos, pod)
+	case kubeletconfig.ContainerTopologyManagerScope:
+		ret, err = AlignContainers(lh, nodeAllocatable, numaNodes.DeepCopy(), qos, pod)
+	default:
+		return plan, nil // cannot happen
+	}
+	ret.Policy = plan.Policy
+	ret.Scope = plan.Scope
+	return ret, err
+}
+
+// Reserve subtracts in-place from numaNodes the resources the given pod takes according to the given Plan,
+// which must be computed for the same pod. Plans which don't constrain the placement leave numaNodes untouched.
+func Reserve(lh logr.Logger, numaNodes NUMANodeList, plan Plan, pod *corev1.Pod) error {
+	if !plan.Admit {
+		return fmt.Errorf("cannot reserve resources for a rejected pod: %s", plan.Reason)
+	}
+	qos := v1qos.GetPodQOS(pod)
+	if plan.NUMAID >= 0 {
+		return SubtractResources(lh, numaNodes, plan.NUMAID, qos, util.PodRequests(pod))
+	}
+	if len(plan.Containers) == 0 {
+		return nil
+	}
+	for _, container := range pod.Spec.Containers {
+		for _, cp := range plan.Containers {
+			if cp.Kind != logging.KindContainerApp || cp.Name != container.Name {
+				continue
+			}
+			err := SubtractResources(lh, numaNodes, cp.NUMAID, qos, container.Resources.Requests)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// AlignPod computes the Plan of the given pod on the given NUMA nodes in the pod scope of the single-numa-node
+// Topology Manager policy. The returned Plan has neither Policy nor Scope set.
+func AlignPod(lh logr.Logger, nodeResources corev1.ResourceList, numaNodes NUMANodeList, qos corev1.PodQOSClass, pod *corev1.Pod) Plan {
+	resources := util.PodRequests(pod)
+	lh.V(6).Info("pod desired resources", stringify.ResourceListToLoggable(resources)...)
+
+	numaID, match, reason := SingleNUMANodeFit(lh, nodeResources, numaNodes, qos, resources)
+	if !match {
+		lh.V(2).Info("cannot align pod", "name", pod.Name, "reason", reason)
+		return Plan{
+			Reason:   "cannot align pod",
+			Resource: corev1.ResourceName(reason),
+			NUMAID:   -1,
+		}
+	}
+	lh.V(4).Info("all container placed", "numaCell", numaID)
+	return Plan{
+		Admit:  true,
+		NUMAID: numaID,
+	}
+}
+
+// AlignContainers computes the Plan of the given pod on the given NUMA nodes in the container scope of the
+// single-numa-node Topology Manager policy. The resources of the aligned app containers are subtracted from
+// numaNodes in-place. The returned Plan has neither Policy nor Scope set.
+func AlignContainers(lh logr.Logger, nodeResources corev1.ResourceList, numaNodes NUMANodeList, qos corev1.PodQOSClass, pod *corev1.Pod) (Plan, error) {
+	plan := Plan{
+		NUMAID: -1,
+	}
+
+	// the init containers are running SERIALLY and BEFORE the normal containers.
+	// https://kubernetes.io/docs/concepts/workloads/pods/init-containers/#understanding-init-containers
+	// therefore, we don't need to accumulate their resources together
+	for _, initContainer := range pod.Spec.InitContainers {
+		cntKind := logging.GetInitContainerKind(&initContainer)
+		clh := lh.WithValues(logging.KeyContainer, initContainer.Name, logging.KeyContainerKind, cntKind)
+		clh.V(6).Info("desired resources", stringify.ResourceListToLoggable(initContainer.Resources.Requests)...)
+
+		numaID, match, reason := SingleNUMANodeFit(clh, nodeResources, numaNodes, qos, initContainer.Resources.Requests)
+		if !match {
+			msg := "cannot align " + cntKind + " container"
+			// we can't align init container, so definitely we can't align a pod
+			clh.V(2).Info(msg, "reason", reason)
+			plan.Reason = msg
+			plan.Resource = corev1.ResourceName(reason)
+			plan.Containers = nil
+			return plan, nil
+		}
+		plan.Containers = append(plan.Containers, ContainerPlacement{Name: initContainer.Name, Kind: cntKind, NUMAID: numaID})
+	}
+
+	for _, container := range pod.Spec.Containers {
+		clh := lh.WithValues(logging.KeyContainer, container.Name, logging.KeyContainerKind, logging.KindContainerApp)
+		clh.V(6).Info("container requests", stringify.ResourceListToLoggable(container.Resources.Requests)...)
+
+		numaID, match, reason := SingleNUMANodeFit(clh, nodeResources, numaNodes, qos, container.Resources.Requests)
+		if !match {
+			// we can't align container, so definitely we can't align a pod
+			clh.V(2).Info("cannot align container", "reason", reason)
+			plan.Reason = "cannot align container"
+			plan.Resource = corev1.ResourceName(reason)
+			plan.Containers = nil
+			return plan, nil
+		}
+
+		// subtract the resources requested by the container from the given NUMA.
+		// this is necessary, so we won't allocate the same resources for the upcoming containers
+		err := SubtractResources(clh, numaNodes, numaID, qos, container.Resources.Requests)
+		if err != nil {
+			// this is an internal error which should never happen
+			return Plan{NUMAID: -1}, err
+		}
+		clh.V(4).Info("container aligned", "numaCell", numaID)
+		plan.Containers = append(plan.Containers, ContainerPlacement{Name: container.Name, Kind: logging.KindContainerApp, NUMAID: numaID})
+	}
+
+	plan.Admit = true
+	return plan, nil
+}
+
+// AllocatableFromNRT approximates the allocatable resources of a node summing up the allocatable resources
+// reported by all the zones of its NodeResourceTopology object.
+func AllocatableFromNRT(nrt *topologyv1alpha2.NodeResourceTopology) corev1.ResourceList {
+	allocatable := make(corev1.ResourceList)
+	for _, zone := range nrt.Zones {
+		for _, resInfo := range zone.Resources {
+			resName := corev1.ResourceName(resInfo.Name)
+			qty := allocatable[resName]
+			qty.Add(resInfo.Allocatable)
+			allocatable[resName] = qty
+		}
+	}
+	return allocatable
+}
@@ -0,0 +1,251 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+	"testing"
+
+	topologyv1alpha2 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2/ktesting"
+
+	"sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/nodeconfig"
+)
+
+func makeResInfo(name, allocatable, available string) topologyv1alpha2.ResourceInfo {
+	return topologyv1alpha2.ResourceInfo{
+		Name:        name,
+		Capacity:    resource.MustParse(allocatable),
+		Allocatable: resource.MustParse(allocatable),
+		Available:   resource.MustParse(available),
+	}
+}
+
+func makeTestNRT(policy topologyv1alpha2.TopologyManagerPolicy) *topologyv1alpha2.NodeResourceTopology {
+	return &topologyv1alpha2.NodeResourceTopology{
+		ObjectMeta:       metav1.ObjectMeta{Name: "node1"},
+		TopologyPolicies: []string{string(policy)},
+		Zones: topologyv1alpha2.ZoneList{
+			{
+				Name: "node-0",
+				Type: "Node",
+				Resources: topologyv1alpha2.ResourceInfoList{
+					makeResInfo(string(corev1.ResourceCPU), "16", "2"),
+					makeResInfo(string(corev1.ResourceMemory), "8Gi", "8Gi"),
+				},
+			},
+			{
+				Name: "node-1",
+				Type: "Node",
+				Resources: topologyv1alpha2.ResourceInfoList{
+					makeResInfo(string(corev1.ResourceCPU), "16", "6"),
+					makeResInfo(string(corev1.ResourceMemory), "8Gi", "8Gi"),
+				},
+			},
+		},
+	}
+}
+
+func makeGuaranteedContainer(name, cpu, memory string) corev1.Container {
+	res := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse(cpu),
+		corev1.ResourceMemory: resource.MustParse(memory),
+	}
+	return corev1.Container{
+		Name: name,
+		Resources: corev1.ResourceRequirements{
+			Requests: res,
+			Limits:   res,
+		},
+	}
+}
+
+func makeTestPod(containers ...corev1.Container) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pod"},
+		Spec: corev1.PodSpec{
+			Containers: containers,
+		},
+	}
+}
+
+func TestPlace(t *testing.T) {
+	testCases := []struct {
+		description     string
+		policy          topologyv1alpha2.TopologyManagerPolicy
+		nodeAllocatable corev1.ResourceList
+		pod             *corev1.Pod
+		expected        Plan
+	}{
+		{
+			description: "best effort pod",
+			policy:      topologyv1alpha2.SingleNUMANodePodLevel,
+			pod:         makeTestPod(corev1.Container{Name: "cnt"}),
+			expected: Plan{
+				Policy: "single-numa-node",
+				Scope:  "pod",
+				Admit:  true,
+				NUMAID: -1,
+			},
+		},
+		{
+			description: "policy not constraining the placement",
+			policy:      topologyv1alpha2.BestEffortPodLevel,
+			pod:         makeTestPod(makeGuaranteedContainer("cnt", "8", "1Gi")),
+			expected: Plan{
+				Policy: "best-effort",
+				Scope:  "pod",
+				Admit:  true,
+				NUMAID: -1,
+			},
+		},
+		{
+			description: "pod scope, aligned",
+			policy:      topologyv1alpha2.SingleNUMANodePodLevel,
+			pod:         makeTestPod(makeGuaranteedContainer("cnt-0", "2", "1Gi"), makeGuaranteedContainer("cnt-1", "2", "1Gi")),
+			expected: Plan{
+				Policy: "single-numa-node",
+				Scope:  "pod",
+				Admit:  true,
+				NUMAID: 1,
+			},
+		},
+		{
+			description: "pod scope, cannot align",
+			policy:      topologyv1alpha2.SingleNUMANodePodLevel,
+			pod:         makeTestPod(makeGuaranteedContainer("cnt-0", "4", "1Gi"), makeGuaranteedContainer("cnt-1", "4", "1Gi")),
+			expected: Plan{
+				Policy:   "single-numa-node",
+				Scope:    "pod",
+				Reason:   "cannot align pod",
+				Resource: corev1.ResourceCPU,
+				NUMAID:   -1,
+			},
+		},
+		{
+			description: "container scope, aligned",
+			policy:      topologyv1alpha2.SingleNUMANodeContainerLevel,
+			pod:         makeTestPod(makeGuaranteedContainer("cnt-0", "4", "1Gi"), makeGuaranteedContainer("cnt-1", "2", "1Gi"), makeGuaranteedContainer("cnt-2", "2", "1Gi")),
+			expected: Plan{
+				Policy: "single-numa-node",
+				Scope:  "container",
+				Admit:  true,
+				NUMAID: -1,
+				Containers: []ContainerPlacement{
+					{Name: "cnt-0", Kind: "app", NUMAID: 1},
+					{Name: "cnt-1", Kind: "app", NUMAID: 0},
+					{Name: "cnt-2", Kind: "app", NUMAID: 1},
+				},
+			},
+		},
+		{
+			description: "container scope, cannot align",
+			policy:      topologyv1alpha2.SingleNUMANodeContainerLevel,
+			pod:         makeTestPod(makeGuaranteedContainer("cnt-0", "4", "1Gi"), makeGuaranteedContainer("cnt-1", "4", "1Gi")),
+			expected: Plan{
+				Policy:   "single-numa-node",
+				Scope:    "container",
+				Reason:   "cannot align container",
+				Resource: corev1.ResourceCPU,
+				NUMAID:   -1,
+			},
+		},
+		{
+			description: "resource missing from node allocatable",
+			policy:      topologyv1alpha2.SingleNUMANodePodLevel,
+			nodeAllocatable: corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse("32"),
+			},
+			pod: makeTestPod(makeGuaranteedContainer("cnt", "2", "1Gi")),
+			expected: Plan{
+				Policy:   "single-numa-node",
+				Scope:    "pod",
+				Reason:   "cannot align pod",
+				Resource: corev1.ResourceMemory,
+				NUMAID:   -1,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			lh := ktesting.NewLogger(t, ktesting.DefaultConfig)
+			got, err := Place(lh, makeTestNRT(tc.policy), tc.nodeAllocatable, tc.pod)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("got %#v expected %#v", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestReserve(t *testing.T) {
+	testCases := []struct {
+		description string
+		policy      topologyv1alpha2.TopologyManagerPolicy
+		pod         *corev1.Pod
+		expectedCPU []string
+	}{
+		{
+			description: "pod scope",
+			policy:      topologyv1alpha2.SingleNUMANodePodLevel,
+			pod:         makeTestPod(makeGuaranteedContainer("cnt-0", "2", "1Gi"), makeGuaranteedContainer("cnt-1", "2", "1Gi")),
+			expectedCPU: []string{"2", "2"},
+		},
+		{
+			description: "container scope",
+			policy:      topologyv1alpha2.SingleNUMANodeContainerLevel,
+			pod:         makeTestPod(makeGuaranteedContainer("cnt-0", "4", "1Gi"), makeGuaranteedContainer("cnt-1", "2", "1Gi")),
+			expectedCPU: []string{"0", "2"},
+		},
+		{
+			description: "policy not constraining the placement",
+			policy:      topologyv1alpha2.BestEffortPodLevel,
+			pod:         makeTestPod(makeGuaranteedContainer("cnt", "2", "1Gi")),
+			expectedCPU: []string{"2", "6"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			lh := ktesting.NewLogger(t, ktesting.DefaultConfig)
+			nrt := makeTestNRT(tc.policy)
+			numaNodes := NewNUMANodeList(lh, nrt.Zones)
+			conf := nodeconfig.TopologyManagerFromNodeResourceTopology(lh, nrt)
+
+			plan, err := PlaceOnNUMANodes(lh, conf, AllocatableFromNRT(nrt), numaNodes, tc.pod)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := Reserve(lh, numaNodes, plan, tc.pod); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for idx, expected := range tc.expectedCPU {
+				got := numaNodes[idx].Resources[corev1.ResourceCPU]
+				if got.Cmp(resource.MustParse(expected)) != 0 {
+					t.Errorf("NUMA node %d: expected %s CPUs got %s", idx, expected, got.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+
+	"github.com/go-logr/logr"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	v1helper "k8s.io/kubernetes/pkg/apis/core/v1/helper"
+
+	"sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/stringify"
+)
+
+// IsHostLevelResource returns true if the given resource *may* not be bound to NUMA nodes.
+func IsHostLevelResource(resource corev1.ResourceName) bool {
+	// host-level resources are resources which *may* not be bound to NUMA nodes.
+	// A Key example is generic [ephemeral] storage which doesn't expose NUMA affinity.
+	if resource == corev1.ResourceEphemeralStorage {
+		return true
+	}
+	if resource == corev1.ResourceStorage {
+		return true
+	}
+	if !v1helper.IsNativeResource(resource) {
+		return true
+	}
+	return false
+}
+
+// IsNUMAAffineResource returns true if the given resource is required to be bound to NUMA nodes.
+func IsNUMAAffineResource(resource corev1.ResourceName) bool {
+	// NUMA-affine resources are resources which are required to be bound to NUMA nodes.
+	// A Key example is CPU and memory, which must expose NUMA affinity.
+	if resource == corev1.ResourceCPU {
+		return true
+	}
+	if resource == corev1.ResourceMemory {
+		return true
+	}
+	if v1helper.IsHugePageResourceName(resource) {
+		return true
+	}
+	// Devices are *expected* to expose NUMA Affinity, but they are not *required* to do so.
+	// We can't tell for sure, so we default to "no".
+	return false
+}
+
+// IsResourceSetSuitable returns true if the given quantity of the given resource, requested by a pod
+// with the given QoS class, can be taken from the given NUMA quantity.
+func IsResourceSetSuitable(qos corev1.PodQOSClass, resource corev1.ResourceName, quantity, numaQuantity resource.Quantity) bool {
+	if qos != corev1.PodQOSGuaranteed && IsNUMAAffineResource(resource) {
+		return true
+	}
+	return numaQuantity.Cmp(quantity) >= 0
+}
+
+// SubtractResources finds the correct NUMA ID's resources and always subtract them from `nodes` in-place.
+func SubtractResources(lh logr.Logger, nodes NUMANodeList, numaID int, qos corev1.PodQOSClass, containerRes corev1.ResourceList) error {
+	logEntries := []any{"numaCell", numaID}
+
+	for _, node := range nodes {
+		if node.NUMAID != numaID {
+			continue
+		}
+
+		lh.V(5).Info("NUMA resources before", append(logEntries, stringify.ResourceListToLoggable(node.Resources)...)...)
+
+		for resName, resQty := range containerRes {
+			isAffine := IsNUMAAffineResource(resName)
+			if qos != corev1.PodQOSGuaranteed && isAffine {
+				lh.V(4).Info("ignoring QoS-depending exclusive request", "resource", resName, "QoS", qos)
+				continue
+			}
+			if resQty.IsZero() {
+				lh.V(4).Info("ignoring zero-valued request", "resource", resName)
+				continue
+			}
+			nResQ, ok := node.Resources[resName]
+			if !ok {
+				lh.V(4).Info("ignoring missing resource", "resource", resName, "affine", isAffine, "request", resQty.String())
+				continue
+			}
+			nodeResQty := nResQ.DeepCopy()
+			nodeResQty.Sub(resQty)
+			if nodeResQty.Sign() < 0 {
+				lh.V(1).Info("resource quantity should not be a negative value", "numaCell", numaID, "resource", resName, "quantity", nResQ.String(), "request", resQty.String())
+				return fmt.Errorf("resource %q request %s exceeds NUMA %d availability %s", string(resName), resQty.String(), numaID, nResQ.String())
+			}
+			node.Resources[resName] = nodeResQty
+		}
+
+		lh.V(5).Info("NUMA resources after", append(logEntries, stringify.ResourceListToLoggable(node.Resources)...)...)
+	}
+	return nil
+}
@@ -27,9 +27,6 @@ import (
 	"k8s.io/kubernetes/pkg/scheduler/framework"
 
 	"github.com/go-logr/logr"
-	topologyv1alpha2 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2"
-	"github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2/helper"
-	"github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2/helper/numanode"
 
 	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -37,11 +34,6 @@ import (
 	nrtcache "sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/cache"
 	"sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/logging"
 	"sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/podprovider"
-	"sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/stringify"
-)
-
-const (
-	maxNUMAId = 64
 )
 
 func initNodeTopologyInformer(ctx context.Context, lh logr.Logger,
@@ -69,6 +61,8 @@ func initNodeTopologyInformer(ctx context.Context, lh logr.Logger,
 
 	initNodeTopologyForeignPodsDetection(lh, tcfg.Cache, handle, podSharedInformer, nrtCache)
 
+	initNodeTopologyValidation(lh, tcfg.Cache, handle, nrtCache)
+
 	resyncPeriod := time.Duration(tcfg.CacheResyncPeriodSeconds) * time.Second
 	go wait.Forever(nrtCache.Resync, resyncPeriod)
 
@@ -102,62 +96,16 @@ func initNodeTopologyForeignPodsDetection(lh logr.Logger, cfg *apiconfig.NodeRes
 	nrtcache.SetupForeignPodsDetector(lh.WithName(logging.SubsystemForeignPods), profileName, podSharedInformer, nrtCache)
 }
 
-func createNUMANodeList(lh logr.Logger, zones topologyv1alpha2.ZoneList) NUMANodeList {
-	numaIDToZoneIDx := make([]int, maxNUMAId)
-	nodes := NUMANodeList{}
-	// filter non Node zones and create idToIdx lookup array
-	for i, zone := range zones {
-		if zone.Type != helper.ZoneTypeNUMANode {
-			continue
-		}
-
-		numaID, err := numanode.NameToID(zone.Name)
-		if err != nil || numaID > maxNUMAId {
-			lh.Error(err, "error getting the numaID", "zone", zone.Name, "numaID", numaID)
-			continue
-		}
-
-		numaIDToZoneIDx[numaID] = i
-
-		resources := extractResources(zone)
-		numaItems := []interface{}{"numaCell", numaID}
-		lh.V(6).Info("extracted NUMA resources", stringify.ResourceListToLoggableWithValues(numaItems, resources)...)
-		nodes = append(nodes, NUMANode{NUMAID: numaID, Resources: resources})
-	}
-
-	// iterate over nodes and fill them with Costs
-	for i, node := range nodes {
-		nodes[i] = *node.WithCosts(extractCosts(zones[numaIDToZoneIDx[node.NUMAID]].Costs))
-	}
-
-	return nodes
-}
-
-func extractCosts(costs topologyv1alpha2.CostList) map[int]int {
-	nodeCosts := make(map[int]int)
-
-	// return early if CostList is missing
-	if len(costs) == 0 {
-		return nodeCosts
-	}
-
-	for _, cost := range costs {
-		numaID, err := numanode.NameToID(cost.Name)
-		if err != nil || numaID > maxNUMAId {
-			continue
-		}
-		nodeCosts[numaID] = int(cost.Value)
+func initNodeTopologyValidation(lh logr.Logger, cfg *apiconfig.NodeResourceTopologyCache, handle fwk.Handle, nrtCache *nrtcache.OverReserve) {
+	nrtValidation := getNRTValidationMode(lh, cfg)
+	if nrtValidation == apiconfig.CacheNRTValidationNone {
+		lh.V(3).Info("NodeTopology validation disabled by configuration")
+		return
 	}
 
-	return nodeCosts
-}
-
-func extractResources(zone topologyv1alpha2.Zone) corev1.ResourceList {
-	res := make(corev1.ResourceList)
-	for _, resInfo := range zone.Resources {
-		res[corev1.ResourceName(resInfo.Name)] = resInfo.Available.DeepCopy()
-	}
-	return res
+	lh.Info("setting up NodeTopology validation", "mode", nrtValidation)
+	nodeLister := handle.SharedInformerFactory().Core().V1().Nodes().Lister()
+	nrtCache.SetNRTValidator(nrtcache.NewNRTValidator(nrtValidation, nodeLister, handle.EventRecorder()))
 }
 
 func onlyNonNUMAResources(numaNodes NUMANodeList, resources corev1.ResourceList) bool {
@@ -182,3 +130,14 @@ func getForeignPodsDetectMode(lh logr.Logger, cfg *apiconfig.NodeResourceTopolog
 	}
 	return foreignPodsDetect
 }
+
+func getNRTValidationMode(lh logr.Logger, cfg *apiconfig.NodeResourceTopologyCache) apiconfig.CacheNRTValidationMode {
+	var nrtValidation apiconfig.CacheNRTValidationMode
+	if cfg != nil && cfg.NRTValidation != nil {
+		nrtValidation = *cfg.NRTValidation
+	} else { // explicitly set to nil?
+		nrtValidation = apiconfig.CacheNRTValidationNone
+		lh.V(3).Info("NodeTopology validation value missing", "fallback", nrtValidation)
+	}
+	return nrtValidation
+}
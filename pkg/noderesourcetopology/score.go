@@ -32,6 +32,7 @@ import (
 	"github.com/go-logr/logr"
 	"sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/logging"
 	"sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/nodeconfig"
+	numaplacement "sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/numaplacement/v1alpha1"
 	"sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/stringify"
 	"sigs.k8s.io/scheduler-plugins/pkg/util"
 )
@@ -92,7 +93,7 @@ func (tm *TopologyMatch) Score(ctx context.Context, state fwk.CycleState, pod *v
 	if handler == nil {
 		return 0, nil
 	}
-	numaNodes := createNUMANodeList(lh, nodeTopology.Zones)
+	numaNodes := numaplacement.NewNUMANodeList(lh, nodeTopology.Zones)
 	si := scoreInfo{
 		topologyManager: conf,
 		qos:             qos,
@@ -143,7 +144,7 @@ func podScopeScore(lh logr.Logger, pod *v1.Pod, info *scoreInfo, scorerFn scoreS
 	// This code is in Admit implementation of pod scope
 	// https://github.com/kubernetes/kubernetes/blob/9ff3b7e744b34c099c1405d9add192adbef0b6b1/pkg/kubelet/cm/topologymanager/scope_pod.go#L52
 	// but it works with HintProviders, takes into account all possible allocations.
-	resources := util.GetPodEffectiveRequest(pod)
+	resources := util.PodRequests(pod)
 	finalScore := scoreForEachNUMANode(lh, resources, info.numaNodes, scorerFn, resourceToWeightMap)
 	lh.V(2).Info("pod scope scoring final node score", "finalScore", finalScore)
 	return finalScore, nil
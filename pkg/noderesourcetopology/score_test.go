@@ -332,9 +332,9 @@ func TestNodeResourceScorePluginLeastNUMA(t *testing.T) {
 				},
 			},
 			wantedRes: nodeToScoreMap{
-				"Node1": 94,
-				"Node2": 82,
-				"Node3": 82,
+				"Node1": 99,
+				"Node2": 79,
+				"Node3": 77,
 			},
 			nodes: defaultNUMANodes(withPolicy(topologyv1alpha2.BestEffortPodLevel)),
 		},
@@ -351,9 +351,9 @@ func TestNodeResourceScorePluginLeastNUMA(t *testing.T) {
 				},
 			},
 			wantedRes: nodeToScoreMap{
-				"Node1": 94,
-				"Node2": 82,
-				"Node3": 82,
+				"Node1": 99,
+				"Node2": 79,
+				"Node3": 77,
 			},
 			nodes: defaultNUMANodes(withPolicy(topologyv1alpha2.BestEffortPodLevel)),
 		},
@@ -370,9 +370,9 @@ func TestNodeResourceScorePluginLeastNUMA(t *testing.T) {
 				},
 			},
 			wantedRes: nodeToScoreMap{
-				"Node1": 82,
+				"Node1": 79,
 				"Node2": 0,
-				"Node3": 82,
+				"Node3": 78,
 			},
 			nodes: defaultNUMANodes(withPolicy(topologyv1alpha2.BestEffortPodLevel)),
 		},
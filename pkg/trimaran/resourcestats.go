@@ -22,6 +22,8 @@ import (
 	"k8s.io/klog/v2"
 	fwk "k8s.io/kube-scheduler/framework"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	"sigs.k8s.io/scheduler-plugins/pkg/util"
 )
 
 const (
@@ -108,19 +110,19 @@ func GetResourceData(metrics []watcher.Metric, resourceType string) (avg float64
 
 // GetResourceRequested : calculate the resource requests of a pod (CPU and Memory)
 func GetResourceRequested(pod *v1.Pod) *framework.Resource {
-	return GetEffectiveResource(pod, func(container *v1.Container) v1.ResourceList {
-		return container.Resources.Requests
-	})
+	return framework.NewResource(util.PodRequests(pod))
 }
 
-// GetResourceLimits : calculate the resource limits of a pod (CPU and Memory)
+// GetResourceLimits : calculate the resource limits of a pod (CPU and Memory). The pod overhead is only
+// added to the resources with a limit.
 func GetResourceLimits(pod *v1.Pod) *framework.Resource {
-	return GetEffectiveResource(pod, func(container *v1.Container) v1.ResourceList {
-		return container.Resources.Limits
-	})
+	return framework.NewResource(util.PodLimits(pod))
 }
 
 // GetEffectiveResource : calculate effective resources of a pod (CPU and Memory)
+//
+// Deprecated: use GetResourceRequested or GetResourceLimits, which account for sidecar containers,
+// pod-level resources and in-place resize.
 func GetEffectiveResource(pod *v1.Pod, fn func(container *v1.Container) v1.ResourceList) *framework.Resource {
 	result := &framework.Resource{}
 	// add up resources of all containers
@@ -0,0 +1,179 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"slices"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"sigs.k8s.io/scheduler-plugins/apis/config"
+	"sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
+)
+
+// PodCountsTowardsElasticQuota returns whether the pod counts towards the usage of its ElasticQuota
+// under the given accounting policy. Terminated pods never count.
+func PodCountsTowardsElasticQuota(pod *v1.Pod, policy config.ElasticQuotaAccountingPolicy) bool {
+	if pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+		return false
+	}
+	if policy == config.ElasticQuotaAccountingRunning {
+		return pod.Status.Phase == v1.PodRunning
+	}
+	return pod.Spec.NodeName != ""
+}
+
+// ElasticQuotaBorrowed returns, for each resource used over the min, the part of the usage above it: what
+// the quota borrows from the others and may have to give back. A resource missing from the min has a min
+// of zero, the same as the CapacityScheduling plugin tells whether a quota is over its min. It returns nil
+// if the usage is within the min.
+func ElasticQuotaBorrowed(used, min v1.ResourceList) v1.ResourceList {
+	return positiveDifference(used, min)
+}
+
+// ElasticQuotaIdle returns, for each resource of the min, the part of it left unused, which other quotas
+// may borrow. It returns nil if the whole min is used.
+func ElasticQuotaIdle(used, min v1.ResourceList) v1.ResourceList {
+	return positiveDifference(min, used)
+}
+
+// positiveDifference returns, for each resource of a, what exceeds the quantity of b, if anything.
+func positiveDifference(a, b v1.ResourceList) v1.ResourceList {
+	var diff v1.ResourceList
+	for name, quant := range a {
+		over := quant.DeepCopy()
+		over.Sub(b[name])
+		if over.Sign() <= 0 {
+			continue
+		}
+		if diff == nil {
+			diff = v1.ResourceList{}
+		}
+		diff[name] = over
+	}
+	return diff
+}
+
+// ElasticQuotaHasSelector returns whether the ElasticQuota only applies to the pods of its namespace
+// selected by labels or priority class.
+func ElasticQuotaHasSelector(eq *v1alpha1.ElasticQuota) bool {
+	return eq.Spec.Selector != nil || len(eq.Spec.PriorityClassNames) != 0
+}
+
+// ElasticQuotaMatchesPod returns whether the pod matches the selector and the priority classes of the
+// ElasticQuota, regardless of their namespaces. An invalid selector matches nothing.
+func ElasticQuotaMatchesPod(eq *v1alpha1.ElasticQuota, pod *v1.Pod) bool {
+	if len(eq.Spec.PriorityClassNames) != 0 && !slices.Contains(eq.Spec.PriorityClassNames, pod.Spec.PriorityClassName) {
+		return false
+	}
+	if eq.Spec.Selector == nil {
+		return true
+	}
+	selector, err := metav1.LabelSelectorAsSelector(eq.Spec.Selector)
+	if err != nil {
+		return false
+	}
+	return selector.Matches(labels.Set(pod.Labels))
+}
+
+// FindElasticQuota returns the ElasticQuota the pod counts against among the given ElasticQuotas of its
+// namespace: the first one by name with a selector matching the pod, or else the one without selector.
+func FindElasticQuota(eqs []v1alpha1.ElasticQuota, pod *v1.Pod) *v1alpha1.ElasticQuota {
+	return FindElasticQuotaIn(eqs, pod.Namespace, pod)
+}
+
+// FindElasticQuotaIn is FindElasticQuota among the ElasticQuotas of the given namespace rather than of
+// the namespace of the pod, for the members of a PodGroup of another namespace.
+func FindElasticQuotaIn(eqs []v1alpha1.ElasticQuota, namespace string, pod *v1.Pod) *v1alpha1.ElasticQuota {
+	var found, fallback *v1alpha1.ElasticQuota
+	for i := range eqs {
+		eq := &eqs[i]
+		if eq.Namespace != namespace {
+			continue
+		}
+		if !ElasticQuotaHasSelector(eq) {
+			if fallback == nil || eq.Name < fallback.Name {
+				fallback = eq
+			}
+		} else if ElasticQuotaMatchesPod(eq, pod) && (found == nil || eq.Name < found.Name) {
+			found = eq
+		}
+	}
+	if found != nil {
+		return found
+	}
+	return fallback
+}
+
+// ElasticQuotasOverlap returns whether a pod of their namespace may match both ElasticQuotas. Quotas
+// without selector only overlap each other, since a quota with a selector takes precedence. Selectors
+// are deemed to overlap unless a label key or the priority classes tell them apart.
+func ElasticQuotasOverlap(a, b *v1alpha1.ElasticQuota) bool {
+	if a.Namespace != b.Namespace {
+		return false
+	}
+	if ElasticQuotaHasSelector(a) != ElasticQuotaHasSelector(b) {
+		return false
+	}
+	if len(a.Spec.PriorityClassNames) != 0 && len(b.Spec.PriorityClassNames) != 0 &&
+		!sets.New(a.Spec.PriorityClassNames...).HasAny(b.Spec.PriorityClassNames...) {
+		return false
+	}
+	if a.Spec.Selector == nil || b.Spec.Selector == nil {
+		return true
+	}
+	sa, err := metav1.LabelSelectorAsSelector(a.Spec.Selector)
+	if err != nil {
+		return false
+	}
+	sb, err := metav1.LabelSelectorAsSelector(b.Spec.Selector)
+	if err != nil {
+		return false
+	}
+	ra, _ := sa.Requirements()
+	rb, _ := sb.Requirements()
+	for _, x := range ra {
+		for _, y := range rb {
+			if x.Key() == y.Key() && (requirementsExclude(x, y) || requirementsExclude(y, x)) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// requirementsExclude returns whether no value of the label of both requirements satisfies both.
+func requirementsExclude(x, y labels.Requirement) bool {
+	switch x.Operator() {
+	case selection.In, selection.Equals, selection.DoubleEquals:
+		switch y.Operator() {
+		case selection.In, selection.Equals, selection.DoubleEquals:
+			return !sets.New(x.ValuesUnsorted()...).HasAny(y.ValuesUnsorted()...)
+		case selection.NotIn, selection.NotEquals:
+			return sets.New(y.ValuesUnsorted()...).IsSuperset(sets.New(x.ValuesUnsorted()...))
+		case selection.DoesNotExist:
+			return true
+		}
+	case selection.Exists:
+		return y.Operator() == selection.DoesNotExist
+	}
+	return false
+}
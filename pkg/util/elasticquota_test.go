@@ -0,0 +1,262 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/scheduler-plugins/apis/config"
+	"sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
+)
+
+func makeElasticQuota(name string, selector *metav1.LabelSelector, priorityClassNames ...string) v1alpha1.ElasticQuota {
+	return v1alpha1.ElasticQuota{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: name},
+		Spec:       v1alpha1.ElasticQuotaSpec{Selector: selector, PriorityClassNames: priorityClassNames},
+	}
+}
+
+func TestFindElasticQuota(t *testing.T) {
+	eqs := []v1alpha1.ElasticQuota{
+		makeElasticQuota("default", nil),
+		makeElasticQuota("training", &metav1.LabelSelector{MatchLabels: map[string]string{"app": "training"}}),
+		makeElasticQuota("critical", nil, "critical"),
+		makeElasticQuota("batch", &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "batch"}}),
+	}
+	tests := []struct {
+		name              string
+		labels            map[string]string
+		priorityClassName string
+		namespace         string
+		expected          string
+	}{
+		{
+			name:     "pod selected by no quota counts against the quota without selector",
+			labels:   map[string]string{"app": "web"},
+			expected: "default",
+		},
+		{
+			name:     "pod selected by labels",
+			labels:   map[string]string{"app": "training"},
+			expected: "training",
+		},
+		{
+			name:              "pod selected by priority class",
+			priorityClassName: "critical",
+			expected:          "critical",
+		},
+		{
+			name:              "pod selected by several quotas counts against the first one by name",
+			labels:            map[string]string{"app": "training", "tier": "batch"},
+			priorityClassName: "critical",
+			expected:          "batch",
+		},
+		{
+			name:      "pod of another namespace",
+			labels:    map[string]string{"app": "training"},
+			namespace: "other",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Labels: tt.labels},
+				Spec:       v1.PodSpec{PriorityClassName: tt.priorityClassName},
+			}
+			if tt.namespace != "" {
+				pod.Namespace = tt.namespace
+			}
+			got := ""
+			if eq := FindElasticQuota(eqs, pod); eq != nil {
+				got = eq.Name
+			}
+			if got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestElasticQuotasOverlap(t *testing.T) {
+	labelSelector := func(key string, op metav1.LabelSelectorOperator, values ...string) *metav1.LabelSelector {
+		return &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: key, Operator: op, Values: values}}}
+	}
+	tests := []struct {
+		name     string
+		a, b     v1alpha1.ElasticQuota
+		expected bool
+	}{
+		{
+			name:     "quotas without selector",
+			a:        makeElasticQuota("a", nil),
+			b:        makeElasticQuota("b", nil),
+			expected: true,
+		},
+		{
+			name: "quota with selector and quota without selector",
+			a:    makeElasticQuota("a", labelSelector("app", metav1.LabelSelectorOpIn, "x")),
+			b:    makeElasticQuota("b", nil),
+		},
+		{
+			name: "disjoint values of a label",
+			a:    makeElasticQuota("a", labelSelector("app", metav1.LabelSelectorOpIn, "x", "y")),
+			b:    makeElasticQuota("b", labelSelector("app", metav1.LabelSelectorOpIn, "z")),
+		},
+		{
+			name:     "common value of a label",
+			a:        makeElasticQuota("a", labelSelector("app", metav1.LabelSelectorOpIn, "x", "y")),
+			b:        makeElasticQuota("b", labelSelector("app", metav1.LabelSelectorOpIn, "y")),
+			expected: true,
+		},
+		{
+			name: "value excluded by the other quota",
+			a:    makeElasticQuota("a", labelSelector("app", metav1.LabelSelectorOpIn, "x")),
+			b:    makeElasticQuota("b", labelSelector("app", metav1.LabelSelectorOpNotIn, "x")),
+		},
+		{
+			name: "label required and forbidden",
+			a:    makeElasticQuota("a", labelSelector("app", metav1.LabelSelectorOpExists)),
+			b:    makeElasticQuota("b", labelSelector("app", metav1.LabelSelectorOpDoesNotExist)),
+		},
+		{
+			name:     "different labels",
+			a:        makeElasticQuota("a", labelSelector("app", metav1.LabelSelectorOpIn, "x")),
+			b:        makeElasticQuota("b", labelSelector("tier", metav1.LabelSelectorOpIn, "y")),
+			expected: true,
+		},
+		{
+			name: "disjoint priority classes",
+			a:    makeElasticQuota("a", nil, "high"),
+			b:    makeElasticQuota("b", labelSelector("app", metav1.LabelSelectorOpIn, "x"), "low"),
+		},
+		{
+			name:     "common priority class",
+			a:        makeElasticQuota("a", nil, "high", "low"),
+			b:        makeElasticQuota("b", nil, "low"),
+			expected: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ElasticQuotasOverlap(&tt.a, &tt.b); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+			if got := ElasticQuotasOverlap(&tt.b, &tt.a); got != tt.expected {
+				t.Errorf("expected %v in reverse order, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestPodCountsTowardsElasticQuota(t *testing.T) {
+	tests := []struct {
+		name     string
+		phase    v1.PodPhase
+		nodeName string
+		bound    bool
+		running  bool
+	}{
+		{
+			name:  "pending pod",
+			phase: v1.PodPending,
+		},
+		{
+			name:     "bound pending pod",
+			phase:    v1.PodPending,
+			nodeName: "node-a",
+			bound:    true,
+		},
+		{
+			name:     "running pod",
+			phase:    v1.PodRunning,
+			nodeName: "node-a",
+			bound:    true,
+			running:  true,
+		},
+		{
+			name:     "succeeded pod",
+			phase:    v1.PodSucceeded,
+			nodeName: "node-a",
+		},
+		{
+			name:     "failed pod",
+			phase:    v1.PodFailed,
+			nodeName: "node-a",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &v1.Pod{Spec: v1.PodSpec{NodeName: tt.nodeName}, Status: v1.PodStatus{Phase: tt.phase}}
+			if got := PodCountsTowardsElasticQuota(pod, config.ElasticQuotaAccountingBoundNonTerminal); got != tt.bound {
+				t.Errorf("expected %v with BoundNonTerminal, got %v", tt.bound, got)
+			}
+			if got := PodCountsTowardsElasticQuota(pod, config.ElasticQuotaAccountingRunning); got != tt.running {
+				t.Errorf("expected %v with Running, got %v", tt.running, got)
+			}
+		})
+	}
+}
+
+func TestElasticQuotaBorrowedAndIdle(t *testing.T) {
+	tests := []struct {
+		name     string
+		used     v1.ResourceList
+		min      v1.ResourceList
+		borrowed v1.ResourceList
+		idle     v1.ResourceList
+	}{
+		{
+			name: "within min",
+			used: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1"), v1.ResourceMemory: resource.MustParse("1Gi")},
+			min:  v1.ResourceList{v1.ResourceCPU: resource.MustParse("3"), v1.ResourceMemory: resource.MustParse("1Gi")},
+			idle: v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")},
+		},
+		{
+			name:     "over min",
+			used:     v1.ResourceList{v1.ResourceCPU: resource.MustParse("4"), v1.ResourceMemory: resource.MustParse("512Mi")},
+			min:      v1.ResourceList{v1.ResourceCPU: resource.MustParse("3"), v1.ResourceMemory: resource.MustParse("1Gi")},
+			borrowed: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")},
+			idle:     v1.ResourceList{v1.ResourceMemory: resource.MustParse("512Mi")},
+		},
+		{
+			name:     "resource missing from min",
+			used:     v1.ResourceList{v1.ResourceCPU: resource.MustParse("1"), "nvidia.com/gpu": resource.MustParse("2")},
+			min:      v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")},
+			borrowed: v1.ResourceList{"nvidia.com/gpu": resource.MustParse("2")},
+		},
+		{
+			name: "no usage",
+			min:  v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")},
+			idle: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ElasticQuotaBorrowed(tt.used, tt.min); !apiequality.Semantic.DeepEqual(got, tt.borrowed) {
+				t.Errorf("expected borrowed %v, got %v", tt.borrowed, got)
+			}
+			if got := ElasticQuotaIdle(tt.used, tt.min); !apiequality.Semantic.DeepEqual(got, tt.idle) {
+				t.Errorf("expected idle %v, got %v", tt.idle, got)
+			}
+		})
+	}
+}
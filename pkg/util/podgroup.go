@@ -19,9 +19,13 @@ package util
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/strategicpatch"
 
 	"sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
@@ -52,13 +56,37 @@ func GetPodGroupLabel(pod *v1.Pod) string {
 	return pod.Labels[v1alpha1.PodGroupLabel]
 }
 
+// GetPodGroupNamespace get the namespace of the pod group from pod labels, which defaults to
+// the namespace of the pod
+func GetPodGroupNamespace(pod *v1.Pod) string {
+	if ns := pod.Labels[v1alpha1.PodGroupNamespaceLabel]; len(ns) != 0 {
+		return ns
+	}
+	return pod.Namespace
+}
+
 // GetPodGroupFullName get namespaced group name from pod labels
 func GetPodGroupFullName(pod *v1.Pod) string {
 	pgName := GetPodGroupLabel(pod)
 	if len(pgName) == 0 {
 		return ""
 	}
-	return fmt.Sprintf("%v/%v", pod.Namespace, pgName)
+	return fmt.Sprintf("%v/%v", GetPodGroupNamespace(pod), pgName)
+}
+
+// AllowsPodGroupNamespace returns whether the pods of the given namespace may belong to pod groups
+// of pgNamespace, which is the case for its own pod groups and those of the namespaces listed in
+// its allowed-pod-group-namespaces annotation.
+func AllowsPodGroupNamespace(ns *v1.Namespace, pgNamespace string) bool {
+	if ns.Name == pgNamespace {
+		return true
+	}
+	for _, allowed := range strings.Split(ns.Annotations[v1alpha1.AllowedPodGroupNamespacesAnnotation], ",") {
+		if strings.TrimSpace(allowed) == pgNamespace {
+			return true
+		}
+	}
+	return false
 }
 
 // GetWaitTimeDuration returns a wait timeout based on the following precedences:
@@ -74,3 +102,90 @@ func GetWaitTimeDuration(pg *v1alpha1.PodGroup, scheduleTimeout *time.Duration)
 	}
 	return DefaultWaitTime
 }
+
+// CheckPodGroupRoles checks whether the given pods, expected to be members of pg,
+// meet the minMember of every role of pg. It returns an error describing the first
+// role whose quorum is not met, or whose selector is invalid; nil otherwise.
+func CheckPodGroupRoles(pg *v1alpha1.PodGroup, pods []*v1.Pod) error {
+	for _, role := range pg.Spec.Roles {
+		selector := labels.Everything()
+		if role.Selector != nil {
+			var err error
+			if selector, err = metav1.LabelSelectorAsSelector(role.Selector); err != nil {
+				return fmt.Errorf("invalid selector of role %v: %w", role.Name, err)
+			}
+		}
+		var matched int32
+		for _, pod := range pods {
+			if selector.Matches(labels.Set(pod.Labels)) {
+				matched++
+			}
+		}
+		if matched < role.MinMember {
+			return fmt.Errorf("role %v has %v pods, minMember of role: %v", role.Name, matched, role.MinMember)
+		}
+	}
+	return nil
+}
+
+// GetScaleUpWindowEnd returns when the scale-up window of pg closes, i.e. scaleUpWindowSeconds after
+// the minMember-th of the given pods, expected to be members of pg, was scheduled. It returns false
+// if pg has no scale-up window or fewer than minMember pods were scheduled yet.
+func GetScaleUpWindowEnd(pg *v1alpha1.PodGroup, pods []*v1.Pod) (time.Time, bool) {
+	if pg.Spec.ScaleUpWindowSeconds == nil || pg.Spec.MinMember < 1 {
+		return time.Time{}, false
+	}
+	var scheduled []time.Time
+	for _, pod := range pods {
+		if t, ok := getScheduledTime(pod); ok {
+			scheduled = append(scheduled, t)
+		}
+	}
+	if len(scheduled) < int(pg.Spec.MinMember) {
+		return time.Time{}, false
+	}
+	sort.Slice(scheduled, func(i, j int) bool {
+		return scheduled[i].Before(scheduled[j])
+	})
+	return scheduled[pg.Spec.MinMember-1].Add(time.Duration(*pg.Spec.ScaleUpWindowSeconds) * time.Second), true
+}
+
+// GetElasticMemberCounts returns the number of members/tasks pg asks for, i.e. the given pods which
+// are not terminated up to the maxMember of pg, and how many of them are bound to a node.
+func GetElasticMemberCounts(pg *v1alpha1.PodGroup, pods []*v1.Pod) (desired, admitted int32) {
+	for _, pod := range pods {
+		if IsPodTerminated(pod) {
+			continue
+		}
+		desired++
+		if pod.Spec.NodeName != "" {
+			admitted++
+		}
+	}
+	if pg.Spec.MaxMember != nil && desired > *pg.Spec.MaxMember {
+		desired = *pg.Spec.MaxMember
+	}
+	return desired, admitted
+}
+
+// IsPodTerminated returns whether the given pod reached phase Succeeded or Failed.
+func IsPodTerminated(pod *v1.Pod) bool {
+	return pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed
+}
+
+// getScheduledTime returns when the given pod was bound to a node, as recorded by its PodScheduled
+// condition or, failing that, its start time.
+func getScheduledTime(pod *v1.Pod) (time.Time, bool) {
+	if pod.Spec.NodeName == "" {
+		return time.Time{}, false
+	}
+	for _, c := range pod.Status.Conditions {
+		if c.Type == v1.PodScheduled && c.Status == v1.ConditionTrue && !c.LastTransitionTime.IsZero() {
+			return c.LastTransitionTime.Time, true
+		}
+	}
+	if pod.Status.StartTime != nil {
+		return pod.Status.StartTime.Time, true
+	}
+	return time.Time{}, false
+}
@@ -18,8 +18,13 @@ package util
 
 import (
 	"testing"
+	"time"
 
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/kubernetes/pkg/apis/core"
+
+	"sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
 )
 
 func TestCreateMergePatch(t *testing.T) {
@@ -70,3 +75,207 @@ func TestCreateMergePatch(t *testing.T) {
 		}
 	}
 }
+
+func TestCheckPodGroupRoles(t *testing.T) {
+	makePod := func(name, role string) *v1.Pod {
+		return &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"role": role}}}
+	}
+	pods := []*v1.Pod{
+		makePod("launcher", "launcher"),
+		makePod("worker-0", "worker"),
+		makePod("worker-1", "worker"),
+	}
+	makeRole := func(name string, minMember int32, selector *metav1.LabelSelector) v1alpha1.PodGroupRole {
+		return v1alpha1.PodGroupRole{Name: name, MinMember: minMember, Selector: selector}
+	}
+	selectRole := func(role string) *metav1.LabelSelector {
+		return &metav1.LabelSelector{MatchLabels: map[string]string{"role": role}}
+	}
+
+	tests := []struct {
+		name    string
+		roles   []v1alpha1.PodGroupRole
+		pods    []*v1.Pod
+		wantErr bool
+	}{
+		{
+			name: "no roles",
+			pods: pods,
+		},
+		{
+			name:  "all roles satisfied",
+			roles: []v1alpha1.PodGroupRole{makeRole("launcher", 1, selectRole("launcher")), makeRole("worker", 2, selectRole("worker"))},
+			pods:  pods,
+		},
+		{
+			name:    "worker role not satisfied",
+			roles:   []v1alpha1.PodGroupRole{makeRole("launcher", 1, selectRole("launcher")), makeRole("worker", 3, selectRole("worker"))},
+			pods:    pods,
+			wantErr: true,
+		},
+		{
+			name:    "launcher missing",
+			roles:   []v1alpha1.PodGroupRole{makeRole("launcher", 1, selectRole("launcher")), makeRole("worker", 2, selectRole("worker"))},
+			pods:    pods[1:],
+			wantErr: true,
+		},
+		{
+			name:  "nil selector selects all pods",
+			roles: []v1alpha1.PodGroupRole{makeRole("any", 3, nil)},
+			pods:  pods,
+		},
+		{
+			name: "invalid selector",
+			roles: []v1alpha1.PodGroupRole{makeRole("bad", 0, &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "role", Operator: "Bogus"}},
+			})},
+			pods:    pods,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pg := &v1alpha1.PodGroup{Spec: v1alpha1.PodGroupSpec{Roles: tt.roles}}
+			if err := CheckPodGroupRoles(pg, tt.pods); (err != nil) != tt.wantErr {
+				t.Errorf("CheckPodGroupRoles() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetScaleUpWindowEnd(t *testing.T) {
+	now := time.Now()
+	window := int32(60)
+	scheduledAt := func(name string, ago time.Duration) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       v1.PodSpec{NodeName: "node"},
+			Status: v1.PodStatus{Conditions: []v1.PodCondition{{
+				Type:               v1.PodScheduled,
+				Status:             v1.ConditionTrue,
+				LastTransitionTime: metav1.NewTime(now.Add(-ago)),
+			}}},
+		}
+	}
+	pending := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pending"}}
+
+	tests := []struct {
+		name    string
+		window  *int32
+		pods    []*v1.Pod
+		want    time.Time
+		wantSet bool
+	}{
+		{
+			name: "no scale-up window",
+			pods: []*v1.Pod{scheduledAt("p1", time.Hour), scheduledAt("p2", time.Minute)},
+		},
+		{
+			name:   "quorum not scheduled yet",
+			window: &window,
+			pods:   []*v1.Pod{scheduledAt("p1", time.Hour), pending},
+		},
+		{
+			name:    "window starts when the quorum was scheduled",
+			window:  &window,
+			pods:    []*v1.Pod{scheduledAt("p3", time.Second), scheduledAt("p1", time.Hour), scheduledAt("p2", time.Minute), pending},
+			want:    now,
+			wantSet: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pg := &v1alpha1.PodGroup{Spec: v1alpha1.PodGroupSpec{MinMember: 2, ScaleUpWindowSeconds: tt.window}}
+			got, ok := GetScaleUpWindowEnd(pg, tt.pods)
+			if ok != tt.wantSet || !got.Equal(tt.want) {
+				t.Errorf("GetScaleUpWindowEnd() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantSet)
+			}
+		})
+	}
+}
+
+func TestGetElasticMemberCounts(t *testing.T) {
+	makePod := func(name, node string, phase v1.PodPhase) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       v1.PodSpec{NodeName: node},
+			Status:     v1.PodStatus{Phase: phase},
+		}
+	}
+	pods := []*v1.Pod{
+		makePod("p1", "node", v1.PodRunning),
+		makePod("p2", "node", v1.PodRunning),
+		makePod("p3", "", v1.PodPending),
+		makePod("p4", "", v1.PodPending),
+		makePod("p5", "node", v1.PodSucceeded),
+	}
+	maxMember := int32(3)
+
+	tests := []struct {
+		name         string
+		maxMember    *int32
+		wantDesired  int32
+		wantAdmitted int32
+	}{
+		{
+			name:         "no maxMember",
+			wantDesired:  4,
+			wantAdmitted: 2,
+		},
+		{
+			name:         "desired capped at maxMember",
+			maxMember:    &maxMember,
+			wantDesired:  3,
+			wantAdmitted: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pg := &v1alpha1.PodGroup{Spec: v1alpha1.PodGroupSpec{MinMember: 2, MaxMember: tt.maxMember}}
+			desired, admitted := GetElasticMemberCounts(pg, pods)
+			if desired != tt.wantDesired || admitted != tt.wantAdmitted {
+				t.Errorf("GetElasticMemberCounts() = %v, %v, want %v, %v", desired, admitted, tt.wantDesired, tt.wantAdmitted)
+			}
+		})
+	}
+}
+
+func TestAllowsPodGroupNamespace(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		pgNamespace string
+		want        bool
+	}{
+		{
+			name:        "own namespace",
+			pgNamespace: "ns",
+			want:        true,
+		},
+		{
+			name:        "no annotation",
+			pgNamespace: "driver",
+			want:        false,
+		},
+		{
+			name:        "listed namespace",
+			annotations: map[string]string{v1alpha1.AllowedPodGroupNamespacesAnnotation: "spark, driver"},
+			pgNamespace: "driver",
+			want:        true,
+		},
+		{
+			name:        "unlisted namespace",
+			annotations: map[string]string{v1alpha1.AllowedPodGroupNamespacesAnnotation: "spark,driver-2"},
+			pgNamespace: "driver",
+			want:        false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ns := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns", Annotations: tt.annotations}}
+			if got := AllowsPodGroupNamespace(ns, tt.pgNamespace); got != tt.want {
+				t.Errorf("AllowsPodGroupNamespace() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	v1 "k8s.io/api/core/v1"
+	utilfeature "k8s.io/apiserver/pkg/util/feature"
+	resourcehelper "k8s.io/component-helpers/resource"
+	"k8s.io/kubernetes/pkg/features"
+)
+
+// PodRequests returns the requests of the pod as the kubelet admits it: the larger of the sum of its
+// containers and sidecars, and of each init container along with the sidecars started before it, plus
+// its overhead. The pod-level requests, if any, take precedence over the ones of the containers.
+//
+// While the pod is resized in place, the request of each container is the largest of its desired,
+// allocated and actuated ones, the same as the scheduler's, so that the capacity released by a resize
+// down only counts as free once the kubelet has actuated it.
+func PodRequests(pod *v1.Pod) v1.ResourceList {
+	return resourcehelper.PodRequests(pod, podResourcesOptions())
+}
+
+// PodLimits returns the limits of the pod, computed the same way as PodRequests. The overhead of the pod
+// is only added to the resources with a limit.
+func PodLimits(pod *v1.Pod) v1.ResourceList {
+	return resourcehelper.PodLimits(pod, podResourcesOptions())
+}
+
+// podResourcesOptions returns the options of the resource helpers matching the enabled features.
+func podResourcesOptions() resourcehelper.PodResourcesOptions {
+	return resourcehelper.PodResourcesOptions{
+		UseStatusResources: utilfeature.DefaultFeatureGate.Enabled(features.InPlacePodVerticalScaling),
+		InPlacePodLevelResourcesVerticalScalingEnabled: utilfeature.DefaultFeatureGate.Enabled(features.InPlacePodLevelResourcesVerticalScaling),
+		SkipPodLevelResources:                          !utilfeature.DefaultFeatureGate.Enabled(features.PodLevelResources),
+	}
+}
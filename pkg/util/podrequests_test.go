@@ -17,10 +17,10 @@ limitations under the License.
 package util
 
 import (
-	"reflect"
 	"testing"
 
 	"k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/resource"
 )
 
@@ -31,7 +31,7 @@ func makeResourceList(cpu, mem int64) v1.ResourceList {
 	}
 }
 
-func TestGetPodEffectiveRequest(t *testing.T) {
+func TestPodRequests(t *testing.T) {
 	tests := []struct {
 		name                 string
 		containerRequest     []v1.ResourceList
@@ -142,9 +142,95 @@ func TestGetPodEffectiveRequest(t *testing.T) {
 				})
 			}
 			pod.Spec.Overhead = tt.podOverheadRequest
-			if got := GetPodEffectiveRequest(pod); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("GetPodEffectiveRequest() = %v, want %v", got, tt.want)
+			if got := PodRequests(pod); !apiequality.Semantic.DeepEqual(got, tt.want) {
+				t.Errorf("PodRequests() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
+
+func TestPodRequestsWithSidecars(t *testing.T) {
+	always := v1.ContainerRestartPolicyAlways
+	tests := []struct {
+		name           string
+		containers     []v1.Container
+		initContainers []v1.Container
+		overhead       v1.ResourceList
+		want           v1.ResourceList
+	}{
+		{
+			name: "init container larger than the containers",
+			containers: []v1.Container{
+				{Resources: v1.ResourceRequirements{Requests: makeResourceList(2, 1)}},
+				{Resources: v1.ResourceRequirements{Requests: makeResourceList(1, 1)}},
+			},
+			initContainers: []v1.Container{
+				{Resources: v1.ResourceRequirements{Requests: makeResourceList(2, 3)}},
+			},
+			want: makeResourceList(3, 3),
+		},
+		{
+			name: "sidecar runs along with the containers and the later init containers",
+			containers: []v1.Container{
+				{Resources: v1.ResourceRequirements{Requests: makeResourceList(2, 1)}},
+			},
+			initContainers: []v1.Container{
+				{Resources: v1.ResourceRequirements{Requests: makeResourceList(1, 1)}, RestartPolicy: &always},
+				{Resources: v1.ResourceRequirements{Requests: makeResourceList(1, 3)}},
+			},
+			want: makeResourceList(3, 4),
+		},
+		{
+			name: "sidecar with pod overhead",
+			containers: []v1.Container{
+				{Resources: v1.ResourceRequirements{Requests: makeResourceList(2, 1)}},
+			},
+			initContainers: []v1.Container{
+				{Resources: v1.ResourceRequirements{Requests: makeResourceList(1, 1)}, RestartPolicy: &always},
+			},
+			overhead: makeResourceList(1, 1),
+			want:     makeResourceList(4, 3),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &v1.Pod{Spec: v1.PodSpec{Containers: tt.containers, InitContainers: tt.initContainers, Overhead: tt.overhead}}
+			if got := PodRequests(pod); !apiequality.Semantic.DeepEqual(got, tt.want) {
+				t.Errorf("PodRequests() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPodRequestsWithResize(t *testing.T) {
+	pod := &v1.Pod{
+		Spec: v1.PodSpec{Containers: []v1.Container{
+			{Name: "c", Resources: v1.ResourceRequirements{Requests: makeResourceList(1, 4)}},
+		}},
+		Status: v1.PodStatus{ContainerStatuses: []v1.ContainerStatus{{
+			Name:               "c",
+			AllocatedResources: makeResourceList(3, 4),
+			Resources:          &v1.ResourceRequirements{Requests: makeResourceList(2, 2)},
+		}}},
+	}
+	// The pod is resized down from 3 to 1 CPU, the kubelet has only actuated 2 CPUs so far.
+	if got, want := PodRequests(pod), makeResourceList(3, 4); !apiequality.Semantic.DeepEqual(got, want) {
+		t.Errorf("PodRequests() = %v, want %v", got, want)
+	}
+}
+
+func TestPodLimits(t *testing.T) {
+	pod := &v1.Pod{Spec: v1.PodSpec{
+		Containers: []v1.Container{
+			{Resources: v1.ResourceRequirements{Limits: makeResourceList(2, 2)}},
+			{Resources: v1.ResourceRequirements{Limits: makeResourceList(1, 1)}},
+		},
+		InitContainers: []v1.Container{
+			{Resources: v1.ResourceRequirements{Limits: makeResourceList(4, 1)}},
+		},
+		Overhead: makeResourceList(1, 1),
+	}}
+	if got, want := PodLimits(pod), makeResourceList(5, 4); !apiequality.Semantic.DeepEqual(got, want) {
+		t.Errorf("PodLimits() = %v, want %v", got, want)
+	}
+}
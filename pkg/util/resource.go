@@ -41,45 +41,3 @@ func ResourceList(r fwk.Resource) v1.ResourceList {
 	}
 	return result
 }
-
-// GetPodEffectiveRequest gets the effective request resource of a pod to the origin resource.
-// The Pod's effective request is the higher of:
-// - the sum of all app containers(spec.Containers) request for a resource.
-// - the effective init containers(spec.InitContainers) request for a resource.
-// The effective init containers request is the highest request on all init containers.
-// Additionally, if the Pod specifies overhead, it will also be included in the final calculation.
-func GetPodEffectiveRequest(pod *v1.Pod) v1.ResourceList {
-	initResources := make(v1.ResourceList)
-	resources := make(v1.ResourceList)
-
-	for _, container := range pod.Spec.InitContainers {
-		for name, quantity := range container.Resources.Requests {
-			if q, ok := initResources[name]; ok && quantity.Cmp(q) <= 0 {
-				continue
-			}
-			initResources[name] = quantity
-		}
-	}
-	for _, container := range pod.Spec.Containers {
-		for name, quantity := range container.Resources.Requests {
-			if q, ok := resources[name]; ok {
-				quantity.Add(q)
-			}
-			resources[name] = quantity
-		}
-	}
-	for name, quantity := range initResources {
-		if q, ok := resources[name]; ok && quantity.Cmp(q) <= 0 {
-			continue
-		}
-		resources[name] = quantity
-	}
-	// Add pod overhead if present
-	for name, quantity := range pod.Spec.Overhead {
-		if q, ok := resources[name]; ok {
-			quantity.Add(q)
-		}
-		resources[name] = quantity
-	}
-	return resources
-}
@@ -69,6 +69,7 @@ func TestCapacityScheduling(t *testing.T) {
 		t.Fatal(err)
 	}
 	cfg.Profiles[0].Plugins.PreFilter.Enabled = append(cfg.Profiles[0].Plugins.PreFilter.Enabled, schedapi.Plugin{Name: capacityscheduling.Name})
+	cfg.Profiles[0].Plugins.Filter.Enabled = append(cfg.Profiles[0].Plugins.Filter.Enabled, schedapi.Plugin{Name: capacityscheduling.Name})
 	cfg.Profiles[0].Plugins.PostFilter = schedapi.PluginSet{
 		Enabled:  []schedapi.Plugin{{Name: capacityscheduling.Name}},
 		Disabled: []schedapi.Plugin{{Name: "*"}},
@@ -122,11 +122,31 @@ func (p *podWrapper) InitContainerRequest(request v1.ResourceList) *podWrapper {
 	return p
 }
 
+func (p *podWrapper) SidecarRequest(request v1.ResourceList) *podWrapper {
+	always := v1.ContainerRestartPolicyAlways
+	p.InitContainerRequest(request)
+	p.Pod.Spec.InitContainers[len(p.Pod.Spec.InitContainers)-1].RestartPolicy = &always
+	return p
+}
+
 func (p *podWrapper) Node(name string) *podWrapper {
 	p.Pod.Spec.NodeName = name
 	return p
 }
 
+func (p *podWrapper) Label(key, value string) *podWrapper {
+	if p.Pod.Labels == nil {
+		p.Pod.Labels = map[string]string{}
+	}
+	p.Pod.Labels[key] = value
+	return p
+}
+
+func (p *podWrapper) Priority(priority int32) *podWrapper {
+	p.Pod.Spec.Priority = &priority
+	return p
+}
+
 func (p *podWrapper) Obj() *v1.Pod {
 	return p.Pod
 }
@@ -153,11 +173,36 @@ func (e *eqWrapper) Max(max v1.ResourceList) *eqWrapper {
 	return e
 }
 
+func (e *eqWrapper) Selector(matchLabels map[string]string) *eqWrapper {
+	e.ElasticQuota.Spec.Selector = &metav1.LabelSelector{MatchLabels: matchLabels}
+	return e
+}
+
+func (e *eqWrapper) Parent(namespace, name string) *eqWrapper {
+	e.ElasticQuota.Spec.Parent = &v1alpha1.ElasticQuotaReference{Namespace: namespace, Name: name}
+	return e
+}
+
 func (e *eqWrapper) Used(used v1.ResourceList) *eqWrapper {
 	e.ElasticQuota.Status.Used = used
 	return e
 }
 
+func (e *eqWrapper) Borrowed(borrowed v1.ResourceList) *eqWrapper {
+	e.ElasticQuota.Status.Borrowed = borrowed
+	return e
+}
+
+func (e *eqWrapper) LentOut(lentOut v1.ResourceList) *eqWrapper {
+	e.ElasticQuota.Status.LentOut = lentOut
+	return e
+}
+
+func (e *eqWrapper) PodsAtRisk(names ...string) *eqWrapper {
+	e.ElasticQuota.Status.PodsAtRisk = names
+	return e
+}
+
 func (e *eqWrapper) Obj() *v1alpha1.ElasticQuota {
 	return e.ElasticQuota
 }
@@ -21,6 +21,7 @@ import (
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
 )
@@ -50,6 +51,16 @@ func (p *PodGroupWrapper) MinMember(i int32) *PodGroupWrapper {
 	return p
 }
 
+func (p *PodGroupWrapper) MaxMember(i int32) *PodGroupWrapper {
+	p.Spec.MaxMember = &i
+	return p
+}
+
+func (p *PodGroupWrapper) ScaleUpWindowSeconds(i int32) *PodGroupWrapper {
+	p.Spec.ScaleUpWindowSeconds = &i
+	return p
+}
+
 func (p *PodGroupWrapper) Time(t time.Time) *PodGroupWrapper {
 	p.CreationTimestamp.Time = t
 	return p
@@ -68,3 +79,18 @@ func (p *PodGroupWrapper) Phase(phase v1alpha1.PodGroupPhase) *PodGroupWrapper {
 	p.Status.Phase = phase
 	return p
 }
+
+// Role appends a role whose members are selected by the given labels; nil selects all the pods of the group.
+func (p *PodGroupWrapper) Role(name string, minMember int32, matchLabels map[string]string) *PodGroupWrapper {
+	role := v1alpha1.PodGroupRole{Name: name, MinMember: minMember}
+	if matchLabels != nil {
+		role.Selector = &metav1.LabelSelector{MatchLabels: matchLabels}
+	}
+	p.Spec.Roles = append(p.Spec.Roles, role)
+	return p
+}
+
+func (p *PodGroupWrapper) TopologyConstraint(key string, mode v1alpha1.TopologyConstraintMode) *PodGroupWrapper {
+	p.Spec.TopologyConstraint = &v1alpha1.PodGroupTopologyConstraint{TopologyKey: key, Mode: mode}
+	return p
+}